@@ -5,6 +5,7 @@ import (
 	"io"
 
 	azUtils "github.com/kosli-dev/cli/internal/azure"
+	"github.com/kosli-dev/cli/internal/prcache"
 	"github.com/spf13/cobra"
 )
 
@@ -59,7 +60,7 @@ func newAssertPullRequestAzureCmd(out io.Writer) *cobra.Command {
 }
 
 func (o *assertPullRequestAzureOptions) run(args []string) error {
-	pullRequestsEvidence, err := o.azureConfig.PREvidenceForCommitV1(o.commit)
+	pullRequestsEvidence, err := prcache.Wrap(o.azureConfig).PREvidenceForCommitV1(o.commit)
 	if err != nil {
 		return err
 	}
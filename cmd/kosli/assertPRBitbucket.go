@@ -5,6 +5,7 @@ import (
 	"io"
 
 	bbUtils "github.com/kosli-dev/cli/internal/bitbucket"
+	"github.com/kosli-dev/cli/internal/prcache"
 	"github.com/spf13/cobra"
 )
 
@@ -82,7 +83,7 @@ func newAssertPullRequestBitbucketCmd(out io.Writer) *cobra.Command {
 }
 
 func (o *assertPullRequestBitbucketOptions) run(args []string) error {
-	pullRequestsEvidence, err := o.bbConfig.PREvidenceForCommitV1(o.commit)
+	pullRequestsEvidence, err := prcache.Wrap(o.bbConfig).PREvidenceForCommitV1(o.commit)
 	if err != nil {
 		return err
 	}
@@ -5,6 +5,7 @@ import (
 	"io"
 
 	ghUtils "github.com/kosli-dev/cli/internal/github"
+	"github.com/kosli-dev/cli/internal/prcache"
 	"github.com/kosli-dev/cli/internal/types"
 	"github.com/spf13/cobra"
 )
@@ -41,6 +42,9 @@ func newAssertPullRequestGithubCmd(out io.Writer) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			o.retriever = ghUtils.NewGithubRetrieverFunc(githubFlagsValues.Token, githubFlagsValues.BaseURL,
 				githubFlagsValues.Org, githubFlagsValues.Repository, global.Debug)
+			if err := useGithubAuth(o.retriever, githubFlagsValues); err != nil {
+				return err
+			}
 			return o.run(args)
 		},
 	}
@@ -50,7 +54,7 @@ func newAssertPullRequestGithubCmd(out io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&o.commit, "commit", DefaultValueForCommit(ci, true), commitPREvidenceFlag)
 	addDryRunFlag(cmd)
 
-	err := RequireFlags(cmd, []string{"github-token", "github-org", "commit", "repository"})
+	err := RequireFlags(cmd, []string{"github-org", "commit", "repository"})
 	if err != nil {
 		logger.Error("failed to configure required flags: %v", err)
 	}
@@ -59,7 +63,7 @@ func newAssertPullRequestGithubCmd(out io.Writer) *cobra.Command {
 }
 
 func (o *assertPullRequestGithubOptions) run(args []string) error {
-	pullRequestsEvidence, err := o.retriever.PREvidenceForCommitHybrid(o.commit)
+	pullRequestsEvidence, err := prcache.Wrap(o.retriever).PREvidenceForCommitHybrid(o.commit)
 	if err != nil {
 		return err
 	}
@@ -5,6 +5,7 @@ import (
 	"io"
 
 	gitlabUtils "github.com/kosli-dev/cli/internal/gitlab"
+	"github.com/kosli-dev/cli/internal/prcache"
 	"github.com/spf13/cobra"
 )
 
@@ -58,7 +59,7 @@ func newAssertPullRequestGitlabCmd(out io.Writer) *cobra.Command {
 }
 
 func (o *assertPullRequestGitlabOptions) run(args []string) error {
-	pullRequestsEvidence, err := o.gitlabConfig.PREvidenceForCommitV2(o.commit)
+	pullRequestsEvidence, err := prcache.Wrap(o.gitlabConfig).PREvidenceForCommitV2(o.commit)
 	if err != nil {
 		return err
 	}
@@ -21,7 +21,10 @@ func newAttestCmd(out io.Writer) *cobra.Command {
 		newAttestGenericCmd(out),
 		newAttestSnykCmd(out),
 		newAttestJunitCmd(out),
+		newAttestCoverageCmd(out),
 		newAttestJiraCmd(out),
+		newAttestIssueCmd(out),
+		newAttestRepoSettingsCmd(out),
 		newAttestPRCmd(out),
 		newAttestSonarCmd(out),
 		newAttestCustomCmd(out),
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/kosli-dev/cli/internal/coverage"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/spf13/cobra"
+)
+
+type CoverageAttestationPayload struct {
+	*CommonAttestationPayload
+	Compliant       bool              `json:"is_compliant"`
+	MinLineCoverage *float64          `json:"min_line_coverage,omitempty"`
+	CoverageResults *coverage.Results `json:"coverage_results"`
+}
+
+type attestCoverageOptions struct {
+	*CommonAttestationOptions
+	coverageFile      string
+	format            string
+	minLineCoverage   float64
+	uploadResultsFile bool
+	assert            bool
+	payload           CoverageAttestationPayload
+}
+
+const attestCoverageShortDesc = `Report a code coverage attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestCoverageLongDesc = attestCoverageShortDesc + `
+The coverage report given with ^--coverage-file^ is parsed and its line and branch coverage totals
+are reported, together with a breakdown per package. Supported formats are ^cobertura^ (Cobertura XML,
+as written by coverage.py, gcovr, Istanbul or coverlet), ^lcov^ (LCOV tracefiles), ^jacoco^ (JaCoCo XML)
+and ^go^ (Go coverage profiles from ^go test -coverprofile^). By default the format is detected from the
+content of the file. Go measures statement coverage, which is reported as the line coverage, and does not
+record branch coverage.
+
+When ^--min-line-coverage^ is given, the attestation is compliant only if the line coverage is at least
+that percentage. Otherwise the attestation is always compliant.
+The coverage report is uploaded as an attachment unless ^--upload-results=false^ is given.
+` + attestationBindingDesc + `
+
+` + commitDescription
+
+const attestCoverageExample = `
+# report a coverage attestation about a pre-built docker artifact (kosli calculates the fingerprint):
+kosli attest coverage yourDockerImageName \
+	--artifact-type docker \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--coverage-file yourCoverageReportFile \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a coverage attestation about a trail:
+kosli attest coverage \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--coverage-file coverage.xml \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a coverage attestation about a trail from a Go coverage profile,
+# which is non-compliant below 80% coverage:
+kosli attest coverage \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--coverage-file coverage.out \
+	--format go \
+	--min-line-coverage 80 \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a coverage attestation about an artifact which has not been reported yet in a trail:
+kosli attest coverage \
+	--name yourTemplateArtifactName.yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--commit yourArtifactGitCommit \
+	--coverage-file lcov.info \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+func newAttestCoverageCmd(out io.Writer) *cobra.Command {
+	o := &attestCoverageOptions{
+		CommonAttestationOptions: &CommonAttestationOptions{
+			fingerprintOptions: &fingerprintOptions{},
+		},
+		payload: CoverageAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{},
+		},
+	}
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1),  // See CustomMaximumNArgs() below
+		Use:     "coverage [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Short:   attestCoverageShortDesc,
+		Long:    attestCoverageLongDesc,
+		Example: attestCoverageExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			err := CustomMaximumNArgs(1, args)
+			if err != nil {
+				return err
+			}
+
+			err = RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
+			if err != nil {
+				return err
+			}
+
+			err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
+			if err != nil {
+				return fmt.Errorf("%s for --redact-commit-info", err.Error())
+			}
+
+			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			if _, err := coverage.ParseFormat(o.format); err != nil {
+				return fmt.Errorf("%s for --format", err.Error())
+			}
+
+			if cmd.Flags().Changed("min-line-coverage") {
+				if o.minLineCoverage < 0 || o.minLineCoverage > 100 {
+					return fmt.Errorf("--min-line-coverage must be a percentage between 0 and 100, got %v", o.minLineCoverage)
+				}
+				o.payload.MinLineCoverage = &o.minLineCoverage
+			}
+
+			return ValidateRegistryFlags(cmd, o.fingerprintOptions)
+
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.repoURLExplicit = cmd.Flags().Changed("repo-url")
+			o.repoNameExplicit = cmd.Flags().Changed("repository")
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	cmd.Flags().StringVar(&o.coverageFile, "coverage-file", "", coverageFileFlag)
+	cmd.Flags().StringVar(&o.format, "format", string(coverage.FormatAuto), coverageFormatFlag)
+	cmd.Flags().Float64Var(&o.minLineCoverage, "min-line-coverage", 0, minLineCoverageFlag)
+	cmd.Flags().BoolVar(&o.uploadResultsFile, "upload-results", true, uploadCoverageResultsFlag)
+	cmd.Flags().BoolVar(&o.assert, "assert", false, attestationAssertFlag)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name", "coverage-file"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+func (o *attestCoverageOptions) run(args []string) error {
+	url, err := url.JoinPath(global.Host, "api/v2/attestations", global.Org, o.flowName, "trail", o.trailName, "coverage")
+	if err != nil {
+		return err
+	}
+
+	err = o.CommonAttestationOptions.run(args, o.payload.CommonAttestationPayload)
+	if err != nil {
+		return err
+	}
+
+	o.payload.CoverageResults, err = coverage.ParseFile(coverage.Format(o.format), o.coverageFile)
+	if err != nil {
+		return err
+	}
+	o.payload.Compliant = coverageCompliant(o.payload.CoverageResults, o.payload.MinLineCoverage)
+
+	if o.uploadResultsFile {
+		o.attachments = append(o.attachments, o.coverageFile)
+	}
+
+	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
+	if err != nil {
+		return err
+	}
+	// if we created a tar package, remove it after uploading it
+	if cleanupNeeded {
+		defer func() {
+			if err := os.Remove(evidencePath); err != nil {
+				logger.Warn("failed to remove evidence file %s: %v", evidencePath, err)
+			}
+		}()
+	}
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodPost,
+		URL:    url,
+		Form:   form,
+		DryRun: global.DryRun,
+		Token:  global.ApiToken,
+	}
+	_, err = kosliClient.Do(reqParams)
+	if err == nil && !global.DryRun {
+		logger.Info("coverage attestation '%s' is reported to trail: %s", o.payload.AttestationName, o.trailName)
+	}
+
+	if !o.payload.Compliant && o.assert && !global.DryRun {
+		errString := ""
+		if err != nil {
+			errString = fmt.Sprintf("%s\nError: ", err.Error())
+		}
+		err = fmt.Errorf("%sline coverage %.2f%% is below the minimum of %.2f%%", errString,
+			o.payload.CoverageResults.Lines.Percent, *o.payload.MinLineCoverage)
+	}
+	return wrapAttestationError(err)
+}
+
+// coverageCompliant reports whether the line coverage meets the minimum, if any.
+func coverageCompliant(results *coverage.Results, minLineCoverage *float64) bool {
+	if minLineCoverage == nil {
+		return true
+	}
+	return results.Lines.Percent >= *minLineCoverage
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+const attestIssueDesc = `All Kosli commands to attest issue tracker references (GitHub Issues, GitLab Issues, Linear).
+For Jira, use ^kosli attest jira^.`
+
+// attestIssueLongDesc is shared by the attest issue commands, after their
+// provider specific description of the issue references they look for.
+const attestIssueLongDesc = `
+The found issue references are checked against the issue tracker to confirm their existence,
+and the title and state of each issue are reported with it.
+The attestation is reported in all cases, and its compliance status depends on referencing
+existing issues. Use ^--assert^ to exit with a non-zero code when no reference is found or
+a referenced issue does not exist.
+
+The branch name is searched for issue references unless ^--ignore-branch-match^ is set, and
+^--issue-secondary-source^ can be used to search an additional string, such as the original
+branch name of a pull request.
+` + attestationBindingDesc + `
+
+` + commitDescription
+
+func newAttestIssueCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "issue",
+		Short: attestIssueDesc,
+		Long:  attestIssueDesc,
+	}
+
+	// Add subcommands
+	cmd.AddCommand(
+		newAttestGithubIssueCmd(out),
+		newAttestGitlabIssueCmd(out),
+		newAttestLinearIssueCmd(out),
+	)
+
+	return cmd
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"io"
+
+	ghUtils "github.com/kosli-dev/cli/internal/github"
+	"github.com/spf13/cobra"
+)
+
+const attestIssueGithubShortDesc = `Report a GitHub issue attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestIssueGithubLongDesc = attestIssueGithubShortDesc + `
+Parses the given commit's message, current branch name or the content of the ^--issue-secondary-source^
+argument for GitHub issue references of the form ^#123^ (an issue of the ^--repository^) or
+^owner/repo#123^ (an issue of another repository). References to pull requests, which share their
+numbering with issues, are ignored.
+` + attestIssueLongDesc
+
+const attestIssueGithubExample = `
+# report a GitHub issue attestation about a pre-built docker artifact (kosli calculates the fingerprint):
+kosli attest issue github yourDockerImageName \
+	--artifact-type docker \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--github-token yourGithubToken \
+	--github-org yourGithubOrg \
+	--commit yourGitCommitSha1 \
+	--repository yourGithubGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a GitHub issue attestation about a trail:
+kosli attest issue github \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--github-token yourGithubToken \
+	--github-org yourGithubOrg \
+	--commit yourGitCommitSha1 \
+	--repository yourGithubGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# fail if no issue reference is found, or an issue does not exist
+kosli attest issue github \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--github-token yourGithubToken \
+	--github-org yourGithubOrg \
+	--commit yourGitCommitSha1 \
+	--repository yourGithubGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName \
+	--assert
+`
+
+func newAttestGithubIssueCmd(out io.Writer) *cobra.Command {
+	o := newAttestIssueOptions()
+	githubFlagsValues := new(ghUtils.GithubFlagsTempValueHolder)
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1),  // See CustomMaximumNArgs() below
+		Use:     "github [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Aliases: []string{"gh"},
+		Short:   attestIssueGithubShortDesc,
+		Long:    attestIssueGithubLongDesc,
+		Example: attestIssueGithubExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.validate(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.repoURLExplicit = cmd.Flags().Changed("repo-url")
+			o.repoNameExplicit = cmd.Flags().Changed("repository")
+			o.retriever = ghUtils.NewGithubIssueRetrieverFunc(githubFlagsValues.Token, githubFlagsValues.BaseURL,
+				githubFlagsValues.Org, o.repoName, global.Debug)
+			if err := useGithubAuth(o.retriever, githubFlagsValues); err != nil {
+				return err
+			}
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	addGithubFlags(cmd, githubFlagsValues, ci)
+	addIssueFlags(cmd, o)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name",
+		"github-org", "commit", "repository"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"io"
+
+	gitlabUtils "github.com/kosli-dev/cli/internal/gitlab"
+	"github.com/spf13/cobra"
+)
+
+const attestIssueGitlabShortDesc = `Report a Gitlab issue attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestIssueGitlabLongDesc = attestIssueGitlabShortDesc + `
+Parses the given commit's message, current branch name or the content of the ^--issue-secondary-source^
+argument for Gitlab issue references of the form ^#123^ (an issue of the ^--repository^ project) or
+^group/project#123^ (an issue of another project, in any subgroup).
+` + attestIssueLongDesc
+
+const attestIssueGitlabExample = `
+# report a Gitlab issue attestation about a pre-built docker artifact (kosli calculates the fingerprint):
+kosli attest issue gitlab yourDockerImageName \
+	--artifact-type docker \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--gitlab-token yourGitlabToken \
+	--gitlab-org yourGitlabOrg \
+	--commit yourGitCommitSha1 \
+	--repository yourGitlabGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a Gitlab issue attestation about a trail:
+kosli attest issue gitlab \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--gitlab-token yourGitlabToken \
+	--gitlab-org yourGitlabOrg \
+	--commit yourGitCommitSha1 \
+	--repository yourGitlabGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+func newAttestGitlabIssueCmd(out io.Writer) *cobra.Command {
+	o := newAttestIssueOptions()
+	gitlabFlagsValues := new(gitlabUtils.GitlabConfig)
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1),  // See CustomMaximumNArgs() below
+		Use:     "gitlab [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Aliases: []string{"gl"},
+		Short:   attestIssueGitlabShortDesc,
+		Long:    attestIssueGitlabLongDesc,
+		Example: attestIssueGitlabExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.validate(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.repoURLExplicit = cmd.Flags().Changed("repo-url")
+			o.repoNameExplicit = cmd.Flags().Changed("repository")
+			// as for merge requests, the short project name is combined with the
+			// namespace to form the project of "#123" references
+			o.retriever = gitlabUtils.NewGitlabIssueRetrieverFunc(gitlabFlagsValues.Token,
+				gitlabFlagsValues.BaseURL, gitlabFlagsValues.Org, o.repoName)
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	addGitlabFlags(cmd, gitlabFlagsValues, ci)
+	addIssueFlags(cmd, o)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name",
+		"gitlab-token", "gitlab-org", "commit", "repository"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
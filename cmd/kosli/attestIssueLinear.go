@@ -0,0 +1,81 @@
+package main
+
+import (
+	"io"
+
+	"github.com/kosli-dev/cli/internal/linear"
+	"github.com/spf13/cobra"
+)
+
+type linearFlagsValueHolder struct {
+	apiKey   string
+	teamKeys []string
+}
+
+const attestIssueLinearShortDesc = `Report a Linear issue attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestIssueLinearLongDesc = attestIssueLinearShortDesc + `
+Parses the given commit's message, current branch name or the content of the ^--issue-secondary-source^
+argument for Linear issue identifiers of the form ^ENG-42^: a team key followed by a dash and the
+issue number. Matching is case-insensitive, and follows the same rules as Jira issue keys in
+^kosli attest jira^. Use ^--linear-team-key^ to restrict matching to one or more known team keys
+and avoid unintended candidates.
+` + attestIssueLongDesc
+
+const attestIssueLinearExample = `
+# report a Linear issue attestation about a pre-built docker artifact (kosli calculates the fingerprint):
+kosli attest issue linear yourDockerImageName \
+	--artifact-type docker \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--linear-api-key yourLinearAPIKey \
+	--commit yourGitCommitSha1 \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a Linear issue attestation about a trail, matching issues of the ENG team only:
+kosli attest issue linear \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--linear-api-key yourLinearAPIKey \
+	--linear-team-key ENG \
+	--commit yourGitCommitSha1 \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+func newAttestLinearIssueCmd(out io.Writer) *cobra.Command {
+	o := newAttestIssueOptions()
+	linearFlagsValues := new(linearFlagsValueHolder)
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1),  // See CustomMaximumNArgs() below
+		Use:     "linear [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Short:   attestIssueLinearShortDesc,
+		Long:    attestIssueLinearLongDesc,
+		Example: attestIssueLinearExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.validate(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.repoURLExplicit = cmd.Flags().Changed("repo-url")
+			o.repoNameExplicit = cmd.Flags().Changed("repository")
+			o.retriever = linear.NewLinearIssueRetrieverFunc(linearFlagsValues.apiKey, linearFlagsValues.teamKeys)
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	cmd.Flags().StringVar(&linearFlagsValues.apiKey, "linear-api-key", "", linearAPIKeyFlag)
+	cmd.Flags().StringSliceVar(&linearFlagsValues.teamKeys, "linear-team-key", []string{}, linearTeamKeyFlag)
+	addIssueFlags(cmd, o)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name", "commit", "linear-api-key"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
@@ -30,6 +30,8 @@ type attestJiraOptions struct {
 	issueFields       string
 	secondarySource   string
 	ignoreBranchMatch bool
+	comment           bool
+	transition        string
 	assert            bool
 	payload           JiraAttestationPayload
 }
@@ -76,6 +78,12 @@ The ^--jira-issue-fields^ can be used to include fields from the jira issue. By
 are included. ^*all^ will give all fields. Using ^--jira-issue-fields "*all" --dry-run^ will give you
 the complete list so you can select the once you need. The issue fields uses the jira API that is documented here:
 https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issues/#api-rest-api-2-issue-issueidorkey-get-request
+
+Once the attestation is reported, the found issues can be updated in Jira:
+^--jira-comment^ posts a comment on each issue linking to the Kosli trail, and ^--jira-transition^
+moves each issue through the named workflow transition, or to the named status (e.g. ^"In Release"^).
+Issues already in that status are left alone. Both need a Jira user allowed to comment on and
+transition the issues, and nothing is written to Jira with ^--dry-run^.
 ` + attestationBindingDesc + `
 
 ` + commitDescription
@@ -176,6 +184,19 @@ kosli attest jira \
 	--org yourOrgName \
 	--assert
 
+# report a jira attestation about a trail, then comment on the issues and move them to "In Release"
+kosli attest jira \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--jira-base-url https://kosli.atlassian.net \
+	--jira-username user@domain.com \
+	--jira-api-token yourJiraAPIToken \
+	--jira-comment \
+	--jira-transition "In Release" \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
 # get jira reference from original branch name in a GitHub Pull Request merge job
 kosli attest jira \
 	--name yourAttestationName \
@@ -261,6 +282,8 @@ func newAttestJiraCmd(out io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&o.issueFields, "jira-issue-fields", "", jiraIssueFieldFlag)
 	cmd.Flags().StringVar(&o.secondarySource, "jira-secondary-source", "", jiraSecondarySourceFlag)
 	cmd.Flags().BoolVar(&o.ignoreBranchMatch, "ignore-branch-match", false, ignoreBranchMatchFlag)
+	cmd.Flags().BoolVar(&o.comment, "jira-comment", false, jiraCommentFlag)
+	cmd.Flags().StringVar(&o.transition, "jira-transition", "", jiraTransitionFlag)
 	cmd.Flags().BoolVar(&o.assert, "assert", false, attestationAssertFlag)
 
 	err := RequireFlags(cmd, []string{"flow", "trail", "name", "commit", "jira-base-url"})
@@ -303,7 +326,7 @@ func (o *attestJiraOptions) run(args []string) error {
 
 	// Search commit message, branch name, and secondary source for Jira issue keys,
 	// filtering out false positives from multi-segment identifiers like CVE-2026-41284.
-	issueIDs := jira.FindJiraIssueKeys(jiraSearchText(commitInfo, o.secondarySource, o.ignoreBranchMatch), o.projectKeys)
+	issueIDs := jira.FindJiraIssueKeys(issueSearchText(commitInfo, o.secondarySource, o.ignoreBranchMatch), o.projectKeys)
 	logger.Debug("Checked for Jira issue references in Git commit %s on branch %s commit message:\n%s", commitInfo.Sha1, commitInfo.Branch, commitInfo.Message)
 	logger.Debug("the following Jira references are found in commit message or branch name: %v", issueIDs)
 
@@ -346,6 +369,7 @@ func (o *attestJiraOptions) run(args []string) error {
 	_, err = kosliClient.Do(reqParams)
 	if err == nil && !global.DryRun {
 		logger.Info("jira attestation '%s' is reported to trail: %s", o.payload.AttestationName, o.trailName)
+		err = o.writeBack(jc)
 	}
 
 	if len(issueIDs) == 0 && o.assert && !global.DryRun {
@@ -366,18 +390,42 @@ func (o *attestJiraOptions) run(args []string) error {
 	return wrapAttestationError(err)
 }
 
-// jiraSearchText joins the texts that are searched for Jira issue keys: the commit
-// message, the branch name unless ignoreBranchMatch is set, and the secondary source
-// when one is given.
-func jiraSearchText(commitInfo *gitview.CommitInfo, secondarySource string, ignoreBranchMatch bool) string {
-	searchTexts := []string{commitInfo.Message}
-	if !ignoreBranchMatch {
-		searchTexts = append(searchTexts, commitInfo.Branch)
+// writeBack comments on and/or transitions the existing issues found for the
+// attestation, as asked for with --jira-comment and --jira-transition.
+func (o *attestJiraOptions) writeBack(jc *jira.JiraConfig) error {
+	if !o.comment && o.transition == "" {
+		return nil
 	}
-	if secondarySource != "" {
-		searchTexts = append(searchTexts, secondarySource)
+	trailURL, err := url.JoinPath(global.Host, global.Org, "flows", o.flowName, "trails", o.trailName)
+	if err != nil {
+		return err
 	}
-	return strings.Join(searchTexts, "\n")
+	comment := fmt.Sprintf("Kosli jira attestation '%s' is reported to trail '%s' of flow '%s': %s",
+		o.payload.AttestationName, o.trailName, o.flowName, trailURL)
+
+	for _, result := range o.payload.JiraResults {
+		if !result.IssueExists {
+			continue
+		}
+		if o.comment {
+			if err := jc.AddIssueComment(result.IssueID, comment); err != nil {
+				return err
+			}
+			logger.Info("commented on jira issue %s", result.IssueID)
+		}
+		if o.transition != "" {
+			transitioned, err := jc.TransitionIssue(result.IssueID, o.transition)
+			if err != nil {
+				return err
+			}
+			if transitioned {
+				logger.Info("transitioned jira issue %s with '%s'", result.IssueID, o.transition)
+			} else {
+				logger.Debug("jira issue %s is already in status '%s'", result.IssueID, o.transition)
+			}
+		}
+	}
+	return nil
 }
 
 // jiraProjectKeyRegexp is compiled once, so validateJiraProjectKeys does not re-compile a
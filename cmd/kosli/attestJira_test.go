@@ -1,13 +1,18 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	billy "github.com/go-git/go-billy/v5"
 	git "github.com/go-git/go-git/v5"
 	"github.com/kosli-dev/cli/internal/gitview"
+	"github.com/kosli-dev/cli/internal/jira"
 	"github.com/kosli-dev/cli/internal/testHelpers"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -387,7 +392,7 @@ func execJiraTestCase(test cmdTestCase, suite *AttestJiraCommandTestSuite) {
 	runTestCmd(suite.T(), []cmdTestCase{test})
 }
 
-func TestJiraSearchText(t *testing.T) {
+func TestIssueSearchText(t *testing.T) {
 	commitInfo := &gitview.CommitInfo{
 		BasicCommitInfo: gitview.BasicCommitInfo{
 			Message: "EX-1 fix the thing",
@@ -422,7 +427,7 @@ func TestJiraSearchText(t *testing.T) {
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			require.Equal(t, tc.want, jiraSearchText(commitInfo, tc.secondarySource, tc.ignoreBranchMatch))
+			require.Equal(t, tc.want, issueSearchText(commitInfo, tc.secondarySource, tc.ignoreBranchMatch))
 		})
 	}
 }
@@ -525,6 +530,57 @@ func TestValidateJiraProjectKeys(t *testing.T) {
 	}
 }
 
+func TestJiraWriteBack(t *testing.T) {
+	var requests, comments []string
+	jiraServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/transitions"):
+			fmt.Fprint(w, `{"transitions": [{"id": "31", "name": "Release", "to": {"name": "In Release"}}]}`)
+		case r.Method == http.MethodGet:
+			fmt.Fprint(w, `{"fields": {"status": {"name": "In Progress"}}}`)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/comment"):
+			var comment struct{ Body string }
+			_ = json.NewDecoder(r.Body).Decode(&comment)
+			comments = append(comments, comment.Body)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer jiraServer.Close()
+	global = &GlobalOpts{Host: "https://app.kosli.com", Org: "acme"}
+
+	o := &attestJiraOptions{
+		CommonAttestationOptions: &CommonAttestationOptions{flowName: "backend", trailName: "v1.2.3"},
+		payload: JiraAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{AttestationName: "jira"},
+			JiraResults: []*jira.JiraIssueInfo{
+				{IssueID: "EX-1", IssueExists: true},
+				{IssueID: "EX-2", IssueExists: false},
+			},
+		},
+	}
+	jc := jira.NewJiraConfig(jiraServer.URL, "user@example.com", "token", "")
+
+	require.NoError(t, o.writeBack(jc))
+	require.Empty(t, requests, "nothing is written without --jira-comment or --jira-transition")
+
+	o.comment = true
+	o.transition = "In Release"
+	require.NoError(t, o.writeBack(jc))
+	require.Equal(t, []string{
+		"POST /rest/api/2/issue/EX-1/comment",
+		"GET /rest/api/2/issue/EX-1",
+		"GET /rest/api/2/issue/EX-1/transitions",
+		"POST /rest/api/2/issue/EX-1/transitions",
+	}, requests, "only existing issues are written to")
+	require.Equal(t, []string{
+		"Kosli jira attestation 'jira' is reported to trail 'v1.2.3' of flow 'backend': https://app.kosli.com/acme/flows/backend/trails/v1.2.3",
+	}, comments)
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestAttestJiraCommandTestSuite(t *testing.T) {
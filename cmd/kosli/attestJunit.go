@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -8,11 +9,11 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	junit "github.com/joshdk/go-junit"
 	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/testreport"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +26,8 @@ type attestJunitOptions struct {
 	*CommonAttestationOptions
 	testResultsDir   string
 	uploadResultsDir bool
+	format           string
+	ingestOptions    junitIngestOptions
 	payload          JunitAttestationPayload
 }
 
@@ -32,7 +35,21 @@ const attestJunitShortDesc = `Report a junit attestation to an artifact or a tra
 JUnit xml files are read from the ^--results-dir^ directory which defaults to the current directory.
 The xml files are automatically uploaded as ^--attachments^ via the ^--upload-results^ flag which defaults to ^true^.  `
 
-const attestJunitLongDesc = attestJunitShortDesc + attestationBindingDesc + `
+const attestJunitLongDesc = attestJunitShortDesc + `
+Test reports in other formats can be read with ^--format^: ^tap^ (TAP files with a .tap or .txt extension),
+^go-test-json^ (^go test -json^ output in .json, .jsonl or .ndjson files), ^trx^ (.NET .trx files) and
+^ctrf^ (CTRF .json files). They are reported as the same junit results. With ^--format auto^ the format of
+each file is detected from its extension and content, and files that are not test reports are skipped.
+XML files declaring a character encoding other than UTF-8 (e.g. ISO-8859-1) are converted before parsing.
+
+By default only the totals of each test suite are reported. Use ^--include-test-cases^ to also report
+every test case with its classname, name, status, duration and (truncated) failure message.
+
+Use ^--detect-flaky^ to take test reruns into account: the attempts at a test are counted once, and a test
+that failed and then passed on a retry is reported as flaky instead of failed. Reruns are recognized from
+Maven surefire's ^<flakyFailure>^/^<rerunFailure>^ elements and from the same test case being reported
+more than once (e.g. by pytest-rerunfailures).
+` + attestationBindingDesc + `
 
 ` + commitDescription
 
@@ -76,6 +93,27 @@ kosli attest junit \
 	--api-token yourAPIToken \
 	--org yourOrgName
 
+# report a junit attestation about a trail from the output of 'go test -json':
+kosli attest junit \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--results-dir yourFolderWithGoTestJsonOutput \
+	--format go-test-json \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a junit attestation about a trail with every test case, reporting retried tests as flaky:
+kosli attest junit \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--results-dir yourFolderWithJUnitResults \
+	--include-test-cases \
+	--detect-flaky \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
 # report a junit attestation about a trail with an attachment:
 kosli attest junit \
 	--name yourAttestationName \
@@ -129,6 +167,11 @@ func newAttestJunitCmd(out io.Writer) *cobra.Command {
 				return ErrorBeforePrintingUsage(cmd, err.Error())
 			}
 
+			o.ingestOptions.format, err = testreport.ParseFormat(o.format)
+			if err != nil {
+				return fmt.Errorf("%s for --format", err.Error())
+			}
+
 			return ValidateRegistryFlags(cmd, o.fingerprintOptions)
 
 		},
@@ -143,6 +186,9 @@ func newAttestJunitCmd(out io.Writer) *cobra.Command {
 	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
 	cmd.Flags().StringVarP(&o.testResultsDir, "results-dir", "R", ".", resultsDirFlag)
 	cmd.Flags().BoolVar(&o.uploadResultsDir, "upload-results", true, uploadJunitResultsFlag)
+	cmd.Flags().StringVar(&o.format, "format", string(testreport.FormatJUnit), testReportFormatFlag)
+	cmd.Flags().BoolVar(&o.ingestOptions.includeTestCases, "include-test-cases", false, junitIncludeTestCasesFlag)
+	cmd.Flags().BoolVar(&o.ingestOptions.detectFlaky, "detect-flaky", false, junitDetectFlakyFlag)
 
 	err := RequireFlags(cmd, []string{"flow", "trail", "name"})
 	if err != nil {
@@ -164,7 +210,7 @@ func (o *attestJunitOptions) run(args []string) error {
 	}
 
 	var junitFilenames []string
-	o.payload.JUnitResults, junitFilenames, err = ingestJunitDir(o.testResultsDir)
+	o.payload.JUnitResults, junitFilenames, err = ingestJunitDir(o.testResultsDir, o.ingestOptions)
 	if err != nil {
 		return err
 	}
@@ -201,37 +247,68 @@ func (o *attestJunitOptions) run(args []string) error {
 }
 
 type JUnitResults struct {
-	Name      string  `json:"name"`
-	Failures  int     `json:"failures"`
-	Errors    int     `json:"errors"`
-	Skipped   int     `json:"skipped"`
-	Total     int     `json:"total"`
-	Duration  float64 `json:"duration"`
-	Timestamp float64 `json:"timestamp,omitempty"`
+	Name       string           `json:"name"`
+	Failures   int              `json:"failures"`
+	Errors     int              `json:"errors"`
+	Skipped    int              `json:"skipped"`
+	Flaky      int              `json:"flaky,omitempty"`
+	Total      int              `json:"total"`
+	Duration   float64          `json:"duration"`
+	Timestamp  float64          `json:"timestamp,omitempty"`
+	FlakyTests []string         `json:"flaky_tests,omitempty"`
+	TestCases  []*JUnitTestCase `json:"test_cases,omitempty"`
 }
 
-func ingestJunitDir(testResultsDir string) ([]*JUnitResults, []string, error) {
+// junitFileSuite is a parsed suite together with the reruns recorded in the
+// file it came from.
+type junitFileSuite struct {
+	suite  junit.Suite
+	reruns map[string]testreport.Reruns
+}
+
+func ingestJunitDir(testResultsDir string, opts junitIngestOptions) ([]*JUnitResults, []string, error) {
 	results := []*JUnitResults{}
 	var junitFilenames []string
 
-	var allSuites []junit.Suite
+	format := opts.format
+	if format == "" {
+		format = testreport.FormatJUnit
+	}
+
+	var allSuites []junitFileSuite
 	err := filepath.WalkDir(testResultsDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		if d.Type().IsRegular() && strings.HasSuffix(d.Name(), ".xml") {
-			suites, err := junit.IngestFile(path)
-			if err != nil {
-				if strings.Contains(err.Error(), "Decoder.CharsetReader is nil") {
-					return fmt.Errorf("failed to parse XML file %s: only UTF-8 encoding is supported. "+
-						"If this is not a JUnit results file, use --results-dir to specify the directory containing only JUnit XML files", path)
-				}
-				return fmt.Errorf("failed to parse XML file %s: %w", path, err)
+		if !d.Type().IsRegular() || !format.Accepts(d.Name()) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fileFormat := format
+		if format == testreport.FormatAuto {
+			fileFormat = testreport.Detect(path, data)
+			if fileFormat == "" {
+				logger.Debug("skipping %s: not a recognized test report", path)
+				return nil
 			}
-			if len(suites) > 0 {
-				allSuites = append(allSuites, suites...)
-				junitFilenames = append(junitFilenames, path)
+		}
+		report, err := testreport.Parse(fileFormat, path, data)
+		if err != nil {
+			var encodingErr *testreport.UnsupportedEncodingError
+			if errors.As(err, &encodingErr) {
+				return fmt.Errorf("failed to parse XML file %s: %s. "+
+					"If this is not a JUnit results file, use --results-dir to specify the directory containing only JUnit XML files", path, err)
 			}
+			return fmt.Errorf("failed to parse %s file %s: %w", fileFormat, path, err)
+		}
+		if len(report.Suites) > 0 {
+			for _, suite := range report.Suites {
+				allSuites = append(allSuites, junitFileSuite{suite: suite, reruns: report.Reruns})
+			}
+			junitFilenames = append(junitFilenames, path)
 		}
 		return nil
 	})
@@ -243,7 +320,8 @@ func ingestJunitDir(testResultsDir string) ([]*JUnitResults, []string, error) {
 		return nil, nil, fmt.Errorf("no tests found in %s directory", testResultsDir)
 	}
 
-	for _, suite := range allSuites {
+	for _, fileSuite := range allSuites {
+		suite := fileSuite.suite
 		var timestamp float64
 		timestamp, err := parseTimestamp(suite.Properties["timestamp"])
 		if err != nil {
@@ -260,6 +338,15 @@ func ingestJunitDir(testResultsDir string) ([]*JUnitResults, []string, error) {
 			Failures:  suite.Totals.Failed,
 			Timestamp: timestamp,
 		}
+		if opts.includeTestCases || opts.detectFlaky {
+			cases := junitTestCases(flattenJunitTests(suite), fileSuite.reruns, opts.detectFlaky)
+			if opts.detectFlaky {
+				applyJunitTestCaseTotals(suiteResult, cases)
+			}
+			if opts.includeTestCases {
+				suiteResult.TestCases = cases
+			}
+		}
 		logger.Debug("parsed <testsuite> result: %+v", suiteResult)
 		results = append(results, suiteResult)
 	}
@@ -276,6 +363,7 @@ func parseTimestamp(timestampStr string) (float64, error) {
 		"2006-01-02T15:04:05.999999", // pytest
 		"2006-01-02T15:04:05+00:00",  // Ruby minitest
 		"2006-01-02T15:04:05.999Z",   // vitest
+		time.RFC3339,                 // TAP, go test, TRX and CTRF reports
 	}
 
 	var err error
@@ -2,8 +2,12 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/kosli-dev/cli/internal/testreport"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -124,6 +128,17 @@ func (suite *AttestJunitCommandTestSuite) TestAttestJunitCmd() {
 					       --annotate foo.bar=bar %s`, suite.defaultKosliArguments),
 			golden: "Error: --annotate flag should be in the format key=value. Invalid key: 'foo.bar'. Key can only contain [A-Za-z0-9_]\n",
 		},
+		{
+			wantError: true,
+			name:      "fails when --format is not a supported format",
+			cmd:       fmt.Sprintf("attest junit --name bar --format xunit --results-dir testdata %s", suite.defaultKosliArguments),
+			golden:    "Error: \"xunit\" is not a supported test report format. Valid formats are: [auto, junit, tap, go-test-json, trx, ctrf] for --format\n",
+		},
+		{
+			name:   "can attest a TAP report with --format",
+			cmd:    fmt.Sprintf("attest junit --name bar --commit HEAD --origin-url http://example.com --format tap --results-dir testdata/test_reports %s", suite.defaultKosliArguments),
+			golden: "junit attestation 'bar' is reported to trail: test-123\n",
+		},
 		{
 			wantError: true,
 			name:      "fails when --name has invalid dot format",
@@ -142,32 +157,163 @@ func TestAttestJunitCommandTestSuite(t *testing.T) {
 }
 
 func TestIngestJunitDir(t *testing.T) {
+	t.Run("parses JUnit XML declaring a non UTF-8 encoding", func(t *testing.T) {
+		results, filenames, err := ingestJunitDir("testdata_junit_iso8859", junitIngestOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "com.example.MyTest", results[0].Name)
+		assert.Equal(t, 2, results[0].Total)
+		assert.Len(t, filenames, 1)
+	})
+
 	t.Run("error includes filename and user-friendly message for unsupported encoding", func(t *testing.T) {
-		_, _, err := ingestJunitDir("testdata_junit_iso8859")
+		dir := t.TempDir()
+		content := `<?xml version="1.0" encoding="x-unknown"?><testsuite name="s"><testcase name="t"/></testsuite>`
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "results.xml"), []byte(content), 0644))
+		_, _, err := ingestJunitDir(dir, junitIngestOptions{})
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "results.xml")
-		assert.Contains(t, err.Error(), "only UTF-8 encoding is supported")
+		assert.Contains(t, err.Error(), `unsupported character encoding "x-unknown"`)
 		assert.Contains(t, err.Error(), "--results-dir")
 	})
 
+	t.Run("only reads files of the given format", func(t *testing.T) {
+		results, filenames, err := ingestJunitDir("testdata/test_reports", junitIngestOptions{format: testreport.FormatTAP})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "results", results[0].Name)
+		assert.Equal(t, 5, results[0].Total)
+		assert.Equal(t, []string{"testdata/test_reports/results.tap"}, filenames)
+	})
+
+	t.Run("fails on a file that is not of the given format", func(t *testing.T) {
+		_, _, err := ingestJunitDir("testdata/test_reports", junitIngestOptions{format: testreport.FormatCTRF})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse ctrf file testdata/test_reports/package.json")
+	})
+
+	t.Run("detects the format of each file and skips other files with auto", func(t *testing.T) {
+		results, filenames, err := ingestJunitDir("testdata/test_reports", junitIngestOptions{format: testreport.FormatAuto})
+		require.NoError(t, err)
+		assert.Len(t, results, 4)
+		assert.ElementsMatch(t, []string{"testdata/test_reports/ctrf.json", "testdata/test_reports/results.tap"}, filenames)
+	})
+
+	t.Run("reports CTRF retries as flaky with --detect-flaky", func(t *testing.T) {
+		results, _, err := ingestJunitDir("testdata/test_reports", junitIngestOptions{format: testreport.FormatAuto, detectFlaky: true})
+		require.NoError(t, err)
+		header := junitResultByName(t, results, "Header")
+		assert.Equal(t, 1, header.Flaky)
+		assert.Equal(t, []string{"Header.opens the menu"}, header.FlakyTests)
+	})
+
 	t.Run("returns no tests found for empty directory", func(t *testing.T) {
 		dir := t.TempDir()
-		_, _, err := ingestJunitDir(dir)
+		_, _, err := ingestJunitDir(dir, junitIngestOptions{})
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "no tests found")
 	})
 
 	t.Run("parses valid JUnit XML correctly", func(t *testing.T) {
-		results, _, err := ingestJunitDir("testdata/junit")
+		results, _, err := ingestJunitDir("testdata/junit", junitIngestOptions{})
 		require.NoError(t, err)
 		assert.NotEmpty(t, results)
 	})
 
 	t.Run("returns filenames of parsed JUnit XML files", func(t *testing.T) {
-		results, filenames, err := ingestJunitDir("testdata/junit")
+		results, filenames, err := ingestJunitDir("testdata/junit", junitIngestOptions{})
 		require.NoError(t, err)
 		assert.NotEmpty(t, results)
 		assert.Len(t, filenames, 1)
 		assert.Contains(t, filenames[0], ".xml")
 	})
+
+	t.Run("does not report test cases by default", func(t *testing.T) {
+		results, _, err := ingestJunitDir("testdata/junit", junitIngestOptions{})
+		require.NoError(t, err)
+		for _, result := range results {
+			assert.Empty(t, result.TestCases)
+		}
+	})
+
+	t.Run("reports every test case with --include-test-cases", func(t *testing.T) {
+		results, _, err := ingestJunitDir("testdata/junit", junitIngestOptions{includeTestCases: true})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.Len(t, results[0].TestCases, results[0].Total)
+		testCase := results[0].TestCases[0]
+		assert.Equal(t, "testRootEndpoint", testCase.Name)
+		assert.Equal(t, "com.$ACR_HELM_REPO.insight.EmailResourceTest", testCase.Classname)
+		assert.Equal(t, "skipped", testCase.Status)
+		assert.Contains(t, testCase.Message, "is @Disabled")
+		assert.Zero(t, testCase.Attempts)
+	})
+
+	t.Run("counts every attempt as a test without --detect-flaky", func(t *testing.T) {
+		results, _, err := ingestJunitDir("testdata/junit_flaky", junitIngestOptions{includeTestCases: true})
+		require.NoError(t, err)
+		pytest := junitResultByName(t, results, "pytest")
+		assert.Equal(t, 5, pytest.Total)
+		assert.Equal(t, 2, pytest.Failures)
+		assert.Zero(t, pytest.Flaky)
+		assert.Len(t, pytest.TestCases, 5)
+	})
+
+	t.Run("reports tests passing on a rerun as flaky with --detect-flaky", func(t *testing.T) {
+		results, _, err := ingestJunitDir("testdata/junit_flaky", junitIngestOptions{includeTestCases: true, detectFlaky: true})
+		require.NoError(t, err)
+
+		pytest := junitResultByName(t, results, "pytest")
+		assert.Equal(t, 4, pytest.Total)
+		assert.Equal(t, 1, pytest.Failures)
+		assert.Equal(t, 1, pytest.Skipped)
+		assert.Equal(t, 1, pytest.Flaky)
+		assert.Equal(t, []string{"tests.test_api.test_login"}, pytest.FlakyTests)
+		require.Len(t, pytest.TestCases, 4)
+		login := pytest.TestCases[0]
+		assert.Equal(t, "flaky", login.Status)
+		assert.Equal(t, 2, login.Attempts)
+		assert.Equal(t, "AssertionError: status 503", login.Message)
+		assert.InDelta(t, 0.7, login.Duration, 0.001)
+
+		surefire := junitResultByName(t, results, "com.example.OrderServiceTest")
+		assert.Equal(t, 3, surefire.Total)
+		assert.Equal(t, 1, surefire.Failures)
+		assert.Equal(t, 1, surefire.Flaky)
+		assert.Equal(t, []string{"com.example.OrderServiceTest.cancelsOrder"}, surefire.FlakyTests)
+		require.Len(t, surefire.TestCases, 3)
+		assert.Equal(t, "passed", surefire.TestCases[0].Status)
+		assert.Equal(t, 1, surefire.TestCases[0].Attempts)
+		assert.Equal(t, "flaky", surefire.TestCases[1].Status)
+		assert.Equal(t, 2, surefire.TestCases[1].Attempts)
+		assert.Equal(t, "expected: <CANCELLED> but was: <PENDING>", surefire.TestCases[1].Message)
+		assert.Equal(t, "failed", surefire.TestCases[2].Status)
+		assert.Equal(t, 2, surefire.TestCases[2].Attempts)
+	})
+
+	t.Run("reports flaky counts without test cases when only --detect-flaky is set", func(t *testing.T) {
+		results, _, err := ingestJunitDir("testdata/junit_flaky", junitIngestOptions{detectFlaky: true})
+		require.NoError(t, err)
+		pytest := junitResultByName(t, results, "pytest")
+		assert.Equal(t, 1, pytest.Flaky)
+		assert.Empty(t, pytest.TestCases)
+	})
+}
+
+func TestTruncateJunitMessage(t *testing.T) {
+	assert.Equal(t, "short", truncateJunitMessage("short"))
+	long := strings.Repeat("é", junitMessageLimit+10)
+	truncated := truncateJunitMessage(long)
+	assert.Equal(t, strings.Repeat("é", junitMessageLimit)+"...", truncated)
+}
+
+func junitResultByName(t *testing.T, results []*JUnitResults, name string) *JUnitResults {
+	t.Helper()
+	for _, result := range results {
+		if result.Name == name {
+			return result
+		}
+	}
+	require.Failf(t, "suite not found", "no junit results for suite %q", name)
+	return nil
 }
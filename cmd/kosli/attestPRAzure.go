@@ -12,7 +12,7 @@ const attestPRAzureShortDesc = `Report an Azure Devops pull request attestation
 
 const attestPRAzureLongDesc = attestPRAzureShortDesc + `
 It checks if a pull request exists for the artifact (based on its git commit) and reports the pull-request attestation to the artifact in Kosli.
-` + attestationBindingDesc
+` + prCommitRangeDesc + prReviewAnalysisDesc + prCacheDesc + attestationBindingDesc
 
 const attestPRAzureExample = `
 # report an Azure Devops pull request attestation about a pre-built docker artifact (kosli calculates the fingerprint):
@@ -158,6 +158,7 @@ func newAttestAzurePRCmd(out io.Writer) *cobra.Command {
 	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
 	addAzureFlags(cmd, azureFlagsValues, ci)
 	cmd.Flags().BoolVar(&o.assert, "assert", false, assertPREvidenceFlag)
+	cmd.Flags().StringVar(&o.oldestCommit, "oldest-commit", "", oldestCommitPRFlag)
 
 	err := RequireFlags(cmd, []string{"flow", "trail", "name",
 		"azure-token", "azure-org-url",
@@ -14,7 +14,7 @@ const attestPRBitbucketLongDesc = attestPRBitbucketShortDesc + `
 It checks if a pull request exists for a given merge commit and reports the pull-request attestation to Kosli.
 Authentication to Bitbucket can be done with an access token (recommended) or an Atlassian API token, passed via --bitbucket-username (your Atlassian account email) and --bitbucket-password.
 Bitbucket app passwords are no longer supported as of 28 July 2026; replace any app passwords with API tokens. Credentials need to have read access for both repos and pull requests.
-` + attestationBindingDesc
+` + prCommitRangeDesc + prReviewAnalysisDesc + prCacheDesc + attestationBindingDesc
 
 const attestPRBitbucketExample = `
 # report a Bitbucket pull request attestation about a pre-built docker artifact (kosli calculates the fingerprint):
@@ -178,6 +178,7 @@ func newAttestBitbucketPRCmd(out io.Writer) *cobra.Command {
 	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
 	addBitbucketFlags(cmd, o.getRetriever().(*bbUtils.Config), ci)
 	cmd.Flags().BoolVar(&o.assert, "assert", false, assertPREvidenceFlag)
+	cmd.Flags().StringVar(&o.oldestCommit, "oldest-commit", "", oldestCommitPRFlag)
 
 	err := RequireFlags(cmd, []string{"flow", "trail", "name",
 		"bitbucket-workspace", "commit", "repository"})
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	giteaUtils "github.com/kosli-dev/cli/internal/gitea"
+	"github.com/spf13/cobra"
+)
+
+const attestPRGiteaShortDesc = `Report a Gitea or Forgejo pull request attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestPRGiteaLongDesc = attestPRGiteaShortDesc + `
+It checks if a pull request exists for a given merge commit and reports the pull-request attestation to Kosli.
+A pull request is found when it was merged with the given commit, or when it is open and the commit is its head.
+The attestation includes the approvals of the pull request reviews and the commits of the pull request, with
+the verification state of their signatures.
+The token needs read access to the repository and its pull requests.
+` + prCommitRangeDesc + prReviewAnalysisDesc + prCacheDesc + attestationBindingDesc
+
+const attestPRGiteaExample = `
+# report a Gitea pull request attestation about a pre-built docker artifact (kosli calculates the fingerprint):
+kosli attest pullrequest gitea yourDockerImageName \
+	--artifact-type docker \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--gitea-token yourGiteaToken \
+	--gitea-base-url yourGiteaURL \
+	--gitea-org yourGiteaOrg \
+	--commit yourArtifactGitCommit \
+	--repository yourGiteaGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a Gitea pull request attestation about a pre-built docker artifact (you provide the fingerprint):
+kosli attest pullrequest gitea \
+	--fingerprint yourDockerImageFingerprint \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--gitea-token yourGiteaToken \
+	--gitea-base-url yourGiteaURL \
+	--gitea-org yourGiteaOrg \
+	--commit yourArtifactGitCommit \
+	--repository yourGiteaGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a Forgejo pull request attestation about a trail:
+kosli attest pullrequest forgejo \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--gitea-token yourForgejoToken \
+	--gitea-base-url yourForgejoURL \
+	--gitea-org yourForgejoOrg \
+	--commit yourArtifactGitCommit \
+	--repository yourForgejoGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a Gitea pull request attestation about an artifact which has not been reported yet in a trail:
+kosli attest pullrequest gitea \
+	--name yourTemplateArtifactName.yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--gitea-token yourGiteaToken \
+	--gitea-base-url yourGiteaURL \
+	--gitea-org yourGiteaOrg \
+	--commit yourArtifactGitCommit \
+	--repository yourGiteaGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# fail if a pull request does not exist for your artifact
+kosli attest pullrequest gitea \
+	--name yourTemplateArtifactName.yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--gitea-token yourGiteaToken \
+	--gitea-base-url yourGiteaURL \
+	--gitea-org yourGiteaOrg \
+	--commit yourArtifactGitCommit \
+	--repository yourGiteaGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName \
+	--assert
+`
+
+func newAttestGiteaPRCmd(out io.Writer) *cobra.Command {
+	o := &attestPROptions{
+		CommonAttestationOptions: &CommonAttestationOptions{
+			fingerprintOptions: &fingerprintOptions{},
+		},
+		payload: PRAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{},
+		},
+	}
+	giteaFlagsValues := new(giteaUtils.GiteaConfig)
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1),  // See CustomMaximumNArgs() below
+		Use:         "gitea [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Aliases:     []string{"forgejo"},
+		Short:       attestPRGiteaShortDesc,
+		Long:        attestPRGiteaLongDesc,
+		Example:     attestPRGiteaExample,
+		Annotations: map[string]string{"pr": "true"},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			err := CustomMaximumNArgs(1, args)
+			if err != nil {
+				return err
+			}
+
+			err = RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
+			if err != nil {
+				return err
+			}
+
+			err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
+			if err != nil {
+				return fmt.Errorf("%s for --redact-commit-info", err.Error())
+			}
+
+			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			return ValidateRegistryFlags(cmd, o.fingerprintOptions)
+
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.repoURLExplicit = cmd.Flags().Changed("repo-url")
+			o.repoNameExplicit = cmd.Flags().Changed("repository")
+			o.retriever = giteaUtils.NewGiteaRetrieverFunc(giteaFlagsValues.Token,
+				giteaFlagsValues.BaseURL, giteaFlagsValues.Org, o.repoName)
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	addGiteaFlags(cmd, giteaFlagsValues, ci)
+	cmd.Flags().BoolVar(&o.assert, "assert", false, assertPREvidenceFlag)
+	cmd.Flags().StringVar(&o.oldestCommit, "oldest-commit", "", oldestCommitPRFlag)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name",
+		"gitea-token", "gitea-org", "gitea-base-url", "commit", "repository"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
@@ -12,7 +12,9 @@ const attestPRGithubShortDesc = `Report a Github pull request attestation to an
 
 const attestPRGithubLongDesc = attestPRGithubShortDesc + `
 It checks if a pull request exists for a given merge commit and reports the pull-request attestation to Kosli.
-` + attestationBindingDesc
+The GitHub API is called with ^--github-token^, or as a GitHub App installation with ^--github-app-id^,
+^--github-app-installation-id^ and ^--github-app-private-key^, so that no long-lived token is needed.
+` + prCommitRangeDesc + prReviewAnalysisDesc + prCacheDesc + attestationBindingDesc
 
 const attestPRGithubExample = `
 # report a Github pull request attestation about a pre-built docker artifact (kosli calculates the fingerprint):
@@ -78,6 +80,20 @@ kosli attest pullrequest github \
 	--api-token yourAPIToken \
 	--org yourOrgName
 
+# report a Github pull request attestation about a trail, authenticating as a GitHub App:
+kosli attest pullrequest github \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--github-app-id yourGithubAppID \
+	--github-app-installation-id yourGithubAppInstallationID \
+	--github-app-private-key yourGithubAppPrivateKeyFile \
+	--github-org yourGithubOrg \
+	--commit yourArtifactGitCommit \
+	--repository yourGithubGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
 # fail if a pull request does not exist for your artifact
 kosli attest pullrequest github \
 	--name yourTemplateArtifactName.yourAttestationName \
@@ -90,6 +106,20 @@ kosli attest pullrequest github \
 	--api-token yourAPIToken \
 	--org yourOrgName \
 	--assert
+
+# report the pull requests of all the commits of a release, and fail if any commit was pushed without a pull request
+kosli attest pullrequest github \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--github-token yourGithubToken \
+	--github-org yourGithubOrg \
+	--commit yourReleaseGitCommit \
+	--oldest-commit yourPreviousReleaseGitCommit \
+	--repository yourGithubGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName \
+	--assert
 `
 
 func newAttestGithubPRCmd(out io.Writer) *cobra.Command {
@@ -145,6 +175,9 @@ func newAttestGithubPRCmd(out io.Writer) *cobra.Command {
 			o.repoNameExplicit = cmd.Flags().Changed("repository")
 			o.retriever = ghUtils.NewGithubRetrieverFunc(githubFlagsValues.Token, githubFlagsValues.BaseURL,
 				githubFlagsValues.Org, o.repoName, global.Debug)
+			if err := useGithubAuth(o.retriever, githubFlagsValues); err != nil {
+				return err
+			}
 			return o.run(args)
 		},
 	}
@@ -153,9 +186,10 @@ func newAttestGithubPRCmd(out io.Writer) *cobra.Command {
 	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
 	addGithubFlags(cmd, githubFlagsValues, ci)
 	cmd.Flags().BoolVar(&o.assert, "assert", false, assertPREvidenceFlag)
+	cmd.Flags().StringVar(&o.oldestCommit, "oldest-commit", "", oldestCommitPRFlag)
 
 	err := RequireFlags(cmd, []string{"flow", "trail", "name",
-		"github-token", "github-org", "commit", "repository"})
+		"github-org", "commit", "repository"})
 	if err != nil {
 		logger.Error("failed to configure required flags: %v", err)
 	}
@@ -12,7 +12,7 @@ const attestPRGitlabShortDesc = `Report a Gitlab merge request attestation to an
 
 const attestPRGitlabLongDesc = attestPRGitlabShortDesc + `
 It checks if a merge request exists for a given merge commit and reports the merge request attestation to Kosli.
-` + attestationBindingDesc
+` + prCommitRangeDesc + prReviewAnalysisDesc + prCacheDesc + attestationBindingDesc
 
 const attestPRGitlabExample = `
 # report a Gitlab merge request attestation about a pre-built docker artifact (kosli calculates the fingerprint):
@@ -156,6 +156,7 @@ func newAttestGitlabPRCmd(out io.Writer) *cobra.Command {
 	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
 	addGitlabFlags(cmd, gitlabFlagsValues, ci)
 	cmd.Flags().BoolVar(&o.assert, "assert", false, assertPREvidenceFlag)
+	cmd.Flags().StringVar(&o.oldestCommit, "oldest-commit", "", oldestCommitPRFlag)
 
 	err := RequireFlags(cmd, []string{"flow", "trail", "name",
 		"gitlab-token", "gitlab-org", "commit", "repository"})
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+const attestRepoSettingsDesc = `All Kosli commands to attest the branch protection settings of a repository (GitHub, GitLab, Bitbucket, Azure DevOps).`
+
+// attestRepoSettingsLongDesc is shared by the attest repo-settings commands,
+// after their provider specific description of where the settings come from.
+const attestRepoSettingsLongDesc = `
+The settings are reported in a provider independent form: whether the branch is protected, the number of
+required approvals, whether stale approvals are dismissed, whether code owners must review, the required
+status checks, whether signed commits and a linear history are required, whether force pushes and deletion
+are allowed, and whether administrators are bound by the rules. The provider's own rules are reported with them.
+
+Use ^--expected-settings^ to give a YAML or JSON file of the settings the branch is expected to have, such as:
+
+	protected: true
+	required_approvals: 2
+	required_status_checks: [build, test]
+	allow_force_pushes: false
+
+Only the settings given in the file are checked. ^required_approvals^ is a minimum, and the
+^required_status_checks^ must all be required. The attestation is compliant when the branch has
+the expected settings, or when no expected settings are given.
+Use ^--assert^ to exit with a non-zero code when the attestation is non-compliant.
+` + attestationBindingDesc + `
+
+` + commitDescription
+
+func newAttestRepoSettingsCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "repo-settings",
+		Aliases: []string{"repository-settings", "branch-protection"},
+		Short:   attestRepoSettingsDesc,
+		Long:    attestRepoSettingsDesc,
+	}
+
+	// Add subcommands
+	cmd.AddCommand(
+		newAttestGithubRepoSettingsCmd(out),
+		newAttestGitlabRepoSettingsCmd(out),
+		newAttestBitbucketRepoSettingsCmd(out),
+		newAttestAzureRepoSettingsCmd(out),
+	)
+
+	return cmd
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"io"
+
+	azUtils "github.com/kosli-dev/cli/internal/azure"
+	"github.com/spf13/cobra"
+)
+
+const attestRepoSettingsAzureShortDesc = `Report the Azure DevOps branch policies of a repository as an attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestRepoSettingsAzureLongDesc = attestRepoSettingsAzureShortDesc + `
+The settings of ^--branch^ are computed from the enabled branch policies that apply to it. Only required
+(blocking) policies are enforced, and a branch with a required policy can only be changed through pull
+requests, so it cannot be force pushed to or deleted by those not allowed to bypass its policies.
+Required reviewers for paths are reported as code owner review.
+` + attestRepoSettingsLongDesc
+
+const attestRepoSettingsAzureExample = `
+# report the settings of the main branch as an attestation about a trail:
+kosli attest repo-settings azure \
+	--branch main \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--azure-token yourAzureToken \
+	--azure-org-url yourAzureOrgUrl \
+	--project yourAzureDevOpsProject \
+	--repository yourAzureGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report the settings of the main branch, and fail if they do not match the expected settings:
+kosli attest repo-settings azure \
+	--branch main \
+	--expected-settings expected-settings.yaml \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--azure-token yourAzureToken \
+	--azure-org-url yourAzureOrgUrl \
+	--project yourAzureDevOpsProject \
+	--repository yourAzureGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName \
+	--assert
+`
+
+func newAttestAzureRepoSettingsCmd(out io.Writer) *cobra.Command {
+	o := newAttestRepoSettingsOptions()
+	azureFlagsValues := new(azUtils.AzureFlagsTempValueHolder)
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1),  // See CustomMaximumNArgs() below
+		Use:     "azure [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Aliases: []string{"az"},
+		Short:   attestRepoSettingsAzureShortDesc,
+		Long:    attestRepoSettingsAzureLongDesc,
+		Example: attestRepoSettingsAzureExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.validate(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.repoURLExplicit = cmd.Flags().Changed("repo-url")
+			o.repoNameExplicit = cmd.Flags().Changed("repository")
+			o.retriever = azUtils.NewAzureConfig(azureFlagsValues.Token,
+				azureFlagsValues.OrgUrl, azureFlagsValues.Project, o.repoName)
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	addAzureFlags(cmd, azureFlagsValues, ci)
+	addRepoSettingsFlags(cmd, o)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name",
+		"azure-token", "azure-org-url", "project", "repository", "branch"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"io"
+
+	bbUtils "github.com/kosli-dev/cli/internal/bitbucket"
+	"github.com/spf13/cobra"
+)
+
+const attestRepoSettingsBitbucketShortDesc = `Report the Bitbucket branch restrictions of a repository as an attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestRepoSettingsBitbucketLongDesc = attestRepoSettingsBitbucketShortDesc + `
+The settings of ^--branch^ are computed from the branch permissions and merge checks that apply to it, by
+branch pattern or by its type in the branching model of the repository.
+Merge checks are only enforced in Bitbucket Premium workspaces.
+` + attestRepoSettingsLongDesc
+
+const attestRepoSettingsBitbucketExample = `
+# report the settings of the main branch as an attestation about a trail:
+kosli attest repo-settings bitbucket \
+	--branch main \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--bitbucket-access-token yourBitbucketAccessToken \
+	--bitbucket-workspace yourBitbucketWorkspace \
+	--repository yourBitbucketGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report the settings of the main branch, and fail if they do not match the expected settings:
+kosli attest repo-settings bitbucket \
+	--branch main \
+	--expected-settings expected-settings.yaml \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--bitbucket-access-token yourBitbucketAccessToken \
+	--bitbucket-workspace yourBitbucketWorkspace \
+	--repository yourBitbucketGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName \
+	--assert
+`
+
+func newAttestBitbucketRepoSettingsCmd(out io.Writer) *cobra.Command {
+	config := new(bbUtils.Config)
+	config.Logger = logger
+	config.KosliClient = kosliClient
+
+	o := newAttestRepoSettingsOptions()
+	o.retriever = config
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1),  // See CustomMaximumNArgs() below
+		Use:     "bitbucket [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Aliases: []string{"bb"},
+		Short:   attestRepoSettingsBitbucketShortDesc,
+		Long:    attestRepoSettingsBitbucketLongDesc,
+		Example: attestRepoSettingsBitbucketExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			err := MuXRequiredFlags(cmd, []string{"bitbucket-username", "bitbucket-access-token"}, true)
+			if err != nil {
+				return err
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"bitbucket-password", "bitbucket-access-token"}, true)
+			if err != nil {
+				return err
+			}
+
+			err = ConditionallyRequiredFlags(cmd, "bitbucket-username", "bitbucket-password")
+			if err != nil {
+				return err
+			}
+
+			err = ConditionallyRequiredFlags(cmd, "bitbucket-password", "bitbucket-username")
+			if err != nil {
+				return err
+			}
+
+			return o.validate(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.repoURLExplicit = cmd.Flags().Changed("repo-url")
+			o.repoNameExplicit = cmd.Flags().Changed("repository")
+			config.Repository = o.repoName
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	addBitbucketFlags(cmd, config, ci)
+	addRepoSettingsFlags(cmd, o)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name",
+		"bitbucket-workspace", "repository", "branch"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io"
+
+	ghUtils "github.com/kosli-dev/cli/internal/github"
+	"github.com/spf13/cobra"
+)
+
+const attestRepoSettingsGithubShortDesc = `Report the GitHub branch protection settings of a repository as an attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestRepoSettingsGithubLongDesc = attestRepoSettingsGithubShortDesc + `
+The settings of ^--branch^ combine its branch protection rule with the rules of the rulesets that apply to it.
+Reading the branch protection rule needs a token, or a GitHub App installation, with admin access to the repository.
+` + attestRepoSettingsLongDesc
+
+const attestRepoSettingsGithubExample = `
+# report the settings of the main branch as an attestation about a trail:
+kosli attest repo-settings github \
+	--branch main \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--github-token yourGithubToken \
+	--github-org yourGithubOrg \
+	--repository yourGithubGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report the settings of the main branch, and fail if they do not match the expected settings:
+kosli attest repo-settings github \
+	--branch main \
+	--expected-settings expected-settings.yaml \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--github-token yourGithubToken \
+	--github-org yourGithubOrg \
+	--repository yourGithubGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName \
+	--assert
+`
+
+func newAttestGithubRepoSettingsCmd(out io.Writer) *cobra.Command {
+	o := newAttestRepoSettingsOptions()
+	githubFlagsValues := new(ghUtils.GithubFlagsTempValueHolder)
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1),  // See CustomMaximumNArgs() below
+		Use:     "github [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Aliases: []string{"gh"},
+		Short:   attestRepoSettingsGithubShortDesc,
+		Long:    attestRepoSettingsGithubLongDesc,
+		Example: attestRepoSettingsGithubExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.validate(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.repoURLExplicit = cmd.Flags().Changed("repo-url")
+			o.repoNameExplicit = cmd.Flags().Changed("repository")
+			o.retriever = ghUtils.NewGithubRepoSettingsRetrieverFunc(githubFlagsValues.Token, githubFlagsValues.BaseURL,
+				githubFlagsValues.Org, o.repoName, global.Debug)
+			if err := useGithubAuth(o.retriever, githubFlagsValues); err != nil {
+				return err
+			}
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	addGithubFlags(cmd, githubFlagsValues, ci)
+	addRepoSettingsFlags(cmd, o)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name",
+		"github-org", "repository", "branch"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
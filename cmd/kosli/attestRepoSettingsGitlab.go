@@ -0,0 +1,80 @@
+package main
+
+import (
+	"io"
+
+	gitlabUtils "github.com/kosli-dev/cli/internal/gitlab"
+	"github.com/spf13/cobra"
+)
+
+const attestRepoSettingsGitlabShortDesc = `Report the GitLab branch protection settings of a project as an attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestRepoSettingsGitlabLongDesc = attestRepoSettingsGitlabShortDesc + `
+The settings of ^--branch^ combine the protected branch rules matching it, the merge request approval rules
+that apply to it, and the merge request, approval and push rule settings of the project.
+The approval and push rule settings are only read on GitLab Premium and Ultimate.
+` + attestRepoSettingsLongDesc
+
+const attestRepoSettingsGitlabExample = `
+# report the settings of the main branch as an attestation about a trail:
+kosli attest repo-settings gitlab \
+	--branch main \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--gitlab-token yourGitlabToken \
+	--gitlab-org yourGitlabOrg \
+	--repository yourGitlabGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report the settings of the main branch, and fail if they do not match the expected settings:
+kosli attest repo-settings gitlab \
+	--branch main \
+	--expected-settings expected-settings.yaml \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--gitlab-token yourGitlabToken \
+	--gitlab-org yourGitlabOrg \
+	--repository yourGitlabGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName \
+	--assert
+`
+
+func newAttestGitlabRepoSettingsCmd(out io.Writer) *cobra.Command {
+	o := newAttestRepoSettingsOptions()
+	gitlabFlagsValues := new(gitlabUtils.GitlabConfig)
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1),  // See CustomMaximumNArgs() below
+		Use:     "gitlab [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Aliases: []string{"gl"},
+		Short:   attestRepoSettingsGitlabShortDesc,
+		Long:    attestRepoSettingsGitlabLongDesc,
+		Example: attestRepoSettingsGitlabExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.validate(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.repoURLExplicit = cmd.Flags().Changed("repo-url")
+			o.repoNameExplicit = cmd.Flags().Changed("repository")
+			o.retriever = gitlabUtils.NewGitlabRepoSettingsRetrieverFunc(gitlabFlagsValues.Token,
+				gitlabFlagsValues.BaseURL, gitlabFlagsValues.Org, o.repoName)
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	addGitlabFlags(cmd, gitlabFlagsValues, ci)
+	addRepoSettingsFlags(cmd, o)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name",
+		"gitlab-token", "gitlab-org", "repository", "branch"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
@@ -271,6 +271,8 @@ func getGitRepoInfoFromEnvironment() (*gitview.GitRepoInfo, error) {
 	switch ci {
 	case github:
 		return getGitRepoInfoFromGitHub(), nil
+	case gitea:
+		return getGitRepoInfoFromGitea(), nil
 	case gitlab:
 		return getGitRepoInfoFromGitLab(), nil
 	case bitbucket:
@@ -296,6 +298,19 @@ func getGitRepoInfoFromGitHub() *gitview.GitRepoInfo {
 	}
 }
 
+// getGitRepoInfoFromGitea reads the GitHub-compatible variables set by Gitea
+// Actions and Forgejo Actions. Gitea is not a Kosli repo provider, so the repo
+// is reported as a plain git repo.
+func getGitRepoInfoFromGitea() *gitview.GitRepoInfo {
+	return &gitview.GitRepoInfo{
+		URL:           fmt.Sprintf("%s/%s", os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY")),
+		Name:          os.Getenv("GITHUB_REPOSITORY"),
+		ID:            os.Getenv("GITHUB_REPOSITORY_ID"),
+		Provider:      "git",
+		NamespacePath: splitNonEmpty(os.Getenv("GITHUB_REPOSITORY_OWNER")),
+	}
+}
+
 func getGitRepoInfoFromGitLab() *gitview.GitRepoInfo {
 	return &gitview.GitRepoInfo{
 		URL:           os.Getenv("CI_PROJECT_URL"),
@@ -517,6 +517,21 @@ func TestGetGitRepoInfoFromGitLab(t *testing.T) {
 	}
 }
 
+func TestGetGitRepoInfoFromGitea(t *testing.T) {
+	t.Setenv("GITHUB_SERVER_URL", "https://gitea.example.com")
+	t.Setenv("GITHUB_REPOSITORY", "acme/web")
+	t.Setenv("GITHUB_REPOSITORY_ID", "42")
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "acme")
+
+	result := getGitRepoInfoFromGitea()
+
+	assert.Equal(t, "https://gitea.example.com/acme/web", result.URL)
+	assert.Equal(t, "acme/web", result.Name)
+	assert.Equal(t, "42", result.ID)
+	assert.Equal(t, "git", result.Provider)
+	assert.Equal(t, []string{"acme"}, result.NamespacePath)
+}
+
 func TestValidateRepoFlags(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -17,6 +17,7 @@ import (
 	"unicode"
 
 	"github.com/kosli-dev/cli/internal/digest"
+	ghUtils "github.com/kosli-dev/cli/internal/github"
 	"github.com/kosli-dev/cli/internal/gitview"
 	log "github.com/kosli-dev/cli/internal/logger"
 	"github.com/kosli-dev/cli/internal/utils"
@@ -107,6 +108,7 @@ func confirmDeletion(prompt string, in io.Reader) (bool, error) {
 const (
 	bitbucket   = "Bitbucket"
 	github      = "Github"
+	gitea       = "Gitea Actions"
 	teamcity    = "Teamcity"
 	gitlab      = "Gitlab"
 	azureDevops = "Azure Devops"
@@ -117,7 +119,7 @@ const (
 )
 
 // supportedCIs the set of CI tools that are supported for defaulting
-var supportedCIs = []string{bitbucket, github, teamcity, gitlab, azureDevops, circleci, codeBuild}
+var supportedCIs = []string{bitbucket, github, gitea, teamcity, gitlab, azureDevops, circleci, codeBuild}
 
 // ciTemplates a map of kosli flags and corresponding default templates in supported CI tools
 var ciTemplates = map[string]map[string]string{
@@ -142,6 +144,19 @@ var ciTemplates = map[string]map[string]string{
 		// Bitbucket Pipelines is Cloud-only, so this is a known fact, not a heuristic.
 		"repo-provider": "bitbucket_cloud",
 	},
+	// Gitea Actions (and Forgejo Actions) mirror the GitHub Actions variables.
+	// Runs are addressed by their number, not their ID, in the web UI.
+	gitea: {
+		"git-commit":    "${GITHUB_SHA}",
+		"repository":    "${GITHUB_REPOSITORY}",
+		"org":           "${GITHUB_REPOSITORY_OWNER}",
+		"server-url":    "${GITHUB_SERVER_URL}",
+		"commit-url":    "${GITHUB_SERVER_URL}/${GITHUB_REPOSITORY}/commit/${GITHUB_SHA}",
+		"build-url":     "${GITHUB_SERVER_URL}/${GITHUB_REPOSITORY}/actions/runs/${GITHUB_RUN_NUMBER}",
+		"repo-id":       "${GITHUB_REPOSITORY_ID}",
+		"repo-url":      "${GITHUB_SERVER_URL}/${GITHUB_REPOSITORY}",
+		"repo-provider": "git",
+	},
 	teamcity: {
 		"git-commit": "${BUILD_VCS_NUMBER}",
 	},
@@ -212,6 +227,9 @@ func GetCIDefaultsTemplates(ciTools, keys []string) string {
 func WhichCI() string {
 	if _, ok := os.LookupEnv("BITBUCKET_BUILD_NUMBER"); ok {
 		return bitbucket
+	} else if isGiteaActions() {
+		// checked before GitHub, as Gitea Actions also sets the GITHUB_* variables
+		return gitea
 	} else if _, ok := os.LookupEnv("GITHUB_RUN_NUMBER"); ok {
 		return github
 	} else if _, ok := os.LookupEnv("TEAMCITY_VERSION"); ok {
@@ -231,6 +249,13 @@ func WhichCI() string {
 	}
 }
 
+// isGiteaActions reports whether we run in Gitea Actions or Forgejo Actions.
+func isGiteaActions() bool {
+	_, inGitea := os.LookupEnv("GITEA_ACTIONS")
+	_, inForgejo := os.LookupEnv("FORGEJO_ACTIONS")
+	return inGitea || inForgejo
+}
+
 // DefaultValue looks up the default value of a given flag in a given CI tool
 // if the DOCS env variable is set, return empty string to avoid
 // having irrelevant defaults in the docs
@@ -826,3 +851,27 @@ func BooleanArgsMessageLink(args []string) string {
 	}
 
 }
+
+// useGithubAuth checks that either --github-token or the --github-app-*
+// flags are set, and makes retriever authenticate as the GitHub App when
+// they are. Retrievers replaced in tests are left as they are.
+func useGithubAuth(retriever any, githubFlagsValueHolder *ghUtils.GithubFlagsTempValueHolder) error {
+	app := githubFlagsValueHolder.App
+	if !app.IsSet() {
+		if githubFlagsValueHolder.Token == "" {
+			return fmt.Errorf("--github-token is required, unless you authenticate as a GitHub App with --github-app-id, --github-app-installation-id and --github-app-private-key")
+		}
+		return nil
+	}
+	if githubFlagsValueHolder.Token != "" {
+		return fmt.Errorf("only one of --github-token, --github-app-id is allowed")
+	}
+	tokenSource, err := ghUtils.NewAppTokenSource(app, githubFlagsValueHolder.BaseURL)
+	if err != nil {
+		return err
+	}
+	if config, ok := retriever.(*ghUtils.GithubConfig); ok {
+		config.TokenSource = tokenSource
+	}
+	return nil
+}
@@ -4,6 +4,10 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"os"
@@ -11,6 +15,7 @@ import (
 	"strings"
 	"testing"
 
+	ghUtils "github.com/kosli-dev/cli/internal/github"
 	log "github.com/kosli-dev/cli/internal/logger"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -60,6 +65,16 @@ func (suite *CliUtilsTestSuite) TestWhichCI() {
 			envVars: map[string]string{"GITHUB_RUN_NUMBER": "50"},
 			want:    github,
 		},
+		{
+			name:    "Gitea actions is detected before Github actions.",
+			envVars: map[string]string{"GITEA_ACTIONS": "true", "GITHUB_RUN_NUMBER": "50"},
+			want:    gitea,
+		},
+		{
+			name:    "Forgejo actions is detected as Gitea actions.",
+			envVars: map[string]string{"FORGEJO_ACTIONS": "true", "GITHUB_RUN_NUMBER": "50"},
+			want:    gitea,
+		},
 		{
 			name:    "Bitbucket actions is detected.",
 			envVars: map[string]string{"BITBUCKET_BUILD_NUMBER": "50"},
@@ -97,7 +112,7 @@ func (suite *CliUtilsTestSuite) TestWhichCI() {
 // whichCIEnvVars lists every env var WhichCI() checks, so tests can clear
 // ambient values from the developer's shell before asserting on its result.
 var whichCIEnvVars = []string{
-	"BITBUCKET_BUILD_NUMBER", "GITHUB_RUN_NUMBER", "TEAMCITY_VERSION",
+	"BITBUCKET_BUILD_NUMBER", "GITEA_ACTIONS", "FORGEJO_ACTIONS", "GITHUB_RUN_NUMBER", "TEAMCITY_VERSION",
 	"GITLAB_CI", "TF_BUILD", "CIRCLECI", "CODEBUILD_CI", "JENKINS_URL",
 }
 
@@ -143,6 +158,20 @@ func (suite *CliUtilsTestSuite) TestDefaultValue() {
 			},
 			want: "cyber-dojo/dashboard",
 		},
+		{
+			name: "Lookup default build url for Gitea.",
+			args: args{
+				ci:   gitea,
+				flag: "build-url",
+				envVars: map[string]string{
+					"GITHUB_SERVER_URL": "https://gitea.example.com",
+					"GITHUB_REPOSITORY": "acme/web",
+					"GITHUB_RUN_NUMBER": "12",
+				},
+				unsetTestsEnvVar: true,
+			},
+			want: "https://gitea.example.com/acme/web/actions/runs/12",
+		},
 		{
 			name: "Lookup an existing default for Bitbucket.",
 			args: args{
@@ -723,6 +752,55 @@ func (suite *CliUtilsTestSuite) TestValidateRegistryFlags() {
 	}
 }
 
+func (suite *CliUtilsTestSuite) TestUseGithubAuth() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(suite.T(), err)
+	privateKey := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	app := ghUtils.AppConfig{AppID: "1234", InstallationID: 42, PrivateKey: privateKey}
+
+	for _, t := range []struct {
+		name          string
+		values        ghUtils.GithubFlagsTempValueHolder
+		wantError     string
+		wantAppTokens bool
+	}{
+		{
+			name:   "a token is enough",
+			values: ghUtils.GithubFlagsTempValueHolder{Token: "ghp_xxx"},
+		},
+		{
+			name:          "a GitHub App replaces the token",
+			values:        ghUtils.GithubFlagsTempValueHolder{App: app},
+			wantAppTokens: true,
+		},
+		{
+			name:      "the token or a GitHub App is required",
+			wantError: "--github-token is required, unless you authenticate as a GitHub App with --github-app-id, --github-app-installation-id and --github-app-private-key",
+		},
+		{
+			name:      "not both",
+			values:    ghUtils.GithubFlagsTempValueHolder{Token: "ghp_xxx", App: app},
+			wantError: "only one of --github-token, --github-app-id is allowed",
+		},
+		{
+			name:      "a GitHub App needs all its flags",
+			values:    ghUtils.GithubFlagsTempValueHolder{App: ghUtils.AppConfig{AppID: "1234"}},
+			wantError: "a GitHub App needs an App ID, an installation ID and a private key",
+		},
+	} {
+		suite.Run(t.name, func() {
+			config := ghUtils.NewGithubConfig(t.values.Token, "", "acme", "web", false)
+			err := useGithubAuth(config, &t.values)
+			if t.wantError != "" {
+				require.EqualError(suite.T(), err, t.wantError)
+				return
+			}
+			require.NoError(suite.T(), err)
+			require.Equal(suite.T(), t.wantAppTokens, config.TokenSource != nil)
+		})
+	}
+}
+
 // setEnvVars sets env variables
 func (suite *CliUtilsTestSuite) setEnvVars(envVars map[string]string) {
 	for key, value := range envVars {
@@ -6,6 +6,7 @@ import (
 	"github.com/kosli-dev/cli/internal/aws"
 	azUtils "github.com/kosli-dev/cli/internal/azure"
 	bbUtils "github.com/kosli-dev/cli/internal/bitbucket"
+	giteaUtils "github.com/kosli-dev/cli/internal/gitea"
 	ghUtils "github.com/kosli-dev/cli/internal/github"
 	gitlabUtils "github.com/kosli-dev/cli/internal/gitlab"
 	"github.com/spf13/cobra"
@@ -61,6 +62,9 @@ func addGithubFlags(cmd *cobra.Command, githubFlagsValueHolder *ghUtils.GithubFl
 		cmd.Flags().StringVar(&githubFlagsValueHolder.Repository, "repository", DefaultValue(ci, "repository"), repositoryFlag)
 	}
 	cmd.Flags().StringVar(&githubFlagsValueHolder.BaseURL, "github-base-url", "", githubBaseURLFlag)
+	cmd.Flags().StringVar(&githubFlagsValueHolder.App.AppID, "github-app-id", "", githubAppIDFlag)
+	cmd.Flags().Int64Var(&githubFlagsValueHolder.App.InstallationID, "github-app-installation-id", 0, githubAppInstallationIDFlag)
+	cmd.Flags().StringVar(&githubFlagsValueHolder.App.PrivateKey, "github-app-private-key", "", githubAppPrivateKeyFlag)
 }
 
 func addAzureFlags(cmd *cobra.Command, azureFlagsValueHolder *azUtils.AzureFlagsTempValueHolder, ci string) {
@@ -81,8 +85,17 @@ func addGitlabFlags(cmd *cobra.Command, gitlabConfig *gitlabUtils.GitlabConfig,
 	}
 }
 
+func addGiteaFlags(cmd *cobra.Command, giteaConfig *giteaUtils.GiteaConfig, ci string) {
+	cmd.Flags().StringVar(&giteaConfig.Token, "gitea-token", "", giteaTokenFlag)
+	cmd.Flags().StringVar(&giteaConfig.Org, "gitea-org", DefaultValue(ci, "org"), giteaOrgFlag)
+	cmd.Flags().StringVar(&giteaConfig.BaseURL, "gitea-base-url", DefaultValue(ci, "server-url"), giteaBaseURLFlag)
+	if cmd.Flags().Lookup("repository") == nil {
+		cmd.Flags().StringVar(&giteaConfig.Repository, "repository", DefaultValue(ci, "repository"), repositoryFlag)
+	}
+}
+
 func addListFlags(cmd *cobra.Command, o *listOptions, customPageLimit ...int) {
-	cmd.Flags().StringVarP(&o.output, "output", "o", "table", outputFlag)
+	cmd.Flags().StringVarP(&o.output, "output", "o", "table", listOutputFlag)
 	cmd.Flags().IntVar(&o.pageNumber, "page", 1, pageNumberFlag)
 
 	// Use customPageLimit if provided, otherwise default to 15
@@ -92,6 +105,8 @@ func addListFlags(cmd *cobra.Command, o *listOptions, customPageLimit ...int) {
 	}
 
 	cmd.Flags().IntVarP(&o.pageLimit, "page-limit", "n", pageLimit, pageLimitFlag)
+	cmd.Flags().BoolVar(&o.all, "all", false, listAllFlag)
+	cmd.Flags().IntVar(&o.maxItems, "max-items", defaultMaxListItems, listMaxItemsFlag)
 }
 
 func addAttestationFlags(cmd *cobra.Command, o *CommonAttestationOptions, payload *CommonAttestationPayload, ci string) {
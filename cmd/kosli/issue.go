@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/gitview"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/telemetry"
+	"github.com/kosli-dev/cli/internal/types"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type IssueAttestationPayload struct {
+	*CommonAttestationPayload
+	IssueProvider string             `json:"issue_provider"`
+	IssueResults  []*types.IssueInfo `json:"issue_results"`
+}
+
+type attestIssueOptions struct {
+	*CommonAttestationOptions
+	retriever         any
+	secondarySource   string
+	ignoreBranchMatch bool
+	assert            bool
+	payload           IssueAttestationPayload
+}
+
+func newAttestIssueOptions() *attestIssueOptions {
+	return &attestIssueOptions{
+		CommonAttestationOptions: &CommonAttestationOptions{
+			fingerprintOptions: &fingerprintOptions{},
+		},
+		payload: IssueAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{},
+		},
+	}
+}
+
+// addIssueFlags adds the flags shared by all attest issue commands.
+func addIssueFlags(cmd *cobra.Command, o *attestIssueOptions) {
+	cmd.Flags().StringVar(&o.secondarySource, "issue-secondary-source", "", issueSecondarySourceFlag)
+	cmd.Flags().BoolVar(&o.ignoreBranchMatch, "ignore-branch-match", false, issueIgnoreBranchMatchFlag)
+	cmd.Flags().BoolVar(&o.assert, "assert", false, assertIssueEvidenceFlag)
+}
+
+// validate runs the checks shared by all attest issue commands before running them.
+func (o *attestIssueOptions) validate(cmd *cobra.Command, args []string) error {
+	err := CustomMaximumNArgs(1, args)
+	if err != nil {
+		return err
+	}
+
+	err = RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+	if err != nil {
+		return ErrorBeforePrintingUsage(cmd, err.Error())
+	}
+
+	err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
+	if err != nil {
+		return err
+	}
+
+	err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
+	if err != nil {
+		return fmt.Errorf("%s for --redact-commit-info", err.Error())
+	}
+
+	err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
+	if err != nil {
+		return ErrorBeforePrintingUsage(cmd, err.Error())
+	}
+
+	return ValidateRegistryFlags(cmd, o.fingerprintOptions)
+}
+
+func (o *attestIssueOptions) getRetriever() types.IssueRetriever {
+	return o.retriever.(types.IssueRetriever)
+}
+
+func (o *attestIssueOptions) run(args []string) error {
+	url, err := url.JoinPath(global.Host, "api/v2/attestations", global.Org, o.flowName, "trail", o.trailName, "issue")
+	if err != nil {
+		return err
+	}
+
+	err = o.CommonAttestationOptions.run(args, o.payload.CommonAttestationPayload)
+	if err != nil {
+		return err
+	}
+
+	retriever := o.getRetriever()
+	o.payload.IssueProvider = retriever.IssueProvider()
+	o.payload.IssueResults = []*types.IssueInfo{}
+
+	gv, err := gitview.New(o.srcRepoRoot)
+	if err != nil {
+		return err
+	}
+	commitInfo, err := gv.GetCommitInfoFromCommitSHA(o.payload.Commit.Sha1, true, []string{})
+	if err != nil {
+		return err
+	}
+
+	references := retriever.FindIssueReferences(issueSearchText(commitInfo, o.secondarySource, o.ignoreBranchMatch))
+	logger.Debug("Checked for %s issue references in Git commit %s on branch %s commit message:\n%s", o.payload.IssueProvider, commitInfo.Sha1, commitInfo.Branch, commitInfo.Message)
+	logger.Debug("the following %s issue references are found in commit message or branch name: %v", o.payload.IssueProvider, references)
+
+	issueLog := ""
+	issueFoundCount := 0
+	for _, reference := range references {
+		_, span := telemetry.Start(context.Background(), "issue provider issue",
+			attribute.String("kosli.issue_provider", o.payload.IssueProvider), attribute.String("kosli.issue_id", reference))
+		result, err := retriever.IssueInfo(reference)
+		telemetry.End(span, err)
+		if err != nil {
+			return err
+		}
+		if result == nil {
+			logger.Debug("%s is not an issue and is ignored", reference)
+			continue
+		}
+		o.payload.IssueResults = append(o.payload.IssueResults, result)
+		issueExistLog := "issue not found"
+		if result.IssueExists {
+			issueExistLog = "issue found"
+			issueFoundCount++
+		}
+		issueLog += fmt.Sprintf("\n\t%s: %s", result.IssueID, issueExistLog)
+	}
+
+	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
+	if err != nil {
+		return err
+	}
+	// if we created a tar package, remove it after uploading it
+	if cleanupNeeded {
+		defer func() {
+			if err := os.Remove(evidencePath); err != nil {
+				logger.Warn("failed to remove evidence file %s: %v", evidencePath, err)
+			}
+		}()
+	}
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodPost,
+		URL:    url,
+		Form:   form,
+		DryRun: global.DryRun,
+		Token:  global.ApiToken,
+	}
+	_, err = kosliClient.Do(reqParams)
+	if err == nil && !global.DryRun {
+		logger.Info("%s issue attestation '%s' is reported to trail: %s", o.payload.IssueProvider, o.payload.AttestationName, o.trailName)
+	}
+
+	if len(o.payload.IssueResults) == 0 && o.assert && !global.DryRun {
+		errString := ""
+		if err != nil {
+			errString = fmt.Sprintf("%s\nError: ", err.Error())
+		}
+		err = fmt.Errorf("%sno %s issue references are found in commit message or branch name", errString, o.payload.IssueProvider)
+	}
+
+	if issueFoundCount != len(o.payload.IssueResults) && o.assert && !global.DryRun {
+		errString := ""
+		if err != nil {
+			errString = fmt.Sprintf("%s\nError: ", err.Error())
+		}
+		err = fmt.Errorf("%smissing %s issues from references found in commit message or branch name%s", errString, o.payload.IssueProvider, issueLog)
+	}
+	return wrapAttestationError(err)
+}
+
+// issueSearchText joins the texts that are searched for issue references: the
+// commit message, the branch name unless ignoreBranchMatch is set, and the
+// secondary source when one is given.
+func issueSearchText(commitInfo *gitview.CommitInfo, secondarySource string, ignoreBranchMatch bool) string {
+	searchTexts := []string{commitInfo.Message}
+	if !ignoreBranchMatch {
+		searchTexts = append(searchTexts, commitInfo.Branch)
+	}
+	if secondarySource != "" {
+		searchTexts = append(searchTexts, secondarySource)
+	}
+	return strings.Join(searchTexts, "\n")
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	junit "github.com/joshdk/go-junit"
+	"github.com/kosli-dev/cli/internal/testreport"
+)
+
+// junitMessageLimit caps the failure message kept for each test case, so that
+// a stack trace dumped into the message attribute cannot bloat the payload.
+const junitMessageLimit = 1000
+
+// Test case statuses counted per suite. The first three come straight from the
+// report; flaky is only ever assigned when reruns are taken into account.
+const (
+	junitStatusSkipped = string(junit.StatusSkipped)
+	junitStatusFailed  = string(junit.StatusFailed)
+	junitStatusError   = string(junit.StatusError)
+	junitStatusFlaky   = "flaky"
+)
+
+// JUnitTestCase is the record of a single test case in a suite.
+// Attempts is only set when reruns are detected (--detect-flaky).
+type JUnitTestCase struct {
+	Classname string  `json:"classname"`
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Duration  float64 `json:"duration"`
+	Message   string  `json:"message,omitempty"`
+	Attempts  int     `json:"attempts,omitempty"`
+}
+
+// junitIngestOptions controls how much of each test report is kept beyond the
+// suite-level counts.
+type junitIngestOptions struct {
+	format           testreport.Format
+	includeTestCases bool
+	detectFlaky      bool
+}
+
+// flattenJunitTests returns the tests of a suite and of all its nested suites,
+// in document order.
+func flattenJunitTests(suite junit.Suite) []junit.Test {
+	tests := append([]junit.Test{}, suite.Tests...)
+	for _, nested := range suite.Suites {
+		tests = append(tests, flattenJunitTests(nested)...)
+	}
+	return tests
+}
+
+// junitTestCases turns the tests of a suite into test case records. Without
+// detectFlaky every <testcase> element is a record of its own. With it, the
+// attempts at one test are collapsed into a single record whose status is the
+// outcome of the last attempt, or flaky when an earlier attempt failed.
+// Attempts are recognized both from the reruns recorded in the report (e.g.
+// surefire rerun elements) and from the same test being reported more than
+// once, as pytest-rerunfailures and other retrying runners do.
+func junitTestCases(tests []junit.Test, reruns map[string]testreport.Reruns, detectFlaky bool) []*JUnitTestCase {
+	cases := []*JUnitTestCase{}
+	if !detectFlaky {
+		for _, test := range tests {
+			cases = append(cases, &JUnitTestCase{
+				Classname: test.Classname,
+				Name:      test.Name,
+				Status:    string(test.Status),
+				Duration:  test.Duration.Seconds(),
+				Message:   junitTestMessage(test),
+			})
+		}
+		return cases
+	}
+
+	var order []string
+	attempts := map[string][]junit.Test{}
+	for _, test := range tests {
+		key := testreport.TestKey(test.Classname, test.Name)
+		if _, seen := attempts[key]; !seen {
+			order = append(order, key)
+		}
+		attempts[key] = append(attempts[key], test)
+	}
+
+	for _, key := range order {
+		runs := attempts[key]
+		last := runs[len(runs)-1]
+		rerun := reruns[key]
+		testCase := &JUnitTestCase{
+			Classname: last.Classname,
+			Name:      last.Name,
+			Status:    string(last.Status),
+			Message:   junitTestMessage(last),
+			Attempts:  len(runs) + len(rerun.FlakyMessages) + rerun.RerunCount,
+		}
+		for _, run := range runs {
+			testCase.Duration += run.Duration.Seconds()
+		}
+
+		if last.Status == junit.StatusPassed {
+			flakyMessage, flaky := "", len(rerun.FlakyMessages) > 0
+			if flaky {
+				flakyMessage = rerun.FlakyMessages[len(rerun.FlakyMessages)-1]
+			}
+			for _, run := range runs[:len(runs)-1] {
+				if run.Status == junit.StatusFailed || run.Status == junit.StatusError {
+					flaky = true
+					flakyMessage = junitTestMessage(run)
+				}
+			}
+			if flaky {
+				testCase.Status = junitStatusFlaky
+				testCase.Message = truncateJunitMessage(flakyMessage)
+			}
+		}
+		cases = append(cases, testCase)
+	}
+	return cases
+}
+
+// junitTestMessage returns the message of a failed, errored or skipped test,
+// falling back to the failure body when the message attribute is empty.
+func junitTestMessage(test junit.Test) string {
+	message := test.Message
+	if message == "" && test.Error != nil {
+		message = test.Error.Error()
+	}
+	return truncateJunitMessage(message)
+}
+
+func truncateJunitMessage(message string) string {
+	runes := []rune(message)
+	if len(runes) <= junitMessageLimit {
+		return message
+	}
+	return string(runes[:junitMessageLimit]) + "..."
+}
+
+// applyJunitTestCaseTotals recomputes the counts of a suite result from its
+// collapsed test cases, so that a test that was retried is counted once and a
+// flaky test is not counted as a failure.
+func applyJunitTestCaseTotals(result *JUnitResults, cases []*JUnitTestCase) {
+	result.Total = len(cases)
+	result.Failures, result.Errors, result.Skipped, result.Flaky = 0, 0, 0, 0
+	result.FlakyTests = nil
+	for _, c := range cases {
+		switch c.Status {
+		case junitStatusFailed:
+			result.Failures++
+		case junitStatusError:
+			result.Errors++
+		case junitStatusSkipped:
+			result.Skipped++
+		case junitStatusFlaky:
+			result.Flaky++
+			result.FlakyTests = append(result.FlakyTests, junitTestDisplayName(c))
+		}
+	}
+}
+
+func junitTestDisplayName(c *JUnitTestCase) string {
+	if c.Classname == "" {
+		return c.Name
+	}
+	return c.Classname + "." + c.Name
+}
@@ -1,17 +1,30 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"strconv"
 
+	"github.com/kosli-dev/cli/internal/output"
+	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/spf13/cobra"
 )
 
 const listDesc = `All Kosli list commands.`
 
+// defaultMaxListItems is the default safety cap on the number of items
+// fetched by a list command with --all.
+const defaultMaxListItems = 10000
+
 type listOptions struct {
 	output     string
 	pageNumber int
 	pageLimit  int
+	all        bool
+	maxItems   int
 }
 
 func (o *listOptions) validate(cmd *cobra.Command) error {
@@ -21,6 +34,12 @@ func (o *listOptions) validate(cmd *cobra.Command) error {
 	if o.pageLimit <= 0 {
 		return ErrorBeforePrintingUsage(cmd, "page limit must be a positive integer")
 	}
+	if o.all && cmd.Flags().Changed("page") {
+		return ErrorBeforePrintingUsage(cmd, "only one of --all, --page is allowed")
+	}
+	if o.maxItems <= 0 {
+		return ErrorBeforePrintingUsage(cmd, "max items must be a positive integer")
+	}
 	return nil
 }
 
@@ -49,3 +68,216 @@ func newListCmd(out io.Writer) *cobra.Command {
 
 	return cmd
 }
+
+// listPageFunc returns the raw response to the request for a page of a list.
+type listPageFunc func(page int) (string, error)
+
+// listPage returns a listPageFunc getting pages of perPage items of the list
+// at base, filtered by params. With a perPage of 0 the page is ignored and
+// the whole list is requested, for the endpoints that only paginate when
+// asked to.
+func listPage(base string, params url.Values, perPage int) listPageFunc {
+	return func(page int) (string, error) {
+		query := url.Values{}
+		for key, values := range params {
+			query[key] = values
+		}
+		if perPage > 0 {
+			query.Set("page", strconv.Itoa(page))
+			query.Set("per_page", strconv.Itoa(perPage))
+		}
+		reqURL := base
+		if encoded := query.Encode(); encoded != "" {
+			reqURL = base + "?" + encoded
+		}
+		response, err := kosliClient.Do(&requests.RequestParams{
+			Method: http.MethodGet,
+			URL:    reqURL,
+			Token:  global.ApiToken,
+		})
+		if err != nil {
+			return "", err
+		}
+		return response.Body, nil
+	}
+}
+
+// printList prints a list in the output format of o, using printFunctions
+// for the formats of the command. It prints the page of o, or with --all
+// every page up to the --max-items cap. itemsKey is the key of the items in
+// a response that is a JSON object, or "" when the response is a JSON list.
+//
+// The jsonl and csv formats print the items of the list, one per line or
+// row. With --all, jsonl prints each page as soon as it is received, so a
+// long list streams to its consumer.
+func (o *listOptions) printList(out io.Writer, getPage listPageFunc, itemsKey string, printFunctions map[string]output.FormatOutputFunc) error {
+	if !output.IsSupported(o.output, printFunctions) {
+		return fmt.Errorf("unsupported output format: %s", o.output)
+	}
+	itemsOnly := o.output == "jsonl" || o.output == "csv"
+
+	if !o.all {
+		raw, err := getPage(o.pageNumber)
+		if err != nil {
+			return err
+		}
+		if itemsOnly {
+			page, err := parseListPage(raw, itemsKey, o.pageNumber, o.pageLimit)
+			if err != nil {
+				return err
+			}
+			if raw, err = listItemsJSON(page.items); err != nil {
+				return err
+			}
+		}
+		return output.FormattedPrint(raw, o.output, out, o.pageNumber, printFunctions)
+	}
+
+	var items []json.RawMessage
+	var last *listResponsePage
+	for pageNumber := 1; ; pageNumber++ {
+		raw, err := getPage(pageNumber)
+		if err != nil {
+			return err
+		}
+		page, err := parseListPage(raw, itemsKey, pageNumber, o.pageLimit)
+		if err != nil {
+			return err
+		}
+		last = page
+
+		pageItems := page.items
+		capped := len(items)+len(pageItems) >= o.maxItems
+		if capped {
+			pageItems = pageItems[:o.maxItems-len(items)]
+		}
+		items = append(items, pageItems...)
+		if o.output == "jsonl" {
+			raw, err := listItemsJSON(pageItems)
+			if err != nil {
+				return err
+			}
+			if err := output.PrintJsonLines(raw, out, pageNumber); err != nil {
+				return err
+			}
+		}
+
+		if !page.more {
+			break
+		}
+		if capped {
+			logger.Warn("stopped after %d items, the --max-items limit. Increase it to get the rest of the list", o.maxItems)
+			break
+		}
+	}
+
+	if o.output == "jsonl" {
+		return nil
+	}
+	var raw string
+	var err error
+	if itemsOnly {
+		raw, err = listItemsJSON(items)
+	} else {
+		raw, err = last.withItems(items)
+	}
+	if err != nil {
+		return err
+	}
+	return output.FormattedPrint(raw, o.output, out, 1, printFunctions)
+}
+
+// listItemsJSON returns items as a JSON list.
+func listItemsJSON(items []json.RawMessage) (string, error) {
+	if items == nil {
+		items = []json.RawMessage{}
+	}
+	raw, err := json.Marshal(items)
+	return string(raw), err
+}
+
+// listResponsePage is a page of a list response.
+type listResponsePage struct {
+	items []json.RawMessage
+	// more is true when there are pages after this one.
+	more bool
+	// itemsKey and envelope are the key of the items and the fields of a
+	// response that is a JSON object.
+	itemsKey string
+	envelope map[string]json.RawMessage
+}
+
+// parseListPage parses the response to the request for page of a list of
+// perPage items per page.
+func parseListPage(raw, itemsKey string, page, perPage int) (*listResponsePage, error) {
+	result := &listResponsePage{itemsKey: itemsKey}
+	itemsJSON := []byte(raw)
+	totalPages := -1
+	if itemsKey != "" {
+		if err := json.Unmarshal([]byte(raw), &result.envelope); err != nil {
+			return nil, fmt.Errorf("failed to parse the list response: %v", err)
+		}
+		itemsJSON = result.envelope[itemsKey]
+		totalPages = listTotalPages(result.envelope)
+	}
+	if len(itemsJSON) > 0 && string(itemsJSON) != "null" {
+		if err := json.Unmarshal(itemsJSON, &result.items); err != nil {
+			return nil, fmt.Errorf("failed to parse the list response: %v", err)
+		}
+	}
+
+	switch {
+	case len(result.items) == 0:
+		result.more = false
+	case totalPages >= 0:
+		result.more = page < totalPages
+	default:
+		// without a page count, a full page may be followed by another one
+		result.more = len(result.items) >= perPage
+	}
+	return result, nil
+}
+
+// listTotalPages returns the page count of a list response envelope, given
+// as total_pages or as pagination.page_count, or -1 when it has none.
+func listTotalPages(envelope map[string]json.RawMessage) int {
+	var count float64
+	if raw, ok := envelope["total_pages"]; ok && json.Unmarshal(raw, &count) == nil {
+		return int(count)
+	}
+	var pagination struct {
+		PageCount *float64 `json:"page_count"`
+	}
+	if raw, ok := envelope["pagination"]; ok && json.Unmarshal(raw, &pagination) == nil && pagination.PageCount != nil {
+		return int(*pagination.PageCount)
+	}
+	return -1
+}
+
+// withItems returns the response of p with items instead of its own, as a
+// single page holding all of them, so the print functions of a page can
+// print the whole list.
+func (p *listResponsePage) withItems(items []json.RawMessage) (string, error) {
+	if p.envelope == nil {
+		return listItemsJSON(items)
+	}
+	if items == nil {
+		items = []json.RawMessage{}
+	}
+
+	envelope := map[string]any{}
+	for key, value := range p.envelope {
+		envelope[key] = value
+	}
+	envelope[p.itemsKey] = items
+	for key, value := range map[string]int{"page": 1, "total_pages": 1, "total_count": len(items)} {
+		if _, ok := envelope[key]; ok {
+			envelope[key] = value
+		}
+	}
+	if _, ok := envelope["pagination"]; ok {
+		envelope["pagination"] = Pagination{Page: 1, PageCount: 1, Total: float64(len(items))}
+	}
+	raw, err := json.Marshal(envelope)
+	return string(raw), err
+}
@@ -4,12 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
-	"strconv"
 
 	"github.com/kosli-dev/cli/internal/output"
-	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/spf13/cobra"
 )
 
@@ -86,26 +83,13 @@ func (o *listArtifactsOptions) run(out io.Writer) error {
 		return err
 	}
 	params := url.Values{}
-	params.Set("page", strconv.Itoa(o.pageNumber))
-	params.Set("per_page", strconv.Itoa(o.pageLimit))
 	if o.flowName != "" {
 		params.Set("flow_name", o.flowName)
 	} else if o.repoName != "" {
 		params.Set("repo_name", o.repoName)
 	}
-	reqURL := base + "?" + params.Encode()
 
-	reqParams := &requests.RequestParams{
-		Method: http.MethodGet,
-		URL:    reqURL,
-		Token:  global.ApiToken,
-	}
-	response, err := kosliClient.Do(reqParams)
-	if err != nil {
-		return err
-	}
-
-	return output.FormattedPrint(response.Body, o.output, out, o.pageNumber,
+	return o.printList(out, listPage(base, params, o.pageLimit), "",
 		map[string]output.FormatOutputFunc{
 			"table": printArtifactsListAsTable,
 			"json":  output.PrintJson,
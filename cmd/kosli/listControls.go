@@ -4,12 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
-	"strconv"
 
 	"github.com/kosli-dev/cli/internal/output"
-	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/spf13/cobra"
 )
 
@@ -109,8 +106,6 @@ func (o *listControlsOptions) run(out io.Writer) error {
 	}
 
 	params := url.Values{}
-	params.Set("page", strconv.Itoa(o.pageNumber))
-	params.Set("per_page", strconv.Itoa(o.pageLimit))
 	if o.search != "" {
 		params.Set("search", o.search)
 	}
@@ -123,19 +118,8 @@ func (o *listControlsOptions) run(out io.Writer) error {
 	if o.sortDirection != "" {
 		params.Set("sort_direction", o.sortDirection)
 	}
-	reqURL := base + "?" + params.Encode()
 
-	reqParams := &requests.RequestParams{
-		Method: http.MethodGet,
-		URL:    reqURL,
-		Token:  global.ApiToken,
-	}
-	response, err := kosliClient.Do(reqParams)
-	if err != nil {
-		return err
-	}
-
-	return output.FormattedPrint(response.Body, o.output, out, o.pageNumber,
+	return o.printList(out, listPage(base, params, o.pageLimit), "controls",
 		map[string]output.FormatOutputFunc{
 			"table": printControlsListAsTable,
 			"json":  output.PrintJson,
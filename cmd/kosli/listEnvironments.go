@@ -4,14 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/kosli-dev/cli/internal/output"
-	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/spf13/cobra"
 )
 
@@ -93,7 +90,7 @@ func newListEnvironmentsCmd(out io.Writer) *cobra.Command {
 			if err != nil {
 				return ErrorBeforePrintingUsage(cmd, err.Error())
 			}
-			o.withPagination = cmd.Flags().Changed("page") || cmd.Flags().Changed("page-limit")
+			o.withPagination = o.all || cmd.Flags().Changed("page") || cmd.Flags().Changed("page-limit")
 			if o.withPagination {
 				return o.validate(cmd)
 			}
@@ -121,11 +118,11 @@ func (o *environmentLsOptions) run(out io.Writer, args []string) error {
 		return err
 	}
 
-	params := url.Values{}
+	perPage, itemsKey := 0, ""
 	if o.withPagination {
-		params.Set("page", strconv.Itoa(o.pageNumber))
-		params.Set("per_page", strconv.Itoa(o.pageLimit))
+		perPage, itemsKey = o.pageLimit, "environments"
 	}
+	params := url.Values{}
 	if o.name != "" {
 		params.Set("name", o.name)
 	}
@@ -144,22 +141,8 @@ func (o *environmentLsOptions) run(out io.Writer, args []string) error {
 	if o.sortDirection != "" {
 		params.Set("sort_direction", o.sortDirection)
 	}
-	reqURL := base
-	if encoded := params.Encode(); encoded != "" {
-		reqURL = base + "?" + encoded
-	}
-
-	reqParams := &requests.RequestParams{
-		Method: http.MethodGet,
-		URL:    reqURL,
-		Token:  global.ApiToken,
-	}
-	response, err := kosliClient.Do(reqParams)
-	if err != nil {
-		return err
-	}
 
-	return output.FormattedPrint(response.Body, o.output, out, o.pageNumber,
+	return o.printList(out, listPage(base, params, perPage), itemsKey,
 		map[string]output.FormatOutputFunc{
 			"table": printEnvListAsTable,
 			"json":  output.PrintJson,
@@ -4,13 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
 
 	"github.com/kosli-dev/cli/internal/output"
-	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/spf13/cobra"
 )
 
@@ -74,7 +71,7 @@ func newListFlowsCmd(out io.Writer) *cobra.Command {
 			// pagination is opt-in: only when the user explicitly sets --page or
 			// --page-limit do we paginate. Otherwise all flows are returned (the
 			// historical behaviour), keeping output backwards compatible.
-			o.paginate = cmd.Flags().Changed("page") || cmd.Flags().Changed("page-limit")
+			o.paginate = o.all || cmd.Flags().Changed("page") || cmd.Flags().Changed("page-limit")
 			return o.run(out)
 		},
 	}
@@ -92,13 +89,13 @@ func (o *listFlowsOptions) run(out io.Writer) error {
 		return err
 	}
 
-	params := url.Values{}
+	// sending per_page switches the endpoint to the paginated {data, pagination}
+	// envelope; omitting it returns all flows as a plain array (the default)
+	perPage, itemsKey := 0, ""
 	if o.paginate {
-		// sending per_page switches the endpoint to the paginated {data, pagination}
-		// envelope; omitting it returns all flows as a plain array (the default)
-		params.Set("page", strconv.Itoa(o.pageNumber))
-		params.Set("per_page", strconv.Itoa(o.pageLimit))
+		perPage, itemsKey = o.pageLimit, "data"
 	}
+	params := url.Values{}
 	if o.name != "" {
 		params.Set("search_by_name", o.name)
 		// case_sensitive only affects search, so only send it alongside a search term
@@ -106,22 +103,8 @@ func (o *listFlowsOptions) run(out io.Writer) error {
 			params.Set("case_sensitive", "false")
 		}
 	}
-	reqURL := base
-	if encoded := params.Encode(); encoded != "" {
-		reqURL = base + "?" + encoded
-	}
-
-	reqParams := &requests.RequestParams{
-		Method: http.MethodGet,
-		URL:    reqURL,
-		Token:  global.ApiToken,
-	}
-	response, err := kosliClient.Do(reqParams)
-	if err != nil {
-		return err
-	}
 
-	return output.FormattedPrint(response.Body, o.output, out, o.pageNumber,
+	return o.printList(out, listPage(base, params, perPage), itemsKey,
 		map[string]output.FormatOutputFunc{
 			"table": printFlowsListAsTable,
 			"json":  output.PrintJson,
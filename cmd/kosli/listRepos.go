@@ -4,11 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	neturl "net/url"
 
 	"github.com/kosli-dev/cli/internal/output"
-	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/spf13/cobra"
 )
 
@@ -111,8 +109,6 @@ func newListReposCmd(out io.Writer) *cobra.Command {
 
 func (o *listReposOptions) run(out io.Writer) error {
 	params := neturl.Values{}
-	params.Set("page", fmt.Sprintf("%d", o.pageNumber))
-	params.Set("per_page", fmt.Sprintf("%d", o.pageLimit))
 	if o.name != "" {
 		params.Set("name", o.name)
 	}
@@ -135,19 +131,8 @@ func (o *listReposOptions) run(out io.Writer) error {
 	if err != nil {
 		return err
 	}
-	reqURL := base + "?" + params.Encode()
 
-	reqParams := &requests.RequestParams{
-		Method: http.MethodGet,
-		URL:    reqURL,
-		Token:  global.ApiToken,
-	}
-	response, err := kosliClient.Do(reqParams)
-	if err != nil {
-		return err
-	}
-
-	return output.FormattedPrint(response.Body, o.output, out, o.pageNumber,
+	return o.printList(out, listPage(base, params, o.pageLimit), "repos",
 		map[string]output.FormatOutputFunc{
 			"table": printReposListAsTable,
 			"json":  output.PrintJson,
@@ -4,13 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/kosli-dev/cli/internal/output"
-	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/spf13/cobra"
 	"github.com/xeonx/timeago"
 )
@@ -97,23 +95,10 @@ func (o *listSnapshotsOptions) getSnapshotsList(out io.Writer, envName, interval
 		return err
 	}
 	params := url.Values{}
-	params.Set("page", strconv.Itoa(o.pageNumber))
-	params.Set("per_page", strconv.Itoa(o.pageLimit))
 	params.Set("interval", interval)
 	params.Set("reverse", strconv.FormatBool(o.reverse))
-	reqURL := base + "?" + params.Encode()
 
-	reqParams := &requests.RequestParams{
-		Method: http.MethodGet,
-		URL:    reqURL,
-		Token:  global.ApiToken,
-	}
-	response, err := kosliClient.Do(reqParams)
-	if err != nil {
-		return err
-	}
-
-	return output.FormattedPrint(response.Body, o.output, out, o.pageNumber,
+	return o.printList(out, listPage(base, params, o.pageLimit), "",
 		map[string]output.FormatOutputFunc{
 			"table": printSnapshotsListAsTable,
 			"json":  output.PrintJson,
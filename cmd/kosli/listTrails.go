@@ -4,13 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	neturl "net/url"
-	"strconv"
 	"strings"
 
 	"github.com/kosli-dev/cli/internal/output"
-	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/spf13/cobra"
 )
 
@@ -57,6 +54,14 @@ kosli list trails \
 	--org yourOrgName \
 	--output json 
 
+# get every trail of a flow, one JSON object per line:
+kosli list trails \
+	--flow yourFlowName \
+	--all \
+	--output jsonl \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
 	# get a paginated list of trails across all flows tagged with the provided key-value pair:
 kosli list trails \
 	--flow-tag team=backend \
@@ -132,8 +137,6 @@ func (o *listTrailsOptions) run(out io.Writer) error {
 		return err
 	}
 	q := neturl.Values{}
-	q.Set("per_page", strconv.Itoa(o.pageLimit))
-	q.Set("page", strconv.Itoa(o.pageNumber))
 	if o.flowName != "" {
 		q.Set("flow", o.flowName)
 	}
@@ -143,19 +146,8 @@ func (o *listTrailsOptions) run(out io.Writer) error {
 	if o.flowTag != "" {
 		q.Set("flow_tag", o.flowTag)
 	}
-	url := base + "?" + q.Encode()
-
-	reqParams := &requests.RequestParams{
-		Method: http.MethodGet,
-		URL:    url,
-		Token:  global.ApiToken,
-	}
-	response, err := kosliClient.Do(reqParams)
-	if err != nil {
-		return err
-	}
 
-	return output.FormattedPrint(response.Body, o.output, out, o.pageNumber,
+	return o.printList(out, listPage(base, q, o.pageLimit), "data",
 		map[string]output.FormatOutputFunc{
 			"table": printTrailsListAsTable,
 			"json":  output.PrintJson,
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeListServer returns a fake Kosli API serving a list of total items,
+// as a paginated {data, pagination} envelope for trails and as a plain JSON
+// list for artifacts.
+func newFakeListServer(t *testing.T, total int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		items := []map[string]any{}
+		for i := (page-1)*perPage + 1; i <= min(page*perPage, total); i++ {
+			items = append(items, map[string]any{
+				"name":             fmt.Sprintf("item-%d", i),
+				"fingerprint":      fmt.Sprintf("fp-%d", i),
+				"compliance_state": "COMPLIANT",
+			})
+		}
+		var body any = items
+		if strings.Contains(r.URL.Path, "/api/v2/trails/") {
+			body = map[string]any{
+				"data": items,
+				"pagination": map[string]any{
+					"page": page, "page_count": (total + perPage - 1) / perPage, "total": total,
+				},
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestListAll(t *testing.T) {
+	server := newFakeListServer(t, 5)
+	args := fmt.Sprintf("--host %s --org test-org --api-token test-token --max-api-retries 0", server.URL)
+
+	jsonLines := func(from, to int) string {
+		lines := ""
+		for i := from; i <= to; i++ {
+			lines += fmt.Sprintf(`{"compliance_state":"COMPLIANT","fingerprint":"fp-%d","name":"item-%d"}`+"\n", i, i)
+		}
+		return lines
+	}
+
+	csvRows := func(from, to int) string {
+		rows := "compliance_state,fingerprint,name\n"
+		for i := from; i <= to; i++ {
+			rows += fmt.Sprintf("COMPLIANT,fp-%d,item-%d\n", i, i)
+		}
+		return rows
+	}
+
+	tests := []cmdTestCase{
+		{
+			name:         "--all follows the page count of a paginated envelope",
+			cmd:          "list trails --all --page-limit 2 --output jsonl " + args,
+			goldenStdout: jsonLines(1, 5),
+		},
+		{
+			name:         "--all follows full pages of a plain list",
+			cmd:          "list artifacts --all --page-limit 2 --output jsonl " + args,
+			goldenStdout: jsonLines(1, 5),
+		},
+		{
+			name: "--all prints every item as a single page in json",
+			cmd:  "list trails --all --page-limit 2 --output json " + args,
+			goldenJson: []jsonCheck{
+				{"data.[4].name", "item-5"},
+				{"pagination.page", float64(1)},
+				{"pagination.page_count", float64(1)},
+				{"pagination.total", float64(5)},
+			},
+		},
+		{
+			name:        "--all prints every item in a table",
+			cmd:         "list trails --all --page-limit 2 " + args,
+			goldenRegex: `(?s)item-1.*item-5.*Showing page 1 of 1, total 5 items`,
+		},
+		{
+			name:         "--all stops at --max-items",
+			cmd:          "list trails --all --page-limit 2 --max-items 3 --output jsonl " + args,
+			goldenStdout: jsonLines(1, 3),
+			goldenStderr: "[warning] stopped after 3 items, the --max-items limit. Increase it to get the rest of the list\n",
+		},
+		{
+			name:         "jsonl prints the items of a single page",
+			cmd:          "list trails --page 2 --page-limit 2 --output jsonl " + args,
+			goldenStdout: jsonLines(3, 4),
+		},
+		{
+			name:         "csv prints a row per item of every page",
+			cmd:          "list trails --all --page-limit 2 --output csv " + args,
+			goldenStdout: csvRows(1, 5),
+		},
+		{
+			name:        "yaml prints the response of a page",
+			cmd:         "list trails --page-limit 2 --output yaml " + args,
+			goldenRegex: `(?s)^data:\n  - compliance_state: COMPLIANT\n.*name: item-2\npagination:\n  page: 1\n  page_count: 3\n  total: 5\n$`,
+		},
+		{
+			wantError: true,
+			name:      "--all cannot be used with --page",
+			cmd:       "list trails --all --page 2 " + args,
+			golden:    "Error: only one of --all, --page is allowed\nUsage: kosli list trails [flags]\n",
+		},
+		{
+			wantError: true,
+			name:      "--max-items must be positive",
+			cmd:       "list artifacts --all --max-items 0 " + args,
+			golden:    "Error: max items must be a positive integer\nUsage: kosli list artifacts [flags]\n",
+		},
+		{
+			wantError: true,
+			name:      "an unsupported output format fails before any request",
+			cmd:       "list trails --all --output xml " + args,
+			golden:    "Error: unsupported output format: xml\n",
+		},
+	}
+
+	runTestCmd(t, tests)
+}
@@ -3,12 +3,10 @@ package main
 import (
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
 	"strconv"
 
 	"github.com/kosli-dev/cli/internal/output"
-	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/spf13/cobra"
 )
 
@@ -147,8 +145,6 @@ func (o *logEnvironmentOptions) getEnvironmentEvents(out io.Writer, envName, int
 		return fmt.Errorf("failed to join URL path: %w", err)
 	}
 	q := u.Query()
-	q.Set("page", strconv.Itoa(o.pageNumber))
-	q.Set("per_page", strconv.Itoa(o.pageLimit))
 	q.Set("interval", interval)
 	q.Set("reverse", strconv.FormatBool(o.reverse))
 	for _, repo := range o.repos {
@@ -168,18 +164,8 @@ func (o *logEnvironmentOptions) getEnvironmentEvents(out io.Writer, envName, int
 	if o.endTS != 0 {
 		q.Set("end_ts", strconv.FormatFloat(o.endTS, 'f', -1, 64))
 	}
-	u.RawQuery = q.Encode()
 
-	reqParams := &requests.RequestParams{
-		Method: http.MethodGet,
-		URL:    u.String(),
-		Token:  global.ApiToken,
-	}
-	response, err := kosliClient.Do(reqParams)
-	if err != nil {
-		return err
-	}
-	return output.FormattedPrint(response.Body, o.output, out, o.pageNumber,
+	return o.printList(out, listPage(u.String(), q, o.pageLimit), "",
 		map[string]output.FormatOutputFunc{
 			"table": printEnvironmentEventsLogAsTable,
 			"json":  output.PrintJson,
@@ -9,6 +9,7 @@ import (
 
 	log "github.com/kosli-dev/cli/internal/logger"
 	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/telemetry"
 	"github.com/kosli-dev/cli/internal/version"
 	"github.com/spf13/cobra"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -26,6 +27,11 @@ func init() {
 }
 
 func main() {
+	if err := telemetry.Setup(version.GetVersion()); err != nil {
+		logger.Warn("OpenTelemetry tracing is disabled: %v", err)
+	}
+	span := telemetry.StartCommand("kosli")
+
 	var err error
 	if isMultiHost() {
 		var output string
@@ -38,6 +44,11 @@ func main() {
 			err = innerMain(cmd, os.Args)
 		}
 	}
+	telemetry.End(span, err)
+	// logger.Error exits, so the spans are exported before it
+	if shutdownErr := telemetry.Shutdown(); shutdownErr != nil {
+		logger.Warn("failed to export the OpenTelemetry spans: %v", shutdownErr)
+	}
 	if err != nil {
 		logger.Error(err.Error())
 	}
@@ -146,6 +157,9 @@ func innerMain(cmd *cobra.Command, args []string) error {
 		}
 	}
 	executedCmd, err := cmd.ExecuteC()
+	if executedCmd != nil {
+		telemetry.CommandSpan().SetName(executedCmd.CommandPath())
+	}
 	if err == nil {
 		// Cobra handles --version internally and bypasses all hooks, so we print
 		// the update notice here after the fact.
@@ -1,32 +1,86 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 
+	"github.com/kosli-dev/cli/internal/gitview"
+	"github.com/kosli-dev/cli/internal/prcache"
 	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/review"
+	"github.com/kosli-dev/cli/internal/telemetry"
 	"github.com/kosli-dev/cli/internal/types"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+const prReviewAnalysisDesc = `Each pull request in the attestation includes a review analysis: which approvers also authored commits
+of the pull request, which approvals were given before its last commit (when the provider reports approval times),
+and whether the code owners of the changed files approved it. The CODEOWNERS file and the changed files are read
+from the git repository at ^--repo-root^. A commit rebased or amended after an approval makes it stale, even when it
+was authored before. Approvers and commit authors are matched on their provider accounts, never on their names:
+the login on GitHub and Gitea, the account UUID on Bitbucket, the user found from the commit email on GitLab, and
+the email unique name on Azure DevOps. A pull request with an approver or commit author without an account, or found
+without its commits, has no approver known to be independent, so it does not pass four-eyes.
+`
+
+const prCommitRangeDesc = `With ^--oldest-commit^, the attestation covers the pull requests of every commit after the oldest commit up to
+and including ^--commit^, such as all the changes of a release. Each pull request is reported once, and the commits
+which were not introduced by any of the pull requests (direct pushes) are reported as unreviewed commits. In this
+mode, ^--assert^ also fails when there are unreviewed commits.
+`
+
+const prCacheDesc = `Rate limited calls to the provider API are retried once the rate limit resets. To make fewer calls, set
+^KOSLI_PR_CACHE_TTL^ (such as ^10m^) to cache the pull requests found for each commit on disk for that long,
+in ^KOSLI_PR_CACHE_DIR^ or the user cache directory. The pull requests of a commit are only cached once they are all
+merged or closed, as open ones can still change. Entries older than ^KOSLI_PR_CACHE_TTL^ are deleted from the
+directory when a new entry is written.
+`
+
 type PRAttestationPayload struct {
 	*CommonAttestationPayload
 	GitProvider  string              `json:"git_provider"`
 	PullRequests []*types.PREvidence `json:"pull_requests"`
+	CommitRange  *PRCommitRange      `json:"commit_range,omitempty"`
+}
+
+// PRCommitRange is the range of commits whose pull requests are attested
+// when --oldest-commit is set.
+type PRCommitRange struct {
+	OldestCommit string   `json:"oldest_commit"`
+	NewestCommit string   `json:"newest_commit"`
+	Commits      []string `json:"commits"`
+	// UnreviewedCommits are the commits of the range which none of the pull
+	// requests introduced.
+	UnreviewedCommits []*gitview.BasicCommitInfo `json:"unreviewed_commits"`
 }
 
 type attestPROptions struct {
 	*CommonAttestationOptions
-	retriever any
-	assert    bool
-	payload   PRAttestationPayload
+	retriever    any
+	assert       bool
+	oldestCommit string
+	payload      PRAttestationPayload
 }
 
 func (o *attestPROptions) getRetriever() types.PRRetriever {
 	return o.retriever.(types.PRRetriever)
 }
 
+// pullRequestsForCommit looks up the pull requests of commit with retriever,
+// in a span of the git provider call.
+func pullRequestsForCommit(retriever types.PRRetriever, commit string) ([]*types.PREvidence, error) {
+	provider, _ := retriever.ProviderAndLabel()
+	_, span := telemetry.Start(context.Background(), "git provider pull requests",
+		attribute.String("kosli.git_provider", provider), attribute.String("kosli.commit", commit))
+	prs, err := retriever.PREvidenceForCommitHybrid(commit)
+	telemetry.End(span, err)
+	return prs, err
+}
+
 func (o *attestPROptions) run(args []string) error {
 	url, err := url.JoinPath(global.Host, "api/v2/attestations", global.Org, o.flowName, "trail", o.trailName, "pull_request")
 	if err != nil {
@@ -38,15 +92,21 @@ func (o *attestPROptions) run(args []string) error {
 		return err
 	}
 
+	retriever := prcache.Wrap(o.getRetriever())
 	label := ""
-	o.payload.GitProvider, label = o.getRetriever().ProviderAndLabel()
+	o.payload.GitProvider, label = retriever.ProviderAndLabel()
 
 	var pullRequestsEvidence []*types.PREvidence
-	pullRequestsEvidence, err = o.getRetriever().PREvidenceForCommitHybrid(o.payload.Commit.Sha1)
+	if o.oldestCommit != "" {
+		pullRequestsEvidence, err = o.pullRequestsInRange(retriever)
+	} else {
+		pullRequestsEvidence, err = pullRequestsForCommit(retriever, o.payload.Commit.Sha1)
+	}
 	if err != nil {
 		return err
 	}
 
+	o.analyzeReviews(pullRequestsEvidence)
 	o.payload.PullRequests = pullRequestsEvidence
 
 	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
@@ -62,7 +122,15 @@ func (o *attestPROptions) run(args []string) error {
 		}()
 	}
 
-	logger.Info("found %d %s(s) for commit: %s", len(pullRequestsEvidence), label, o.payload.Commit.Sha1)
+	if o.payload.CommitRange != nil {
+		logger.Info("found %d %s(s) for %d commit(s) between %s and %s", len(pullRequestsEvidence), label,
+			len(o.payload.CommitRange.Commits), o.oldestCommit, o.payload.Commit.Sha1)
+		for _, commit := range o.payload.CommitRange.UnreviewedCommits {
+			logger.Info("commit %s was not introduced by a %s", commit.Sha1, label)
+		}
+	} else {
+		logger.Info("found %d %s(s) for commit: %s", len(pullRequestsEvidence), label, o.payload.Commit.Sha1)
+	}
 
 	reqParams := &requests.RequestParams{
 		Method: http.MethodPost,
@@ -75,13 +143,158 @@ func (o *attestPROptions) run(args []string) error {
 	if err == nil && !global.DryRun {
 		logger.Info("%s %s attestation '%s' is reported to trail: %s", o.payload.GitProvider, label, o.payload.AttestationName, o.trailName)
 	}
-	if len(pullRequestsEvidence) == 0 && o.assert && !global.DryRun {
+	if o.assert && !global.DryRun {
 		errString := ""
 		if err != nil {
 			errString = fmt.Sprintf("%s\nError: ", err.Error())
 		}
-		err = fmt.Errorf("%sassert failed: no %s found for the given commit: %s", errString, label, o.payload.Commit.Sha1)
+		if len(pullRequestsEvidence) == 0 {
+			err = fmt.Errorf("%sassert failed: no %s found for the given commit: %s", errString, label, o.payload.Commit.Sha1)
+		} else if o.payload.CommitRange != nil && len(o.payload.CommitRange.UnreviewedCommits) > 0 {
+			err = fmt.Errorf("%sassert failed: %d commit(s) between %s and %s were not introduced by a %s", errString,
+				len(o.payload.CommitRange.UnreviewedCommits), o.oldestCommit, o.payload.Commit.Sha1, label)
+		}
 	}
 
 	return wrapAttestationError(err)
 }
+
+// pullRequestsInRange returns the pull requests of the commits after
+// --oldest-commit up to --commit, and records the range in the payload.
+func (o *attestPROptions) pullRequestsInRange(retriever types.PRRetriever) ([]*types.PREvidence, error) {
+	gv, err := gitview.New(o.srcRepoRoot)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := gv.CommitsBetween(o.oldestCommit, o.payload.Commit.Sha1, logger)
+	if err != nil {
+		return nil, err
+	}
+	var pullRequests []*types.PREvidence
+	pullRequests, o.payload.CommitRange, err = collectPullRequests(retriever, commits)
+	if err != nil {
+		return nil, err
+	}
+	o.payload.CommitRange.OldestCommit = o.oldestCommit
+	o.payload.CommitRange.NewestCommit = o.payload.Commit.Sha1
+	return pullRequests, nil
+}
+
+// collectPullRequests looks up the pull requests of each commit and returns
+// them once each, in the order they are first found. A commit is reviewed
+// when a pull request is found for it, or when it is one of the commits or
+// the merge commit of any of the pull requests; the other commits are
+// returned as unreviewed.
+func collectPullRequests(retriever types.PRRetriever, commits []*gitview.CommitInfo) ([]*types.PREvidence, *PRCommitRange, error) {
+	pullRequests := []*types.PREvidence{}
+	commitRange := &PRCommitRange{
+		Commits:           []string{},
+		UnreviewedCommits: []*gitview.BasicCommitInfo{},
+	}
+	seen := map[string]bool{}
+	reviewed := map[string]bool{}
+	for _, commit := range commits {
+		commitRange.Commits = append(commitRange.Commits, commit.Sha1)
+		prs, err := pullRequestsForCommit(retriever, commit.Sha1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(prs) > 0 {
+			reviewed[commit.Sha1] = true
+		}
+		for _, pr := range prs {
+			key := pr.URL
+			if key == "" {
+				key = pr.MergeCommit
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			pullRequests = append(pullRequests, pr)
+			reviewed[pr.MergeCommit] = true
+			for _, c := range pr.Commits {
+				reviewed[c.SHA] = true
+			}
+		}
+	}
+	for _, commit := range commits {
+		if !reviewed[commit.Sha1] {
+			commitRange.UnreviewedCommits = append(commitRange.UnreviewedCommits, &commit.BasicCommitInfo)
+		}
+	}
+	return pullRequests, commitRange, nil
+}
+
+// analyzeReviews adds the four-eyes review analysis to each pull request.
+// The CODEOWNERS file is read at the attested commit, and the files changed
+// by a pull request are those changed by its commits that are in the local
+// repository, or by the attested commit when none of them are. Code owners
+// are left out of the analysis when the local repository cannot be read.
+func (o *attestPROptions) analyzeReviews(pullRequests []*types.PREvidence) {
+	if len(pullRequests) == 0 {
+		return
+	}
+	var codeOwners *review.CodeOwners
+	gv, err := gitview.New(o.srcRepoRoot)
+	if err != nil {
+		logger.Warn("failed to read the git repository to check code owners approvals: %v", err)
+	} else {
+		codeOwners, err = readCodeOwners(gv, o.payload.Commit.Sha1)
+		if err != nil {
+			logger.Warn("failed to read CODEOWNERS file: %v", err)
+		}
+	}
+
+	for _, pr := range pullRequests {
+		var changedFiles []string
+		if codeOwners != nil {
+			changedFiles = changedFilesOf(gv, pr, o.payload.Commit.Sha1)
+		}
+		pr.ReviewAnalysis = review.Analyze(pr, codeOwners, changedFiles)
+	}
+}
+
+// readCodeOwners returns the first CODEOWNERS file found at commit, or nil
+// if there is none.
+func readCodeOwners(gv *gitview.GitView, commit string) (*review.CodeOwners, error) {
+	for _, path := range review.CodeOwnersLocations {
+		content, found, err := gv.FileContent(commit, path)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return review.ParseCodeOwners(path, content)
+		}
+	}
+	return nil, nil
+}
+
+func changedFilesOf(gv *gitview.GitView, pr *types.PREvidence, commit string) []string {
+	files := map[string]bool{}
+	for _, c := range pr.Commits {
+		changed, err := gv.ChangedFiles(c.SHA)
+		if err != nil {
+			logger.Debug("commit %s of %s is not in the local repository: %v", c.SHA, pr.URL, err)
+			continue
+		}
+		for _, file := range changed {
+			files[file] = true
+		}
+	}
+	if len(files) == 0 {
+		changed, err := gv.ChangedFiles(commit)
+		if err != nil {
+			logger.Warn("failed to get the files changed by commit %s: %v", commit, err)
+		}
+		for _, file := range changed {
+			files[file] = true
+		}
+	}
+	result := make([]string, 0, len(files))
+	for file := range files {
+		result = append(result, file)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	ghUtils "github.com/kosli-dev/cli/internal/github"
+	"github.com/kosli-dev/cli/internal/gitview"
+	"github.com/kosli-dev/cli/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+func commitInfos(shas ...string) []*gitview.CommitInfo {
+	commits := []*gitview.CommitInfo{}
+	for _, sha := range shas {
+		commits = append(commits, &gitview.CommitInfo{BasicCommitInfo: gitview.BasicCommitInfo{Sha1: sha}})
+	}
+	return commits
+}
+
+// A pull request merged with a merge commit is found for the merge commit, and
+// its branch commits, which are also in the range, are reviewed by it. Each
+// pull request is reported once, and only the commit no pull request
+// introduced is unreviewed.
+func TestCollectPullRequestsInACommitRange(t *testing.T) {
+	pr1 := &types.PREvidence{URL: "https://github.com/acme/web/pull/1", MergeCommit: "merge1",
+		Commits: []types.Commit{{SHA: "branch1a"}, {SHA: "branch1b"}}}
+	pr2 := &types.PREvidence{URL: "https://github.com/acme/web/pull/2", MergeCommit: "squash2"}
+	retriever := &ghUtils.FakeGitHubClient{PRsByCommit: map[string][]*types.PREvidence{
+		"merge1":   {pr1},
+		"branch1a": {pr1},
+		"squash2":  {pr2},
+	}}
+
+	prs, commitRange, err := collectPullRequests(retriever,
+		commitInfos("squash2", "merge1", "branch1b", "direct", "branch1a"))
+	require.NoError(t, err)
+	require.Equal(t, []*types.PREvidence{pr2, pr1}, prs)
+	require.Equal(t, []string{"squash2", "merge1", "branch1b", "direct", "branch1a"}, commitRange.Commits)
+	require.Equal(t, []*gitview.BasicCommitInfo{{Sha1: "direct"}}, commitRange.UnreviewedCommits)
+}
+
+func TestCollectPullRequestsWithoutPullRequests(t *testing.T) {
+	retriever := &ghUtils.FakeGitHubClient{}
+
+	prs, commitRange, err := collectPullRequests(retriever, commitInfos("direct"))
+	require.NoError(t, err)
+	require.NotNil(t, prs, "must be an empty slice, not nil")
+	require.Empty(t, prs)
+	require.Len(t, commitRange.UnreviewedCommits, 1)
+}
+
+func TestCollectPullRequestsFailsWhenTheRetrieverFails(t *testing.T) {
+	retriever := &ghUtils.FakeGitHubClient{Err: errors.New("rate limited")}
+
+	_, _, err := collectPullRequests(retriever, commitInfos("squash2"))
+	require.EqualError(t, err, "rate limited")
+}
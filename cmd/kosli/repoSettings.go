@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/reposettings"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/telemetry"
+	"github.com/kosli-dev/cli/internal/types"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type RepoSettingsAttestationPayload struct {
+	*CommonAttestationPayload
+	GitProvider      string                 `json:"git_provider"`
+	RepoSettings     *types.RepoSettings    `json:"repo_settings"`
+	ExpectedSettings *reposettings.Expected `json:"expected_settings,omitempty"`
+	Compliant        bool                   `json:"is_compliant"`
+	Violations       []string               `json:"violations"`
+}
+
+type attestRepoSettingsOptions struct {
+	*CommonAttestationOptions
+	retriever            any
+	branch               string
+	expectedSettingsFile string
+	assert               bool
+	payload              RepoSettingsAttestationPayload
+}
+
+func newAttestRepoSettingsOptions() *attestRepoSettingsOptions {
+	return &attestRepoSettingsOptions{
+		CommonAttestationOptions: &CommonAttestationOptions{
+			fingerprintOptions: &fingerprintOptions{},
+		},
+		payload: RepoSettingsAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{},
+		},
+	}
+}
+
+// addRepoSettingsFlags adds the flags shared by all attest repo-settings commands.
+func addRepoSettingsFlags(cmd *cobra.Command, o *attestRepoSettingsOptions) {
+	cmd.Flags().StringVar(&o.branch, "branch", "", repoSettingsBranchFlag)
+	cmd.Flags().StringVar(&o.expectedSettingsFile, "expected-settings", "", expectedRepoSettingsFlag)
+	cmd.Flags().BoolVar(&o.assert, "assert", false, attestationAssertFlag)
+}
+
+// validate runs the checks shared by all attest repo-settings commands before running them.
+func (o *attestRepoSettingsOptions) validate(cmd *cobra.Command, args []string) error {
+	err := CustomMaximumNArgs(1, args)
+	if err != nil {
+		return err
+	}
+
+	err = RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+	if err != nil {
+		return ErrorBeforePrintingUsage(cmd, err.Error())
+	}
+
+	err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
+	if err != nil {
+		return err
+	}
+
+	err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
+	if err != nil {
+		return fmt.Errorf("%s for --redact-commit-info", err.Error())
+	}
+
+	err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
+	if err != nil {
+		return ErrorBeforePrintingUsage(cmd, err.Error())
+	}
+
+	return ValidateRegistryFlags(cmd, o.fingerprintOptions)
+}
+
+func (o *attestRepoSettingsOptions) getRetriever() types.RepoSettingsRetriever {
+	return o.retriever.(types.RepoSettingsRetriever)
+}
+
+func (o *attestRepoSettingsOptions) run(args []string) error {
+	url, err := url.JoinPath(global.Host, "api/v2/attestations", global.Org, o.flowName, "trail", o.trailName, "repo_settings")
+	if err != nil {
+		return err
+	}
+
+	if o.expectedSettingsFile != "" {
+		o.payload.ExpectedSettings, err = reposettings.LoadExpected(o.expectedSettingsFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = o.CommonAttestationOptions.run(args, o.payload.CommonAttestationPayload)
+	if err != nil {
+		return err
+	}
+
+	retriever := o.getRetriever()
+	o.payload.GitProvider = retriever.RepoSettingsProvider()
+	_, span := telemetry.Start(context.Background(), "git provider repo settings",
+		attribute.String("kosli.git_provider", o.payload.GitProvider), attribute.String("kosli.branch", o.branch))
+	o.payload.RepoSettings, err = retriever.RepoSettings(o.branch)
+	telemetry.End(span, err)
+	if err != nil {
+		return err
+	}
+
+	o.payload.Violations = []string{}
+	if o.payload.ExpectedSettings != nil {
+		o.payload.Violations = o.payload.ExpectedSettings.Violations(o.payload.RepoSettings)
+	}
+	o.payload.Compliant = len(o.payload.Violations) == 0
+	logger.Debug("%s settings of branch %s: protected=%t, violations: %v", o.payload.GitProvider, o.branch,
+		o.payload.RepoSettings.Protected, o.payload.Violations)
+
+	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
+	if err != nil {
+		return err
+	}
+	// if we created a tar package, remove it after uploading it
+	if cleanupNeeded {
+		defer func() {
+			if err := os.Remove(evidencePath); err != nil {
+				logger.Warn("failed to remove evidence file %s: %v", evidencePath, err)
+			}
+		}()
+	}
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodPost,
+		URL:    url,
+		Form:   form,
+		DryRun: global.DryRun,
+		Token:  global.ApiToken,
+	}
+	_, err = kosliClient.Do(reqParams)
+	if err == nil && !global.DryRun {
+		logger.Info("%s repo settings attestation '%s' is reported to trail: %s", o.payload.GitProvider, o.payload.AttestationName, o.trailName)
+	}
+
+	if !o.payload.Compliant && o.assert && !global.DryRun {
+		errString := ""
+		if err != nil {
+			errString = fmt.Sprintf("%s\nError: ", err.Error())
+		}
+		err = fmt.Errorf("%sthe settings of branch %s do not match the expected settings:\n\t%s", errString,
+			o.branch, strings.Join(o.payload.Violations, "\n\t"))
+	}
+	return wrapAttestationError(err)
+}
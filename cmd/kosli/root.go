@@ -29,6 +29,12 @@ You can set any flag from an environment variable by capitalizing it in snake ca
 For example, to set --api-token from an environment variable, you can export KOSLI_API_TOKEN=YOUR_API_TOKEN.
 
 Setting the API token to DRY_RUN sets the --dry-run flag.
+
+Tracing:
+Set OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT to trace the CLI with OpenTelemetry.
+The spans of each command, Kosli API request, fingerprint calculation and git provider call are exported
+with the protocol set by OTEL_EXPORTER_OTLP_PROTOCOL (http/protobuf or grpc). When the endpoint is a file URL,
+such as file:///tmp/kosli-traces.jsonl, the spans are appended to that file as OTLP JSON lines instead.
 `
 
 const (
@@ -134,8 +140,8 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	trailNameFlagOptional           = "[optional] The Kosli trail name."
 	templateArtifactName            = "The name of the artifact in the yml template file."
 	flowNamesFlag                   = "[defaulted] The comma separated list of Kosli flows. Defaults to all flows of the org."
-	outputFlag                      = "[defaulted] The format of the output. Valid formats are: [table, json]."
-	outputFlagWithMarkdown          = "[defaulted] The format of the output. Valid formats are: [table, json, markdown]."
+	outputFlag                      = "[defaulted] The format of the output. Valid formats are: [table, json, yaml, jsonl, csv, go-template=TEMPLATE, jsonpath=EXPRESSION]."
+	outputFlagWithMarkdown          = "[defaulted] The format of the output. Valid formats are: [table, json, markdown, yaml, jsonl, csv, go-template=TEMPLATE, jsonpath=EXPRESSION]."
 	searchByNameFlag                = "[optional] Only list flows whose name contains this substring. The Kosli API supports alphanumeric characters and '-'."
 	ignoreCaseFlag                  = "[optional] Perform case-insensitive matching for --name. By default matching is case sensitive."
 	serviceAccountNameFlag          = "The name of the service account whose API keys are managed."
@@ -149,6 +155,9 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	environmentNameFlag             = "The environment name."
 	pageNumberFlag                  = "[defaulted] The page number of a response."
 	pageLimitFlag                   = "[defaulted] The number of elements per page."
+	listOutputFlag                  = "[defaulted] The format of the output. Valid formats are: [table, json, yaml, jsonl, csv, go-template=TEMPLATE, jsonpath=EXPRESSION]. jsonl and csv print the items of the list, and jsonl streams the pages with --all."
+	listAllFlag                     = "[optional] Get every page of the list, --page-limit items at a time, instead of a single page. Cannot be used with --page."
+	listMaxItemsFlag                = "[defaulted] The maximum number of items to get with --all."
 	newEnvTypeFlag                  = "The type of environment. Valid types are: [" + validEnvTypesList + "]."
 	envSearchNameFlag               = "[optional] Only list environments whose name contains this substring (case-insensitive)."
 	envTypeFilterFlag               = "[optional] Only list environments of this type. Valid types are: [" + validEnvTypesList + "]. Can be repeated to match more than one type."
@@ -192,6 +201,16 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	commitPREvidenceFlag            = "Git commit for which to find pull request evidence. (defaulted in some CIs: https://docs.kosli.com/integrations/ci_cd )."
 	repositoryFlag                  = "Git repository. (defaulted in some CIs: https://docs.kosli.com/integrations/ci_cd )."
 	assertPREvidenceFlag            = "[optional] Exit with non-zero code if no pull requests found for the given commit."
+	oldestCommitPRFlag              = "[optional] The oldest commit of a range of commits to attest the pull requests of. Can be any commit-ish. When set, the pull requests of every commit after it up to and including --commit are attested, and commits which no pull request introduced are reported as unreviewed."
+	jiraCommentFlag                 = "[optional] Post a comment linking to the Kosli trail on each Jira issue found, once the attestation is reported."
+	jiraTransitionFlag              = "[optional] The name of a Jira workflow transition, or of the status it leads to, to move each Jira issue found through once the attestation is reported, e.g. 'In Release'."
+	issueSecondarySourceFlag        = "[optional] An optional string to search for issue references, e.g. '--issue-secondary-source ${{ github.head_ref }}'"
+	issueIgnoreBranchMatchFlag      = "Ignore branch name when searching for issue references."
+	assertIssueEvidenceFlag         = "[optional] Exit with non-zero code if no issue reference is found, or an issue does not exist, for the given commit or branch."
+	repoSettingsBranchFlag          = "The name of the branch to report the protection settings of, e.g. 'main'."
+	expectedRepoSettingsFlag        = "[optional] The path to a YAML or JSON file of the settings the branch is expected to have. The attestation is non-compliant when the branch does not have them."
+	linearAPIKeyFlag                = "Linear API key, or OAuth access token."
+	linearTeamKeyFlag               = "[optional] Linear team key to match against. Can be repeated, or given as a comma-separated list. Defaults to matching any team key."
 	assertJiraEvidenceFlag          = "[optional] Exit with non-zero code if no jira issue reference found, or jira issue does not exist, for the given commit or branch."
 	assertStatusFlag                = "[optional] Exit with non-zero code if Kosli server is not responding."
 	azureTokenFlag                  = "Azure Personal Access token."
@@ -202,17 +221,28 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	azureTenantIdFlag               = "Azure tenant ID."
 	azureSubscriptionIdFlag         = "Azure subscription ID."
 	azureResourceGroupNameFlag      = "Azure resource group name."
+	azureResourceGroupScopeFlag     = "[optional] Azure resource group name. Defaults to every resource group of the subscription."
 	azureDigestsSourceFlag          = "[defaulted] Where to get the digests from. Valid values are 'acr' and 'logs'."
-	githubTokenFlag                 = "Github token."
+	githubTokenFlag                 = "[conditional] Github token. Only required if you don't authenticate as a GitHub App with '--github-app-id'."
 	githubOrgFlag                   = "Github organization. (defaulted if you are running in GitHub Actions: https://docs.kosli.com/integrations/ci_cd )."
 	githubBaseURLFlag               = "[optional] GitHub base URL (only needed for GitHub Enterprise installations)."
+	githubAppIDFlag                 = "[conditional] The App ID or Client ID of a GitHub App to authenticate as, instead of with '--github-token'. Needs '--github-app-installation-id' and '--github-app-private-key'. The CLI creates an installation access token for the App, and creates a new one if it expires."
+	githubAppInstallationIDFlag     = "[conditional] The ID of the installation of the GitHub App (given by '--github-app-id') in the GitHub organization."
+	githubAppPrivateKeyFlag         = "[conditional] The path to the private key file of the GitHub App (given by '--github-app-id'), or the PEM encoded key itself."
 	gitlabTokenFlag                 = "Gitlab token."
 	gitlabOrgFlag                   = "Gitlab organization. (defaulted if you are running in Gitlab Pipelines: https://docs.kosli.com/integrations/ci_cd )."
 	gitlabBaseURLFlag               = "[optional] Gitlab base URL (only needed for on-prem Gitlab installations)."
+	giteaTokenFlag                  = "Gitea or Forgejo access token."
+	giteaOrgFlag                    = "Gitea or Forgejo organization or user owning the repository. (defaulted if you are running in Gitea or Forgejo Actions: https://docs.kosli.com/integrations/ci_cd )."
+	giteaBaseURLFlag                = "Gitea or Forgejo base URL, e.g. 'https://gitea.example.com'. (defaulted if you are running in Gitea or Forgejo Actions: https://docs.kosli.com/integrations/ci_cd )."
 	registryProviderFlag            = "[deprecated] The docker registry provider or url. Only required if you want to read docker image SHA256 digest from a remote docker registry."
 	registryUsernameFlag            = "[conditional] The container registry username. Only required if you want to read container image SHA256 digest from a remote container registry and it is not already accessible via Docker/Podman auth files or a credential helper."
 	registryPasswordFlag            = "[conditional] The container registry password or access token. Only required if you want to read container image SHA256 digest from a remote container registry and it is not already accessible via Docker/Podman auth files or a credential helper."
-	resultsDirFlag                  = "[defaulted] The path to a directory with JUnit test results. By default, the directory will be uploaded to Kosli's evidence vault."
+	nomadAddressFlag                = "[defaulted] The address of the Nomad HTTP API. Defaults to $NOMAD_ADDR, or http://127.0.0.1:4646."
+	nomadTokenFlag                  = "[optional] The Nomad ACL token. Defaults to $NOMAD_TOKEN."
+	nomadNamespaceFlag              = "[defaulted] The Nomad namespace to report the allocations of. Use '*' for all namespaces. Defaults to $NOMAD_NAMESPACE, or 'default'."
+	nomadRegionFlag                 = "[optional] The Nomad region. Defaults to $NOMAD_REGION, or the region of the agent at the Nomad address."
+	resultsDirFlag                  = "[defaulted] The path to a directory with test results (JUnit XML by default, see --format). By default, the directory will be uploaded to Kosli's evidence vault."
 	snykJsonResultsFileFlag         = "The path to Snyk SARIF or JSON scan results file from 'snyk test' and 'snyk container test'. By default, the Snyk results will be uploaded to Kosli's evidence vault."
 	snykSarifResultsFileFlag        = "The path to Snyk scan SARIF results file from 'snyk test' and 'snyk container test'. By default, the Snyk results will be uploaded to Kosli's evidence vault."
 	ecsClusterFlag                  = "The name of the ECS cluster."
@@ -230,6 +260,14 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	cloudRunIncludeRegexFlag        = "[optional] The comma-separated list of Cloud Run service or job name regex patterns to snapshot. Can't be used together with --exclude or --exclude-regex."
 	cloudRunExcludeFlag             = "[optional] The comma-separated list of Cloud Run service or job names to exclude. Can't be used together with --include or --include-regex."
 	cloudRunExcludeRegexFlag        = "[optional] The comma-separated list of Cloud Run service or job name regex patterns to exclude. Can't be used together with --include or --include-regex."
+	cloudFunctionsIncludeFlag       = "[optional] The comma-separated list of Cloud Function names to snapshot. Can't be used together with --exclude or --exclude-regex."
+	cloudFunctionsIncludeRegexFlag  = "[optional] The comma-separated list of Cloud Function name regex patterns to snapshot. Can't be used together with --exclude or --exclude-regex."
+	cloudFunctionsExcludeFlag       = "[optional] The comma-separated list of Cloud Function names to exclude. Can't be used together with --include or --include-regex."
+	cloudFunctionsExcludeRegexFlag  = "[optional] The comma-separated list of Cloud Function name regex patterns to exclude. Can't be used together with --include or --include-regex."
+	appEngineIncludeFlag            = "[optional] The comma-separated list of App Engine service names to snapshot. Can't be used together with --exclude or --exclude-regex."
+	appEngineIncludeRegexFlag       = "[optional] The comma-separated list of App Engine service name regex patterns to snapshot. Can't be used together with --exclude or --exclude-regex."
+	appEngineExcludeFlag            = "[optional] The comma-separated list of App Engine service names to exclude. Can't be used together with --include or --include-regex."
+	appEngineExcludeRegexFlag       = "[optional] The comma-separated list of App Engine service name regex patterns to exclude. Can't be used together with --include or --include-regex."
 	kubeconfigFlag                  = "[defaulted] The kubeconfig path for the target cluster."
 	namespacesFlag                  = "[optional] The comma separated list of namespaces names to report artifacts info from. Can't be used together with --exclude-namespaces or --exclude-namespaces-regex."
 	excludeNamespacesFlag           = "[optional] The comma separated list of namespaces names to exclude from reporting artifacts info from. Requires cluster-wide read permissions for pods and namespaces. Can't be used together with --namespaces or --namespaces-regex."
@@ -241,10 +279,19 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	excludeFlag                     = "[optional] The comma-separated list of AWS Lambda function names to be excluded. Cannot be used together with --function-names"
 	excludeRegexFlag                = "[optional] The comma-separated list of name regex patterns for AWS Lambda functions to be excluded. Cannot be used together with --function-names. Allowed regex patterns are described in [RE2 syntax](https://github.com/google/re2/wiki/Syntax)"
 	functionVersionFlag             = "[optional] The version of the AWS Lambda function."
+	appRunnerServicesFlag           = "[optional] The comma-separated list of AWS App Runner service names to snapshot. Can't be used together with --exclude or --exclude-regex."
+	appRunnerServicesRegexFlag      = "[optional] The comma-separated list of AWS App Runner service name regex patterns to snapshot. Can't be used together with --exclude or --exclude-regex."
+	appRunnerExcludeFlag            = "[optional] The comma-separated list of AWS App Runner service names to exclude. Can't be used together with --services or --services-regex."
+	appRunnerExcludeRegexFlag       = "[optional] The comma-separated list of AWS App Runner service name regex patterns to exclude. Can't be used together with --services or --services-regex."
+	beanstalkEnvironmentsFlag       = "[optional] The comma-separated list of AWS Elastic Beanstalk environment names to snapshot. Can't be used together with --exclude or --exclude-regex."
+	beanstalkEnvironmentsRegexFlag  = "[optional] The comma-separated list of AWS Elastic Beanstalk environment name regex patterns to snapshot. Can't be used together with --exclude or --exclude-regex."
+	beanstalkExcludeFlag            = "[optional] The comma-separated list of AWS Elastic Beanstalk environment names to exclude. Can't be used together with --environments or --environments-regex."
+	beanstalkExcludeRegexFlag       = "[optional] The comma-separated list of AWS Elastic Beanstalk environment name regex patterns to exclude. Can't be used together with --environments or --environments-regex."
 	awsKeyIdFlag                    = "The AWS access key ID."
 	awsSecretKeyFlag                = "The AWS secret access key."
 	awsRegionFlag                   = "The AWS region."
 	bucketNameFlag                  = "The name of the S3 bucket."
+	awsAccountsFileFlag             = "[optional] The path to a YAML, JSON or TOML file of the AWS accounts and regions to snapshot, and of the Kosli environments to report them to. Replaces the ENVIRONMENT-NAME argument."
 	bucketPathsFlag                 = "[optional] The comma separated list of file and/or directory paths in the S3 bucket to include when fingerprinting. Paths match by literal prefix. Cannot be used together with --exclude or --exclude-regex."
 	bucketPathsRegexFlag            = "[optional] The comma separated list of Go regular expressions matched against object keys in the S3 bucket to include when fingerprinting. Cannot be used together with --exclude or --exclude-regex."
 	excludeBucketPathsFlag          = "[optional] The comma separated list of file and/or directory paths in the S3 bucket to exclude when fingerprinting. Paths match by literal prefix. Cannot be used together with --include or --include-regex."
@@ -267,6 +314,13 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	attestationCustomTypeNameFlag   = "The name of the custom attestation type."
 	attestationCustomDataFileFlag   = "The filepath of a json file containing the custom attestation data."
 	uploadJunitResultsFlag          = "[defaulted] Whether to upload the provided Junit results directory as an attachment to Kosli or not."
+	testReportFormatFlag            = "[defaulted] The format of the test reports in --results-dir. Valid formats are: [auto, junit, tap, go-test-json, trx, ctrf]."
+	junitIncludeTestCasesFlag       = "[optional] Include a record of every test case (classname, name, status, duration and truncated failure message) in the junit attestation."
+	junitDetectFlakyFlag            = "[optional] Take test reruns into account: count the attempts at a test once and report a test that passed on a retry as flaky instead of failed."
+	coverageFileFlag                = "The path to a code coverage report file (Cobertura XML, LCOV, JaCoCo XML or Go coverage profile). By default, the file will be uploaded to Kosli's evidence vault."
+	coverageFormatFlag              = "[defaulted] The format of the coverage report in --coverage-file. Valid formats are: [auto, cobertura, lcov, jacoco, go]."
+	minLineCoverageFlag             = "[optional] The minimum line coverage percentage (0-100) for the coverage attestation to be compliant. If not set, the attestation is always compliant."
+	uploadCoverageResultsFlag       = "[defaulted] Whether to upload the provided coverage report file as an attachment to Kosli or not."
 	uploadSnykResultsFlag           = "[defaulted] Whether to upload the provided Snyk results file as an attachment to Kosli or not."
 	attestationAssertFlag           = "[optional] Exit with non-zero code if the attestation is non-compliant"
 	beginTrailCommitFlag            = "[defaulted] The git commit from which the trail is begun. (defaulted in some CIs: https://docs.kosli.com/integrations/ci_cd, otherwise defaults to HEAD )."
@@ -283,10 +337,19 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	includeScalingFlag              = "[optional] Include scaling events for snapshots. Snapshots with scaling changes will result in new environment records."
 	includedEnvironments            = "[optional] Comma separated list of environments to include in logical environment"
 	autoEnvironmentFlag             = "[optional] Create the environment (with the type inferred from the snapshot subcommand) if it does not already exist, before reporting the snapshot."
+	skipUnchangedFlag               = "[optional] Do not report a snapshot which has not changed since the last snapshot reported to the environment from this machine, until --heartbeat-interval has passed."
+	heartbeatIntervalFlag           = "[optional] With --skip-unchanged, how long after the last report an unchanged snapshot is reported again, so that Kosli sees the environment is still being reported. The Kosli API has no lighter request for this, so the whole snapshot is sent again. 0 never reports an unchanged snapshot again."
+	snapshotStateDirFlag            = "[optional] With --skip-unchanged, the directory to record the last snapshot reported to each environment in. (defaults to kosli/snapshots in the user cache directory)"
+	daemonEnvironmentsFileFlag      = "The path to an environments file in YAML/JSON/TOML format, listing the environments to report the snapshots of, with their types, intervals and flags."
+	daemonHealthAddressFlag         = "[optional] The address to serve the health endpoint at. An empty address does not serve it."
+	daemonLogFormatFlag             = "[optional] The format of the logs. Valid formats are: [json, text]."
 	requireProvenanceFlag           = "[defaulted] Require provenance for all artifacts running in environment snapshots."
 	setTagsFlag                     = "[optional] The key-value pairs to tag the resource with. The format is: key=value"
 	unsetTagsFlag                   = "[optional] The list of tag keys to remove from the resource."
 	pathsSpecFileFlag               = "The path to a paths file in YAML/JSON/TOML format. Cannot be used together with --path ."
+	s3PathsSpecFileFlag             = "[optional] The path to an S3 paths file in YAML/JSON/TOML format, to report several artifacts in the bucket. Cannot be used together with --include, --include-regex, --exclude or --exclude-regex."
+	s3UseChecksumsFlag              = "[optional] Fingerprint the objects from their SHA-256 checksums instead of downloading them. Objects without a full object SHA-256 checksum are downloaded."
+	s3ChecksumsManifestFlag         = "[optional] The key of an object in the bucket listing the SHA-256 digests of objects in sha256sum format, to fingerprint the objects without a SHA-256 checksum without downloading them. Implies --use-checksums."
 	snapshotPathPathFlag            = "The base path for the artifact to snapshot."
 	snapshotPathExcludeFlag         = "[optional] The comma-separated list of literal paths or glob patterns to exclude when fingerprinting the artifact."
 	snapshotPathArtifactNameFlag    = "The reported name of the artifact."
@@ -322,6 +385,10 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	controlSortDirectionFlag        = "[optional] The direction to sort controls in. Valid values are: [asc, desc]. (defaults to asc)"
 	envNameFlag                     = "The Kosli environment name to assert the artifact against."
 	pathsWatchFlag                  = "[optional] Watch the filesystem for changes and report snapshots of artifacts running in specific filesystem paths to Kosli."
+	systemdUnitsFlag                = "[conditional] The comma separated list of name patterns of the running systemd services to report, e.g. 'myapp-*.service'. Required if --process-patterns is not set."
+	processPatternsFlag             = "[conditional] The comma separated list of regular expressions matched against the command lines of the running processes to report. Required if --systemd-units is not set."
+	workingDirectoryFlag            = "[optional] Fingerprint the working directory of each service or process as a directory artifact, instead of its executable."
+	workingDirectoryExcludeFlag     = "[optional] The comma separated list of paths to exclude when fingerprinting working directories. Can take glob patterns. Only applicable with --working-directory."
 	getAttestationFingerprintFlag   = "[conditional] The fingerprint of the artifact for the attestation. Cannot be used together with --trail or --attestation-id."
 	getAttestationTrailNameFlag     = "[conditional] The name of the Kosli trail for the attestation. Cannot be used together with --fingerprint or --attestation-id."
 	getAttestationFlowNameFlag      = "[conditional] The name of the Kosli flow for the attestation. Required if ATTESTATION-NAME provided. Cannot be used together with --attestation-id."
@@ -508,7 +575,6 @@ func reportEmptyFlagValue(cmd *cobra.Command, err error) error {
 }
 
 func initialize(cmd *cobra.Command, out, errOut io.Writer) error {
-	v := viper.New()
 	logger.SetInfoOut(out) // needed to allow tests to overwrite the logger output stream
 	logger.SetErrOut(errOut)
 	// assign debug value early here to enable debug logs during config file and env var binding
@@ -530,6 +596,37 @@ func initialize(cmd *cobra.Command, out, errOut io.Writer) error {
 			global.ConfigFile = path
 		}
 	}
+	v, err := newConfigViper()
+	if err != nil {
+		return err
+	}
+
+	// Bind the current command's flags to viper
+	if err := bindFlags(cmd, v); err != nil {
+		return err
+	}
+
+	// re-assign debug after binding flags to config or env vars as it may have
+	// a different value now
+	logger.DebugEnabled = global.Debug
+	logger.QuietEnabled = global.Quiet && !global.Debug
+	if global.Quiet && global.Debug {
+		logger.Debug("--quiet is ignored because --debug is set")
+	}
+
+	kosliClient, err = requests.NewKosliClient(global.HttpProxy, global.MaxAPIRetries, global.Debug, logger)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// newConfigViper returns a viper which reads the config file global.ConfigFile,
+// if it exists, and the KOSLI_ prefixed environment variables, for bindFlags to
+// apply to the flags of a command.
+func newConfigViper() (*viper.Viper, error) {
+	v := viper.New()
 	dir, file := filepath.Split(global.ConfigFile)
 	file = strings.TrimSuffix(file, filepath.Ext(file))
 
@@ -550,7 +647,7 @@ func initialize(cmd *cobra.Command, out, errOut io.Writer) error {
 	if err := v.ReadInConfig(); err != nil {
 		// It's okay if there isn't a config file
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return fmt.Errorf("failed to parse config file [%s] : %v", global.ConfigFile, err)
+			return nil, fmt.Errorf("failed to parse config file [%s] : %v", global.ConfigFile, err)
 		} else {
 			logger.Debug("config file [%s] not found. Skipping.", global.ConfigFile)
 		}
@@ -571,26 +668,7 @@ func initialize(cmd *cobra.Command, out, errOut io.Writer) error {
 	// because nothing fails if the two drift apart.
 	v.AutomaticEnv()
 
-	// Bind the current command's flags to viper
-	if err := bindFlags(cmd, v); err != nil {
-		return err
-	}
-
-	// re-assign debug after binding flags to config or env vars as it may have
-	// a different value now
-	logger.DebugEnabled = global.Debug
-	logger.QuietEnabled = global.Quiet && !global.Debug
-	if global.Quiet && global.Debug {
-		logger.Debug("--quiet is ignored because --debug is set")
-	}
-
-	var err error
-	kosliClient, err = requests.NewKosliClient(global.HttpProxy, global.MaxAPIRetries, global.Debug, logger)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return v, nil
 }
 
 // configValueSource names where a flag's value came from, so that a failure to
@@ -16,20 +16,36 @@ func newSnapshotCmd(out io.Writer) *cobra.Command {
 	}
 
 	addAutoEnvironmentFlags(cmd)
+	addChangeDetectionFlags(cmd)
 
 	// Add subcommands
-	cmd.AddCommand(
+	cmd.AddCommand(newSnapshotEnvironmentCmds(out)...)
+	cmd.AddCommand(newSnapshotRunDaemonCmd(out))
+
+	return cmd
+}
+
+// newSnapshotEnvironmentCmds returns the snapshot subcommands which report
+// the snapshot of an environment, which run-daemon runs periodically.
+func newSnapshotEnvironmentCmds(out io.Writer) []*cobra.Command {
+	return []*cobra.Command{
 		newSnapshotDockerCmd(out),
 		newSnapshotECSCmd(out),
 		newSnapshotK8SCmd(out),
+		newSnapshotNomadCmd(out),
 		newSnapshotServerCmd(out),
 		newSnapshotLambdaCmd(out),
+		newSnapshotAppRunnerCmd(out),
+		newSnapshotBeanstalkCmd(out),
 		newSnapshotS3Cmd(out),
 		newSnapshotAzureAppsCmd(out),
+		newSnapshotAzureContainerAppsCmd(out),
+		newSnapshotAzureFunctionsCmd(out),
 		newSnapshotPathsCmd(out),
 		newSnapshotPathCmd(out),
+		newSnapshotProcessesCmd(out),
 		newSnapshotCloudRunCmd(out),
-	)
-
-	return cmd
+		newSnapshotCloudFunctionsCmd(out),
+		newSnapshotAppEngineCmd(out),
+	}
 }
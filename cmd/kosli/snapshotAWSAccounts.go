@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/kosli-dev/cli/internal/aws"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/spf13/cobra"
+)
+
+// awsAccountsConcurrency is how many AWS accounts and regions are looked
+// into at a time with --aws-accounts-file.
+const awsAccountsConcurrency = 8
+
+const awsAccountsDesc = `
+
+To snapshot several AWS accounts and regions at once, use ^--aws-accounts-file^ instead of the ENVIRONMENT-NAME argument.
+The file lists the accounts, the IAM role assumed in each of them with STS AssumeRole, the regions to look into and
+the Kosli environment each account and region is reported to. The AWS credentials above are the ones assuming the roles.
+Accounts and regions are looked into concurrently. When one of them fails, the environments it reports to are not reported,
+while the other environments are.
+
+The environment names can use the {account}, {account_name} and {region} placeholders, and {cluster} in ^kosli snapshot ecs^
+to report each ECS cluster to its own environment. This is an example YAML AWS accounts file:
+` +
+	"```yaml\n" +
+	`version: 1
+role_name: kosli-snapshot         # assumed in every account, unless it has its own role_name or role_arn
+external_id: yourExternalID       # optional, passed to AssumeRole
+regions: [eu-west-1, us-east-1]  # the regions of the accounts without regions
+environment: "{account_name}-{region}"
+accounts:
+  - id: "111111111111"            # quoted, to keep the leading zeros of account IDs
+    name: production
+  - id: "222222222222"
+    name: staging
+    role_arn: arn:aws:iam::222222222222:role/custom-role
+    regions: [eu-west-1]
+    environment: staging          # an environment can aggregate several accounts and regions
+    bucket: staging-artifacts     # the bucket of kosli snapshot s3, --bucket by default` +
+	"\n```"
+
+type awsAccountsOptions struct {
+	accountsFile string
+	// perAccount looks into each account once rather than into each of its
+	// regions, for the resources which are global, like S3 buckets.
+	perAccount bool
+}
+
+func addAWSAccountsFlag(cmd *cobra.Command, o *awsAccountsOptions) {
+	cmd.Flags().StringVar(&o.accountsFile, "aws-accounts-file", "", awsAccountsFileFlag)
+}
+
+// awsAccountsArgs requires the ENVIRONMENT-NAME argument, unless an accounts
+// file names the environments.
+func awsAccountsArgs(o *awsAccountsOptions) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if o.accountsFile == "" {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		if len(args) > 0 {
+			return fmt.Errorf("ENVIRONMENT-NAME cannot be used with --aws-accounts-file, which names the environments")
+		}
+		return nil
+	}
+}
+
+// reportAWSAccountsSnapshots reports the snapshots of the accounts and
+// regions of the accounts file of o to environments of envType. snapshot
+// returns the artifacts of an account and region by environment name, and
+// newPayload the report of the artifacts of an environment.
+func reportAWSAccountsSnapshots[T any](o *awsAccountsOptions, creds *aws.AWSStaticCreds, envType string,
+	snapshot func(target *aws.AccountTarget) (map[string][]T, error), newPayload func(artifacts []T) any) error {
+	spec, err := loadSpecFile[aws.AccountsSpec](o.accountsFile, "AWS accounts file", nil)
+	if err != nil {
+		return err
+	}
+	targets, err := spec.Targets(creds)
+	if o.perAccount {
+		targets, err = spec.BucketTargets(creds)
+	}
+	if err != nil {
+		return fmt.Errorf("AWS accounts file [%s] is invalid: %v", o.accountsFile, err)
+	}
+
+	var mu sync.Mutex
+	artifacts := map[string][]T{}
+	failures := aws.ForEachTarget(targets, awsAccountsConcurrency, func(target *aws.AccountTarget) error {
+		byEnvironment, err := snapshot(target)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for envName, envArtifacts := range byEnvironment {
+			// an environment without artifacts is reported with an empty list
+			// rather than null
+			if artifacts[envName] == nil {
+				artifacts[envName] = []T{}
+			}
+			artifacts[envName] = append(artifacts[envName], envArtifacts...)
+		}
+		return nil
+	})
+	// the environment of an account and region that failed misses some of its
+	// artifacts, which would be reported as exited, so it is not reported
+	for target := range failures {
+		if !target.PerCluster() {
+			delete(artifacts, target.Environment(""))
+		}
+	}
+
+	errs := []error{aws.JoinTargetErrors(targets, failures)}
+	envNames := make([]string, 0, len(artifacts))
+	for envName := range artifacts {
+		envNames = append(envNames, envName)
+	}
+	slices.Sort(envNames)
+	for _, envName := range envNames {
+		reported, err := reportAWSSnapshot(envName, envType, newPayload(artifacts[envName]))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("environment %s: %w", envName, err))
+			continue
+		}
+		if reported && !global.DryRun {
+			logger.Info("[%d] artifacts were reported to environment %s", len(artifacts[envName]), envName)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func reportAWSSnapshot(envName, envType string, payload any) (bool, error) {
+	if err := ensureEnvironment(envName, envType); err != nil {
+		return false, err
+	}
+	url, err := url.JoinPath(global.Host, "api/v2/environments", global.Org, envName, "report", envType)
+	if err != nil {
+		return false, err
+	}
+	return reportSnapshot(&requests.RequestParams{
+		Method:  http.MethodPut,
+		URL:     url,
+		Payload: payload,
+		DryRun:  global.DryRun,
+		Token:   global.ApiToken,
+	}, envName)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/kosli-dev/cli/internal/aws"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReportServer is a fake Kosli API recording the body of every request
+// by "METHOD path".
+type fakeReportServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	reports map[string]string
+}
+
+func newFakeReportServer(t *testing.T) *fakeReportServer {
+	t.Helper()
+	f := &fakeReportServer{reports: map[string]string{}}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		f.mu.Lock()
+		f.reports[r.Method+" "+r.URL.Path] = string(body)
+		f.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("{}"))
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// args returns the global flags pointing the CLI at the fake Kosli API.
+func (f *fakeReportServer) args() string {
+	return fmt.Sprintf("--host %s --org test-org --api-token test-token --max-api-retries 0", f.URL)
+}
+
+func TestSnapshotLambdaAWSAccounts(t *testing.T) {
+	server := newFakeReportServer(t)
+
+	// each account and region runs a function named after its role and region
+	aws.NewLambdaClientFunc = func(creds *aws.AWSStaticCreds) (aws.LambdaAPI, error) {
+		if creds.ExternalID != "test-external-id" {
+			return nil, fmt.Errorf("unexpected external ID %q", creds.ExternalID)
+		}
+		if strings.Contains(creds.RoleARN, "111111111111") && creds.Region == "us-east-1" {
+			return nil, fmt.Errorf("access denied")
+		}
+		name := strings.TrimPrefix(creds.RoleARN, "arn:aws:iam::") + "@" + creds.Region
+		sha, lastModified := "Mh48OOkSYuXHLfS9QF6bF3tvTXUOGvC3jKLiuF1vkbQ=", "2024-01-15T10:30:00.000+0000"
+		return &aws.FakeLambdaClient{Functions: []types.FunctionConfiguration{
+			{FunctionName: &name, CodeSha256: &sha, LastModified: &lastModified, PackageType: types.PackageTypeZip},
+		}}, nil
+	}
+	t.Cleanup(aws.ResetLambdaClientFactory)
+
+	args := server.args()
+	tests := []cmdTestCase{
+		{
+			wantError: true,
+			name:      "an account and region that fails is not reported, while the others are",
+			cmd:       "snapshot lambda --aws-accounts-file testdata/aws-accounts/accounts.yml " + args,
+			goldenRegex: `(?s)\[1\] artifacts were reported to environment production-eu-west-1\n` +
+				`\[1\] artifacts were reported to environment staging\n` +
+				`Error: account production in us-east-1: access denied`,
+		},
+		{
+			wantError: true,
+			name:      "ENVIRONMENT-NAME cannot be used with --aws-accounts-file",
+			cmd:       "snapshot lambda my-env --aws-accounts-file testdata/aws-accounts/accounts.yml " + args,
+			golden:    "Error: ENVIRONMENT-NAME cannot be used with --aws-accounts-file, which names the environments\n",
+		},
+		{
+			wantError: true,
+			name:      "ENVIRONMENT-NAME is required without --aws-accounts-file",
+			cmd:       "snapshot lambda " + args,
+			golden:    "Error: accepts 1 arg(s), received 0\n",
+		},
+		{
+			wantError:   true,
+			name:        "an invalid accounts file fails",
+			cmd:         "snapshot lambda --aws-accounts-file testdata/aws-accounts/invalid.yml " + args,
+			goldenRegex: `^Error: AWS accounts file \[testdata/aws-accounts/invalid.yml\] is invalid: .*'Version' failed on the 'oneof' tag`,
+		},
+		{
+			wantError: true,
+			name:      "snapshot s3 requires --bucket without --aws-accounts-file",
+			cmd:       "snapshot s3 my-env " + args,
+			golden:    "Error: required flag(s) \"bucket\" not set\n",
+		},
+	}
+	runTestCmd(t, tests)
+
+	require.Len(t, server.reports, 2)
+	require.Contains(t, server.reports["PUT /api/v2/environments/test-org/production-eu-west-1/report/lambda"],
+		`"digests":{"111111111111:role/kosli-snapshot@eu-west-1"`)
+	require.Contains(t, server.reports["PUT /api/v2/environments/test-org/staging/report/lambda"],
+		`"digests":{"222222222222:role/kosli-snapshot@eu-west-1"`)
+}
+
+func TestSnapshotLambdaAWSAccountsWithoutFunctions(t *testing.T) {
+	server := newFakeReportServer(t)
+	aws.NewLambdaClientFunc = func(_ *aws.AWSStaticCreds) (aws.LambdaAPI, error) {
+		return &aws.FakeLambdaClient{}, nil
+	}
+	t.Cleanup(aws.ResetLambdaClientFactory)
+
+	runTestCmd(t, []cmdTestCase{
+		{
+			name: "an environment without functions is reported with no artifacts",
+			cmd:  "snapshot lambda --aws-accounts-file testdata/aws-accounts/accounts.yml " + server.args(),
+			golden: "[0] artifacts were reported to environment production-eu-west-1\n" +
+				"[0] artifacts were reported to environment production-us-east-1\n" +
+				"[0] artifacts were reported to environment staging\n",
+		},
+	})
+
+	require.Len(t, server.reports, 3)
+	for _, report := range server.reports {
+		require.Contains(t, report, `"artifacts":[]`)
+	}
+}
+
+func TestSnapshotS3AWSAccounts(t *testing.T) {
+	server := newFakeReportServer(t)
+
+	// the bucket of each account is in one region, found from the first
+	// region of the account
+	bucketRegions := map[string]string{"111111111111": "us-west-2", "222222222222": "eu-west-1"}
+	var mu sync.Mutex
+	lookups := []string{}
+	aws.BucketRegion = func(creds *aws.AWSStaticCreds, bucket string) (string, error) {
+		account := strings.Split(strings.TrimPrefix(creds.RoleARN, "arn:aws:iam::"), ":")[0]
+		mu.Lock()
+		lookups = append(lookups, account+"@"+creds.Region)
+		mu.Unlock()
+		return bucketRegions[account], nil
+	}
+	t.Cleanup(aws.ResetBucketRegion)
+	aws.NewS3ClientFunc = func(creds *aws.AWSStaticCreds) (aws.S3API, error) {
+		account := strings.Split(strings.TrimPrefix(creds.RoleARN, "arn:aws:iam::"), ":")[0]
+		if creds.Region != bucketRegions[account] {
+			return nil, fmt.Errorf("client in %s for a bucket in %s", creds.Region, bucketRegions[account])
+		}
+		return &aws.FakeS3Client{Bucket: "artifacts", Objects: map[string][]byte{account: []byte(account)}}, nil
+	}
+	t.Cleanup(aws.ResetS3ClientFactory)
+
+	runTestCmd(t, []cmdTestCase{
+		{
+			name: "the bucket of each account is reported once, from its own region",
+			cmd:  "snapshot s3 --aws-accounts-file testdata/aws-accounts/accounts.yml --bucket artifacts " + server.args(),
+			golden: "[1] artifacts were reported to environment production-us-west-2\n" +
+				"[1] artifacts were reported to environment staging\n",
+		},
+	})
+
+	require.ElementsMatch(t, []string{"111111111111@eu-west-1", "222222222222@eu-west-1"}, lookups)
+	require.Len(t, server.reports, 2)
+	require.Contains(t, server.reports, "PUT /api/v2/environments/test-org/production-us-west-2/report/S3")
+	require.Contains(t, server.reports, "PUT /api/v2/environments/test-org/staging/report/S3")
+}
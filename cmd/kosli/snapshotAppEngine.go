@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"io"
+
+	"github.com/kosli-dev/cli/internal/cloudrun"
+	"github.com/kosli-dev/cli/internal/filters"
+	"github.com/spf13/cobra"
+)
+
+const snapshotAppEngineShortDesc = `Report a snapshot of App Engine flexible services in a Google Cloud project to Kosli.  `
+const snapshotAppEngineLongDesc = snapshotAppEngineShortDesc + `
+App Engine flexible versions run containers. Each App Engine service contributes one
+artifact per flexible version in its traffic split (any fraction, including 0): the
+container image of the version. Services are reported to a ^cloud-run^ environment, like
+Cloud Run services, with the version ID as the revision name. Standard versions run in a
+sandbox rather than a container and are not reported.
+
+GCP authentication uses Application Default Credentials, like ^kosli snapshot cloud-run^.
+The caller needs ^roles/appengine.appViewer^ on the target project, plus read access to
+the registry of the images (^roles/artifactregistry.reader^ for Artifact Registry and
+Container Registry) to resolve the digests of tag-pinned images. Missing the registry role
+is non-fatal — tag-pinned artifacts then surface with empty digests.
+
+Skip all filtering flags to report every service of the App Engine application of the
+project. Use ^--include^ and/or ^--include-regex^ to snapshot only a subset, OR
+^--exclude^ and/or ^--exclude-regex^ to omit a subset; include and exclude are mutually
+exclusive.`
+
+const snapshotAppEngineExample = `
+# report every App Engine flexible service in a project:
+kosli snapshot appengine yourEnvironmentName \
+	--project yourGCPProject \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report only the default App Engine service:
+kosli snapshot appengine yourEnvironmentName \
+	--project yourGCPProject \
+	--include default \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+// appEngineLister is the seam between the command and the GCP client. Tests
+// override newAppEngineClient with a stub that returns canned services.
+type appEngineLister interface {
+	ListAppEngineServices(ctx context.Context, project string) ([]cloudrun.Service, error)
+}
+
+var newAppEngineClient = func(ctx context.Context) (appEngineLister, error) {
+	return cloudrun.NewAppEngineClient(ctx, logger)
+}
+
+type snapshotAppEngineOptions struct {
+	project        string
+	resourceFilter *filters.ResourceFilterOptions
+}
+
+func newSnapshotAppEngineCmd(out io.Writer) *cobra.Command {
+	o := new(snapshotAppEngineOptions)
+	o.resourceFilter = new(filters.ResourceFilterOptions)
+	cmd := &cobra.Command{
+		Use:     "appengine ENVIRONMENT-NAME",
+		Aliases: []string{"app-engine"},
+		Short:   snapshotAppEngineShortDesc,
+		Long:    snapshotAppEngineLongDesc,
+		Example: snapshotAppEngineExample,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := RequireGlobalFlags(global, []string{"Org", "ApiToken"}); err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+			return muxCloudRunFilterFlags(cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.project, "project", "", "[required] GCP project ID.")
+	cmd.Flags().StringSliceVar(&o.resourceFilter.IncludeNames, "include", []string{}, appEngineIncludeFlag)
+	cmd.Flags().StringSliceVar(&o.resourceFilter.IncludeNamesRegex, "include-regex", []string{}, appEngineIncludeRegexFlag)
+	cmd.Flags().StringSliceVar(&o.resourceFilter.ExcludeNames, "exclude", []string{}, appEngineExcludeFlag)
+	cmd.Flags().StringSliceVar(&o.resourceFilter.ExcludeNamesRegex, "exclude-regex", []string{}, appEngineExcludeRegexFlag)
+	addDryRunFlag(cmd)
+
+	if err := RequireFlags(cmd, []string{"project"}); err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+func (o *snapshotAppEngineOptions) run(args []string) error {
+	ctx := context.Background()
+	client, err := newAppEngineClient(ctx)
+	if err != nil {
+		return err
+	}
+	if closer, ok := client.(io.Closer); ok {
+		defer func() { _ = closer.Close() }()
+	}
+	services, err := client.ListAppEngineServices(ctx, o.project)
+	if err != nil {
+		return cloudrun.ClassifyAppEngine(err, o.project)
+	}
+	return reportCloudRunServices(args[0], services, o.resourceFilter)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/cloudrun"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type stubAppEngineLister struct {
+	services []cloudrun.Service
+	err      error
+}
+
+func (s stubAppEngineLister) ListAppEngineServices(_ context.Context, _ string) ([]cloudrun.Service, error) {
+	return s.services, s.err
+}
+
+func TestSnapshotAppEngine(t *testing.T) {
+	server := newFakeReportServer(t)
+
+	origNewAppEngineClient := newAppEngineClient
+	t.Cleanup(func() { newAppEngineClient = origNewAppEngineClient })
+	newAppEngineClient = func(_ context.Context) (appEngineLister, error) {
+		return stubAppEngineLister{services: stubServices()}, nil
+	}
+
+	args := server.args()
+	tests := []cmdTestCase{
+		{
+			wantError: true,
+			name:      "snapshot appengine fails if --project is missing",
+			cmd:       fmt.Sprintf(`snapshot appengine appengine-env %s`, args),
+			golden:    "Error: required flag(s) \"project\" not set\n",
+		},
+		{
+			wantError: true,
+			name:      "snapshot appengine fails if --include-regex and --exclude are set",
+			cmd:       fmt.Sprintf(`snapshot appengine appengine-env --project p --include-regex "^a" --exclude beta %s`, args),
+			golden:    "Error: only one of --include-regex, --exclude is allowed\n",
+		},
+		{
+			name:        "snapshot appengine dry-runs the services as cloud-run services",
+			cmd:         fmt.Sprintf(`snapshot appengine appengine-env --project p --dry-run %s`, args),
+			goldenRegex: `(?s)THIS IS A DRY-RUN.*report/cloud-run.*"type": "cloud-run".*"serviceName": "alpha".*"serviceName": "beta"`,
+		},
+		{
+			name:        "snapshot appengine reports the services that are not excluded",
+			cmd:         fmt.Sprintf(`snapshot app-engine appengine-env --project p --exclude alpha %s`, args),
+			goldenRegex: `\[1\] artifacts were reported to environment appengine-env`,
+		},
+	}
+	runTestCmd(t, tests)
+
+	report := server.reports["PUT /api/v2/environments/test-org/appengine-env/report/cloud-run"]
+	require.NotContains(t, report, `"serviceName":"alpha"`)
+	require.Contains(t, report, `"serviceName":"beta"`)
+
+	newAppEngineClient = func(_ context.Context) (appEngineLister, error) {
+		return stubAppEngineLister{err: status.Error(codes.NotFound, "no app")}, nil
+	}
+	_, combined, _, _, err := executeCommandC(fmt.Sprintf(`snapshot appengine appengine-env --project p %s`, args))
+	require.Error(t, err)
+	require.Contains(t, combined, "without an App Engine application")
+}
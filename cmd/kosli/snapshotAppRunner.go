@@ -0,0 +1,123 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/kosli-dev/cli/internal/aws"
+	"github.com/kosli-dev/cli/internal/filters"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/spf13/cobra"
+)
+
+const snapshotAppRunnerShortDesc = `Report a snapshot of the images running in one or more AWS App Runner services to Kosli.  `
+const snapshotAppRunnerLongDesc = snapshotAppRunnerShortDesc + `
+Skip all filtering flags to report every running service in a given AWS account and region.
+Use ^--services^ and/or ^--services-regex^ OR ^--exclude^ and/or ^--exclude-regex^ to filter the services to snapshot.
+
+The digest of an image pinned by tag in a private ECR repository is looked up in ECR, which needs the ^ecr:DescribeImages^ permission.
+Services deployed from source code have no image, and are not reported.
+
+The services are reported to an ECS environment, with their service names, image digests and the time of their latest deployment.` + awsAuthDesc
+
+const snapshotAppRunnerExample = `
+# report all running App Runner services in an AWS account and region (AWS auth provided in env variables):
+export AWS_REGION=yourAWSRegion
+export AWS_ACCESS_KEY_ID=yourAWSAccessKeyID
+export AWS_SECRET_ACCESS_KEY=yourAWSSecretAccessKey
+
+kosli snapshot apprunner yourEnvironmentName \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report some App Runner services (AWS auth provided in flags):
+kosli snapshot apprunner yourEnvironmentName \
+	--services backend,frontend \
+	--aws-key-id yourAWSAccessKeyID \
+	--aws-secret-key yourAWSSecretAccessKey \
+	--aws-region yourAWSRegion \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# exclude App Runner services matching a pattern
+kosli snapshot apprunner yourEnvironmentName --exclude-regex "^staging-.*" ...
+`
+
+type snapshotAppRunnerOptions struct {
+	filter         *filters.ResourceFilterOptions
+	awsStaticCreds *aws.AWSStaticCreds
+}
+
+func newSnapshotAppRunnerCmd(out io.Writer) *cobra.Command {
+	o := new(snapshotAppRunnerOptions)
+	o.awsStaticCreds = new(aws.AWSStaticCreds)
+	o.filter = new(filters.ResourceFilterOptions)
+	cmd := &cobra.Command{
+		Use:     "apprunner ENVIRONMENT-NAME",
+		Short:   snapshotAppRunnerShortDesc,
+		Long:    snapshotAppRunnerLongDesc,
+		Example: snapshotAppRunnerExample,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			err := RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			for _, pair := range [][]string{
+				{"services", "exclude"},
+				{"services", "exclude-regex"},
+				{"services-regex", "exclude"},
+				{"services-regex", "exclude-regex"},
+			} {
+				if err = MuXRequiredFlags(cmd, pair, false); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&o.filter.IncludeNames, "services", []string{}, appRunnerServicesFlag)
+	cmd.Flags().StringSliceVar(&o.filter.IncludeNamesRegex, "services-regex", []string{}, appRunnerServicesRegexFlag)
+	cmd.Flags().StringSliceVar(&o.filter.ExcludeNames, "exclude", []string{}, appRunnerExcludeFlag)
+	cmd.Flags().StringSliceVar(&o.filter.ExcludeNamesRegex, "exclude-regex", []string{}, appRunnerExcludeRegexFlag)
+	addAWSAuthFlags(cmd, o.awsStaticCreds)
+	addDryRunFlag(cmd)
+
+	return cmd
+}
+
+func (o *snapshotAppRunnerOptions) run(args []string) error {
+	envName := args[0]
+
+	if err := ensureEnvironment(envName, "ECS"); err != nil {
+		return err
+	}
+
+	url, err := url.JoinPath(global.Host, "api/v2/environments", global.Org, envName, "report/ECS")
+	if err != nil {
+		return err
+	}
+	servicesData, err := o.awsStaticCreds.GetAppRunnerServicesData(o.filter, logger)
+	if err != nil {
+		return err
+	}
+
+	reqParams := &requests.RequestParams{
+		Method:  http.MethodPut,
+		URL:     url,
+		Payload: &aws.EcsEnvRequest{Artifacts: servicesData},
+		DryRun:  global.DryRun,
+		Token:   global.ApiToken,
+	}
+	reported, err := reportSnapshot(reqParams, envName)
+	if err == nil && reported && !global.DryRun {
+		logger.Info("%d App Runner services were reported to environment %s", len(servicesData), envName)
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	awsSdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apprunner/types"
+	"github.com/kosli-dev/cli/internal/aws"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotAppRunnerCmd(t *testing.T) {
+	server := newFakeReportServer(t)
+	aws.NewAppRunnerClientFunc = func(_ *aws.AWSStaticCreds) (aws.AppRunnerAPI, error) {
+		services := []types.Service{}
+		for _, name := range []string{"backend", "frontend"} {
+			services = append(services, types.Service{
+				ServiceArn:  awsSdk.String("arn:aws:apprunner:eu-west-1:111111111111:service/" + name),
+				ServiceName: awsSdk.String(name),
+				Status:      types.ServiceStatusRunning,
+				UpdatedAt:   awsSdk.Time(time.Unix(1700000000, 0)),
+				SourceConfiguration: &types.SourceConfiguration{ImageRepository: &types.ImageRepository{
+					ImageIdentifier:     awsSdk.String("public.ecr.aws/team/" + name + "@sha256:0123abcd"),
+					ImageRepositoryType: types.ImageRepositoryTypeEcrPublic,
+				}},
+			})
+		}
+		return &aws.FakeAppRunnerClient{Services: services}, nil
+	}
+	t.Cleanup(aws.ResetAppRunnerClientFactory)
+
+	tests := []cmdTestCase{
+		{
+			name:   "snapshot apprunner reports the running services to an ECS environment",
+			cmd:    "snapshot apprunner my-env --exclude frontend " + server.args(),
+			golden: "1 App Runner services were reported to environment my-env\n",
+		},
+		{
+			wantError: true,
+			name:      "snapshot apprunner fails with both --services and --exclude",
+			cmd:       "snapshot apprunner my-env --services backend --exclude frontend " + server.args(),
+			golden:    "Error: only one of --services, --exclude is allowed\n",
+		},
+		{
+			wantError: true,
+			name:      "snapshot apprunner fails without an environment name",
+			cmd:       "snapshot apprunner " + server.args(),
+			golden:    "Error: accepts 1 arg(s), received 0\n",
+		},
+	}
+	runTestCmd(t, tests)
+
+	require.JSONEq(t, `{"artifacts":[{"taskArn":"arn:aws:apprunner:eu-west-1:111111111111:service/backend","service_name":"backend",`+
+		`"digests":{"public.ecr.aws/team/backend@sha256:0123abcd":"0123abcd"},"creationTimestamp":1700000000}]}`,
+		server.reports["PUT /api/v2/environments/test-org/my-env/report/ECS"])
+}
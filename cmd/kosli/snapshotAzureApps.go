@@ -108,8 +108,15 @@ func newSnapshotAzureAppsCmd(out io.Writer) *cobra.Command {
 }
 
 func (o *snapshotAzureAppsOptions) run(args []string) error {
-	envName := args[0]
+	webAppsData, err := o.azureStaticCredentials.GetAzureAppsData(logger)
+	if err != nil {
+		return err
+	}
+	return reportAzureApps(args[0], webAppsData)
+}
 
+// reportAzureApps reports appsData to the azure-apps environment envName.
+func reportAzureApps(envName string, appsData []*azure.AppData) error {
 	if err := ensureEnvironment(envName, "azure-apps"); err != nil {
 		return err
 	}
@@ -119,12 +126,8 @@ func (o *snapshotAzureAppsOptions) run(args []string) error {
 		return err
 	}
 
-	webAppsData, err := o.azureStaticCredentials.GetAzureAppsData(logger)
-	if err != nil {
-		return err
-	}
 	payload := &azure.AzureAppsRequest{
-		Artifacts: webAppsData,
+		Artifacts: appsData,
 	}
 	reqParams := &requests.RequestParams{
 		Method:  http.MethodPut,
@@ -133,9 +136,9 @@ func (o *snapshotAzureAppsOptions) run(args []string) error {
 		DryRun:  global.DryRun,
 		Token:   global.ApiToken,
 	}
-	_, err = kosliClient.Do(reqParams)
-	if err == nil && !global.DryRun {
-		logger.Info("%d azure apps were reported to environment %s", len(webAppsData), envName)
+	reported, err := reportSnapshot(reqParams, envName)
+	if err == nil && reported && !global.DryRun {
+		logger.Info("%d azure apps were reported to environment %s", len(appsData), envName)
 	}
 	return err
 }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"io"
+
+	"github.com/kosli-dev/cli/internal/azure"
+	"github.com/spf13/cobra"
+)
+
+const snapshotAzureContainerAppsShortDesc = `Report a snapshot of Azure Container Apps in an Azure resource group or subscription to Kosli.  `
+
+const snapshotAzureContainerAppsLongDesc = snapshotAzureContainerAppsShortDesc + `
+Each container app is reported with the container images of its revisions that receive traffic
+(an active revision with a traffic weight above 0), so an app splitting traffic between two
+revisions reports the images of both. Inactive revisions and revisions without traffic are not
+reported. The digests of tag-pinned images are read from Azure Container Registry; digest-pinned
+images are reported with their pinned digest.
+
+Skip ^--azure-resource-group-name^ to report every container app of the subscription.
+
+To authenticate to Azure, you need to create Azure service principal with a secret
+and provide these Azure credentials via flags or by exporting the equivalent KOSLI env vars (e.g. KOSLI_AZURE_CLIENT_ID).
+The service principal needs to have the following permissions:
+  1) Microsoft.App/containerApps/read
+  2) Microsoft.App/containerApps/revisions/read
+  3) Microsoft.ContainerRegistry/registries/pull/read
+`
+
+const snapshotAzureContainerAppsExample = `
+# report the container apps of a resource group:
+kosli snapshot azure-containerapps yourEnvironmentName \
+	--azure-client-id yourAzureClientID \
+	--azure-client-secret yourAzureClientSecret \
+	--azure-tenant-id yourAzureTenantID \
+	--azure-subscription-id yourAzureSubscriptionID \
+	--azure-resource-group-name yourAzureResourceGroupName \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report the container apps of a subscription:
+kosli snapshot azure-containerapps yourEnvironmentName \
+	--azure-client-id yourAzureClientID \
+	--azure-client-secret yourAzureClientSecret \
+	--azure-tenant-id yourAzureTenantID \
+	--azure-subscription-id yourAzureSubscriptionID \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+// getAzureContainerAppsData is the seam between the command and Azure. Tests
+// override it to return canned apps.
+var getAzureContainerAppsData = func(creds *azure.AzureStaticCredentials) ([]*azure.AppData, error) {
+	return creds.GetAzureContainerAppsData(logger)
+}
+
+type snapshotAzureContainerAppsOptions struct {
+	azureStaticCredentials *azure.AzureStaticCredentials
+}
+
+func newSnapshotAzureContainerAppsCmd(out io.Writer) *cobra.Command {
+	o := new(snapshotAzureContainerAppsOptions)
+	o.azureStaticCredentials = new(azure.AzureStaticCredentials)
+	cmd := &cobra.Command{
+		Use:     "azure-containerapps ENVIRONMENT-NAME",
+		Short:   snapshotAzureContainerAppsShortDesc,
+		Long:    snapshotAzureContainerAppsLongDesc,
+		Example: snapshotAzureContainerAppsExample,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			err := RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	addAzureScopeFlags(cmd, o.azureStaticCredentials)
+	addDryRunFlag(cmd)
+
+	return cmd
+}
+
+func (o *snapshotAzureContainerAppsOptions) run(args []string) error {
+	appsData, err := getAzureContainerAppsData(o.azureStaticCredentials)
+	if err != nil {
+		return err
+	}
+	return reportAzureApps(args[0], appsData)
+}
+
+// addAzureScopeFlags adds the flags of the Azure snapshot commands which
+// report the apps of a resource group, or of a whole subscription.
+func addAzureScopeFlags(cmd *cobra.Command, creds *azure.AzureStaticCredentials) {
+	cmd.Flags().StringVar(&creds.ClientId, "azure-client-id", "", azureClientIdFlag)
+	cmd.Flags().StringVar(&creds.ClientSecret, "azure-client-secret", "", azureClientSecretFlag)
+	cmd.Flags().StringVar(&creds.TenantId, "azure-tenant-id", "", azureTenantIdFlag)
+	cmd.Flags().StringVar(&creds.SubscriptionId, "azure-subscription-id", "", azureSubscriptionIdFlag)
+	cmd.Flags().StringVar(&creds.ResourceGroupName, "azure-resource-group-name", "", azureResourceGroupScopeFlag)
+
+	err := RequireFlags(cmd, []string{
+		"azure-client-id", "azure-client-secret",
+		"azure-tenant-id", "azure-subscription-id",
+	})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+}
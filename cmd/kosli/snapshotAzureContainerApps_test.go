@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/azure"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotAzureContainerAppsAndFunctions(t *testing.T) {
+	server := newFakeReportServer(t)
+
+	var scopes []string
+	origGetAzureContainerAppsData, origGetAzureFunctionAppsData := getAzureContainerAppsData, getAzureFunctionAppsData
+	t.Cleanup(func() {
+		getAzureContainerAppsData, getAzureFunctionAppsData = origGetAzureContainerAppsData, origGetAzureFunctionAppsData
+	})
+	getAzureContainerAppsData = func(creds *azure.AzureStaticCredentials) ([]*azure.AppData, error) {
+		scopes = append(scopes, creds.SubscriptionId+"/"+creds.ResourceGroupName)
+		return []*azure.AppData{{
+			AppName:       "web",
+			AppKind:       "containerapp",
+			DigestsSource: "acr",
+			Digests:       map[string]string{"myregistry.azurecr.io/web:v1": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"},
+			StartedAt:     1777636800,
+		}}, nil
+	}
+	getAzureFunctionAppsData = func(creds *azure.AzureStaticCredentials) ([]*azure.AppData, error) {
+		return []*azure.AppData{
+			{AppName: "func-a", AppKind: "functionapp,linux", DigestsSource: "kosli-cli", Digests: map[string]string{"func-a": "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"}},
+			{AppName: "func-b", AppKind: "functionapp", DigestsSource: "kosli-cli", Digests: map[string]string{"func-b": "cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc"}},
+		}, nil
+	}
+
+	azureArgs := "--azure-client-id id --azure-client-secret secret --azure-tenant-id tenant --azure-subscription-id sub"
+	args := server.args()
+	tests := []cmdTestCase{
+		{
+			wantError: true,
+			name:      "snapshot azure-containerapps fails when Azure subscription ID is not set",
+			cmd:       fmt.Sprintf(`snapshot azure-containerapps apps-env --azure-client-id id --azure-client-secret secret --azure-tenant-id tenant %s`, args),
+			golden:    "Error: required flag(s) \"azure-subscription-id\" not set\n",
+		},
+		{
+			name:        "snapshot azure-containerapps dry-runs the container apps",
+			cmd:         fmt.Sprintf(`snapshot azure-containerapps apps-env %s --azure-resource-group-name rg --dry-run %s`, azureArgs, args),
+			goldenRegex: `(?s)THIS IS A DRY-RUN.*report/azure-apps.*"app_name": "web".*"app_kind": "containerapp"`,
+		},
+		{
+			name:   "snapshot azure-containerapps reports the container apps of a subscription",
+			cmd:    fmt.Sprintf(`snapshot azure-containerapps apps-env %s %s`, azureArgs, args),
+			golden: "1 azure apps were reported to environment apps-env\n",
+		},
+		{
+			wantError: true,
+			name:      "snapshot azure-functions fails when Azure client secret is not set",
+			cmd:       fmt.Sprintf(`snapshot azure-functions functions-env --azure-client-id id --azure-tenant-id tenant --azure-subscription-id sub %s`, args),
+			golden:    "Error: required flag(s) \"azure-client-secret\" not set\n",
+		},
+		{
+			name:   "snapshot azure-functions reports the function apps",
+			cmd:    fmt.Sprintf(`snapshot azure-functions functions-env %s --azure-resource-group-name rg %s`, azureArgs, args),
+			golden: "2 azure apps were reported to environment functions-env\n",
+		},
+	}
+	runTestCmd(t, tests)
+
+	require.Equal(t, []string{"sub/rg", "sub/"}, scopes)
+	require.Contains(t, server.reports["PUT /api/v2/environments/test-org/apps-env/report/azure-apps"], `"app_name":"web"`)
+	report := server.reports["PUT /api/v2/environments/test-org/functions-env/report/azure-apps"]
+	require.Contains(t, report, `"app_name":"func-a"`)
+	require.Contains(t, report, `"app_name":"func-b"`)
+}
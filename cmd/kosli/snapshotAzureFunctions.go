@@ -0,0 +1,95 @@
+package main
+
+import (
+	"io"
+
+	"github.com/kosli-dev/cli/internal/azure"
+	"github.com/spf13/cobra"
+)
+
+const snapshotAzureFunctionsShortDesc = `Report a snapshot of running Azure Function apps in an Azure resource group or subscription to Kosli.  `
+
+const snapshotAzureFunctionsLongDesc = snapshotAzureFunctionsShortDesc + `
+Containerized function apps are reported with the digest of their image, read from Azure Container Registry.
+
+Other function apps are fingerprinted from the content of their zip package, like ^kosli snapshot azure^ does.
+A function app with a ^WEBSITE_RUN_FROM_PACKAGE^ app setting set to a URL, which is how function apps on
+consumption plans usually run, is fingerprinted from the package downloaded from that URL. Other function apps
+are fingerprinted from the package downloaded from their Kudu site. The fingerprint is the same as unzipping
+the package and then running ^kosli fingerprint -t dir yourDirName^.
+
+Skip ^--azure-resource-group-name^ to report every function app of the subscription.
+
+To authenticate to Azure, you need to create Azure service principal with a secret
+and provide these Azure credentials via flags or by exporting the equivalent KOSLI env vars (e.g. KOSLI_AZURE_CLIENT_ID).
+The service principal needs to have the following permissions:
+  1) Microsoft.Web/sites/Read
+  2) Microsoft.Web/sites/config/list/Action
+  3) Microsoft.ContainerRegistry/registries/pull/read
+`
+
+const snapshotAzureFunctionsExample = `
+# report the function apps of a resource group:
+kosli snapshot azure-functions yourEnvironmentName \
+	--azure-client-id yourAzureClientID \
+	--azure-client-secret yourAzureClientSecret \
+	--azure-tenant-id yourAzureTenantID \
+	--azure-subscription-id yourAzureSubscriptionID \
+	--azure-resource-group-name yourAzureResourceGroupName \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report the function apps of a subscription:
+kosli snapshot azure-functions yourEnvironmentName \
+	--azure-client-id yourAzureClientID \
+	--azure-client-secret yourAzureClientSecret \
+	--azure-tenant-id yourAzureTenantID \
+	--azure-subscription-id yourAzureSubscriptionID \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+// getAzureFunctionAppsData is the seam between the command and Azure. Tests
+// override it to return canned apps.
+var getAzureFunctionAppsData = func(creds *azure.AzureStaticCredentials) ([]*azure.AppData, error) {
+	return creds.GetAzureFunctionAppsData(logger)
+}
+
+type snapshotAzureFunctionsOptions struct {
+	azureStaticCredentials *azure.AzureStaticCredentials
+}
+
+func newSnapshotAzureFunctionsCmd(out io.Writer) *cobra.Command {
+	o := new(snapshotAzureFunctionsOptions)
+	o.azureStaticCredentials = new(azure.AzureStaticCredentials)
+	cmd := &cobra.Command{
+		Use:     "azure-functions ENVIRONMENT-NAME",
+		Short:   snapshotAzureFunctionsShortDesc,
+		Long:    snapshotAzureFunctionsLongDesc,
+		Example: snapshotAzureFunctionsExample,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			err := RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	addAzureScopeFlags(cmd, o.azureStaticCredentials)
+	addDryRunFlag(cmd)
+
+	return cmd
+}
+
+func (o *snapshotAzureFunctionsOptions) run(args []string) error {
+	appsData, err := getAzureFunctionAppsData(o.azureStaticCredentials)
+	if err != nil {
+		return err
+	}
+	return reportAzureApps(args[0], appsData)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/kosli-dev/cli/internal/aws"
+	"github.com/kosli-dev/cli/internal/filters"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/spf13/cobra"
+)
+
+const snapshotBeanstalkShortDesc = `Report a snapshot of the application versions running in one or more AWS Elastic Beanstalk environments to Kosli.  `
+const snapshotBeanstalkLongDesc = snapshotBeanstalkShortDesc + `
+Skip all filtering flags to report every Elastic Beanstalk environment in a given AWS account and region.
+Use ^--environments^ and/or ^--environments-regex^ OR ^--exclude^ and/or ^--exclude-regex^ to filter the Elastic Beanstalk environments to snapshot.
+
+The fingerprint of an application version is the SHA256 digest of its source bundle, which is downloaded from S3 once per version.
+It is the fingerprint of ^kosli fingerprint --artifact-type file^ on the source bundle.
+
+The Elastic Beanstalk environments are reported to an ECS environment, with their application and environment names, version labels,
+source bundle fingerprints and the time of their latest update.` + awsAuthDesc
+
+const snapshotBeanstalkExample = `
+# report all Elastic Beanstalk environments in an AWS account and region (AWS auth provided in env variables):
+export AWS_REGION=yourAWSRegion
+export AWS_ACCESS_KEY_ID=yourAWSAccessKeyID
+export AWS_SECRET_ACCESS_KEY=yourAWSSecretAccessKey
+
+kosli snapshot beanstalk yourEnvironmentName \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report some Elastic Beanstalk environments (AWS auth provided in flags):
+kosli snapshot beanstalk yourEnvironmentName \
+	--environments shop-prod,shop-blue \
+	--aws-key-id yourAWSAccessKeyID \
+	--aws-secret-key yourAWSSecretAccessKey \
+	--aws-region yourAWSRegion \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# exclude Elastic Beanstalk environments matching a pattern
+kosli snapshot beanstalk yourEnvironmentName --exclude-regex ".*-staging$" ...
+`
+
+type snapshotBeanstalkOptions struct {
+	filter         *filters.ResourceFilterOptions
+	awsStaticCreds *aws.AWSStaticCreds
+}
+
+func newSnapshotBeanstalkCmd(out io.Writer) *cobra.Command {
+	o := new(snapshotBeanstalkOptions)
+	o.awsStaticCreds = new(aws.AWSStaticCreds)
+	o.filter = new(filters.ResourceFilterOptions)
+	cmd := &cobra.Command{
+		Use:     "beanstalk ENVIRONMENT-NAME",
+		Short:   snapshotBeanstalkShortDesc,
+		Long:    snapshotBeanstalkLongDesc,
+		Example: snapshotBeanstalkExample,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			err := RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			for _, pair := range [][]string{
+				{"environments", "exclude"},
+				{"environments", "exclude-regex"},
+				{"environments-regex", "exclude"},
+				{"environments-regex", "exclude-regex"},
+			} {
+				if err = MuXRequiredFlags(cmd, pair, false); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&o.filter.IncludeNames, "environments", []string{}, beanstalkEnvironmentsFlag)
+	cmd.Flags().StringSliceVar(&o.filter.IncludeNamesRegex, "environments-regex", []string{}, beanstalkEnvironmentsRegexFlag)
+	cmd.Flags().StringSliceVar(&o.filter.ExcludeNames, "exclude", []string{}, beanstalkExcludeFlag)
+	cmd.Flags().StringSliceVar(&o.filter.ExcludeNamesRegex, "exclude-regex", []string{}, beanstalkExcludeRegexFlag)
+	addAWSAuthFlags(cmd, o.awsStaticCreds)
+	addDryRunFlag(cmd)
+
+	return cmd
+}
+
+func (o *snapshotBeanstalkOptions) run(args []string) error {
+	envName := args[0]
+
+	if err := ensureEnvironment(envName, "ECS"); err != nil {
+		return err
+	}
+
+	url, err := url.JoinPath(global.Host, "api/v2/environments", global.Org, envName, "report/ECS")
+	if err != nil {
+		return err
+	}
+	environmentsData, err := o.awsStaticCreds.GetBeanstalkEnvironmentsData(o.filter, logger)
+	if err != nil {
+		return err
+	}
+
+	reqParams := &requests.RequestParams{
+		Method:  http.MethodPut,
+		URL:     url,
+		Payload: &aws.EcsEnvRequest{Artifacts: environmentsData},
+		DryRun:  global.DryRun,
+		Token:   global.ApiToken,
+	}
+	reported, err := reportSnapshot(reqParams, envName)
+	if err == nil && reported && !global.DryRun {
+		logger.Info("%d Elastic Beanstalk environments were reported to environment %s", len(environmentsData), envName)
+	}
+	return err
+}
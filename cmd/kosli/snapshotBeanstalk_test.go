@@ -0,0 +1,59 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+	"time"
+
+	awsSdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk/types"
+	"github.com/kosli-dev/cli/internal/aws"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotBeanstalkCmd(t *testing.T) {
+	server := newFakeReportServer(t)
+	bundle := []byte("the source bundle of v1")
+	aws.NewBeanstalkClientFunc = func(_ *aws.AWSStaticCreds) (aws.BeanstalkAPI, error) {
+		return &aws.FakeBeanstalkClient{
+			Environments: []types.EnvironmentDescription{{
+				EnvironmentArn:  awsSdk.String("arn:aws:elasticbeanstalk:eu-west-1:111111111111:environment/shop/shop-prod"),
+				EnvironmentName: awsSdk.String("shop-prod"),
+				ApplicationName: awsSdk.String("shop"),
+				VersionLabel:    awsSdk.String("v1"),
+				Status:          types.EnvironmentStatusReady,
+				DateUpdated:     awsSdk.Time(time.Unix(1700000000, 0)),
+			}},
+			Versions: []types.ApplicationVersionDescription{{
+				ApplicationName: awsSdk.String("shop"),
+				VersionLabel:    awsSdk.String("v1"),
+				SourceBundle:    &types.S3Location{S3Bucket: awsSdk.String("bundles"), S3Key: awsSdk.String("shop/v1.zip")},
+			}},
+		}, nil
+	}
+	aws.NewS3ClientFunc = func(_ *aws.AWSStaticCreds) (aws.S3API, error) {
+		return &aws.FakeS3Client{Bucket: "bundles", Objects: map[string][]byte{"shop/v1.zip": bundle}}, nil
+	}
+	t.Cleanup(aws.ResetBeanstalkClientFactory)
+	t.Cleanup(aws.ResetS3ClientFactory)
+
+	tests := []cmdTestCase{
+		{
+			name:   "snapshot beanstalk reports the source bundle fingerprints to an ECS environment",
+			cmd:    "snapshot beanstalk my-env " + server.args(),
+			golden: "1 Elastic Beanstalk environments were reported to environment my-env\n",
+		},
+		{
+			wantError: true,
+			name:      "snapshot beanstalk fails with both --environments-regex and --exclude-regex",
+			cmd:       "snapshot beanstalk my-env --environments-regex shop --exclude-regex blue " + server.args(),
+			golden:    "Error: only one of --environments-regex, --exclude-regex is allowed\n",
+		},
+	}
+	runTestCmd(t, tests)
+
+	require.JSONEq(t, fmt.Sprintf(`{"artifacts":[{"taskArn":"arn:aws:elasticbeanstalk:eu-west-1:111111111111:environment/shop/shop-prod",`+
+		`"cluster_name":"shop","service_name":"shop-prod","digests":{"v1":"%x"},"creationTimestamp":1700000000}]}`, sha256.Sum256(bundle)),
+		server.reports["PUT /api/v2/environments/test-org/my-env/report/ECS"])
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"time"
+
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/snapshotstate"
+	"github.com/spf13/cobra"
+)
+
+// changeDetectionOptions holds the values of the group-wide change detection
+// flags that are shared across all `kosli snapshot` subcommands.
+type changeDetectionOptions struct {
+	skipUnchanged     bool
+	heartbeatInterval time.Duration
+	stateDir          string
+}
+
+// snapshotChangeDetection is the shared destination for the change detection
+// flags, registered once as persistent flags on the parent `snapshot` command
+// like the auto-environment flags.
+var snapshotChangeDetection = &changeDetectionOptions{}
+
+const defaultHeartbeatInterval = time.Hour
+
+// addChangeDetectionFlags registers the change detection flags on the snapshot
+// command group, so that every subcommand inherits them.
+func addChangeDetectionFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&snapshotChangeDetection.skipUnchanged, "skip-unchanged", false, skipUnchangedFlag)
+	cmd.PersistentFlags().DurationVar(&snapshotChangeDetection.heartbeatInterval, "heartbeat-interval", defaultHeartbeatInterval, heartbeatIntervalFlag)
+	cmd.PersistentFlags().StringVar(&snapshotChangeDetection.stateDir, "state-dir", "", snapshotStateDirFlag)
+}
+
+// reportSnapshot sends the PUT request of a snapshot report to an environment.
+// With --skip-unchanged, the request is not sent when its payload has the same
+// digest as the last snapshot reported to the environment from this machine,
+// unless --heartbeat-interval has passed since then, in which case the
+// unchanged snapshot is sent again so that Kosli sees the environment is still
+// being reported. The Kosli API has no lighter request to say so, so the
+// heartbeat is the full report. It returns false when the request was not sent.
+func reportSnapshot(reqParams *requests.RequestParams, envName string) (bool, error) {
+	o := snapshotChangeDetection
+	if !o.skipUnchanged || reqParams.DryRun {
+		_, err := kosliClient.Do(reqParams)
+		return true, err
+	}
+
+	dir := o.stateDir
+	if dir == "" {
+		var err error
+		dir, err = snapshotstate.DefaultDir()
+		if err != nil {
+			return false, err
+		}
+	}
+	store := &snapshotstate.Store{Dir: dir}
+
+	digest, err := snapshotstate.Digest(reqParams.Payload)
+	if err != nil {
+		return false, err
+	}
+	if state, unchanged := store.Unchanged(reqParams.URL, digest, o.heartbeatInterval); unchanged {
+		logger.Info("environment %s has not changed since its snapshot was last reported at %s. Skipping the report",
+			envName, time.Unix(state.ReportedAt, 0).Format(time.RFC3339))
+		return false, nil
+	}
+
+	if _, err := kosliClient.Do(reqParams); err != nil {
+		return true, err
+	}
+	// failing to record the state only costs an unchanged report next time
+	if err := store.Put(reqParams.URL, digest); err != nil {
+		logger.Warn("failed to record the snapshot reported to environment %s in %s: %v", envName, dir, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotSkipUnchanged(t *testing.T) {
+	server := newFakeReportServer(t)
+	artifactPath := filepath.Join(t.TempDir(), "app")
+	require.NoError(t, os.WriteFile(artifactPath, []byte("v1"), 0o644))
+	stateDir := t.TempDir()
+
+	const reportKey = "PUT /api/v2/environments/test-org/change-env/report/server"
+	snapshot := fmt.Sprintf("snapshot path change-env --path %s --name app --skip-unchanged --state-dir %s %s",
+		artifactPath, stateDir, server.args())
+	reported := "[1] artifacts were reported to environment change-env\n"
+	skipped := `^environment change-env has not changed since its snapshot was last reported at \S+\. Skipping the report\n$`
+
+	runTestCmd(t, []cmdTestCase{
+		{
+			name:   "the first snapshot is reported",
+			cmd:    snapshot,
+			golden: reported,
+		},
+	})
+	require.Contains(t, server.reports, reportKey)
+	delete(server.reports, reportKey)
+
+	runTestCmd(t, []cmdTestCase{
+		{
+			name:        "an unchanged snapshot is not reported",
+			cmd:         snapshot,
+			goldenRegex: skipped,
+		},
+		{
+			name:   "an unchanged snapshot is reported without --skip-unchanged",
+			cmd:    fmt.Sprintf("snapshot path change-env --path %s --name app %s", artifactPath, server.args()),
+			golden: reported,
+		},
+	})
+	delete(server.reports, reportKey)
+
+	runTestCmd(t, []cmdTestCase{
+		{
+			name:        "an unchanged snapshot is still not reported",
+			cmd:         snapshot,
+			goldenRegex: skipped,
+		},
+	})
+	require.NotContains(t, server.reports, reportKey)
+
+	runTestCmd(t, []cmdTestCase{
+		{
+			name:   "an unchanged snapshot is reported again once the heartbeat interval has passed",
+			cmd:    snapshot + " --heartbeat-interval 1ns",
+			golden: reported,
+		},
+	})
+
+	require.NoError(t, os.WriteFile(artifactPath, []byte("v2"), 0o644))
+	runTestCmd(t, []cmdTestCase{
+		{
+			name:   "a changed snapshot is reported",
+			cmd:    snapshot,
+			golden: reported,
+		},
+		{
+			name:        "and is then unchanged",
+			cmd:         snapshot,
+			goldenRegex: skipped,
+		},
+	})
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/kosli-dev/cli/internal/cloudrun"
+	"github.com/kosli-dev/cli/internal/filters"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/spf13/cobra"
+)
+
+const snapshotCloudFunctionsShortDesc = `Report a snapshot of Cloud Functions (2nd gen) in a Google Cloud project and region to Kosli.  `
+const snapshotCloudFunctionsLongDesc = snapshotCloudFunctionsShortDesc + `
+A 2nd gen Cloud Function runs as a Cloud Run service, built into a container image.
+Each function contributes one artifact: the image of the Cloud Run revision the function
+runs as. Functions are reported to a ^cloud-run^ environment, like Cloud Run services,
+with the function name as the service name. 1st gen functions are not containers and are
+not reported. Functions that are being deployed or failed to deploy are skipped.
+
+GCP authentication uses Application Default Credentials, like ^kosli snapshot cloud-run^.
+The caller needs ^roles/cloudfunctions.viewer^ and ^roles/run.viewer^ on the target project,
+plus ^roles/artifactregistry.reader^ on the Artifact Registry repository of the function
+images (^gcf-artifacts^ by default) to resolve the digests of tag-pinned images. Missing
+the AR role is non-fatal — tag-pinned artifacts then surface with empty digests.
+
+Skip all filtering flags to report every function in the given project + region. Use
+^--include^ and/or ^--include-regex^ to snapshot only a subset, OR ^--exclude^ and/or
+^--exclude-regex^ to omit a subset; include and exclude are mutually exclusive.`
+
+const snapshotCloudFunctionsExample = `
+# report every Cloud Function in a project + region:
+kosli snapshot cloudfunctions yourEnvironmentName \
+	--project yourGCPProject \
+	--region yourGCPRegion \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report every Cloud Function except those whose names start with test-:
+kosli snapshot cloudfunctions yourEnvironmentName \
+	--project yourGCPProject \
+	--region yourGCPRegion \
+	--exclude-regex "^test-" \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+// cloudFunctionsLister is the seam between the command and the GCP client.
+// Tests override newCloudFunctionsClient with a stub that returns canned
+// functions.
+type cloudFunctionsLister interface {
+	ListFunctions(ctx context.Context, project, region string) ([]cloudrun.Service, error)
+}
+
+var newCloudFunctionsClient = func(ctx context.Context) (cloudFunctionsLister, error) {
+	return cloudrun.NewFunctionsClient(ctx, logger)
+}
+
+type snapshotCloudFunctionsOptions struct {
+	project        string
+	region         string
+	resourceFilter *filters.ResourceFilterOptions
+}
+
+func newSnapshotCloudFunctionsCmd(out io.Writer) *cobra.Command {
+	o := new(snapshotCloudFunctionsOptions)
+	o.resourceFilter = new(filters.ResourceFilterOptions)
+	cmd := &cobra.Command{
+		Use:     "cloudfunctions ENVIRONMENT-NAME",
+		Aliases: []string{"cloud-functions"},
+		Short:   snapshotCloudFunctionsShortDesc,
+		Long:    snapshotCloudFunctionsLongDesc,
+		Example: snapshotCloudFunctionsExample,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := RequireGlobalFlags(global, []string{"Org", "ApiToken"}); err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+			return muxCloudRunFilterFlags(cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.project, "project", "", "[required] GCP project ID.")
+	cmd.Flags().StringVar(&o.region, "region", "", "[required] GCP region (e.g. europe-west1).")
+	cmd.Flags().StringSliceVar(&o.resourceFilter.IncludeNames, "include", []string{}, cloudFunctionsIncludeFlag)
+	cmd.Flags().StringSliceVar(&o.resourceFilter.IncludeNamesRegex, "include-regex", []string{}, cloudFunctionsIncludeRegexFlag)
+	cmd.Flags().StringSliceVar(&o.resourceFilter.ExcludeNames, "exclude", []string{}, cloudFunctionsExcludeFlag)
+	cmd.Flags().StringSliceVar(&o.resourceFilter.ExcludeNamesRegex, "exclude-regex", []string{}, cloudFunctionsExcludeRegexFlag)
+	addDryRunFlag(cmd)
+
+	if err := RequireFlags(cmd, []string{"project", "region"}); err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+func (o *snapshotCloudFunctionsOptions) run(args []string) error {
+	ctx := context.Background()
+	client, err := newCloudFunctionsClient(ctx)
+	if err != nil {
+		return err
+	}
+	if closer, ok := client.(io.Closer); ok {
+		defer func() { _ = closer.Close() }()
+	}
+	functions, err := client.ListFunctions(ctx, o.project, o.region)
+	if err != nil {
+		return cloudrun.ClassifyFunctions(err, o.project, o.region)
+	}
+	return reportCloudRunServices(args[0], functions, o.resourceFilter)
+}
+
+// muxCloudRunFilterFlags makes the include and exclude flags of the GCP
+// snapshot commands mutually exclusive in every combination.
+func muxCloudRunFilterFlags(cmd *cobra.Command) error {
+	for _, pair := range [][]string{
+		{"include", "exclude"},
+		{"include", "exclude-regex"},
+		{"include-regex", "exclude"},
+		{"include-regex", "exclude-regex"},
+	} {
+		if err := MuXRequiredFlags(cmd, pair, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reportCloudRunServices reports the services that resourceFilter includes
+// to the cloud-run environment envName.
+func reportCloudRunServices(envName string, services []cloudrun.Service, resourceFilter *filters.ResourceFilterOptions) error {
+	if err := ensureEnvironment(envName, "cloud-run"); err != nil {
+		return err
+	}
+
+	reportURL, err := url.JoinPath(global.Host, "api/v2/environments", global.Org, envName, "report/cloud-run")
+	if err != nil {
+		return err
+	}
+
+	compiledFilter := resourceFilter.Compile()
+	filteredServices := make([]cloudrun.Service, 0, len(services))
+	for _, svc := range services {
+		included, err := compiledFilter.ShouldInclude(svc.Name)
+		if err != nil {
+			return err
+		}
+		if included {
+			filteredServices = append(filteredServices, svc)
+		}
+	}
+
+	payload := cloudrun.ToEnvRequest(filteredServices, nil)
+	reported, err := reportSnapshot(&requests.RequestParams{
+		Method:  http.MethodPut,
+		URL:     reportURL,
+		Payload: payload,
+		DryRun:  global.DryRun,
+		Token:   global.ApiToken,
+	}, envName)
+	if err == nil && reported && !global.DryRun {
+		logger.Info("[%d] artifacts were reported to environment %s", len(payload.Artifacts), envName)
+	}
+	return err
+}
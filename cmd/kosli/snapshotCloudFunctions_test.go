@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/cloudrun"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type stubCloudFunctionsLister struct {
+	functions []cloudrun.Service
+	err       error
+}
+
+func (s stubCloudFunctionsLister) ListFunctions(_ context.Context, _, _ string) ([]cloudrun.Service, error) {
+	return s.functions, s.err
+}
+
+func TestSnapshotCloudFunctions(t *testing.T) {
+	server := newFakeReportServer(t)
+
+	origNewCloudFunctionsClient := newCloudFunctionsClient
+	t.Cleanup(func() { newCloudFunctionsClient = origNewCloudFunctionsClient })
+	newCloudFunctionsClient = func(_ context.Context) (cloudFunctionsLister, error) {
+		return stubCloudFunctionsLister{functions: stubServices()}, nil
+	}
+
+	args := server.args()
+	tests := []cmdTestCase{
+		{
+			wantError: true,
+			name:      "snapshot cloudfunctions fails if --region is missing",
+			cmd:       fmt.Sprintf(`snapshot cloudfunctions functions-env --project p %s`, args),
+			golden:    "Error: required flag(s) \"region\" not set\n",
+		},
+		{
+			wantError: true,
+			name:      "snapshot cloudfunctions fails if --include and --exclude-regex are set",
+			cmd:       fmt.Sprintf(`snapshot cloudfunctions functions-env --project p --region r --include alpha --exclude-regex "^b" %s`, args),
+			golden:    "Error: only one of --include, --exclude-regex is allowed\n",
+		},
+		{
+			name:        "snapshot cloudfunctions dry-runs the functions as cloud-run services",
+			cmd:         fmt.Sprintf(`snapshot cloudfunctions functions-env --project p --region r --dry-run %s`, args),
+			goldenRegex: `(?s)THIS IS A DRY-RUN.*report/cloud-run.*"type": "cloud-run".*"kind": "service".*"serviceName": "alpha".*"revisionName": "alpha-rev1".*"serviceName": "beta"`,
+		},
+		{
+			name:        "snapshot cloudfunctions reports the included functions",
+			cmd:         fmt.Sprintf(`snapshot cloud-functions functions-env --project p --region r --include-regex "^al" %s`, args),
+			goldenRegex: `\[1\] artifacts were reported to environment functions-env`,
+		},
+	}
+	runTestCmd(t, tests)
+
+	report := server.reports["PUT /api/v2/environments/test-org/functions-env/report/cloud-run"]
+	require.Contains(t, report, `"serviceName":"alpha"`)
+	require.NotContains(t, report, `"serviceName":"beta"`)
+
+	newCloudFunctionsClient = func(_ context.Context) (cloudFunctionsLister, error) {
+		return stubCloudFunctionsLister{err: status.Error(codes.PermissionDenied, "denied")}, nil
+	}
+	_, combined, _, _, err := executeCommandC(fmt.Sprintf(`snapshot cloudfunctions functions-env --project p --region r %s`, args))
+	require.Error(t, err)
+	require.Contains(t, combined, "roles/cloudfunctions.viewer")
+}
@@ -113,17 +113,7 @@ func newSnapshotCloudRunCmd(out io.Writer) *cobra.Command {
 			if err := RequireGlobalFlags(global, []string{"Org", "ApiToken"}); err != nil {
 				return ErrorBeforePrintingUsage(cmd, err.Error())
 			}
-			for _, pair := range [][]string{
-				{"include", "exclude"},
-				{"include", "exclude-regex"},
-				{"include-regex", "exclude"},
-				{"include-regex", "exclude-regex"},
-			} {
-				if err := MuXRequiredFlags(cmd, pair, false); err != nil {
-					return err
-				}
-			}
-			return nil
+			return muxCloudRunFilterFlags(cmd)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return o.run(args)
@@ -208,8 +198,8 @@ func (o *snapshotCloudRunOptions) run(args []string) error {
 		DryRun:  global.DryRun,
 		Token:   global.ApiToken,
 	}
-	_, err = kosliClient.Do(reqParams)
-	if err == nil && !global.DryRun {
+	reported, err := reportSnapshot(reqParams, envName)
+	if err == nil && reported && !global.DryRun {
 		logger.Info("[%d] artifacts were reported to environment %s", len(payload.Artifacts), envName)
 	}
 	return err
@@ -92,8 +92,8 @@ func (o *snapshotDockerOptions) run(args []string) error {
 		DryRun:  global.DryRun,
 		Token:   global.ApiToken,
 	}
-	_, err = kosliClient.Do(reqParams)
-	if err == nil && !global.DryRun {
+	reported, err := reportSnapshot(reqParams, envName)
+	if err == nil && reported && !global.DryRun {
 		logger.Info("[%d] containers were reported to environment %s", len(payload.Artifacts), envName)
 	}
 	return err
@@ -21,7 +21,7 @@ Note that service filtering is applied to all clusters being snapshot.
 
 All filtering options are case-sensitive.
 
-The reported data includes cluster and service names, container image digests and creation timestamps.` + awsAuthDesc
+The reported data includes cluster and service names, container image digests and creation timestamps.` + awsAuthDesc + awsAccountsDesc
 
 const snapshotECSExample = `
 # authentication to AWS using flags
@@ -93,6 +93,12 @@ kosli snapshot ecs my-env --exclude-services backend-app ...
 
 # exclude Services matching a list of names in all clusters in the AWS account
 kosli snapshot ecs my-env --exclude-services backend-app,frontend-app ...
+
+# report the clusters of several AWS accounts and regions, assuming a role in each account
+kosli snapshot ecs \
+	--aws-accounts-file aws-accounts.yml \
+	--api-token yourAPIToken \
+	--org yourOrgName
 `
 
 type snapshotECSOptions struct {
@@ -100,6 +106,7 @@ type snapshotECSOptions struct {
 	serviceFilter  *filters.ResourceFilterOptions
 	serviceName    string
 	awsStaticCreds *aws.AWSStaticCreds
+	accounts       awsAccountsOptions
 }
 
 func newSnapshotECSCmd(out io.Writer) *cobra.Command {
@@ -108,11 +115,11 @@ func newSnapshotECSCmd(out io.Writer) *cobra.Command {
 	o.clustersFilter = new(filters.ResourceFilterOptions)
 	o.serviceFilter = new(filters.ResourceFilterOptions)
 	cmd := &cobra.Command{
-		Use:     "ecs ENVIRONMENT-NAME",
+		Use:     "ecs [ENVIRONMENT-NAME]",
 		Short:   snapshotECSShortDesc,
 		Long:    snapshotECSLongDesc,
 		Example: snapshotECSExample,
-		Args:    cobra.ExactArgs(1),
+		Args:    awsAccountsArgs(&o.accounts),
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			err := RequireGlobalFlags(global, []string{"Org", "ApiToken"})
 			if err != nil {
@@ -179,6 +186,7 @@ func newSnapshotECSCmd(out io.Writer) *cobra.Command {
 	cmd.Flags().StringSliceVarP(&o.clustersFilter.IncludeNames, "cluster", "C", []string{}, ecsClusterFlag)
 	cmd.Flags().StringVarP(&o.serviceName, "service-name", "s", "", ecsServiceFlag)
 	addAWSAuthFlags(cmd, o.awsStaticCreds)
+	addAWSAccountsFlag(cmd, &o.accounts)
 	addDryRunFlag(cmd)
 
 	err := DeprecateFlags(cmd, map[string]string{
@@ -193,6 +201,10 @@ func newSnapshotECSCmd(out io.Writer) *cobra.Command {
 }
 
 func (o *snapshotECSOptions) run(args []string) error {
+	if o.accounts.accountsFile != "" {
+		return reportAWSAccountsSnapshots(&o.accounts, o.awsStaticCreds, "ECS", o.accountSnapshot,
+			func(tasks []*aws.EcsTaskData) any { return &aws.EcsEnvRequest{Artifacts: tasks} })
+	}
 	envName := args[0]
 
 	if err := ensureEnvironment(envName, "ECS"); err != nil {
@@ -220,9 +232,27 @@ func (o *snapshotECSOptions) run(args []string) error {
 		DryRun:  global.DryRun,
 		Token:   global.ApiToken,
 	}
-	_, err = kosliClient.Do(reqParams)
-	if err == nil && !global.DryRun {
+	reported, err := reportSnapshot(reqParams, envName)
+	if err == nil && reported && !global.DryRun {
 		logger.Info("[%d] containers were reported to environment %s", len(payload.Artifacts), envName)
 	}
 	return err
 }
+
+// accountSnapshot returns the ECS tasks of an AWS account and region by
+// environment.
+func (o *snapshotECSOptions) accountSnapshot(target *aws.AccountTarget) (map[string][]*aws.EcsTaskData, error) {
+	tasksData, err := target.Creds.GetEcsTasksData(o.clustersFilter, o.serviceFilter, logger)
+	if err != nil {
+		return nil, err
+	}
+	if !target.PerCluster() {
+		return map[string][]*aws.EcsTaskData{target.Environment(""): tasksData}, nil
+	}
+	byCluster := map[string][]*aws.EcsTaskData{}
+	for _, task := range tasksData {
+		envName := target.Environment(task.Cluster)
+		byCluster[envName] = append(byCluster[envName], task)
+	}
+	return byCluster, nil
+}
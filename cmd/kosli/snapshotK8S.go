@@ -279,8 +279,8 @@ func (o *snapshotK8SOptions) reportEnvironment(clientset *kube.K8SConnection, en
 		DryRun:  global.DryRun,
 		Token:   global.ApiToken,
 	}
-	_, err = kosliClient.Do(reqParams)
-	if err == nil && !global.DryRun {
+	reported, err := reportSnapshot(reqParams, envName)
+	if err == nil && reported && !global.DryRun {
 		logger.Info("[%d] pods were reported to environment %s", len(podsData), envName)
 	}
 	return err
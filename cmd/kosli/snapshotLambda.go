@@ -14,7 +14,7 @@ import (
 const snapshotLambdaShortDesc = `Report a snapshot of artifacts deployed as one or more AWS Lambda functions and their digests to Kosli.`
 
 const snapshotLambdaLongDesc = snapshotLambdaShortDesc + `  
-Skip ^--function-names^ and ^--function-names-regex^ to report all functions in a given AWS account. Or use ^--exclude^ and/or ^--exclude-regex^ to report all functions excluding some.` + awsAuthDesc
+Skip ^--function-names^ and ^--function-names-regex^ to report all functions in a given AWS account. Or use ^--exclude^ and/or ^--exclude-regex^ to report all functions excluding some.` + awsAuthDesc + awsAccountsDesc
 
 const snapshotLambdaExample = `
 # report all Lambda functions running in an AWS account (AWS auth provided in env variables):
@@ -75,12 +75,19 @@ kosli snapshot lambda yourEnvironmentName \
 	--aws-region yourAWSRegion \
 	--api-token yourAPIToken \
 	--org yourOrgName
+
+# report the Lambda functions of several AWS accounts and regions, assuming a role in each account
+kosli snapshot lambda \
+	--aws-accounts-file aws-accounts.yml \
+	--api-token yourAPIToken \
+	--org yourOrgName
 `
 
 type snapshotLambdaOptions struct {
 	functionVersion string
 	filter          *filters.ResourceFilterOptions
 	awsStaticCreds  *aws.AWSStaticCreds
+	accounts        awsAccountsOptions
 }
 
 func newSnapshotLambdaCmd(out io.Writer) *cobra.Command {
@@ -88,11 +95,11 @@ func newSnapshotLambdaCmd(out io.Writer) *cobra.Command {
 	o.awsStaticCreds = new(aws.AWSStaticCreds)
 	o.filter = new(filters.ResourceFilterOptions)
 	cmd := &cobra.Command{
-		Use:     "lambda ENVIRONMENT-NAME",
+		Use:     "lambda [ENVIRONMENT-NAME]",
 		Short:   snapshotLambdaShortDesc,
 		Long:    snapshotLambdaLongDesc,
 		Example: snapshotLambdaExample,
-		Args:    cobra.ExactArgs(1),
+		Args:    awsAccountsArgs(&o.accounts),
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			err := RequireGlobalFlags(global, []string{"Org", "ApiToken"})
 			if err != nil {
@@ -133,6 +140,7 @@ func newSnapshotLambdaCmd(out io.Writer) *cobra.Command {
 	cmd.Flags().StringSliceVar(&o.filter.ExcludeNames, "exclude", []string{}, excludeFlag)
 	cmd.Flags().StringSliceVar(&o.filter.ExcludeNamesRegex, "exclude-regex", []string{}, excludeRegexFlag)
 	addAWSAuthFlags(cmd, o.awsStaticCreds)
+	addAWSAccountsFlag(cmd, &o.accounts)
 	addDryRunFlag(cmd)
 
 	err := DeprecateFlags(cmd, map[string]string{
@@ -147,6 +155,17 @@ func newSnapshotLambdaCmd(out io.Writer) *cobra.Command {
 }
 
 func (o *snapshotLambdaOptions) run(args []string) error {
+	if o.accounts.accountsFile != "" {
+		return reportAWSAccountsSnapshots(&o.accounts, o.awsStaticCreds, "lambda",
+			func(target *aws.AccountTarget) (map[string][]*aws.LambdaData, error) {
+				lambdaData, err := target.Creds.GetLambdaPackageData(o.filter)
+				if err != nil {
+					return nil, err
+				}
+				return map[string][]*aws.LambdaData{target.Environment(""): lambdaData}, nil
+			},
+			func(lambdaData []*aws.LambdaData) any { return &aws.LambdaEnvRequest{Artifacts: lambdaData} })
+	}
 	envName := args[0]
 
 	if err := ensureEnvironment(envName, "lambda"); err != nil {
@@ -173,8 +192,8 @@ func (o *snapshotLambdaOptions) run(args []string) error {
 		DryRun:  global.DryRun,
 		Token:   global.ApiToken,
 	}
-	_, err = kosliClient.Do(reqParams)
-	if err == nil && !global.DryRun {
+	reported, err := reportSnapshot(reqParams, envName)
+	if err == nil && reported && !global.DryRun {
 		logger.Info("%d lambda functions were reported to environment %s", len(lambdaData), envName)
 	}
 	return err
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/kosli-dev/cli/internal/digest"
+	"github.com/kosli-dev/cli/internal/nomad"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/spf13/cobra"
+)
+
+const snapshotNomadShortDesc = `Report a snapshot of running allocations in a HashiCorp Nomad cluster to Kosli.  `
+
+const snapshotNomadLongDesc = snapshotNomadShortDesc + `
+The reported data includes allocation names, the container image digests of their running tasks
+and creation timestamps, in the same form as the pods of ^kosli snapshot k8s^. Each allocation
+is reported with its job as owner, and with its job, task group, node and task names as annotations.
+Tasks which do not run an image (e.g. ^exec^ or ^raw_exec^ tasks) are not reported.
+
+The digests of digest-pinned images are taken from their names. The digests of tag-pinned images
+are read from their registry, like for ^--artifact-type=oci^ (see ^kosli fingerprint^).
+
+The Nomad HTTP API is configured with flags, or with the standard Nomad environment variables
+(^NOMAD_ADDR^, ^NOMAD_TOKEN^, ^NOMAD_NAMESPACE^, ^NOMAD_REGION^, ^NOMAD_CACERT^, ^NOMAD_CLIENT_CERT^,
+^NOMAD_CLIENT_KEY^ and ^NOMAD_SKIP_VERIFY^), like the ^nomad^ CLI.
+The Nomad token needs the ^read-job^ capability in the reported namespaces.`
+
+const snapshotNomadExample = `
+# report the running allocations of the default namespace of the Nomad cluster at $NOMAD_ADDR:
+kosli snapshot nomad yourEnvironmentName \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report the running allocations of all namespaces:
+kosli snapshot nomad yourEnvironmentName \
+	--nomad-address https://nomad.example.com:4646 \
+	--nomad-token yourNomadToken \
+	--nomad-namespace '*' \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+type snapshotNomadOptions struct {
+	config           nomad.Config
+	registryUsername string
+	registryPassword string
+}
+
+func newSnapshotNomadCmd(out io.Writer) *cobra.Command {
+	o := new(snapshotNomadOptions)
+	cmd := &cobra.Command{
+		Use:     "nomad ENVIRONMENT-NAME",
+		Short:   snapshotNomadShortDesc,
+		Long:    snapshotNomadLongDesc,
+		Example: snapshotNomadExample,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			err := RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.config.Address, "nomad-address", "", nomadAddressFlag)
+	cmd.Flags().StringVar(&o.config.Token, "nomad-token", "", nomadTokenFlag)
+	cmd.Flags().StringVar(&o.config.Namespace, "nomad-namespace", "", nomadNamespaceFlag)
+	cmd.Flags().StringVar(&o.config.Region, "nomad-region", "", nomadRegionFlag)
+	cmd.Flags().StringVar(&o.registryUsername, "registry-username", "", registryUsernameFlag)
+	cmd.Flags().StringVar(&o.registryPassword, "registry-password", "", registryPasswordFlag)
+	addDryRunFlag(cmd)
+	return cmd
+}
+
+func (o *snapshotNomadOptions) run(args []string) error {
+	envName := args[0]
+
+	client, err := nomad.NewClient(o.config, func(imageName string) (string, error) {
+		return digest.OciSha256(imageName, o.registryUsername, o.registryPassword)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := ensureEnvironment(envName, "K8S"); err != nil {
+		return err
+	}
+
+	allocationsData, err := client.GetAllocationsData(logger)
+	if err != nil {
+		return err
+	}
+
+	url, err := url.JoinPath(global.Host, "api/v2/environments", global.Org, envName, "report/K8S")
+	if err != nil {
+		return err
+	}
+
+	reqParams := &requests.RequestParams{
+		Method:  http.MethodPut,
+		URL:     url,
+		Payload: &nomad.NomadEnvRequest{Artifacts: allocationsData},
+		DryRun:  global.DryRun,
+		Token:   global.ApiToken,
+	}
+	reported, err := reportSnapshot(reqParams, envName)
+	if err == nil && reported && !global.DryRun {
+		logger.Info("[%d] allocations were reported to environment %s", len(allocationsData), envName)
+	}
+	return err
+}
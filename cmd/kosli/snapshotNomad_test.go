@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newStubNomadAPI returns a stub of the Nomad HTTP API with a running
+// allocation of a docker task, pinned by digest so that no registry is read.
+func newStubNomadAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Nomad-Token") != "nomad-token" || r.URL.Query().Get("namespace") != "prod" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = fmt.Fprint(w, "Permission denied")
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/allocations":
+			_, _ = fmt.Fprint(w, `[{"ID": "a1", "Name": "web.frontend[0]", "Namespace": "prod", "JobID": "web", "TaskGroup": "frontend",
+				"NodeName": "node-1", "ClientStatus": "running", "CreateTime": 1777636800000000000,
+				"TaskStates": {"app": {"State": "running"}}}]`)
+		case "/v1/allocation/a1":
+			_, _ = fmt.Fprint(w, `{"Job": {"TaskGroups": [{"Name": "frontend", "Tasks": [{"Name": "app", "Driver": "docker",
+				"Config": {"image": "registry.example.com/app@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}}]}]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSnapshotNomad(t *testing.T) {
+	server := newFakeReportServer(t)
+	nomadAPI := newStubNomadAPI(t)
+
+	args := server.args()
+	tests := []cmdTestCase{
+		{
+			wantError: true,
+			name:      "snapshot nomad fails if no args are set",
+			cmd:       fmt.Sprintf(`snapshot nomad --nomad-address %s %s`, nomadAPI.URL, args),
+			golden:    "Error: accepts 1 arg(s), received 0\n",
+		},
+		{
+			name: "snapshot nomad dry-runs the running allocations",
+			cmd: fmt.Sprintf(`snapshot nomad nomad-env --nomad-address %s --nomad-token nomad-token --nomad-namespace prod --dry-run %s`,
+				nomadAPI.URL, args),
+			goldenRegex: `(?s)THIS IS A DRY-RUN.*report/K8S.*"podName": "web.frontend\[0\]".*"namespace": "prod".*"nomad/job": "web"`,
+		},
+		{
+			name: "snapshot nomad reports the running allocations",
+			cmd: fmt.Sprintf(`snapshot nomad nomad-env --nomad-address %s --nomad-token nomad-token --nomad-namespace prod %s`,
+				nomadAPI.URL, args),
+			golden: "[1] allocations were reported to environment nomad-env\n",
+		},
+		{
+			wantError: true,
+			name:      "snapshot nomad fails when the Nomad token is denied",
+			cmd:       fmt.Sprintf(`snapshot nomad nomad-env --nomad-address %s --nomad-token wrong --nomad-namespace prod %s`, nomadAPI.URL, args),
+			golden:    "Error: failed to list Nomad allocations: 403 Forbidden: Permission denied\n",
+		},
+	}
+	runTestCmd(t, tests)
+
+	report := server.reports["PUT /api/v2/environments/test-org/nomad-env/report/K8S"]
+	require.Contains(t, report, `"nomad/task/app":"registry.example.com/app@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"`)
+	require.Contains(t, report, `"owners":[{"apiVersion":"nomad/v1","kind":"Job","name":"web","uid":""}]`)
+}
+
+func TestSnapshotNomadUsesNomadEnvVars(t *testing.T) {
+	server := newFakeReportServer(t)
+	nomadAPI := newStubNomadAPI(t)
+	t.Setenv("NOMAD_ADDR", nomadAPI.URL)
+	t.Setenv("NOMAD_TOKEN", "nomad-token")
+	t.Setenv("NOMAD_NAMESPACE", "prod")
+
+	runTestCmd(t, []cmdTestCase{{
+		name:   "snapshot nomad reads the Nomad address, token and namespace from NOMAD_* env vars",
+		cmd:    fmt.Sprintf(`snapshot nomad nomad-env %s`, server.args()),
+		golden: "[1] allocations were reported to environment nomad-env\n",
+	}})
+}
@@ -95,7 +95,7 @@ func (o *snapshotPathsOptions) run(args []string) error {
 	}
 
 	// load path spec from file
-	ps, err := processPathSpecFile(o.pathSpecFile)
+	ps, err := loadSpecFile[server.PathsSpec](o.pathSpecFile, "path spec file", nil)
 	if err != nil {
 		return err
 	}
@@ -123,34 +123,40 @@ func (o *snapshotPathsOptions) run(args []string) error {
 	return nil
 }
 
-func processPathSpecFile(pathsSpecFile string) (*server.PathsSpec, error) {
-	var ps *server.PathsSpec
+// loadSpecFile reads the YAML, JSON or TOML file at path into a T, which it
+// validates. what names the file in errors, such as "path spec file", and
+// defaults are the values of the keys the file does not set.
+func loadSpecFile[T any](path, what string, defaults map[string]any) (*T, error) {
+	var spec *T
 	v := viper.New()
-	dir, file := filepath.Split(pathsSpecFile)
+	dir, file := filepath.Split(path)
 	file = strings.TrimSuffix(file, filepath.Ext(file))
 
-	// Set the base name of the pathspec file, without the file extension.
+	// Set the base name of the spec file, without the file extension.
 	v.SetConfigName(file)
 
 	// Set the dir path where viper should look for the
-	// pathspec file. By default, we are looking in the current working directory.
+	// spec file. By default, we are looking in the current working directory.
 	if dir == "" {
 		dir = "."
 	}
 	v.AddConfigPath(dir)
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
 
 	if err := v.ReadInConfig(); err != nil {
-		return ps, fmt.Errorf("failed to parse path spec file [%s] : %v", pathsSpecFile, err)
+		return spec, fmt.Errorf("failed to parse %s [%s] : %v", what, path, err)
 	}
 
-	if err := v.UnmarshalExact(&ps); err != nil {
-		return ps, fmt.Errorf("failed to unmarshal path spec file [%s] : %v", pathsSpecFile, err)
+	if err := v.UnmarshalExact(&spec); err != nil {
+		return spec, fmt.Errorf("failed to unmarshal %s [%s] : %v", what, path, err)
 	}
 
 	validate := validator.New(validator.WithRequiredStructEnabled())
-	if err := validate.Struct(ps); err != nil {
-		return ps, fmt.Errorf("path spec file [%s] is invalid: %v", pathsSpecFile, err)
+	if err := validate.Struct(spec); err != nil {
+		return spec, fmt.Errorf("%s [%s] is invalid: %v", what, path, err)
 	}
 
-	return ps, nil
+	return spec, nil
 }
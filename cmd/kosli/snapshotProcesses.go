@@ -0,0 +1,98 @@
+package main
+
+import (
+	"io"
+
+	"github.com/kosli-dev/cli/internal/server"
+	"github.com/spf13/cobra"
+)
+
+const snapshotProcessesShortDesc = `Report a snapshot of the artifacts run by systemd services or processes on a server to Kosli.  `
+
+const snapshotProcessesLongDesc = snapshotProcessesShortDesc + `
+Unlike ^kosli snapshot paths^, which fingerprints filesystem paths whether or not anything runs from them,
+this reports what is actually executing on the server.
+
+With ^--systemd-units^, the running systemd services matching the given unit name patterns are reported,
+each named after its unit, and fingerprinted from the executable of its ^ExecStart^.
+With ^--process-patterns^, the running processes whose command line matches any of the given regular
+expressions are reported, each named after, and fingerprinted from, the path of its executable.
+Processes running the same executable are reported once. Processes whose executable was deleted or
+replaced since they started are skipped, since what they run is no longer on disk.
+
+With ^--working-directory^, the working directory of each service or process is fingerprinted as a
+directory artifact instead of its executable, excluding the paths given with ^--exclude^.
+
+Finding processes requires the proc filesystem of Linux, and reading the executables of processes run
+by other users requires root.`
+
+const snapshotProcessesExample = `
+# report the executables of the running systemd services whose unit names start with myapp-:
+kosli snapshot processes yourEnvironmentName \
+	--systemd-units 'myapp-*.service' \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report the working directories of the running systemd services myapp-api and myapp-worker:
+kosli snapshot processes yourEnvironmentName \
+	--systemd-units myapp-api.service,myapp-worker.service \
+	--working-directory \
+	--exclude logs,tmp \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report the executables of the running processes whose command lines match patterns:
+kosli snapshot processes yourEnvironmentName \
+	--process-patterns '^/opt/myapp/bin/','gunicorn .*myapp' \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+type snapshotProcessesOptions struct {
+	runningSpec server.RunningSpec
+}
+
+func newSnapshotProcessesCmd(out io.Writer) *cobra.Command {
+	o := new(snapshotProcessesOptions)
+	cmd := &cobra.Command{
+		Use:     "processes ENVIRONMENT-NAME",
+		Short:   snapshotProcessesShortDesc,
+		Long:    snapshotProcessesLongDesc,
+		Args:    cobra.ExactArgs(1),
+		Example: snapshotProcessesExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			err := RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&o.runningSpec.SystemdUnits, "systemd-units", []string{}, systemdUnitsFlag)
+	cmd.Flags().StringSliceVar(&o.runningSpec.ProcessPatterns, "process-patterns", []string{}, processPatternsFlag)
+	cmd.Flags().BoolVar(&o.runningSpec.WorkingDirectory, "working-directory", false, workingDirectoryFlag)
+	cmd.Flags().StringSliceVarP(&o.runningSpec.Exclude, "exclude", "x", []string{}, workingDirectoryExcludeFlag)
+	cmd.MarkFlagsOneRequired("systemd-units", "process-patterns")
+	addDryRunFlag(cmd)
+
+	return cmd
+}
+
+func (o *snapshotProcessesOptions) run(args []string) error {
+	envName := args[0]
+
+	ps, err := server.CreateRunningPathsSpec(&o.runningSpec, logger)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureEnvironment(envName, "server"); err != nil {
+		return err
+	}
+
+	return reportArtifacts(ps, envName)
+}
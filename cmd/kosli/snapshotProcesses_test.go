@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotProcesses(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("finding processes requires the proc filesystem of Linux")
+	}
+	sleepPath, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not installed")
+	}
+	sleep := exec.Command(sleepPath, "3600.4242")
+	require.NoError(t, sleep.Start())
+	t.Cleanup(func() {
+		_ = sleep.Process.Kill()
+		_ = sleep.Wait()
+	})
+
+	server := newFakeReportServer(t)
+	args := server.args()
+	tests := []cmdTestCase{
+		{
+			wantError: true,
+			name:      "snapshot processes fails if no args are set",
+			cmd:       fmt.Sprintf(`snapshot processes --process-patterns sleep %s`, args),
+			golden:    "Error: accepts 1 arg(s), received 0\n",
+		},
+		{
+			wantError: true,
+			name:      "snapshot processes fails if neither --systemd-units nor --process-patterns is set",
+			cmd:       fmt.Sprintf(`snapshot processes processes-env %s`, args),
+			golden:    "Error: at least one of the flags in the group [systemd-units process-patterns] is required\n",
+		},
+		{
+			wantError: true,
+			name:      "snapshot processes fails if no process matches",
+			cmd:       fmt.Sprintf(`snapshot processes processes-env --process-patterns ^no-such-process-4242$ %s`, args),
+			golden:    "Error: no running systemd services or processes found for [^no-such-process-4242$]\n",
+		},
+		{
+			name:   "snapshot processes reports the executable of a running process",
+			cmd:    fmt.Sprintf(`snapshot processes processes-env --process-patterns sleep.3600.4242$ %s`, args),
+			golden: "[1] artifacts were reported to environment processes-env\n",
+		},
+	}
+	runTestCmd(t, tests)
+
+	exe, err := filepath.EvalSymlinks(sleepPath)
+	require.NoError(t, err)
+	require.Contains(t, server.reports["PUT /api/v2/environments/test-org/processes-env/report/server"], fmt.Sprintf(`"%s":`, exe))
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/daemon"
+	"github.com/spf13/cobra"
+)
+
+const snapshotRunDaemonShortDesc = `Periodically report the snapshots of multiple environments to Kosli.  `
+
+const snapshotRunDaemonLongDesc = snapshotRunDaemonShortDesc + `
+Runs until it is interrupted, reporting the snapshot of each environment of an environments file at its own interval,
+concurrently. Environments can be of any of the types of the other ^kosli snapshot^ subcommands, and each is
+snapshotted exactly as that subcommand does, with the flags given in the environments file.
+Flags which apply to every snapshot subcommand, like ^--skip-unchanged^ and ^--auto-environment^, and the global
+flags, like ^--org^ and ^--api-token^, are given to ^kosli snapshot run-daemon^ itself and apply to every environment.
+
+The environments file can be in YAML, JSON or TOML formats. Each environment has:
+- ^type^: the snapshot subcommand to run, e.g. ^k8s^, ^ecs^, ^lambda^, ^s3^, ^docker^, ^paths^, ^cloud-run^ or ^azure^.
+- ^environment^: the name of the Kosli environment to report to. It can be omitted for subcommands which name
+  their environments in their flags, like ^--aws-accounts-file^.
+- ^interval^: how often to report the snapshot, e.g. ^1m^ or ^1h30m^.
+- ^flags^: the flags of the subcommand, without their leading ^--^. A list is given as the flag repeated for each value.
+  ^dry-run^ and ^watch^ cannot be set for an environment.
+
+Flags which the environments file does not set are read from ^KOSLI_^ environment variables and the config file,
+like for the subcommand itself, so that secrets like ^--azure-client-secret^ can be given as ^KOSLI_AZURE_CLIENT_SECRET^
+rather than in the environments file.
+
+The environments file is checked, and the flags of each environment parsed, before anything is reported.
+
+Each run of an environment is delayed by up to ^jitter^ (a fraction of its interval, 0.1 by default), so that
+environments with the same interval are not all reported at once. When reporting an environment fails, the delay
+before its next run doubles with each consecutive failure, up to ^max_backoff^ (1h by default).
+
+Logs are written as JSON, or as ^key=value^ text with ^--log-format text^, one record per line.
+The statuses of the environments are served as JSON at ^/healthz^ on ^--health-address^, which responds with
+503 Service Unavailable while the last run of any environment failed. Set ^--health-address^ to an empty
+string to not serve it.
+
+This is an example YAML environments file:
+` +
+	"```yaml\n" +
+	`version: 1
+jitter: 0.1
+max_backoff: 30m
+environments:
+  - type: k8s
+    environment: prod-k8s
+    interval: 1m
+    flags:
+      namespaces: [payments, checkout]
+  - type: ecs
+    environment: prod-ecs
+    interval: 5m
+    flags:
+      clusters: [prod]
+  - type: paths
+    environment: prod-server
+    interval: 10m
+    flags:
+      paths-file: /etc/kosli/paths.yml` +
+	"\n```"
+
+const snapshotRunDaemonExample = `
+# report the snapshots of the environments of an environments file until interrupted:
+kosli snapshot run-daemon \
+	--environments-file /etc/kosli/environments.yml \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report only the environments which changed, and the unchanged ones again every 6 hours:
+kosli snapshot run-daemon \
+	--environments-file /etc/kosli/environments.yml \
+	--skip-unchanged \
+	--heartbeat-interval 6h \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+// snapshotDaemonConfig represents the environments file of run-daemon
+type snapshotDaemonConfig struct {
+	Version      int                         `mapstructure:"version" validate:"required,oneof=1"`
+	Jitter       float64                     `mapstructure:"jitter" validate:"gte=0,lt=1"`
+	MaxBackoff   time.Duration               `mapstructure:"max_backoff" validate:"gte=0"`
+	Environments []snapshotDaemonEnvironment `mapstructure:"environments" validate:"required,min=1,dive"`
+}
+
+// snapshotDaemonEnvironment represents an environment of the environments file
+// of run-daemon
+type snapshotDaemonEnvironment struct {
+	Type        string         `mapstructure:"type" validate:"required"`
+	Environment string         `mapstructure:"environment"`
+	Interval    time.Duration  `mapstructure:"interval" validate:"required,gt=0"`
+	Flags       map[string]any `mapstructure:"flags"`
+}
+
+// snapshotDaemonContext returns the context run-daemon runs in until it is
+// done. Tests override it to stop the daemon.
+var snapshotDaemonContext = func() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// kosliLogLevels are the prefixes of the lines written by the kosli logger
+// which mark their levels.
+var kosliLogLevels = map[string]slog.Level{
+	"[warning] ": slog.LevelWarn,
+	"[debug] ":   slog.LevelDebug,
+	"Error: ":    slog.LevelError,
+}
+
+// snapshotDaemonFlagsNotPerEnvironment are the flags of the snapshot
+// subcommands which the environments file cannot set, with why.
+var snapshotDaemonFlagsNotPerEnvironment = map[string]string{
+	"dry-run": "give --dry-run to run-daemon itself, for every environment",
+	"watch":   "run-daemon reports the environment at its interval instead",
+}
+
+type snapshotRunDaemonOptions struct {
+	environmentsFile string
+	healthAddress    string
+	logFormat        string
+}
+
+func newSnapshotRunDaemonCmd(out io.Writer) *cobra.Command {
+	o := new(snapshotRunDaemonOptions)
+	cmd := &cobra.Command{
+		Use:     "run-daemon",
+		Short:   snapshotRunDaemonShortDesc,
+		Long:    snapshotRunDaemonLongDesc,
+		Example: snapshotRunDaemonExample,
+		Args:    cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			err := RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+			if o.logFormat != "json" && o.logFormat != "text" {
+				return fmt.Errorf("invalid --log-format %s. Valid formats are: [json, text]", o.logFormat)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(out)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.environmentsFile, "environments-file", "", daemonEnvironmentsFileFlag)
+	cmd.Flags().StringVar(&o.healthAddress, "health-address", "127.0.0.1:8090", daemonHealthAddressFlag)
+	cmd.Flags().StringVar(&o.logFormat, "log-format", "json", daemonLogFormatFlag)
+	addDryRunFlag(cmd)
+
+	if err := RequireFlags(cmd, []string{"environments-file"}); err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+func (o *snapshotRunDaemonOptions) run(out io.Writer) error {
+	config, err := loadSpecFile[snapshotDaemonConfig](o.environmentsFile, "environments file", map[string]any{
+		"jitter":      0.1,
+		"max_backoff": "1h",
+	})
+	if err != nil {
+		return err
+	}
+
+	level := slog.LevelInfo
+	if global.Debug {
+		level = slog.LevelDebug
+	}
+	var handler slog.Handler = slog.NewJSONHandler(out, &slog.HandlerOptions{Level: level})
+	if o.logFormat == "text" {
+		handler = slog.NewTextHandler(out, &slog.HandlerOptions{Level: level})
+	}
+	daemonLogger := slog.New(handler)
+
+	// what the snapshot subcommands log is logged as records too
+	infoOut, errOut := logger.Out, logger.ErrOut
+	logWriter := &daemon.LogWriter{Logger: daemonLogger, Level: slog.LevelInfo, Prefixes: kosliLogLevels}
+	logger.SetInfoOut(logWriter)
+	logger.SetErrOut(logWriter)
+	defer func() {
+		logger.SetInfoOut(infoOut)
+		logger.SetErrOut(errOut)
+	}()
+
+	jobs, err := newSnapshotDaemonJobs(logWriter, config, o.environmentsFile)
+	if err != nil {
+		return err
+	}
+
+	scheduler := &daemon.Scheduler{Jitter: config.Jitter, MaxBackoff: config.MaxBackoff, Logger: daemonLogger}
+	ctx, cancel := snapshotDaemonContext()
+	defer cancel()
+
+	if o.healthAddress != "" {
+		listener, err := net.Listen("tcp", o.healthAddress)
+		if err != nil {
+			return fmt.Errorf("failed to serve the health endpoint: %v", err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("GET /healthz", scheduler)
+		server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				daemonLogger.Error("health endpoint failed", "error", err.Error())
+			}
+		}()
+		defer func() { _ = server.Close() }()
+		daemonLogger.Info("serving the health endpoint", "address", "http://"+listener.Addr().String()+"/healthz")
+	}
+
+	daemonLogger.Info("daemon started", "environments", len(jobs), "dry_run", global.DryRun)
+	scheduler.Run(ctx, jobs)
+	daemonLogger.Info("daemon stopped")
+	return nil
+}
+
+// newSnapshotDaemonJobs returns a job per environment of config, which runs its
+// snapshot subcommand with its flags. The subcommands are created and their
+// flags parsed once, so that a mistake in the environments file is found
+// before anything is reported.
+func newSnapshotDaemonJobs(out io.Writer, config *snapshotDaemonConfig, environmentsFile string) ([]daemon.Job, error) {
+	// creating the subcommands registers their --dry-run flag, which resets
+	// the one given to run-daemon
+	dryRun := global.DryRun
+	defer func() { global.DryRun = dryRun }()
+
+	jobs := make([]daemon.Job, 0, len(config.Environments))
+	for i, environment := range config.Environments {
+		job, err := newSnapshotDaemonJob(out, environment)
+		if err != nil {
+			return nil, fmt.Errorf("environments file [%s] is invalid: environment %d: %v", environmentsFile, i+1, err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func newSnapshotDaemonJob(out io.Writer, environment snapshotDaemonEnvironment) (daemon.Job, error) {
+	var cmd *cobra.Command
+	var types []string
+	for _, c := range newSnapshotEnvironmentCmds(out) {
+		if c.Name() == environment.Type || c.HasAlias(environment.Type) {
+			cmd = c
+		}
+		types = append(types, c.Name())
+	}
+	if cmd == nil {
+		slices.Sort(types)
+		return daemon.Job{}, fmt.Errorf("unknown type %s. Valid types are: %v", environment.Type, types)
+	}
+
+	for name, reason := range snapshotDaemonFlagsNotPerEnvironment {
+		if _, ok := environment.Flags[name]; ok {
+			return daemon.Job{}, fmt.Errorf("flag %s cannot be set for an environment: %s", name, reason)
+		}
+	}
+	flagArgs, err := snapshotDaemonFlagArgs(environment.Flags)
+	if err != nil {
+		return daemon.Job{}, err
+	}
+	var args []string
+	if environment.Environment != "" {
+		args = []string{environment.Environment}
+	}
+	if err := cmd.ParseFlags(flagArgs); err != nil {
+		return daemon.Job{}, err
+	}
+	// the flags which the environments file does not set are read from the
+	// KOSLI_ environment variables and the config file, like they are for the
+	// subcommand itself, so that secrets need not be in the environments file
+	v, err := newConfigViper()
+	if err != nil {
+		return daemon.Job{}, err
+	}
+	if err := bindFlags(cmd, v); err != nil {
+		return daemon.Job{}, err
+	}
+	if watch := cmd.Flags().Lookup("watch"); watch != nil && watch.Value.String() == "true" {
+		return daemon.Job{}, fmt.Errorf("flag watch cannot be set for an environment: %s", snapshotDaemonFlagsNotPerEnvironment["watch"])
+	}
+	if err := cmd.ValidateArgs(args); err != nil {
+		return daemon.Job{}, err
+	}
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return daemon.Job{}, err
+	}
+	if err := cmd.ValidateFlagGroups(); err != nil {
+		return daemon.Job{}, err
+	}
+	if cmd.PreRunE != nil {
+		if err := cmd.PreRunE(cmd, args); err != nil {
+			return daemon.Job{}, err
+		}
+	}
+	if cmd.Deprecated != "" {
+		logger.Warn("snapshot %s is deprecated, %s", cmd.Name(), cmd.Deprecated)
+	}
+
+	attrs := []any{"type", cmd.Name()}
+	name := cmd.Name()
+	if environment.Environment != "" {
+		attrs = append(attrs, "environment", environment.Environment)
+		name += " " + environment.Environment
+	}
+	return daemon.Job{
+		Name:     name,
+		Interval: environment.Interval,
+		Run:      func() error { return cmd.RunE(cmd, args) },
+		Attrs:    attrs,
+	}, nil
+}
+
+// snapshotDaemonFlagArgs returns the command line arguments which set flags,
+// repeating a flag for each of the values of a list.
+func snapshotDaemonFlagArgs(flags map[string]any) ([]string, error) {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var args []string
+	for _, name := range names {
+		switch value := flags[name].(type) {
+		case []any:
+			for _, element := range value {
+				args = append(args, fmt.Sprintf("--%s=%v", name, element))
+			}
+		case map[string]any, nil:
+			return nil, fmt.Errorf("flag %s must be a value or a list of values", name)
+		default:
+			args = append(args, fmt.Sprintf("--%s=%v", name, value))
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeEnvironmentsFile writes an environments file for run-daemon in a
+// temporary directory and returns its path.
+func writeEnvironmentsFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "environments.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+// freeAddress returns a local address which nothing listens at.
+func freeAddress(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return address
+}
+
+func TestSnapshotRunDaemon(t *testing.T) {
+	server := newFakeReportServer(t)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app"), []byte("app"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "worker"), []byte("worker"), 0o644))
+	environmentsFile := writeEnvironmentsFile(t, fmt.Sprintf(`version: 1
+jitter: 0
+environments:
+  - type: path
+    environment: daemon-app
+    interval: 20ms
+    flags:
+      path: %s
+      name: app
+  - type: path
+    environment: daemon-worker
+    interval: 30ms
+    flags:
+      path: %s
+      exclude: [logs, tmp]
+`, filepath.Join(dir, "app"), filepath.Join(dir, "worker")))
+	// a flag which the environments file does not set is read from its
+	// environment variable, and one which it sets is not
+	t.Setenv("KOSLI_NAME", "worker")
+	healthAddress := freeAddress(t)
+
+	// the daemon runs until both environments were reported twice, as seen
+	// by its health endpoint
+	var health map[string]any
+	origContext := snapshotDaemonContext
+	t.Cleanup(func() { snapshotDaemonContext = origContext })
+	snapshotDaemonContext = func() (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		go func() {
+			defer cancel()
+			for ctx.Err() == nil {
+				time.Sleep(10 * time.Millisecond)
+				response, err := http.Get("http://" + healthAddress + "/healthz")
+				if err != nil {
+					continue
+				}
+				body, _ := io.ReadAll(response.Body)
+				_ = response.Body.Close()
+				var status struct {
+					Status string `json:"status"`
+					Jobs   []struct {
+						Runs int `json:"runs"`
+					} `json:"jobs"`
+				}
+				if json.Unmarshal(body, &status) == nil && len(status.Jobs) == 2 && status.Jobs[0].Runs >= 2 && status.Jobs[1].Runs >= 2 {
+					_ = json.Unmarshal(body, &health)
+					return
+				}
+			}
+		}()
+		return ctx, cancel
+	}
+
+	runTestCmd(t, []cmdTestCase{
+		{
+			name: "run-daemon reports the environments of the environments file periodically",
+			cmd:  fmt.Sprintf("snapshot run-daemon --environments-file %s --health-address %s %s", environmentsFile, healthAddress, server.args()),
+			goldenRegex: `(?s)^\{"time":"[^"]+","level":"INFO","msg":"serving the health endpoint","address":"http://` + healthAddress + `/healthz"\}\n` +
+				`\{"time":"[^"]+","level":"INFO","msg":"daemon started","environments":2,"dry_run":false\}\n` +
+				`.*\{"time":"[^"]+","level":"INFO","msg":"\[1\] artifacts were reported to environment daemon-worker"\}\n` +
+				`.*\{"time":"[^"]+","level":"INFO","msg":"run succeeded","type":"path","environment":"daemon-app","duration":[0-9]+,"next_run_in":20000000\}\n` +
+				`.*\{"time":"[^"]+","level":"INFO","msg":"daemon stopped"\}\n$`,
+		},
+	})
+
+	require.Equal(t, "ok", health["status"])
+	require.Contains(t, server.reports["PUT /api/v2/environments/test-org/daemon-app/report/server"], `"app":`)
+	require.Contains(t, server.reports["PUT /api/v2/environments/test-org/daemon-worker/report/server"], `"worker":`)
+}
+
+func TestSnapshotRunDaemonInvalidEnvironmentsFile(t *testing.T) {
+	server := newFakeReportServer(t)
+	args := server.args()
+
+	unknownType := writeEnvironmentsFile(t, "version: 1\nenvironments:\n  - {type: mainframe, environment: prod, interval: 1m}\n")
+	unknownFlag := writeEnvironmentsFile(t, "version: 1\nenvironments:\n  - {type: k8s, environment: prod, interval: 1m, flags: {namespace: prod}}\n")
+	dryRun := writeEnvironmentsFile(t, "version: 1\nenvironments:\n  - {type: docker, environment: prod, interval: 1m, flags: {dry-run: true}}\n")
+	watch := writeEnvironmentsFile(t, "version: 1\nenvironments:\n  - {type: paths, environment: prod, interval: 1m, flags: {paths-file: paths.yml, watch: true}}\n")
+	missingEnvironment := writeEnvironmentsFile(t, "version: 1\nenvironments:\n  - {type: docker, interval: 1m}\n")
+	missingInterval := writeEnvironmentsFile(t, "version: 1\nenvironments:\n  - {type: docker, environment: prod}\n")
+
+	runTestCmd(t, []cmdTestCase{
+		{
+			wantError: true,
+			name:      "run-daemon fails if --environments-file is not set",
+			cmd:       "snapshot run-daemon " + args,
+			golden:    "Error: required flag(s) \"environments-file\" not set\n",
+		},
+		{
+			wantError:   true,
+			name:        "run-daemon fails for an unknown type",
+			cmd:         fmt.Sprintf("snapshot run-daemon --environments-file %s %s", unknownType, args),
+			goldenRegex: `^Error: environments file \[.*\] is invalid: environment 1: unknown type mainframe\. Valid types are: \[.*cloud-run.*k8s.*\]\n$`,
+		},
+		{
+			wantError:   true,
+			name:        "run-daemon fails for an unknown flag of a type",
+			cmd:         fmt.Sprintf("snapshot run-daemon --environments-file %s %s", unknownFlag, args),
+			goldenRegex: `^Error: environments file \[.*\] is invalid: environment 1: unknown flag: --namespace\n$`,
+		},
+		{
+			wantError:   true,
+			name:        "run-daemon fails for dry-run set for an environment",
+			cmd:         fmt.Sprintf("snapshot run-daemon --environments-file %s %s", dryRun, args),
+			goldenRegex: `^Error: environments file \[.*\] is invalid: environment 1: flag dry-run cannot be set for an environment: give --dry-run to run-daemon itself, for every environment\n$`,
+		},
+		{
+			wantError:   true,
+			name:        "run-daemon fails for watch set for an environment",
+			cmd:         fmt.Sprintf("snapshot run-daemon --environments-file %s %s", watch, args),
+			goldenRegex: `^Error: environments file \[.*\] is invalid: environment 1: flag watch cannot be set for an environment: run-daemon reports the environment at its interval instead\n$`,
+		},
+		{
+			wantError:   true,
+			name:        "run-daemon fails for a missing environment name",
+			cmd:         fmt.Sprintf("snapshot run-daemon --environments-file %s %s", missingEnvironment, args),
+			goldenRegex: `^Error: environments file \[.*\] is invalid: environment 1: accepts 1 arg\(s\), received 0\n$`,
+		},
+		{
+			wantError:   true,
+			name:        "run-daemon fails for a missing interval",
+			cmd:         fmt.Sprintf("snapshot run-daemon --environments-file %s %s", missingInterval, args),
+			goldenRegex: `^Error: environments file \[.*\] is invalid: .*'Interval' failed on the 'required' tag\n$`,
+		},
+		{
+			wantError: true,
+			name:      "run-daemon fails for an invalid log format",
+			cmd:       fmt.Sprintf("snapshot run-daemon --environments-file %s --log-format xml %s", missingInterval, args),
+			golden:    "Error: invalid --log-format xml. Valid formats are: [json, text]\n",
+		},
+	})
+}
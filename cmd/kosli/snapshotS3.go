@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -14,9 +15,36 @@ const snapshotS3ShortDesc = `Report a snapshot of the content of an AWS S3 bucke
 
 const snapshotS3LongDesc = snapshotS3ShortDesc + awsAuthDesc + `
 You can report the entire bucket content, or filter some of the content using ^--include^ / ^--exclude^ (literal prefix match) or ^--include-regex^ / ^--exclude-regex^ (Go regular expressions matched against the full object key).
-In all cases, the content is reported as one artifact. If you wish to report separate files/dirs within the same bucket as separate artifacts, you need to run the command twice.
+The filtered content is reported as one artifact.
 
-` + kosliIgnoreDesc
+To report separate files/dirs within the same bucket as separate artifacts, use ^--paths-file^ instead of the filtering flags.
+The S3 paths file names each artifact and the objects it is made of: the objects matching one of its ^include^ prefixes
+or ^include_regex^ regular expressions, except those matching one of its ^exclude^ prefixes or ^exclude_regex^ regular expressions.
+The bucket is listed once and each object is downloaded once, even when it is part of several artifacts. An artifact has the
+fingerprint the bucket content filtered to the artifact would have. S3 paths files can be in YAML, JSON or TOML formats.
+This is an example YAML S3 paths file:
+` +
+	"```yaml\n" +
+	`version: 1
+artifacts:
+  site-a:
+    include: [sites/a/]
+    exclude: [sites/a/drafts/]
+  site-b:
+    include_regex: ['^sites/b/.*\.(html|css|js)$']` +
+	"\n```" + `
+
+Downloading every object of a large bucket is slow and costly. With ^--use-checksums^, objects uploaded with a
+SHA-256 checksum are fingerprinted from their checksum, and only the other objects are downloaded. The checksum of
+an object uploaded in parts is used only when it is a full object checksum. With ^--checksums-manifest^, the objects
+without a checksum are fingerprinted from their digest in a manifest object of the bucket in ^sha256sum^ format,
+unless they were modified after the manifest, in which case they are downloaded. Either way, the fingerprints are the ones of the downloaded objects,
+except when the reported content has a ^.kosli_ignore^ file at its top level, which requires downloading it.
+
+` + kosliIgnoreDesc + awsAccountsDesc + `
+
+Buckets are global, so the bucket of each account is snapshot once, in its own region, which is the {region} of its
+environment. The regions of the accounts are only used to find the region of their bucket.`
 
 const snapshotS3Example = `
 # report the contents of an entire AWS S3 bucket (AWS auth provided in env variables):
@@ -66,6 +94,27 @@ kosli snapshot s3 yourEnvironmentName \
 	--exclude-regex '.*\.png$' \
 	--api-token yourAPIToken \
 	--org yourOrgName
+
+# report several artifacts in an AWS S3 bucket, as named in an S3 paths file:
+kosli snapshot s3 yourEnvironmentName \
+	--bucket yourBucketName \
+	--paths-file s3-paths.yml \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report the contents of an AWS S3 bucket from the SHA-256 checksums of its objects, and a manifest of the others:
+kosli snapshot s3 yourEnvironmentName \
+	--bucket yourBucketName \
+	--checksums-manifest sha256sums.txt \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a bucket in each of several AWS accounts, assuming a role in each account
+kosli snapshot s3 \
+	--aws-accounts-file aws-accounts.yml \
+	--bucket yourBucketName \
+	--api-token yourAPIToken \
+	--org yourOrgName
 `
 
 type snapshotS3Options struct {
@@ -74,20 +123,30 @@ type snapshotS3Options struct {
 	includeRegex   []string
 	excludePaths   []string
 	excludeRegex   []string
+	pathSpecFile   string
+	useChecksums   bool
+	manifest       string
 	awsStaticCreds *aws.AWSStaticCreds
+	accounts       awsAccountsOptions
 }
 
 func newSnapshotS3Cmd(out io.Writer) *cobra.Command {
 	o := new(snapshotS3Options)
 	o.awsStaticCreds = new(aws.AWSStaticCreds)
+	o.accounts.perAccount = true
 	cmd := &cobra.Command{
-		Use:     "s3 ENVIRONMENT-NAME",
+		Use:     "s3 [ENVIRONMENT-NAME]",
 		Aliases: []string{"S3"},
 		Short:   snapshotS3ShortDesc,
 		Long:    snapshotS3LongDesc,
 		Example: snapshotS3Example,
-		Args:    cobra.ExactArgs(1),
+		Args:    awsAccountsArgs(&o.accounts),
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// the bucket of each account can come from the accounts file
+			if o.bucket == "" && o.accounts.accountsFile == "" {
+				return fmt.Errorf("required flag(s) \"bucket\" not set")
+			}
+
 			err := RequireGlobalFlags(global, []string{"Org", "ApiToken"})
 			if err != nil {
 				return ErrorBeforePrintingUsage(cmd, err.Error())
@@ -105,6 +164,12 @@ func newSnapshotS3Cmd(out io.Writer) *cobra.Command {
 					return err
 				}
 			}
+			// the paths file has the filters of each artifact
+			for _, filterFlag := range []string{"include", "include-regex", "exclude", "exclude-regex"} {
+				if err = MuXRequiredFlags(cmd, []string{"paths-file", filterFlag}, false); err != nil {
+					return err
+				}
+			}
 
 			return nil
 		},
@@ -118,18 +183,49 @@ func newSnapshotS3Cmd(out io.Writer) *cobra.Command {
 	cmd.Flags().StringSliceVar(&o.includeRegex, "include-regex", []string{}, bucketPathsRegexFlag)
 	cmd.Flags().StringSliceVarP(&o.excludePaths, "exclude", "x", []string{}, excludeBucketPathsFlag)
 	cmd.Flags().StringSliceVar(&o.excludeRegex, "exclude-regex", []string{}, excludeBucketPathsRegexFlag)
+	cmd.Flags().StringVar(&o.pathSpecFile, "paths-file", "", s3PathsSpecFileFlag)
+	cmd.Flags().BoolVar(&o.useChecksums, "use-checksums", false, s3UseChecksumsFlag)
+	cmd.Flags().StringVar(&o.manifest, "checksums-manifest", "", s3ChecksumsManifestFlag)
 	addAWSAuthFlags(cmd, o.awsStaticCreds)
+	addAWSAccountsFlag(cmd, &o.accounts)
 	addDryRunFlag(cmd)
 
-	err := RequireFlags(cmd, []string{"bucket"})
-	if err != nil {
-		logger.Error("failed to configure required flags: %v", err)
-	}
-
 	return cmd
 }
 
 func (o *snapshotS3Options) run(args []string) error {
+	var spec *aws.S3PathsSpec
+	if o.pathSpecFile != "" {
+		var err error
+		spec, err = loadSpecFile[aws.S3PathsSpec](o.pathSpecFile, "S3 paths file", nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if o.accounts.accountsFile != "" {
+		return reportAWSAccountsSnapshots(&o.accounts, o.awsStaticCreds, "S3",
+			func(target *aws.AccountTarget) (map[string][]*aws.S3Data, error) {
+				bucket := target.Bucket
+				if bucket == "" {
+					bucket = o.bucket
+				}
+				if bucket == "" {
+					return nil, fmt.Errorf("no bucket to snapshot, set --bucket or the bucket of the account")
+				}
+				region, err := aws.BucketRegion(target.Creds, bucket)
+				if err != nil {
+					return nil, err
+				}
+				target.SetRegion(region)
+				s3Data, err := o.getS3Data(target.Creds, bucket, spec)
+				if err != nil {
+					return nil, err
+				}
+				return map[string][]*aws.S3Data{target.Environment(""): s3Data}, nil
+			},
+			func(s3Data []*aws.S3Data) any { return &aws.S3EnvRequest{Artifacts: s3Data} })
+	}
 	envName := args[0]
 
 	if err := ensureEnvironment(envName, "S3"); err != nil {
@@ -141,7 +237,7 @@ func (o *snapshotS3Options) run(args []string) error {
 		return err
 	}
 
-	s3Data, err := o.awsStaticCreds.GetS3Data(o.bucket, o.includePaths, o.includeRegex, o.excludePaths, o.excludeRegex, logger)
+	s3Data, err := o.getS3Data(o.awsStaticCreds, o.bucket, spec)
 	if err != nil {
 		return err
 	}
@@ -156,9 +252,25 @@ func (o *snapshotS3Options) run(args []string) error {
 		DryRun:  global.DryRun,
 		Token:   global.ApiToken,
 	}
-	_, err = kosliClient.Do(reqParams)
-	if err == nil && !global.DryRun {
+	reported, err := reportSnapshot(reqParams, envName)
+	if err == nil && reported && !global.DryRun {
 		logger.Info("bucket %s was reported to environment %s", o.bucket, envName)
 	}
 	return err
 }
+
+// getS3Data returns an artifact per entry of spec in bucket, or the filtered
+// content of bucket as one artifact without spec.
+func (o *snapshotS3Options) getS3Data(creds *aws.AWSStaticCreds, bucket string, spec *aws.S3PathsSpec) ([]*aws.S3Data, error) {
+	var checksums *aws.S3ChecksumsOptions
+	if o.useChecksums || o.manifest != "" {
+		checksums = &aws.S3ChecksumsOptions{Manifest: o.manifest}
+	}
+	if spec != nil {
+		return creds.GetS3PathsData(bucket, spec, checksums, logger)
+	}
+	if checksums != nil {
+		return creds.GetS3ChecksumsData(bucket, o.includePaths, o.includeRegex, o.excludePaths, o.excludeRegex, *checksums, logger)
+	}
+	return creds.GetS3Data(bucket, o.includePaths, o.includeRegex, o.excludePaths, o.excludeRegex, logger)
+}
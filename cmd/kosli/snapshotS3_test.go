@@ -1,10 +1,13 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"testing"
 
 	"github.com/kosli-dev/cli/internal/aws"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -125,3 +128,84 @@ func (suite *SnapshotS3TestSuite) TestSnapshotS3Cmd() {
 func TestSnapshotS3TestSuite(t *testing.T) {
 	suite.Run(t, new(SnapshotS3TestSuite))
 }
+
+func TestSnapshotS3PathsFile(t *testing.T) {
+	server := newFakeReportServer(t)
+	aws.NewS3ClientFunc = func(_ *aws.AWSStaticCreds) (aws.S3API, error) {
+		return &aws.FakeS3Client{
+			Bucket: "my-bucket",
+			Objects: map[string][]byte{
+				"README.md":                  []byte("# kosli cli public\n"),
+				"dummy/dummy_2/template.yml": []byte("key: value\n"),
+			},
+		}, nil
+	}
+	t.Cleanup(aws.ResetS3ClientFactory)
+
+	tests := []cmdTestCase{
+		{
+			name:   "snapshot s3 reports an artifact per entry of --paths-file",
+			cmd:    "snapshot s3 my-env --bucket my-bucket --paths-file testdata/s3-paths-files/valid-s3-paths.yml " + server.args(),
+			golden: "bucket my-bucket was reported to environment my-env\n",
+		},
+		{
+			wantError:   true,
+			name:        "snapshot s3 fails with an artifact without include or include_regex",
+			cmd:         "snapshot s3 my-env --bucket my-bucket --paths-file testdata/s3-paths-files/invalid-s3-paths.yml " + server.args(),
+			goldenRegex: `^Error: S3 paths file \[testdata/s3-paths-files/invalid-s3-paths.yml\] is invalid: .*'Include' failed on the 'required_without' tag`,
+		},
+		{
+			wantError: true,
+			name:      "snapshot s3 fails with both --paths-file and --include",
+			cmd:       "snapshot s3 my-env --bucket my-bucket --paths-file testdata/s3-paths-files/valid-s3-paths.yml --include README.md " + server.args(),
+			golden:    "Error: only one of --paths-file, --include is allowed\n",
+		},
+	}
+	runTestCmd(t, tests)
+
+	require.JSONEq(t, fmt.Sprintf(`{"artifacts":[`+
+		`{"digests":{"readme":"%x"},"creationTimestamp":1705314600},`+
+		`{"digests":{"templates":"%x"},"creationTimestamp":1705314600}]}`,
+		sha256.Sum256([]byte("# kosli cli public\n")), sha256.Sum256([]byte("key: value\n"))),
+		server.reports["PUT /api/v2/environments/test-org/my-env/report/S3"])
+}
+
+func TestSnapshotS3Checksums(t *testing.T) {
+	server := newFakeReportServer(t)
+	readme, template := sha256.Sum256([]byte("# kosli cli public\n")), sha256.Sum256([]byte("key: value\n"))
+	aws.NewS3ClientFunc = func(_ *aws.AWSStaticCreds) (aws.S3API, error) {
+		return &aws.FakeS3Client{
+			Bucket: "my-bucket",
+			Objects: map[string][]byte{
+				"README.md":                  []byte("# kosli cli public\n"),
+				"dummy/dummy_2/template.yml": []byte("key: value\n"),
+				"sha256sums.txt":             []byte(fmt.Sprintf("%x  dummy/dummy_2/template.yml\n", template)),
+			},
+			ChecksumsSHA256: map[string]string{"README.md": base64.StdEncoding.EncodeToString(readme[:])},
+		}, nil
+	}
+	t.Cleanup(aws.ResetS3ClientFactory)
+
+	tests := []cmdTestCase{
+		{
+			name:   "snapshot s3 with --checksums-manifest reports artifacts without downloading objects with digests",
+			cmd:    "snapshot s3 my-env --bucket my-bucket --paths-file testdata/s3-paths-files/valid-s3-paths.yml --checksums-manifest sha256sums.txt " + server.args(),
+			golden: "bucket my-bucket was reported to environment my-env\n",
+		},
+		{
+			name:   "snapshot s3 with --use-checksums reports the filtered bucket",
+			cmd:    "snapshot s3 other-env --bucket my-bucket --use-checksums --include README.md " + server.args(),
+			golden: "bucket my-bucket was reported to environment other-env\n",
+		},
+	}
+	runTestCmd(t, tests)
+
+	require.JSONEq(t, fmt.Sprintf(`{"artifacts":[`+
+		`{"digests":{"readme":"%x"},"creationTimestamp":1705314600},`+
+		`{"digests":{"templates":"%x"},"creationTimestamp":1705314600}]}`, readme, template),
+		server.reports["PUT /api/v2/environments/test-org/my-env/report/S3"])
+	require.JSONEq(t, fmt.Sprintf(`{"artifacts":[{"digests":{"README.md":"%x"},"creationTimestamp":1705314600}]}`, readme),
+		server.reports["PUT /api/v2/environments/test-org/other-env/report/S3"])
+	require.JSONEq(t, fmt.Sprintf(`{"artifacts":[{"digests":{"README.md":"%x"},"creationTimestamp":1705314600}]}`, readme),
+		server.reports["PUT /api/v2/environments/test-org/other-env/report/S3"])
+}
@@ -50,14 +50,17 @@ VERDICT_COMMANDS = {
 # Commands that need a service we cannot reach. Recorded as skipped rather than
 # guessed at, so the results never claim to know something they do not.
 NEEDS_EXTERNAL = {
-    "aws": ["snapshot ecs", "snapshot lambda", "snapshot s3"],
-    "azure": ["snapshot azure"],
-    "google cloud": ["snapshot cloud-run"],
+    "aws": ["snapshot ecs", "snapshot lambda", "snapshot s3", "snapshot apprunner", "snapshot beanstalk"],
+    "azure": ["snapshot azure", "snapshot azure-containerapps", "snapshot azure-functions"],
+    "google cloud": ["snapshot appengine", "snapshot cloud-run", "snapshot cloudfunctions"],
     "kubernetes": ["snapshot k8s"],
-    "github": ["attest pullrequest github", "assert pullrequest github"],
-    "gitlab": ["attest pullrequest gitlab", "assert pullrequest gitlab"],
-    "bitbucket": ["attest pullrequest bitbucket", "assert pullrequest bitbucket"],
-    "azure devops": ["attest pullrequest azure", "assert pullrequest azure"],
+    "nomad": ["snapshot nomad"],
+    "systemd": ["snapshot processes"],
+    "github": ["attest pullrequest github", "assert pullrequest github", "attest repo-settings github"],
+    "gitlab": ["attest pullrequest gitlab", "assert pullrequest gitlab", "attest repo-settings gitlab"],
+    "gitea": ["attest pullrequest gitea", "assert pullrequest gitea"],
+    "bitbucket": ["attest pullrequest bitbucket", "assert pullrequest bitbucket", "attest repo-settings bitbucket"],
+    "azure devops": ["attest pullrequest azure", "assert pullrequest azure", "attest repo-settings azure"],
     "jira": ["attest jira"],
     "sonarqube": ["attest sonar"],
     "snyk": ["attest snyk"],
@@ -90,6 +93,9 @@ VALUES = {
     # as well, and takes twelve seconds to give up. What makes this fast is an
     # answer, not the absence of one.
     "gitlab-base-url": HOST,
+    # Gitea has no public instance to default to, and the local server answers
+    # its API paths with a 404 as promptly as it does GitLab's.
+    "gitea-base-url": HOST,
     "repo-root": ".",
     "output": "json",
     # Values the CLI or the server states outright, taken from what they say
@@ -193,6 +199,9 @@ COMMAND_VALUES = {
     # value any run of this audit can wait for.
     "snapshot path": {"watch": "false"},
     "snapshot paths": {"watch": "false"},
+    # run-daemon reports its environments until interrupted, so it is given an
+    # environments file that does not exist, which it refuses without waiting.
+    "snapshot run-daemon": {"environments-file": "probe-environments-file"},
 }
 
 # Flags a command needs but does not ask for, because its default is wrong for
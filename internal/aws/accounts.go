@@ -0,0 +1,191 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// AccountsSpec is the content of an AWS accounts file. It lists the AWS
+// accounts a snapshot command looks into, the role it assumes in each of
+// them, the regions it looks into, and the Kosli environment each account
+// and region is reported to. The top level fields are the defaults of the
+// fields of the same name of each account.
+type AccountsSpec struct {
+	Version     int           `mapstructure:"version" validate:"required,oneof=1"`
+	RoleName    string        `mapstructure:"role_name"`
+	ExternalID  string        `mapstructure:"external_id"`
+	SessionName string        `mapstructure:"session_name"`
+	Regions     []string      `mapstructure:"regions"`
+	Environment string        `mapstructure:"environment"`
+	Accounts    []AccountSpec `mapstructure:"accounts" validate:"required,min=1,dive"`
+}
+
+// AccountSpec is an AWS account of an AccountsSpec.
+type AccountSpec struct {
+	ID string `mapstructure:"id" validate:"required,numeric,len=12"`
+	// Name is a friendly name of the account, used in environment names.
+	// It defaults to the ID.
+	Name string `mapstructure:"name"`
+	// RoleARN is the role assumed in the account. It defaults to the role
+	// called RoleName in the account.
+	RoleARN     string   `mapstructure:"role_arn"`
+	RoleName    string   `mapstructure:"role_name"`
+	ExternalID  string   `mapstructure:"external_id"`
+	Regions     []string `mapstructure:"regions"`
+	Environment string   `mapstructure:"environment"`
+	// Bucket is the S3 bucket snapshot by snapshot s3 in the account.
+	Bucket string `mapstructure:"bucket"`
+}
+
+// AccountTarget is an AWS account and region to snapshot, with the
+// credentials assuming the role of the account in the region.
+type AccountTarget struct {
+	AccountID   string
+	AccountName string
+	Region      string
+	Bucket      string
+	Creds       *AWSStaticCreds
+	environment string
+}
+
+// Targets returns an AccountTarget per account and region of s. base has
+// the credentials used to assume the roles, and its region is used for the
+// accounts without regions.
+func (s *AccountsSpec) Targets(base *AWSStaticCreds) ([]*AccountTarget, error) {
+	return s.targets(base, true)
+}
+
+// BucketTargets returns an AccountTarget per account of s, for the S3 bucket
+// of each account. Buckets are global rather than in each region, so a
+// target is in the first region of its account until SetRegion moves it to
+// the region of its bucket, as found by BucketRegion.
+func (s *AccountsSpec) BucketTargets(base *AWSStaticCreds) ([]*AccountTarget, error) {
+	return s.targets(base, false)
+}
+
+func (s *AccountsSpec) targets(base *AWSStaticCreds, perRegion bool) ([]*AccountTarget, error) {
+	targets := []*AccountTarget{}
+	for _, account := range s.Accounts {
+		name := firstNonEmpty(account.Name, account.ID)
+		roleARN := account.RoleARN
+		if roleARN == "" {
+			roleName := firstNonEmpty(account.RoleName, s.RoleName)
+			if roleName == "" {
+				return nil, fmt.Errorf("account %s has no role_arn or role_name to assume", name)
+			}
+			roleARN = fmt.Sprintf("arn:aws:iam::%s:role/%s", account.ID, roleName)
+		}
+		environment := firstNonEmpty(account.Environment, s.Environment)
+		if environment == "" {
+			return nil, fmt.Errorf("account %s has no environment to report to", name)
+		}
+		regions := account.Regions
+		if len(regions) == 0 {
+			regions = s.Regions
+		}
+		if len(regions) == 0 && base.Region != "" {
+			regions = []string{base.Region}
+		}
+		if len(regions) == 0 {
+			return nil, fmt.Errorf("account %s has no regions", name)
+		}
+		if !perRegion {
+			regions = regions[:1]
+		}
+
+		for _, region := range regions {
+			targets = append(targets, &AccountTarget{
+				AccountID:   account.ID,
+				AccountName: name,
+				Region:      region,
+				Bucket:      account.Bucket,
+				Creds: &AWSStaticCreds{
+					AccessKeyID:     base.AccessKeyID,
+					SecretAccessKey: base.SecretAccessKey,
+					Region:          region,
+					RoleARN:         roleARN,
+					ExternalID:      firstNonEmpty(account.ExternalID, s.ExternalID),
+					RoleSessionName: s.SessionName,
+				},
+				environment: environment,
+			})
+		}
+	}
+	return targets, nil
+}
+
+// Environment returns the name of the Kosli environment t reports to, for
+// the ECS cluster when it is not empty. The {account}, {account_name},
+// {region} and {cluster} placeholders of the environment of the account
+// are replaced with their value.
+func (t *AccountTarget) Environment(cluster string) string {
+	return strings.NewReplacer(
+		"{account}", t.AccountID,
+		"{account_name}", t.AccountName,
+		"{region}", t.Region,
+		"{cluster}", cluster,
+	).Replace(t.environment)
+}
+
+// PerCluster reports whether t reports each ECS cluster to its own
+// environment.
+func (t *AccountTarget) PerCluster() bool {
+	return strings.Contains(t.environment, "{cluster}")
+}
+
+// SetRegion moves t and its credentials to region.
+func (t *AccountTarget) SetRegion(region string) {
+	t.Region = region
+	t.Creds.Region = region
+}
+
+func (t *AccountTarget) String() string {
+	return fmt.Sprintf("account %s in %s", t.AccountName, t.Region)
+}
+
+// ForEachTarget calls fn for each of targets, concurrently with at most
+// concurrency calls at a time. It returns the errors of fn by target.
+func ForEachTarget(targets []*AccountTarget, concurrency int, fn func(*AccountTarget) error) map[*AccountTarget]error {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	failures := map[*AccountTarget]error{}
+	sem := make(chan struct{}, max(concurrency, 1))
+	for _, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(target); err != nil {
+				mu.Lock()
+				failures[target] = fmt.Errorf("%s: %w", target, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return failures
+}
+
+// JoinTargetErrors joins the errors returned by ForEachTarget in the order
+// of targets.
+func JoinTargetErrors(targets []*AccountTarget, failures map[*AccountTarget]error) error {
+	errs := []error{}
+	for _, target := range targets {
+		if err, ok := failures[target]; ok {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
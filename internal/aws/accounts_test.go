@@ -0,0 +1,166 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountsSpecTargets(t *testing.T) {
+	base := &AWSStaticCreds{AccessKeyID: "key", SecretAccessKey: "secret", Region: "eu-central-1"}
+	spec := &AccountsSpec{
+		Version:     1,
+		RoleName:    "kosli-snapshot",
+		ExternalID:  "default-id",
+		Environment: "{account_name}-{region}",
+		Accounts: []AccountSpec{
+			{ID: "111111111111"},
+			{
+				ID: "222222222222", Name: "staging", RoleARN: "arn:aws:iam::222222222222:role/custom",
+				ExternalID: "staging-id", Regions: []string{"eu-west-1", "us-east-1"},
+				Environment: "{account}-{cluster}", Bucket: "staging-bucket",
+			},
+		},
+	}
+
+	targets, err := spec.Targets(base)
+	require.NoError(t, err)
+	require.Len(t, targets, 3)
+
+	require.Equal(t, "111111111111", targets[0].AccountName)
+	require.Equal(t, &AWSStaticCreds{
+		AccessKeyID: "key", SecretAccessKey: "secret", Region: "eu-central-1",
+		RoleARN: "arn:aws:iam::111111111111:role/kosli-snapshot", ExternalID: "default-id",
+	}, targets[0].Creds)
+	require.Equal(t, "111111111111-eu-central-1", targets[0].Environment(""))
+	require.False(t, targets[0].PerCluster())
+
+	require.Equal(t, "us-east-1", targets[2].Region)
+	require.Equal(t, "arn:aws:iam::222222222222:role/custom", targets[2].Creds.RoleARN)
+	require.Equal(t, "staging-id", targets[2].Creds.ExternalID)
+	require.Equal(t, "staging-bucket", targets[2].Bucket)
+	require.True(t, targets[2].PerCluster())
+	require.Equal(t, "222222222222-backend", targets[2].Environment("backend"))
+	require.Equal(t, "account staging in us-east-1", targets[2].String())
+}
+
+func TestAccountsSpecBucketTargets(t *testing.T) {
+	base := &AWSStaticCreds{Region: "eu-central-1"}
+	spec := &AccountsSpec{
+		Version:     1,
+		RoleName:    "kosli-snapshot",
+		Regions:     []string{"eu-west-1", "us-east-1"},
+		Environment: "{account_name}-{region}",
+		Accounts:    []AccountSpec{{ID: "111111111111", Name: "production"}, {ID: "222222222222", Regions: []string{"us-west-2"}}},
+	}
+
+	targets, err := spec.BucketTargets(base)
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	require.Equal(t, "eu-west-1", targets[0].Region)
+	require.Equal(t, "us-west-2", targets[1].Creds.Region)
+
+	targets[0].SetRegion("ap-south-1")
+	require.Equal(t, "ap-south-1", targets[0].Creds.Region)
+	require.Equal(t, "production-ap-south-1", targets[0].Environment(""))
+}
+
+func TestAccountsSpecTargetsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		spec    AccountsSpec
+		wantErr string
+	}{
+		{
+			name:    "no role",
+			spec:    AccountsSpec{Environment: "prod", Accounts: []AccountSpec{{ID: "111111111111", Name: "prod"}}},
+			wantErr: "account prod has no role_arn or role_name to assume",
+		},
+		{
+			name:    "no environment",
+			spec:    AccountsSpec{RoleName: "r", Accounts: []AccountSpec{{ID: "111111111111"}}},
+			wantErr: "account 111111111111 has no environment to report to",
+		},
+		{
+			name:    "no regions",
+			spec:    AccountsSpec{RoleName: "r", Environment: "prod", Accounts: []AccountSpec{{ID: "111111111111"}}},
+			wantErr: "account 111111111111 has no regions",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.spec.Targets(&AWSStaticCreds{})
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestForEachTarget(t *testing.T) {
+	targets := []*AccountTarget{
+		{AccountName: "a", Region: "eu-west-1"},
+		{AccountName: "b", Region: "eu-west-1"},
+		{AccountName: "c", Region: "eu-west-1"},
+	}
+	var running, maxRunning atomic.Int32
+	failures := ForEachTarget(targets, 2, func(target *AccountTarget) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		if target.AccountName != "b" {
+			return errors.New("denied")
+		}
+		return nil
+	})
+	require.LessOrEqual(t, maxRunning.Load(), int32(2))
+	require.Len(t, failures, 2)
+	require.EqualError(t, JoinTargetErrors(targets, failures),
+		"account a in eu-west-1: denied\naccount c in eu-west-1: denied")
+}
+
+func TestNewAWSConfigAssumesRole(t *testing.T) {
+	var form map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		form = map[string]string{}
+		for key := range r.PostForm {
+			form[key] = r.PostForm.Get(key)
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = w.Write([]byte(`<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>assumed-key</AccessKeyId>
+      <SecretAccessKey>assumed-secret</SecretAccessKey>
+      <SessionToken>assumed-token</SessionToken>
+      <Expiration>2099-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("AWS_ENDPOINT_URL_STS", server.URL)
+
+	creds := &AWSStaticCreds{
+		AccessKeyID: "key", SecretAccessKey: "secret", Region: "eu-west-1",
+		RoleARN: "arn:aws:iam::111111111111:role/kosli-snapshot", ExternalID: "external-id", RoleSessionName: "kosli-test",
+	}
+	cfg, err := creds.NewAWSConfigFromEnvOrFlags()
+	require.NoError(t, err)
+	assumed, err := cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "assumed-key", assumed.AccessKeyID)
+	require.Equal(t, "assumed-token", assumed.SessionToken)
+	require.Equal(t, "AssumeRole", form["Action"])
+	require.Equal(t, "arn:aws:iam::111111111111:role/kosli-snapshot", form["RoleArn"])
+	require.Equal(t, "external-id", form["ExternalId"])
+	require.Equal(t, "kosli-test", form["RoleSessionName"])
+}
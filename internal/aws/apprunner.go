@@ -0,0 +1,196 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apprunner"
+	appRunnerTypes "github.com/aws/aws-sdk-go-v2/service/apprunner/types"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	ecrTypes "github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/kosli-dev/cli/internal/filters"
+	"github.com/kosli-dev/cli/internal/logger"
+)
+
+// AppRunnerAPI is the subset of AWS App Runner operations used when
+// snapshotting services. The real *apprunner.Client satisfies this
+// implicitly.
+type AppRunnerAPI interface {
+	ListServices(ctx context.Context, params *apprunner.ListServicesInput, optFns ...func(*apprunner.Options)) (*apprunner.ListServicesOutput, error)
+	DescribeService(ctx context.Context, params *apprunner.DescribeServiceInput, optFns ...func(*apprunner.Options)) (*apprunner.DescribeServiceOutput, error)
+}
+
+// ECRImagesAPI is the subset of AWS ECR operations used to resolve a
+// tag-pinned image to its digest. The real *ecr.Client satisfies this
+// implicitly.
+type ECRImagesAPI interface {
+	DescribeImages(ctx context.Context, params *ecr.DescribeImagesInput, optFns ...func(*ecr.Options)) (*ecr.DescribeImagesOutput, error)
+}
+
+// defaultNewAppRunnerClient creates a real App Runner client from credentials.
+func defaultNewAppRunnerClient(creds *AWSStaticCreds) (AppRunnerAPI, error) {
+	cfg, err := creds.NewAWSConfigFromEnvOrFlags()
+	if err != nil {
+		return nil, err
+	}
+	return apprunner.NewFromConfig(cfg), nil
+}
+
+// NewAppRunnerClientFunc is the factory used by GetAppRunnerServicesData to
+// create an AppRunnerAPI client. Tests can replace this to inject a
+// FakeAppRunnerClient.
+var NewAppRunnerClientFunc = defaultNewAppRunnerClient
+
+// ResetAppRunnerClientFactory restores the default (real AWS) client factory.
+func ResetAppRunnerClientFactory() {
+	NewAppRunnerClientFunc = defaultNewAppRunnerClient
+}
+
+// defaultNewECRClient creates a real ECR client from credentials.
+func defaultNewECRClient(creds *AWSStaticCreds) (ECRImagesAPI, error) {
+	cfg, err := creds.NewAWSConfigFromEnvOrFlags()
+	if err != nil {
+		return nil, err
+	}
+	return ecr.NewFromConfig(cfg), nil
+}
+
+// NewECRClientFunc is the factory used to create the ECRImagesAPI client of
+// the region of an ECR image. Tests can replace this to inject a
+// FakeECRClient.
+var NewECRClientFunc = defaultNewECRClient
+
+// ResetECRClientFactory restores the default (real AWS) client factory.
+func ResetECRClientFactory() {
+	NewECRClientFunc = defaultNewECRClient
+}
+
+// ecrHostRegex matches the host of a private ECR registry and captures its
+// account ID and region.
+var ecrHostRegex = regexp.MustCompile(`^(\d{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+// GetAppRunnerServicesData returns the running App Runner services that
+// filter includes, as tasks data with the digest of the image of each
+// service. Services deployed from source code have no image and are skipped.
+func (staticCreds *AWSStaticCreds) GetAppRunnerServicesData(filter *filters.ResourceFilterOptions, logger *logger.Logger) ([]*EcsTaskData, error) {
+	servicesData := []*EcsTaskData{}
+	client, err := NewAppRunnerClientFunc(staticCreds)
+	if err != nil {
+		return servicesData, fmt.Errorf("failed to create App Runner client: %w", err)
+	}
+
+	compiledFilter := filter.Compile()
+	summaries := []appRunnerTypes.ServiceSummary{}
+	var nextToken *string
+	for {
+		out, err := client.ListServices(context.TODO(), &apprunner.ListServicesInput{NextToken: nextToken})
+		if err != nil {
+			return servicesData, fmt.Errorf("failed to list App Runner services: %w", err)
+		}
+		for _, summary := range out.ServiceSummaryList {
+			name := aws.ToString(summary.ServiceName)
+			if summary.Status != appRunnerTypes.ServiceStatusRunning {
+				logger.Debug("skipping App Runner service [%s] with status %s", name, summary.Status)
+				continue
+			}
+			included, err := compiledFilter.ShouldInclude(name)
+			if err != nil {
+				return servicesData, err
+			}
+			if included {
+				summaries = append(summaries, summary)
+			}
+		}
+		if out.NextToken == nil {
+			break
+		}
+		nextToken = out.NextToken
+	}
+
+	resolver := &ecrDigestResolver{creds: staticCreds, clients: map[string]ECRImagesAPI{}}
+	for _, summary := range summaries {
+		out, err := client.DescribeService(context.TODO(), &apprunner.DescribeServiceInput{ServiceArn: summary.ServiceArn})
+		if err != nil {
+			return servicesData, fmt.Errorf("failed to describe App Runner service %s: %w", aws.ToString(summary.ServiceName), err)
+		}
+		service := out.Service
+		if service.SourceConfiguration == nil || service.SourceConfiguration.ImageRepository == nil {
+			logger.Warn("App Runner service [%s] is deployed from source code and has no image to report", aws.ToString(service.ServiceName))
+			continue
+		}
+		repository := service.SourceConfiguration.ImageRepository
+		image := aws.ToString(repository.ImageIdentifier)
+		digest, err := resolver.digest(image, repository.ImageRepositoryType)
+		if err != nil {
+			return servicesData, fmt.Errorf("failed to resolve the digest of image %s of App Runner service %s: %w", image, aws.ToString(service.ServiceName), err)
+		}
+		if digest == "" {
+			logger.Warn("the digest of tag-pinned image %s of App Runner service [%s] cannot be resolved", image, aws.ToString(service.ServiceName))
+		}
+
+		deployedAt := service.UpdatedAt
+		if deployedAt == nil {
+			deployedAt = service.CreatedAt
+		}
+		servicesData = append(servicesData, NewEcsTaskData(aws.ToString(service.ServiceArn), "", aws.ToString(service.ServiceName),
+			map[string]string{image: digest}, aws.ToTime(deployedAt)))
+	}
+	return servicesData, nil
+}
+
+// ecrDigestResolver resolves the digests of images, with an ECR client per
+// region of the images in private ECR registries.
+type ecrDigestResolver struct {
+	creds   *AWSStaticCreds
+	clients map[string]ECRImagesAPI
+}
+
+// digest returns the bare hex digest of image. A digest-pinned image has its
+// digest in its name, and the digest of a tag-pinned image in a private ECR
+// registry is looked up in the registry. Other images have an empty digest,
+// like tag-pinned ECS container images.
+func (r *ecrDigestResolver) digest(image string, repositoryType appRunnerTypes.ImageRepositoryType) (string, error) {
+	if _, digest, ok := strings.Cut(image, "@sha256:"); ok {
+		return digest, nil
+	}
+	if repositoryType != appRunnerTypes.ImageRepositoryTypeEcr {
+		return "", nil
+	}
+	host, path, ok := strings.Cut(image, "/")
+	matches := ecrHostRegex.FindStringSubmatch(host)
+	if !ok || matches == nil {
+		return "", fmt.Errorf("not a private ECR image")
+	}
+	repositoryName, tag, ok := strings.Cut(path, ":")
+	if !ok {
+		tag = "latest"
+	}
+
+	registryID, region := matches[1], matches[2]
+	client, ok := r.clients[region]
+	if !ok {
+		regionCreds := *r.creds
+		regionCreds.Region = region
+		var err error
+		client, err = NewECRClientFunc(&regionCreds)
+		if err != nil {
+			return "", fmt.Errorf("failed to create ECR client: %w", err)
+		}
+		r.clients[region] = client
+	}
+	out, err := client.DescribeImages(context.TODO(), &ecr.DescribeImagesInput{
+		RegistryId:     aws.String(registryID),
+		RepositoryName: aws.String(repositoryName),
+		ImageIds:       []ecrTypes.ImageIdentifier{{ImageTag: aws.String(tag)}},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(out.ImageDetails) == 0 || out.ImageDetails[0].ImageDigest == nil {
+		return "", fmt.Errorf("image not found in ECR")
+	}
+	return strings.TrimPrefix(*out.ImageDetails[0].ImageDigest, "sha256:"), nil
+}
@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	appRunnerTypes "github.com/aws/aws-sdk-go-v2/service/apprunner/types"
+	"github.com/kosli-dev/cli/internal/filters"
+	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeAppRunnerService(name string, status appRunnerTypes.ServiceStatus, repositoryType appRunnerTypes.ImageRepositoryType, image string) appRunnerTypes.Service {
+	service := appRunnerTypes.Service{
+		ServiceArn:  aws.String("arn:aws:apprunner:eu-west-1:111111111111:service/" + name),
+		ServiceName: aws.String(name),
+		Status:      status,
+		CreatedAt:   aws.Time(time.Unix(1700000000, 0)),
+		UpdatedAt:   aws.Time(time.Unix(1700000100, 0)),
+	}
+	if image != "" {
+		service.SourceConfiguration = &appRunnerTypes.SourceConfiguration{
+			ImageRepository: &appRunnerTypes.ImageRepository{ImageIdentifier: aws.String(image), ImageRepositoryType: repositoryType},
+		}
+	} else {
+		service.SourceConfiguration = &appRunnerTypes.SourceConfiguration{CodeRepository: &appRunnerTypes.CodeRepository{}}
+	}
+	return service
+}
+
+func TestGetAppRunnerServicesData(t *testing.T) {
+	ecrImage := "222222222222.dkr.ecr.us-east-1.amazonaws.com/backend:v1"
+	pinnedImage := "public.ecr.aws/team/frontend@sha256:" + fmt.Sprintf("%064d", 1)
+	NewAppRunnerClientFunc = func(_ *AWSStaticCreds) (AppRunnerAPI, error) {
+		return &FakeAppRunnerClient{
+			PageSize: 2,
+			Services: []appRunnerTypes.Service{
+				fakeAppRunnerService("backend", appRunnerTypes.ServiceStatusRunning, appRunnerTypes.ImageRepositoryTypeEcr, ecrImage),
+				fakeAppRunnerService("frontend", appRunnerTypes.ServiceStatusRunning, appRunnerTypes.ImageRepositoryTypeEcrPublic, pinnedImage),
+				fakeAppRunnerService("paused", appRunnerTypes.ServiceStatusPaused, appRunnerTypes.ImageRepositoryTypeEcr, ecrImage),
+				fakeAppRunnerService("from-source", appRunnerTypes.ServiceStatusRunning, "", ""),
+				fakeAppRunnerService("public", appRunnerTypes.ServiceStatusRunning, appRunnerTypes.ImageRepositoryTypeEcrPublic, "public.ecr.aws/team/public:v2"),
+			},
+		}, nil
+	}
+	ecrRegions := []string{}
+	NewECRClientFunc = func(creds *AWSStaticCreds) (ECRImagesAPI, error) {
+		ecrRegions = append(ecrRegions, creds.Region)
+		return &FakeECRClient{Digests: map[string]string{"backend:v1": "sha256:" + fmt.Sprintf("%064d", 2)}}, nil
+	}
+	t.Cleanup(ResetAppRunnerClientFactory)
+	t.Cleanup(ResetECRClientFactory)
+
+	creds := &AWSStaticCreds{Region: "eu-west-1"}
+	data, err := creds.GetAppRunnerServicesData(&filters.ResourceFilterOptions{}, logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Equal(t, []*EcsTaskData{
+		{TaskArn: "arn:aws:apprunner:eu-west-1:111111111111:service/backend", Service: "backend",
+			Digests: map[string]string{ecrImage: fmt.Sprintf("%064d", 2)}, StartedAt: 1700000100},
+		{TaskArn: "arn:aws:apprunner:eu-west-1:111111111111:service/frontend", Service: "frontend",
+			Digests: map[string]string{pinnedImage: fmt.Sprintf("%064d", 1)}, StartedAt: 1700000100},
+		{TaskArn: "arn:aws:apprunner:eu-west-1:111111111111:service/public", Service: "public",
+			Digests: map[string]string{"public.ecr.aws/team/public:v2": ""}, StartedAt: 1700000100},
+	}, data)
+	require.Equal(t, []string{"us-east-1"}, ecrRegions, "ECR is queried in the region of the image")
+
+	data, err = creds.GetAppRunnerServicesData(&filters.ResourceFilterOptions{ExcludeNamesRegex: []string{"^front", "^pub"}}, logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Len(t, data, 1)
+	require.Equal(t, "backend", data[0].Service)
+}
+
+func TestGetAppRunnerServicesDataFailsOnMissingECRImage(t *testing.T) {
+	NewAppRunnerClientFunc = func(_ *AWSStaticCreds) (AppRunnerAPI, error) {
+		return &FakeAppRunnerClient{Services: []appRunnerTypes.Service{
+			fakeAppRunnerService("backend", appRunnerTypes.ServiceStatusRunning, appRunnerTypes.ImageRepositoryTypeEcr,
+				"222222222222.dkr.ecr.us-east-1.amazonaws.com/backend:gone"),
+		}}, nil
+	}
+	NewECRClientFunc = func(_ *AWSStaticCreds) (ECRImagesAPI, error) {
+		return &FakeECRClient{}, nil
+	}
+	t.Cleanup(ResetAppRunnerClientFactory)
+	t.Cleanup(ResetECRClientFactory)
+
+	_, err := (&AWSStaticCreds{}).GetAppRunnerServicesData(&filters.ResourceFilterOptions{}, logger.NewStandardLogger())
+	require.EqualError(t, err, "failed to resolve the digest of image 222222222222.dkr.ecr.us-east-1.amazonaws.com/backend:gone "+
+		"of App Runner service backend: image not found: backend:gone")
+}
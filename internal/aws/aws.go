@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,14 +15,17 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	ecsTypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/kosli-dev/cli/internal/digest"
 	"github.com/kosli-dev/cli/internal/filters"
 	"github.com/kosli-dev/cli/internal/logger"
@@ -80,6 +84,12 @@ type AWSStaticCreds struct {
 	AccessKeyID     string
 	SecretAccessKey string
 	Region          string
+	// RoleARN is the IAM role assumed with STS AssumeRole, using the other
+	// credentials, when it is set. ExternalID and RoleSessionName are
+	// passed to AssumeRole with it.
+	RoleARN         string
+	ExternalID      string
+	RoleSessionName string
 }
 
 // GetConfigOptFns returns a slice of config loading options functions based on
@@ -110,6 +120,10 @@ func (s *AWSStaticCreds) GetConfigOptFns() []func(*config.LoadOptions) error {
 // throttling is detected, rather than each goroutine retrying independently.
 //
 // More details: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/aws/retry
+//
+// When RoleARN is set, the credentials above are used to assume the role, and
+// the config authenticates as the role. The temporary credentials of the role
+// are cached and refreshed before they expire.
 func (staticCreds *AWSStaticCreds) NewAWSConfigFromEnvOrFlags() (aws.Config, error) {
 	optFns := staticCreds.GetConfigOptFns()
 	optFns = append(optFns, config.WithRetryer(func() aws.Retryer {
@@ -119,7 +133,18 @@ func (staticCreds *AWSStaticCreds) NewAWSConfigFromEnvOrFlags() (aws.Config, err
 			})
 		})
 	}))
-	return config.LoadDefaultConfig(context.TODO(), optFns...)
+	cfg, err := config.LoadDefaultConfig(context.TODO(), optFns...)
+	if err != nil || staticCreds.RoleARN == "" {
+		return cfg, err
+	}
+	cfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), staticCreds.RoleARN,
+		func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = staticCreds.RoleSessionName
+			if staticCreds.ExternalID != "" {
+				o.ExternalID = aws.String(staticCreds.ExternalID)
+			}
+		}))
+	return cfg, nil
 }
 
 // NewS3Client returns a new S3 API client
@@ -149,16 +174,24 @@ type S3DownloadAPI interface {
 	DownloadObject(ctx context.Context, params *transfermanager.DownloadObjectInput, optFns ...func(*transfermanager.Options)) (*transfermanager.DownloadObjectOutput, error)
 }
 
+// S3HeadAPI reads the metadata of a single object, like its checksums,
+// without downloading it. The real *s3.Client satisfies this implicitly.
+type S3HeadAPI interface {
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+}
+
 // S3API is the combined S3 surface that GetS3Data depends on.
 type S3API interface {
 	S3ListAPI
+	S3HeadAPI
 	S3DownloadAPI
 }
 
 // s3Client combines the two real AWS clients that back S3API: *s3.Client for
-// listing and *transfermanager.Client for downloading.
+// listing and reading metadata, and *transfermanager.Client for downloading.
 type s3Client struct {
 	S3ListAPI
+	S3HeadAPI
 	S3DownloadAPI
 }
 
@@ -168,7 +201,7 @@ func defaultNewS3Client(creds *AWSStaticCreds) (S3API, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &s3Client{S3ListAPI: client, S3DownloadAPI: transfermanager.New(client)}, nil
+	return &s3Client{S3ListAPI: client, S3HeadAPI: client, S3DownloadAPI: transfermanager.New(client)}, nil
 }
 
 // NewS3ClientFunc is the factory used by GetS3Data to create an S3API client.
@@ -180,6 +213,39 @@ func ResetS3ClientFactory() {
 	NewS3ClientFunc = defaultNewS3Client
 }
 
+// defaultBucketRegion finds the region of bucket with the real S3 API.
+func defaultBucketRegion(creds *AWSStaticCreds, bucket string) (string, error) {
+	client, err := creds.NewS3Client()
+	if err != nil {
+		return "", err
+	}
+	output, err := client.HeadBucket(context.TODO(), &s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	if err == nil && output.BucketRegion != nil {
+		return *output.BucketRegion, nil
+	}
+	// S3 redirects a request for a bucket in another region than the client,
+	// and names the region of the bucket in the redirect
+	var responseErr *awshttp.ResponseError
+	if errors.As(err, &responseErr) && responseErr.Response != nil {
+		if region := responseErr.Response.Header.Get("X-Amz-Bucket-Region"); region != "" {
+			return region, nil
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find the region of bucket %s: %w", bucket, err)
+	}
+	return creds.Region, nil
+}
+
+// BucketRegion returns the region of bucket, which creds can be in any region
+// to find. Tests can replace this to avoid calling AWS.
+var BucketRegion = defaultBucketRegion
+
+// ResetBucketRegion restores the default (real AWS) BucketRegion.
+func ResetBucketRegion() {
+	BucketRegion = defaultBucketRegion
+}
+
 // NewLambdaClient returns a new Lambda API client
 func (staticCreds *AWSStaticCreds) NewLambdaClient() (*lambda.Client, error) {
 	cfg, err := staticCreds.NewAWSConfigFromEnvOrFlags()
@@ -2,12 +2,16 @@ package aws
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"regexp"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager"
 	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/kosli-dev/cli/internal/filters"
 	"github.com/kosli-dev/cli/internal/logger"
 	"github.com/kosli-dev/cli/internal/testHelpers"
@@ -1227,6 +1231,229 @@ func (suite *AWSTestSuite) TestGetS3DataFromClientFilterEquivalence() {
 	}
 }
 
+// countingS3Client counts the downloads and the metadata reads of each
+// object of a FakeS3Client.
+type countingS3Client struct {
+	*FakeS3Client
+	downloads map[string]int
+	heads     map[string]int
+}
+
+func newCountingS3Client(client *FakeS3Client) *countingS3Client {
+	return &countingS3Client{FakeS3Client: client, downloads: map[string]int{}, heads: map[string]int{}}
+}
+
+func (c *countingS3Client) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	c.heads[*params.Key]++
+	return c.FakeS3Client.HeadObject(ctx, params, optFns...)
+}
+
+func (c *countingS3Client) DownloadObject(ctx context.Context, params *transfermanager.DownloadObjectInput, optFns ...func(*transfermanager.Options)) (*transfermanager.DownloadObjectOutput, error) {
+	c.downloads[*params.Key]++
+	return c.FakeS3Client.DownloadObject(ctx, params, optFns...)
+}
+
+// TestGetS3PathsDataFromClient asserts that each artifact of a paths spec is
+// fingerprinted like snapshot s3 filtered to the artifact, while each object
+// is downloaded once.
+func (suite *AWSTestSuite) TestGetS3PathsDataFromClient() {
+	earlier := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	objects := map[string][]byte{
+		"sites/a/index.html":  []byte(fakeReadmeBody),
+		"sites/a/drafts/x.md": []byte(fakeNotesBody),
+		"sites/b/index.html":  []byte(fakeNotesBody),
+		"sites/b/style.css":   []byte(fakeTemplateBody),
+		"other/notes.txt":     []byte(fakeNotesBody),
+	}
+	fingerprint := func(objects map[string][]byte, includePaths []string) string {
+		client := &FakeS3Client{Bucket: fakeS3TestBucketName, Objects: objects}
+		data, err := getS3DataFromClient(client, fakeS3TestBucketName, includePaths, nil, nil, nil, logger.NewStandardLogger())
+		require.NoError(suite.T(), err)
+		for _, digest := range data[0].Digests {
+			return digest
+		}
+		return ""
+	}
+
+	client := newCountingS3Client(&FakeS3Client{Bucket: fakeS3TestBucketName, Objects: objects,
+		LastModified: map[string]time.Time{"sites/b/style.css": earlier}})
+	spec := &S3PathsSpec{Version: 1, Artifacts: map[string]S3ArtifactPathSpec{
+		"site-a":     {Include: []string{"sites/a/"}, Exclude: []string{"sites/a/drafts/"}},
+		"site-b":     {Include: []string{"sites/b/"}},
+		"all-html":   {IncludeRegex: []string{`\.html$`}},
+		"site-b-css": {IncludeRegex: []string{`^sites/b/.*\.css$`}},
+	}}
+	data, err := getS3PathsDataFromClient(client, fakeS3TestBucketName, spec, nil, logger.NewStandardLogger())
+	require.NoError(suite.T(), err)
+
+	require.Equal(suite.T(), []*S3Data{
+		{Digests: map[string]string{"all-html": fingerprint(objects, []string{"sites/a/index.html", "sites/b/index.html"})},
+			LastModifiedTimestamp: fakeS3LastModified.Unix()},
+		{Digests: map[string]string{"site-a": fingerprint(map[string][]byte{"sites/a/index.html": []byte(fakeReadmeBody)}, nil)},
+			LastModifiedTimestamp: fakeS3LastModified.Unix()},
+		{Digests: map[string]string{"site-b": fingerprint(objects, []string{"sites/b/"})},
+			LastModifiedTimestamp: fakeS3LastModified.Unix()},
+		{Digests: map[string]string{"site-b-css": fingerprint(objects, []string{"sites/b/style.css"})},
+			LastModifiedTimestamp: earlier.Unix()},
+	}, data)
+	require.Equal(suite.T(), map[string]int{
+		"sites/a/index.html": 1,
+		"sites/b/index.html": 1,
+		"sites/b/style.css":  1,
+	}, client.downloads, "objects are downloaded once, and only when part of an artifact")
+
+	_, err = getS3PathsDataFromClient(client, fakeS3TestBucketName, &S3PathsSpec{Version: 1, Artifacts: map[string]S3ArtifactPathSpec{
+		"missing": {Include: []string{"sites/c/"}},
+	}}, nil, logger.NewStandardLogger())
+	require.EqualError(suite.T(), err, "no matching file or dirs in bucket: ["+fakeS3TestBucketName+"] for artifact [missing]")
+}
+
+// sha256Checksum returns the base64 SHA-256 checksum S3 reports for content.
+func sha256Checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// TestGetS3ChecksumsDataFromClient asserts that fingerprints from object
+// checksums and manifests are the ones of the downloaded objects, while only
+// the objects with neither are downloaded.
+func (suite *AWSTestSuite) TestGetS3ChecksumsDataFromClient() {
+	objects := map[string][]byte{
+		"a":                []byte(fakeReadmeBody),
+		"a-b/notes.txt":    []byte(fakeNotesBody),
+		"b/c/template.yml": []byte(fakeTemplateBody),
+		"b/c.txt":          []byte(fakeNotesBody),
+		"b/multipart.bin":  []byte(fakeReadmeBody),
+		"b/unsummed.txt":   []byte("no checksum\n"),
+		"b/listed.txt":     []byte("in the manifest\n"),
+		"sha256sums.txt": []byte(fmt.Sprintf("%x  ./b/listed.txt\n%x *b/c.txt\n",
+			sha256.Sum256([]byte("in the manifest\n")), sha256.Sum256([]byte("stale manifest digest\n")))),
+	}
+	checksums := map[string]string{
+		"a":                sha256Checksum(fakeReadmeBody),
+		"a-b/notes.txt":    sha256Checksum(fakeNotesBody),
+		"b/c/template.yml": sha256Checksum(fakeTemplateBody),
+		"b/c.txt":          sha256Checksum(fakeNotesBody),
+		"b/multipart.bin":  "Q2hlY2tzdW0gb2YgY2hlY2tzdW1zIG9mIHBhcnRz-2",
+	}
+
+	for _, t := range []struct {
+		name          string
+		includePaths  []string
+		excludePaths  []string
+		manifest      string
+		wantDownloads map[string]int
+	}{
+		{
+			name:     "the whole bucket downloads the manifest and the objects with no checksum and not in it",
+			manifest: "sha256sums.txt",
+			wantDownloads: map[string]int{
+				"sha256sums.txt":  1,
+				"b/multipart.bin": 1,
+				"b/unsummed.txt":  1,
+			},
+		},
+		{
+			name:          "without a manifest, the objects with no full object checksum are downloaded",
+			includePaths:  []string{"b/"},
+			wantDownloads: map[string]int{"b/multipart.bin": 1, "b/unsummed.txt": 1, "b/listed.txt": 1},
+		},
+		{
+			name:          "a filtered bucket with checksums downloads nothing",
+			excludePaths:  []string{"b/", "sha256sums.txt"},
+			wantDownloads: map[string]int{},
+		},
+		{
+			name:          "a single object is reported with its own name",
+			includePaths:  []string{"b/c/"},
+			wantDownloads: map[string]int{},
+		},
+	} {
+		suite.Run(t.name, func() {
+			want, err := getS3DataFromClient(&FakeS3Client{Bucket: fakeS3TestBucketName, Objects: objects},
+				fakeS3TestBucketName, t.includePaths, nil, t.excludePaths, nil, logger.NewStandardLogger())
+			require.NoError(suite.T(), err)
+
+			client := newCountingS3Client(&FakeS3Client{Bucket: fakeS3TestBucketName, Objects: objects, ChecksumsSHA256: checksums})
+			data, err := getS3ChecksumsDataFromClient(client, fakeS3TestBucketName, t.includePaths, nil, t.excludePaths, nil,
+				S3ChecksumsOptions{Manifest: t.manifest}, logger.NewStandardLogger())
+			require.NoError(suite.T(), err)
+			require.Equal(suite.T(), want, data)
+			require.Equal(suite.T(), t.wantDownloads, client.downloads)
+			for key := range client.heads {
+				require.Contains(suite.T(), checksums, key, "only the objects listed with a checksum are read")
+			}
+		})
+	}
+
+	suite.Run("a bucket with a .kosli_ignore file is downloaded", func() {
+		ignoring := map[string][]byte{".kosli_ignore": []byte("b\n")}
+		for key, content := range objects {
+			ignoring[key] = content
+		}
+		want, err := getS3DataFromClient(&FakeS3Client{Bucket: fakeS3TestBucketName, Objects: ignoring},
+			fakeS3TestBucketName, nil, nil, nil, nil, logger.NewStandardLogger())
+		require.NoError(suite.T(), err)
+
+		client := newCountingS3Client(&FakeS3Client{Bucket: fakeS3TestBucketName, Objects: ignoring, ChecksumsSHA256: checksums})
+		data, err := getS3ChecksumsDataFromClient(client, fakeS3TestBucketName, nil, nil, nil, nil, S3ChecksumsOptions{}, logger.NewStandardLogger())
+		require.NoError(suite.T(), err)
+		require.Equal(suite.T(), want, data)
+		require.Len(suite.T(), client.downloads, len(ignoring))
+	})
+
+	suite.Run("the artifacts of a paths spec have the fingerprints of their downloaded objects", func() {
+		spec := &S3PathsSpec{Version: 1, Artifacts: map[string]S3ArtifactPathSpec{
+			"a":    {Include: []string{"a"}},
+			"b":    {Include: []string{"b/"}, Exclude: []string{"b/unsummed.txt"}},
+			"html": {IncludeRegex: []string{`\.yml$`}},
+		}}
+		want, err := getS3PathsDataFromClient(&FakeS3Client{Bucket: fakeS3TestBucketName, Objects: objects},
+			fakeS3TestBucketName, spec, nil, logger.NewStandardLogger())
+		require.NoError(suite.T(), err)
+
+		client := newCountingS3Client(&FakeS3Client{Bucket: fakeS3TestBucketName, Objects: objects, ChecksumsSHA256: checksums})
+		data, err := getS3PathsDataFromClient(client, fakeS3TestBucketName, spec, &S3ChecksumsOptions{Manifest: "sha256sums.txt"}, logger.NewStandardLogger())
+		require.NoError(suite.T(), err)
+		require.Equal(suite.T(), want, data)
+		require.Equal(suite.T(), map[string]int{"sha256sums.txt": 1, "b/multipart.bin": 1}, client.downloads)
+	})
+
+	suite.Run("the objects modified after the manifest are downloaded", func() {
+		modified := map[string][]byte{}
+		for key, content := range objects {
+			modified[key] = content
+		}
+		modified["b/listed.txt"] = []byte("changed after the manifest\n")
+		lastModified := map[string]time.Time{"b/listed.txt": fakeS3LastModified.Add(time.Hour)}
+		want, err := getS3DataFromClient(&FakeS3Client{Bucket: fakeS3TestBucketName, Objects: modified, LastModified: lastModified},
+			fakeS3TestBucketName, []string{"b/"}, nil, nil, nil, logger.NewStandardLogger())
+		require.NoError(suite.T(), err)
+
+		client := newCountingS3Client(&FakeS3Client{Bucket: fakeS3TestBucketName, Objects: modified, ChecksumsSHA256: checksums, LastModified: lastModified})
+		data, err := getS3ChecksumsDataFromClient(client, fakeS3TestBucketName, []string{"b/"}, nil, nil, nil,
+			S3ChecksumsOptions{Manifest: "sha256sums.txt"}, logger.NewStandardLogger())
+		require.NoError(suite.T(), err)
+		require.Equal(suite.T(), want, data)
+		require.Equal(suite.T(), map[string]int{
+			"sha256sums.txt":  1,
+			"b/multipart.bin": 1,
+			"b/unsummed.txt":  1,
+			"b/listed.txt":    1,
+		}, client.downloads)
+	})
+
+	suite.Run("an invalid manifest is an error", func() {
+		client := &FakeS3Client{Bucket: fakeS3TestBucketName, Objects: map[string][]byte{
+			"a":              []byte(fakeReadmeBody),
+			"sha256sums.txt": []byte("not a digest  a\n"),
+		}}
+		_, err := getS3ChecksumsDataFromClient(client, fakeS3TestBucketName, nil, nil, nil, nil,
+			S3ChecksumsOptions{Manifest: "sha256sums.txt"}, logger.NewStandardLogger())
+		require.EqualError(suite.T(), err, `invalid line 1 of checksums manifest sha256sums.txt: "not a digest  a"`)
+	})
+}
+
 func skipIfCredsUnset(T *testing.T, requireEnvVars bool, creds *AWSStaticCreds) {
 	if requireEnvVars {
 		// skips the test case if it requires env vars and they are not set
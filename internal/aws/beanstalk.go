@@ -0,0 +1,147 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk"
+	beanstalkTypes "github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk/types"
+	"github.com/kosli-dev/cli/internal/digest"
+	"github.com/kosli-dev/cli/internal/filters"
+	"github.com/kosli-dev/cli/internal/logger"
+)
+
+// BeanstalkAPI is the subset of AWS Elastic Beanstalk operations used when
+// snapshotting environments. The real *elasticbeanstalk.Client satisfies this
+// implicitly.
+type BeanstalkAPI interface {
+	DescribeEnvironments(ctx context.Context, params *elasticbeanstalk.DescribeEnvironmentsInput, optFns ...func(*elasticbeanstalk.Options)) (*elasticbeanstalk.DescribeEnvironmentsOutput, error)
+	DescribeApplicationVersions(ctx context.Context, params *elasticbeanstalk.DescribeApplicationVersionsInput, optFns ...func(*elasticbeanstalk.Options)) (*elasticbeanstalk.DescribeApplicationVersionsOutput, error)
+}
+
+// defaultNewBeanstalkClient creates a real Elastic Beanstalk client from credentials.
+func defaultNewBeanstalkClient(creds *AWSStaticCreds) (BeanstalkAPI, error) {
+	cfg, err := creds.NewAWSConfigFromEnvOrFlags()
+	if err != nil {
+		return nil, err
+	}
+	return elasticbeanstalk.NewFromConfig(cfg), nil
+}
+
+// NewBeanstalkClientFunc is the factory used by GetBeanstalkEnvironmentsData
+// to create a BeanstalkAPI client. Tests can replace this to inject a
+// FakeBeanstalkClient.
+var NewBeanstalkClientFunc = defaultNewBeanstalkClient
+
+// ResetBeanstalkClientFactory restores the default (real AWS) client factory.
+func ResetBeanstalkClientFactory() {
+	NewBeanstalkClientFunc = defaultNewBeanstalkClient
+}
+
+// GetBeanstalkEnvironmentsData returns the Elastic Beanstalk environments
+// that filter includes, as tasks data with the fingerprint of the source
+// bundle of the application version each environment runs. The source
+// bundles are downloaded from S3 with the S3 client of NewS3ClientFunc, once
+// per application version.
+func (staticCreds *AWSStaticCreds) GetBeanstalkEnvironmentsData(filter *filters.ResourceFilterOptions, logger *logger.Logger) ([]*EcsTaskData, error) {
+	environmentsData := []*EcsTaskData{}
+	client, err := NewBeanstalkClientFunc(staticCreds)
+	if err != nil {
+		return environmentsData, fmt.Errorf("failed to create Elastic Beanstalk client: %w", err)
+	}
+
+	compiledFilter := filter.Compile()
+	environments := []beanstalkTypes.EnvironmentDescription{}
+	var nextToken *string
+	for {
+		out, err := client.DescribeEnvironments(context.TODO(), &elasticbeanstalk.DescribeEnvironmentsInput{NextToken: nextToken})
+		if err != nil {
+			return environmentsData, fmt.Errorf("failed to describe Elastic Beanstalk environments: %w", err)
+		}
+		for _, environment := range out.Environments {
+			name := aws.ToString(environment.EnvironmentName)
+			if environment.Status == beanstalkTypes.EnvironmentStatusTerminating || environment.Status == beanstalkTypes.EnvironmentStatusTerminated {
+				logger.Debug("skipping Elastic Beanstalk environment [%s] with status %s", name, environment.Status)
+				continue
+			}
+			included, err := compiledFilter.ShouldInclude(name)
+			if err != nil {
+				return environmentsData, err
+			}
+			if included {
+				environments = append(environments, environment)
+			}
+		}
+		if out.NextToken == nil {
+			break
+		}
+		nextToken = out.NextToken
+	}
+	if len(environments) == 0 {
+		return environmentsData, nil
+	}
+
+	tempDirName, err := os.MkdirTemp("", "beanstalk")
+	if err != nil {
+		return environmentsData, err
+	}
+	defer func() {
+		if err := os.RemoveAll(tempDirName); err != nil {
+			logger.Warn("failed to remove temp dir %s: %v", tempDirName, err)
+		}
+	}()
+
+	var s3 S3API
+	// fingerprints of the source bundles by application and version label
+	fingerprints := map[[2]string]string{}
+	for _, environment := range environments {
+		name := aws.ToString(environment.EnvironmentName)
+		application := aws.ToString(environment.ApplicationName)
+		versionLabel := aws.ToString(environment.VersionLabel)
+		if versionLabel == "" {
+			logger.Warn("Elastic Beanstalk environment [%s] runs no application version and has nothing to report", name)
+			continue
+		}
+
+		key := [2]string{application, versionLabel}
+		fingerprint, ok := fingerprints[key]
+		if !ok {
+			out, err := client.DescribeApplicationVersions(context.TODO(), &elasticbeanstalk.DescribeApplicationVersionsInput{
+				ApplicationName: environment.ApplicationName,
+				VersionLabels:   []string{versionLabel},
+			})
+			if err != nil {
+				return environmentsData, fmt.Errorf("failed to describe version %s of Elastic Beanstalk application %s: %w", versionLabel, application, err)
+			}
+			if len(out.ApplicationVersions) == 0 || out.ApplicationVersions[0].SourceBundle == nil {
+				logger.Warn("version %s of Elastic Beanstalk application [%s] has no source bundle to fingerprint", versionLabel, application)
+				continue
+			}
+			bundle := out.ApplicationVersions[0].SourceBundle
+
+			if s3 == nil {
+				s3, err = NewS3ClientFunc(staticCreds)
+				if err != nil {
+					return environmentsData, fmt.Errorf("failed to create S3 client: %w", err)
+				}
+			}
+			versionDir := filepath.Join(tempDirName, fmt.Sprint(len(fingerprints)))
+			bundleKey := aws.ToString(bundle.S3Key)
+			if err := downloadFileFromBucket(s3, versionDir, bundleKey, aws.ToString(bundle.S3Bucket), logger); err != nil {
+				return environmentsData, fmt.Errorf("failed to download the source bundle of version %s of Elastic Beanstalk application %s: %w", versionLabel, application, err)
+			}
+			fingerprint, err = digest.FileSha256(filepath.Join(versionDir, bundleKey), logger)
+			if err != nil {
+				return environmentsData, err
+			}
+			fingerprints[key] = fingerprint
+		}
+
+		environmentsData = append(environmentsData, NewEcsTaskData(aws.ToString(environment.EnvironmentArn), application, name,
+			map[string]string{versionLabel: fingerprint}, aws.ToTime(environment.DateUpdated)))
+	}
+	return environmentsData, nil
+}
@@ -0,0 +1,66 @@
+package aws
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	beanstalkTypes "github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk/types"
+	"github.com/kosli-dev/cli/internal/filters"
+	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeBeanstalkEnvironment(name, application, versionLabel string, status beanstalkTypes.EnvironmentStatus) beanstalkTypes.EnvironmentDescription {
+	return beanstalkTypes.EnvironmentDescription{
+		EnvironmentArn:  aws.String("arn:aws:elasticbeanstalk:eu-west-1:111111111111:environment/" + application + "/" + name),
+		EnvironmentName: aws.String(name),
+		ApplicationName: aws.String(application),
+		VersionLabel:    aws.String(versionLabel),
+		Status:          status,
+		DateUpdated:     aws.Time(time.Unix(1700000000, 0)),
+	}
+}
+
+func TestGetBeanstalkEnvironmentsData(t *testing.T) {
+	bundle := []byte("the source bundle of v1")
+	fingerprint := fmt.Sprintf("%x", sha256.Sum256(bundle))
+	beanstalk := &FakeBeanstalkClient{
+		Environments: []beanstalkTypes.EnvironmentDescription{
+			fakeBeanstalkEnvironment("shop-prod", "shop", "v1", beanstalkTypes.EnvironmentStatusReady),
+			fakeBeanstalkEnvironment("shop-blue", "shop", "v1", beanstalkTypes.EnvironmentStatusUpdating),
+			fakeBeanstalkEnvironment("shop-old", "shop", "v0", beanstalkTypes.EnvironmentStatusTerminating),
+			fakeBeanstalkEnvironment("sample", "sample", "", beanstalkTypes.EnvironmentStatusReady),
+		},
+		Versions: []beanstalkTypes.ApplicationVersionDescription{
+			{ApplicationName: aws.String("shop"), VersionLabel: aws.String("v1"), SourceBundle: &beanstalkTypes.S3Location{
+				S3Bucket: aws.String("elasticbeanstalk-eu-west-1-111111111111"), S3Key: aws.String("shop/v1.zip"),
+			}},
+		},
+	}
+	NewBeanstalkClientFunc = func(_ *AWSStaticCreds) (BeanstalkAPI, error) {
+		return beanstalk, nil
+	}
+	NewS3ClientFunc = func(_ *AWSStaticCreds) (S3API, error) {
+		return &FakeS3Client{Bucket: "elasticbeanstalk-eu-west-1-111111111111", Objects: map[string][]byte{"shop/v1.zip": bundle}}, nil
+	}
+	t.Cleanup(ResetBeanstalkClientFactory)
+	t.Cleanup(ResetS3ClientFactory)
+
+	data, err := (&AWSStaticCreds{}).GetBeanstalkEnvironmentsData(&filters.ResourceFilterOptions{}, logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Equal(t, []*EcsTaskData{
+		{TaskArn: "arn:aws:elasticbeanstalk:eu-west-1:111111111111:environment/shop/shop-prod", Cluster: "shop", Service: "shop-prod",
+			Digests: map[string]string{"v1": fingerprint}, StartedAt: 1700000000},
+		{TaskArn: "arn:aws:elasticbeanstalk:eu-west-1:111111111111:environment/shop/shop-blue", Cluster: "shop", Service: "shop-blue",
+			Digests: map[string]string{"v1": fingerprint}, StartedAt: 1700000000},
+	}, data)
+	require.Equal(t, 1, beanstalk.DescribeApplicationVersionsCalls, "a version run by several environments is fingerprinted once")
+
+	data, err = (&AWSStaticCreds{}).GetBeanstalkEnvironmentsData(&filters.ResourceFilterOptions{IncludeNames: []string{"shop-blue"}}, logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Len(t, data, 1)
+	require.Equal(t, "shop-blue", data[0].Service)
+}
@@ -0,0 +1,90 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apprunner"
+	appRunnerTypes "github.com/aws/aws-sdk-go-v2/service/apprunner/types"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	ecrTypes "github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// FakeAppRunnerClient is an in-memory implementation of AppRunnerAPI for
+// testing. It simulates NextToken pagination of ListServices and returns an
+// error when describing a service it does not have.
+type FakeAppRunnerClient struct {
+	// Services is the list of services, summarized by ListServices and
+	// returned by DescribeService.
+	Services []appRunnerTypes.Service
+	// PageSize controls how many services are returned per ListServices call.
+	// Defaults to 20 (matching the AWS default) if zero.
+	PageSize int
+}
+
+func (f *FakeAppRunnerClient) ListServices(_ context.Context, params *apprunner.ListServicesInput, _ ...func(*apprunner.Options)) (*apprunner.ListServicesOutput, error) {
+	pageSize := f.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	start := 0
+	if params.NextToken != nil {
+		parsed, err := strconv.Atoi(*params.NextToken)
+		if err != nil || parsed < 0 || parsed > len(f.Services) {
+			return nil, fmt.Errorf("invalid next token: %s", *params.NextToken)
+		}
+		start = parsed
+	}
+	end := min(start+pageSize, len(f.Services))
+
+	out := &apprunner.ListServicesOutput{}
+	for _, service := range f.Services[start:end] {
+		out.ServiceSummaryList = append(out.ServiceSummaryList, appRunnerTypes.ServiceSummary{
+			ServiceArn:  service.ServiceArn,
+			ServiceName: service.ServiceName,
+			Status:      service.Status,
+			CreatedAt:   service.CreatedAt,
+			UpdatedAt:   service.UpdatedAt,
+		})
+	}
+	if end < len(f.Services) {
+		out.NextToken = aws.String(strconv.Itoa(end))
+	}
+	return out, nil
+}
+
+func (f *FakeAppRunnerClient) DescribeService(_ context.Context, params *apprunner.DescribeServiceInput, _ ...func(*apprunner.Options)) (*apprunner.DescribeServiceOutput, error) {
+	for _, service := range f.Services {
+		if aws.ToString(service.ServiceArn) == aws.ToString(params.ServiceArn) {
+			return &apprunner.DescribeServiceOutput{Service: &service}, nil
+		}
+	}
+	// Real App Runner returns *types.ResourceNotFoundException.
+	return nil, fmt.Errorf("service not found: %s", aws.ToString(params.ServiceArn))
+}
+
+// FakeECRClient is an in-memory implementation of ECRImagesAPI for testing.
+type FakeECRClient struct {
+	// Digests maps "repository:tag" to the digest of the image, in the
+	// "sha256:<hex>" form ECR returns.
+	Digests map[string]string
+}
+
+func (f *FakeECRClient) DescribeImages(_ context.Context, params *ecr.DescribeImagesInput, _ ...func(*ecr.Options)) (*ecr.DescribeImagesOutput, error) {
+	out := &ecr.DescribeImagesOutput{}
+	for _, id := range params.ImageIds {
+		imageDigest, ok := f.Digests[aws.ToString(params.RepositoryName)+":"+aws.ToString(id.ImageTag)]
+		if !ok {
+			// Real ECR returns *types.ImageNotFoundException.
+			return nil, fmt.Errorf("image not found: %s:%s", aws.ToString(params.RepositoryName), aws.ToString(id.ImageTag))
+		}
+		out.ImageDetails = append(out.ImageDetails, ecrTypes.ImageDetail{
+			RepositoryName: params.RepositoryName,
+			ImageDigest:    aws.String(imageDigest),
+			ImageTags:      []string{aws.ToString(id.ImageTag)},
+		})
+	}
+	return out, nil
+}
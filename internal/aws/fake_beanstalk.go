@@ -0,0 +1,44 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk"
+	beanstalkTypes "github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk/types"
+)
+
+// FakeBeanstalkClient is an in-memory implementation of BeanstalkAPI for
+// testing. Source bundles are served by a FakeS3Client holding the objects
+// the application versions point at.
+type FakeBeanstalkClient struct {
+	// Environments is the list of environments returned by DescribeEnvironments.
+	Environments []beanstalkTypes.EnvironmentDescription
+	// Versions is the list of application versions returned by
+	// DescribeApplicationVersions.
+	Versions []beanstalkTypes.ApplicationVersionDescription
+	// DescribeApplicationVersionsCalls counts the DescribeApplicationVersions
+	// calls, to check that each version is described once.
+	DescribeApplicationVersionsCalls int
+}
+
+func (f *FakeBeanstalkClient) DescribeEnvironments(_ context.Context, params *elasticbeanstalk.DescribeEnvironmentsInput, _ ...func(*elasticbeanstalk.Options)) (*elasticbeanstalk.DescribeEnvironmentsOutput, error) {
+	if params.NextToken != nil {
+		return nil, fmt.Errorf("invalid next token: %s", *params.NextToken)
+	}
+	return &elasticbeanstalk.DescribeEnvironmentsOutput{Environments: f.Environments}, nil
+}
+
+func (f *FakeBeanstalkClient) DescribeApplicationVersions(_ context.Context, params *elasticbeanstalk.DescribeApplicationVersionsInput, _ ...func(*elasticbeanstalk.Options)) (*elasticbeanstalk.DescribeApplicationVersionsOutput, error) {
+	f.DescribeApplicationVersionsCalls++
+	out := &elasticbeanstalk.DescribeApplicationVersionsOutput{}
+	for _, version := range f.Versions {
+		if aws.ToString(version.ApplicationName) == aws.ToString(params.ApplicationName) &&
+			(len(params.VersionLabels) == 0 || slices.Contains(params.VersionLabels, aws.ToString(version.VersionLabel))) {
+			out.ApplicationVersions = append(out.ApplicationVersions, version)
+		}
+	}
+	return out, nil
+}
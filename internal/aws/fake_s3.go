@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -30,12 +31,20 @@ type FakeS3Client struct {
 	// LastModified maps object key to modification time. Keys without an entry
 	// report fakeS3LastModified.
 	LastModified map[string]time.Time
+	// ChecksumsSHA256 maps object key to the base64 SHA-256 additional
+	// checksum of the object. A checksum ending in -N, the number of parts of
+	// a multipart upload, is a checksum of the checksums of the parts and is
+	// reported as a COMPOSITE checksum, like real S3 does.
+	ChecksumsSHA256 map[string]string
 	// PageSize controls how many objects are returned per ListObjectsV2 call.
 	// Defaults to 1000 (matching the AWS default) if zero.
 	PageSize int
 	// ListObjectsV2Err, if set, is returned by ListObjectsV2 for any request.
 	// Useful for testing error propagation.
 	ListObjectsV2Err error
+	// HeadObjectErr, if set, is returned by HeadObject for any object.
+	// Useful for testing error propagation.
+	HeadObjectErr error
 	// DownloadObjectErr, if set, is returned by DownloadObject for any object.
 	// Useful for testing error propagation.
 	DownloadObjectErr error
@@ -66,6 +75,19 @@ func (f *FakeS3Client) lastModified(key string) time.Time {
 	return fakeS3LastModified
 }
 
+// checksumType returns the type of the SHA-256 checksum of the object of key,
+// or an empty type when it has none.
+func (f *FakeS3Client) checksumType(key string) s3Types.ChecksumType {
+	checksum, ok := f.ChecksumsSHA256[key]
+	if !ok {
+		return ""
+	}
+	if strings.Contains(checksum, "-") {
+		return s3Types.ChecksumTypeComposite
+	}
+	return s3Types.ChecksumTypeFullObject
+}
+
 func (f *FakeS3Client) ListObjectsV2(_ context.Context, params *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
 	if params.Bucket == nil {
 		return nil, fmt.Errorf("missing required field: Bucket")
@@ -114,11 +136,16 @@ func (f *FakeS3Client) ListObjectsV2(_ context.Context, params *s3.ListObjectsV2
 
 	contents := make([]s3Types.Object, 0, end-start)
 	for _, key := range keys[start:end] {
-		contents = append(contents, s3Types.Object{
+		object := s3Types.Object{
 			Key:          aws.String(key),
 			LastModified: aws.Time(f.lastModified(key)),
 			Size:         aws.Int64(int64(len(f.Objects[key]))),
-		})
+		}
+		if checksumType := f.checksumType(key); checksumType != "" {
+			object.ChecksumAlgorithm = []s3Types.ChecksumAlgorithm{s3Types.ChecksumAlgorithmSha256}
+			object.ChecksumType = checksumType
+		}
+		contents = append(contents, object)
 	}
 
 	out := &s3.ListObjectsV2Output{
@@ -135,6 +162,37 @@ func (f *FakeS3Client) ListObjectsV2(_ context.Context, params *s3.ListObjectsV2
 	return out, nil
 }
 
+func (f *FakeS3Client) HeadObject(_ context.Context, params *s3.HeadObjectInput, _ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	if params.Bucket == nil || params.Key == nil {
+		return nil, fmt.Errorf("missing required fields: Bucket and Key")
+	}
+	if *params.Bucket != f.Bucket {
+		// Real S3 returns *types.NotFound.
+		return nil, fmt.Errorf("bucket not found: %s", *params.Bucket)
+	}
+	if f.HeadObjectErr != nil {
+		return nil, f.HeadObjectErr
+	}
+	content, ok := f.Objects[*params.Key]
+	if !ok {
+		// Real S3 returns *types.NotFound.
+		return nil, fmt.Errorf("object not found: %s", *params.Key)
+	}
+
+	out := &s3.HeadObjectOutput{
+		ContentLength: aws.Int64(int64(len(content))),
+		LastModified:  aws.Time(f.lastModified(*params.Key)),
+	}
+	// Real S3 only returns checksums when they are asked for.
+	if params.ChecksumMode == s3Types.ChecksumModeEnabled {
+		if checksumType := f.checksumType(*params.Key); checksumType != "" {
+			out.ChecksumSHA256 = aws.String(f.ChecksumsSHA256[*params.Key])
+			out.ChecksumType = checksumType
+		}
+	}
+	return out, nil
+}
+
 func (f *FakeS3Client) DownloadObject(_ context.Context, params *transfermanager.DownloadObjectInput, _ ...func(*transfermanager.Options)) (*transfermanager.DownloadObjectOutput, error) {
 	if params.Bucket == nil || params.Key == nil {
 		return nil, fmt.Errorf("missing required fields: Bucket and Key")
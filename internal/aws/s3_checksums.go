@@ -0,0 +1,261 @@
+package aws
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/kosli-dev/cli/internal/digest"
+	"github.com/kosli-dev/cli/internal/logger"
+)
+
+// S3ChecksumsOptions makes snapshot s3 fingerprint the objects of a bucket
+// from their metadata instead of their downloaded content: an object is
+// fingerprinted from its full object SHA-256 checksum, or its digest in
+// Manifest, and downloaded only when it has neither.
+type S3ChecksumsOptions struct {
+	// Manifest is the key of an object of the bucket listing the SHA-256
+	// digests of objects, in the format of sha256sum: a line per object with
+	// its hex digest and its key, separated by two spaces.
+	Manifest string
+}
+
+// GetS3ChecksumsData returns a digest and metadata of the S3 bucket content
+// like GetS3Data does, without downloading the objects that have a SHA-256
+// checksum or a digest in the manifest of checksums.
+func (staticCreds *AWSStaticCreds) GetS3ChecksumsData(bucket string, includePaths, includeRegex, excludePaths, excludeRegex []string, checksums S3ChecksumsOptions, logger *logger.Logger) ([]*S3Data, error) {
+	client, err := NewS3ClientFunc(staticCreds)
+	if err != nil {
+		return []*S3Data{}, err
+	}
+	return getS3ChecksumsDataFromClient(client, bucket, includePaths, includeRegex, excludePaths, excludeRegex, checksums, logger)
+}
+
+// getS3ChecksumsDataFromClient is getS3DataFromClient with checksums, as an
+// artifact without a name of getS3ArtifactsDataFromClient.
+func getS3ChecksumsDataFromClient(client S3API, bucket string, includePaths, includeRegex, excludePaths, excludeRegex []string, checksums S3ChecksumsOptions, logger *logger.Logger) ([]*S3Data, error) {
+	includeRegexCompiled, err := compilePathRegex(includeRegex)
+	if err != nil {
+		return []*S3Data{}, err
+	}
+	excludeRegexCompiled, err := compilePathRegex(excludeRegex)
+	if err != nil {
+		return []*S3Data{}, err
+	}
+	return getS3ArtifactsDataFromClient(client, bucket, []*s3ArtifactFilter{{
+		include:      includePaths,
+		includeRegex: includeRegexCompiled,
+		exclude:      excludePaths,
+		excludeRegex: excludeRegexCompiled,
+	}}, &checksums, logger)
+}
+
+// s3ObjectDigests gets the hex SHA-256 digests of the content of the listed
+// objects of a bucket, each object once.
+type s3ObjectDigests struct {
+	client  S3API
+	bucket  string
+	dir     string
+	logger  *logger.Logger
+	listed  map[string]s3Types.Object
+	digests map[string]string
+	// manifest is the digests of the manifest of checksums by key
+	manifest map[string]string
+	// manifestKey and manifestModified are the key of the manifest of
+	// checksums and when it was last modified, as listed
+	manifestKey      string
+	manifestModified time.Time
+	// downloaded is the keys of the objects downloaded to dir
+	downloaded map[string]bool
+	downloads  int
+}
+
+func newS3ObjectDigests(client S3API, bucket, dir string, logger *logger.Logger) *s3ObjectDigests {
+	return &s3ObjectDigests{
+		client:     client,
+		bucket:     bucket,
+		dir:        dir,
+		logger:     logger,
+		listed:     map[string]s3Types.Object{},
+		digests:    map[string]string{},
+		manifest:   map[string]string{},
+		downloaded: map[string]bool{},
+	}
+}
+
+// download downloads the object of key to dir, unless it already is.
+func (o *s3ObjectDigests) download(key string) error {
+	if o.downloaded[key] {
+		return nil
+	}
+	if err := downloadFileFromBucket(o.client, o.dir, key, o.bucket, o.logger); err != nil {
+		return err
+	}
+	o.downloaded[key] = true
+	o.downloads++
+	return nil
+}
+
+// digest returns the digest of the object of key from its full object
+// SHA-256 checksum, from the manifest, or from its downloaded content, in
+// this order.
+func (o *s3ObjectDigests) digest(key string) (string, error) {
+	if sha256, ok := o.digests[key]; ok {
+		return sha256, nil
+	}
+	sha256, err := o.checksumDigest(key)
+	if err != nil {
+		return "", err
+	}
+	if sha256 == "" {
+		sha256 = o.manifestDigest(key)
+	}
+	if sha256 == "" {
+		o.logger.Debug("object %s has no SHA-256 checksum and is downloaded", key)
+		if err := o.download(key); err != nil {
+			return "", err
+		}
+		sha256, err = digest.FileSha256(filepath.Join(o.dir, key), o.logger)
+		if err != nil {
+			return "", err
+		}
+	}
+	o.digests[key] = sha256
+	return sha256, nil
+}
+
+// checksumDigest returns the hex digest of the full object SHA-256 checksum
+// of the object of key, or an empty digest when it has none. The checksum of
+// an object uploaded in parts is a checksum of the checksums of its parts,
+// unless it is a full object checksum, and is not a digest of its content.
+func (o *s3ObjectDigests) checksumDigest(key string) (string, error) {
+	object := o.listed[key]
+	if !hasSHA256Checksum(object) || object.ChecksumType == s3Types.ChecksumTypeComposite {
+		return "", nil
+	}
+	out, err := o.client.HeadObject(context.TODO(), &s3.HeadObjectInput{
+		Bucket:       aws.String(o.bucket),
+		Key:          aws.String(key),
+		ChecksumMode: s3Types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get the checksum of object %s: %w", key, err)
+	}
+	checksum := aws.ToString(out.ChecksumSHA256)
+	if checksum == "" || out.ChecksumType == s3Types.ChecksumTypeComposite || strings.Contains(checksum, "-") {
+		return "", nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(checksum)
+	if err != nil || len(decoded) != 32 {
+		return "", fmt.Errorf("invalid SHA-256 checksum %q of object %s", checksum, key)
+	}
+	return hex.EncodeToString(decoded), nil
+}
+
+func hasSHA256Checksum(object s3Types.Object) bool {
+	for _, algorithm := range object.ChecksumAlgorithm {
+		if algorithm == s3Types.ChecksumAlgorithmSha256 {
+			return true
+		}
+	}
+	return false
+}
+
+// artifactDigest returns the fingerprint of the objects of keys, which is
+// the digest of the object when there is one, and the digest of the
+// directory of the objects otherwise.
+func (o *s3ObjectDigests) artifactDigest(keys []string) (string, error) {
+	fileDigests := make(map[string]string, len(keys))
+	for _, key := range keys {
+		sha256, err := o.digest(key)
+		if err != nil {
+			return "", err
+		}
+		fileDigests[key] = sha256
+	}
+	if len(keys) == 1 {
+		return fileDigests[keys[0]], nil
+	}
+	return digest.DirSha256FromFileDigests(fileDigests)
+}
+
+// downloadedArtifactDigest returns the fingerprint of the objects of keys
+// from their downloaded content, linked into artifactDir.
+func (o *s3ObjectDigests) downloadedArtifactDigest(keys []string, artifactDir string) (string, error) {
+	for _, key := range keys {
+		if err := o.download(key); err != nil {
+			return "", err
+		}
+		if err := linkOrCopyFile(filepath.Join(o.dir, key), filepath.Join(artifactDir, key)); err != nil {
+			return "", err
+		}
+	}
+	if len(keys) == 1 {
+		return digest.FileSha256(filepath.Join(artifactDir, keys[0]), o.logger)
+	}
+	return digest.DirSha256(artifactDir, []string{}, o.logger)
+}
+
+// manifestDigest returns the digest of the object of key in the manifest, or
+// an empty digest when it is not in the manifest or was modified after it,
+// as the digest of an object modified after the manifest can be stale.
+func (o *s3ObjectDigests) manifestDigest(key string) string {
+	sha256, ok := o.manifest[key]
+	if !ok {
+		return ""
+	}
+	if modified := aws.ToTime(o.listed[key].LastModified); modified.After(o.manifestModified) {
+		o.logger.Debug("object %s was modified after checksums manifest %s, so its digest in the manifest is not used",
+			key, o.manifestKey)
+		return ""
+	}
+	return sha256
+}
+
+// loadManifest downloads the manifest of checksums of key and reads the
+// digests it lists.
+func (o *s3ObjectDigests) loadManifest(key string) error {
+	if err := o.download(key); err != nil {
+		return fmt.Errorf("failed to download checksums manifest %s: %w", key, err)
+	}
+	o.manifestKey = key
+	o.manifestModified = aws.ToTime(o.listed[key].LastModified)
+	file, err := os.Open(filepath.Join(o.dir, key))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		// sha256sum separates the digest and the file name with a space, and
+		// a space or a * for binary mode
+		sha256, name, ok := strings.Cut(text, " ")
+		if strings.HasPrefix(name, " ") || strings.HasPrefix(name, "*") {
+			name = name[1:]
+		}
+		name = strings.TrimPrefix(name, "./")
+		if !ok || name == "" || digest.ValidateDigest(strings.ToLower(sha256)) != nil {
+			return fmt.Errorf("invalid line %d of checksums manifest %s: %q", line, key, text)
+		}
+		o.manifest[name] = strings.ToLower(sha256)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read checksums manifest %s: %w", key, err)
+	}
+	o.logger.Debug("checksums manifest %s lists %d objects", key, len(o.manifest))
+	return nil
+}
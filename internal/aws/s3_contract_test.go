@@ -10,6 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/kosli-dev/cli/internal/testHelpers"
 	"github.com/stretchr/testify/require"
 )
@@ -19,7 +20,7 @@ var errInjected = errors.New("injected error")
 
 // runS3ContractTests exercises the S3API contract. It verifies the behaviours
 // we depend on — object listing, continuation-token pagination, object
-// download, and error responses for missing buckets and keys.
+// metadata, object download, and error responses for missing buckets and keys.
 //
 // Any implementation that passes this suite is a valid stand-in for the real
 // AWS S3 API as far as this codebase is concerned.
@@ -80,6 +81,27 @@ func runS3ContractTests(t *testing.T, client S3API, bucket, existingKey string)
 		require.Error(t, err)
 	})
 
+	t.Run("HeadObject returns the object size without its body", func(t *testing.T) {
+		out, err := client.HeadObject(context.TODO(), &s3.HeadObjectInput{
+			Bucket:       aws.String(bucket),
+			Key:          aws.String(existingKey),
+			ChecksumMode: s3Types.ChecksumModeEnabled,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, out)
+		require.NotNil(t, out.ContentLength, "ContentLength should be present")
+		require.Positive(t, *out.ContentLength)
+		require.NotNil(t, out.LastModified, "LastModified should be present")
+	})
+
+	t.Run("HeadObject errors for a missing key", func(t *testing.T) {
+		_, err := client.HeadObject(context.TODO(), &s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String("nonexistent-key-that-should-not-exist"),
+		})
+		require.Error(t, err)
+	})
+
 	t.Run("DownloadObject writes the object body to the WriterAt", func(t *testing.T) {
 		file, err := os.Create(filepath.Join(t.TempDir(), "downloaded"))
 		require.NoError(t, err)
@@ -138,6 +160,36 @@ func TestS3Contract_Fake(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("HeadObject returns error when HeadObjectErr is injected", func(t *testing.T) {
+		client.HeadObjectErr = errInjected
+		defer func() { client.HeadObjectErr = nil }()
+		_, err := client.HeadObject(context.TODO(), &s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String("README.md"),
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("HeadObject returns checksums only when checksum mode is enabled", func(t *testing.T) {
+		client.ChecksumsSHA256 = map[string]string{"README.md": "checksum"}
+		defer func() { client.ChecksumsSHA256 = nil }()
+		out, err := client.HeadObject(context.TODO(), &s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String("README.md"),
+		})
+		require.NoError(t, err)
+		require.Nil(t, out.ChecksumSHA256)
+
+		out, err = client.HeadObject(context.TODO(), &s3.HeadObjectInput{
+			Bucket:       aws.String(bucket),
+			Key:          aws.String("README.md"),
+			ChecksumMode: s3Types.ChecksumModeEnabled,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "checksum", aws.ToString(out.ChecksumSHA256))
+		require.Equal(t, s3Types.ChecksumTypeFullObject, out.ChecksumType)
+	})
+
 	t.Run("DownloadObject returns error when DownloadObjectErr is injected", func(t *testing.T) {
 		client.DownloadObjectErr = errInjected
 		defer func() { client.DownloadObjectErr = nil }()
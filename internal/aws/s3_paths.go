@@ -0,0 +1,210 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/kosli-dev/cli/internal/logger"
+)
+
+// S3ArtifactPathSpec specifies the objects of an S3 bucket that make up an
+// artifact: the objects matching one of Include (literal prefixes) or
+// IncludeRegex (Go regular expressions matched against the full key), except
+// those matching one of Exclude or ExcludeRegex.
+type S3ArtifactPathSpec struct {
+	Include      []string `mapstructure:"include" validate:"required_without=IncludeRegex"`
+	IncludeRegex []string `mapstructure:"include_regex" validate:"required_without=Include"`
+	Exclude      []string `mapstructure:"exclude"`
+	ExcludeRegex []string `mapstructure:"exclude_regex"`
+}
+
+// S3PathsSpec specifies a list of artifacts in an S3 bucket, like
+// server.PathsSpec does for filesystem paths.
+type S3PathsSpec struct {
+	Version   int                           `mapstructure:"version" validate:"required,oneof=1"`
+	Artifacts map[string]S3ArtifactPathSpec `mapstructure:"artifacts" validate:"required,min=1,dive"`
+}
+
+// s3ArtifactFilter is an S3ArtifactPathSpec with its regex patterns compiled.
+// An artifact without a name is the filtered content of the bucket, named
+// after the bucket, or after its object when it has a single one, like the
+// artifact of GetS3Data.
+type s3ArtifactFilter struct {
+	name         string
+	include      []string
+	includeRegex []*regexp.Regexp
+	exclude      []string
+	excludeRegex []*regexp.Regexp
+	keys         []string
+	lastModified *time.Time
+}
+
+func (f *s3ArtifactFilter) matches(key string) bool {
+	if f.name == "" {
+		return !shouldExcludePath(key, f.include, f.includeRegex, f.exclude, f.excludeRegex)
+	}
+	return objectMatchesFilter(key, f.include, f.includeRegex) && !objectMatchesFilter(key, f.exclude, f.excludeRegex)
+}
+
+// GetS3PathsData returns an S3Data per artifact of spec in bucket. The
+// objects are fingerprinted from their checksums, unless checksums is nil.
+func (staticCreds *AWSStaticCreds) GetS3PathsData(bucket string, spec *S3PathsSpec, checksums *S3ChecksumsOptions, logger *logger.Logger) ([]*S3Data, error) {
+	client, err := NewS3ClientFunc(staticCreds)
+	if err != nil {
+		return []*S3Data{}, err
+	}
+	return getS3PathsDataFromClient(client, bucket, spec, checksums, logger)
+}
+
+// getS3PathsDataFromClient compiles the artifacts of spec, sorted by name,
+// and fingerprints them with getS3ArtifactsDataFromClient.
+func getS3PathsDataFromClient(client S3API, bucket string, spec *S3PathsSpec, checksums *S3ChecksumsOptions, logger *logger.Logger) ([]*S3Data, error) {
+	names := make([]string, 0, len(spec.Artifacts))
+	for name := range spec.Artifacts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	artifacts := make([]*s3ArtifactFilter, 0, len(names))
+	for _, name := range names {
+		pathSpec := spec.Artifacts[name]
+		includeRegex, err := compilePathRegex(pathSpec.IncludeRegex)
+		if err != nil {
+			return []*S3Data{}, fmt.Errorf("artifact [%s]: %w", name, err)
+		}
+		excludeRegex, err := compilePathRegex(pathSpec.ExcludeRegex)
+		if err != nil {
+			return []*S3Data{}, fmt.Errorf("artifact [%s]: %w", name, err)
+		}
+		artifacts = append(artifacts, &s3ArtifactFilter{
+			name:         name,
+			include:      pathSpec.Include,
+			includeRegex: includeRegex,
+			exclude:      pathSpec.Exclude,
+			excludeRegex: excludeRegex,
+		})
+	}
+	return getS3ArtifactsDataFromClient(client, bucket, artifacts, checksums, logger)
+}
+
+// getS3ArtifactsDataFromClient lists bucket once and gets the digest of each
+// object once, even when it is part of several artifacts. Each artifact is
+// fingerprinted like the content of bucket filtered to the artifact, so that
+// the fingerprint of an artifact is the one of snapshot s3 with the same
+// filters. The objects are downloaded when checksums is nil.
+func getS3ArtifactsDataFromClient(client S3API, bucket string, artifacts []*s3ArtifactFilter, checksums *S3ChecksumsOptions, logger *logger.Logger) ([]*S3Data, error) {
+	s3Data := []*S3Data{}
+
+	tempDirName, err := os.MkdirTemp("", "bucketContent")
+	if err != nil {
+		return s3Data, err
+	}
+	defer func() {
+		if err := os.RemoveAll(tempDirName); err != nil {
+			logger.Warn("failed to remove temp dir %s: %v", tempDirName, err)
+		}
+	}()
+	objects := newS3ObjectDigests(client, bucket, filepath.Join(tempDirName, "objects"), logger)
+
+	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{Bucket: aws.String(bucket)})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return s3Data, err
+		}
+
+		for _, object := range page.Contents {
+			if strings.HasSuffix(*object.Key, "/") { // skip folders
+				continue
+			}
+			objects.listed[*object.Key] = object
+			for _, artifact := range artifacts {
+				if !artifact.matches(*object.Key) {
+					continue
+				}
+				artifact.keys = append(artifact.keys, *object.Key)
+				if artifact.lastModified == nil || object.LastModified.After(*artifact.lastModified) {
+					artifact.lastModified = object.LastModified
+				}
+			}
+		}
+	}
+
+	if checksums != nil && checksums.Manifest != "" {
+		if err := objects.loadManifest(checksums.Manifest); err != nil {
+			return s3Data, err
+		}
+	}
+
+	for i, artifact := range artifacts {
+		if len(artifact.keys) == 0 {
+			if artifact.name == "" {
+				return s3Data, fmt.Errorf("no matching file or dirs in bucket: [%s]", bucket)
+			}
+			return s3Data, fmt.Errorf("no matching file or dirs in bucket: [%s] for artifact [%s]", bucket, artifact.name)
+		}
+		name := artifact.name
+		if name == "" {
+			name = bucket
+			if len(artifact.keys) == 1 {
+				name = path.Base(artifact.keys[0])
+			}
+		}
+
+		var sha256 string
+		if checksums != nil && !slices.Contains(artifact.keys, ".kosli_ignore") {
+			sha256, err = objects.artifactDigest(artifact.keys)
+		} else {
+			if checksums != nil {
+				logger.Info("artifact [%s] has a .kosli_ignore file and is fingerprinted from its downloaded objects", name)
+			}
+			// the artifact gets a directory of its own objects, linked to the
+			// downloaded ones, to be fingerprinted on its own
+			sha256, err = objects.downloadedArtifactDigest(artifact.keys, filepath.Join(tempDirName, "artifacts", fmt.Sprint(i)))
+		}
+		if err != nil {
+			return s3Data, fmt.Errorf("failed to calculate fingerprint for artifact [%s]: %v", name, err)
+		}
+		logger.Debug("fingerprint for artifact [%s]: %s", name, sha256)
+		s3Data = append(s3Data, &S3Data{Digests: map[string]string{name: sha256}, LastModifiedTimestamp: artifact.lastModified.Unix()})
+	}
+
+	if checksums != nil {
+		logger.Debug("%d objects of bucket %s without a SHA-256 checksum were downloaded", objects.downloads, bucket)
+	}
+	return s3Data, nil
+}
+
+// linkOrCopyFile hard links source to target, creating the directories of
+// target, and copies source when it cannot be linked.
+func linkOrCopyFile(source, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if err := os.Link(source, target); err == nil {
+		return nil
+	}
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -2,11 +2,14 @@ package azure
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 
+	"github.com/kosli-dev/cli/internal/ratelimit"
 	"github.com/kosli-dev/cli/internal/types"
 	"github.com/microsoft/azure-devops-go-api/azuredevops"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
@@ -55,6 +58,27 @@ func NewAzureClientFromToken(ctx context.Context, azToken, orgURL string) (git.C
 	return gitClient, nil
 }
 
+// rateLimited reports whether err is an Azure DevOps API response saying the
+// rate limit is exceeded. The Azure DevOps client does not accept an HTTP
+// transport, so its calls are retried with ratelimit.Retry.
+func rateLimited(err error) bool {
+	// the client returns both WrappedError and *WrappedError
+	var status *int
+	var ptr *azuredevops.WrappedError
+	var value azuredevops.WrappedError
+	if errors.As(err, &ptr) && ptr != nil {
+		status = ptr.StatusCode
+	} else if errors.As(err, &value) {
+		status = value.StatusCode
+	}
+	return status != nil && *status == http.StatusTooManyRequests
+}
+
+// CacheScope identifies the repository for the pull request cache.
+func (c *AzureConfig) CacheScope() string {
+	return "azure|" + c.OrgURL + "|" + c.Project + "/" + c.Repository
+}
+
 func (c *AzureConfig) ProviderAndLabel() (string, string) {
 	return "azure", "pull request"
 }
@@ -163,11 +187,13 @@ func (c *AzureConfig) GetPullRequestCommits(pr git.GitPullRequest) ([]types.Comm
 		return commits, err
 	}
 
-	prCommitsResponse, err := client.GetPullRequestCommits(ctx, git.GetPullRequestCommitsArgs{
-		RepositoryId:  &c.Repository,
-		PullRequestId: pr.PullRequestId,
-		Project:       &c.Project,
-	})
+	prCommitsResponse, err := ratelimit.Retry(func() (*git.GetPullRequestCommitsResponseValue, error) {
+		return client.GetPullRequestCommits(ctx, git.GetPullRequestCommitsArgs{
+			RepositoryId:  &c.Repository,
+			PullRequestId: pr.PullRequestId,
+			Project:       &c.Project,
+		})
+	}, rateLimited)
 	if err != nil {
 		return commits, err
 	}
@@ -180,11 +206,15 @@ func (c *AzureConfig) GetPullRequestCommits(pr git.GitPullRequest) ([]types.Comm
 }
 
 // commitFromAzureCommit maps an Azure DevOps API commit to a types.Commit.
+// Commits have no account, so the email of the author stands for the account
+// whose unique name it is, as the unique name of an Entra ID account is its
+// email.
 func commitFromAzureCommit(commit git.GitCommitRef, branch string) types.Commit {
 	return types.Commit{
 		SHA:       *commit.CommitId,
 		Message:   *commit.Comment,
 		Author:    fmt.Sprintf("%s <%s>", *commit.Author.Name, *commit.Author.Email),
+		AuthorID:  *commit.Author.Email,
 		Timestamp: commit.Author.Date.Time.Unix(),
 		URL:       *commit.Url,
 		Branch:    branch,
@@ -199,18 +229,20 @@ func (c *AzureConfig) PullRequestsForCommit(commit string) ([]git.GitPullRequest
 		return []git.GitPullRequest{}, err
 	}
 
-	prQuery, err := client.GetPullRequestQuery(ctx, git.GetPullRequestQueryArgs{
-		Queries: &git.GitPullRequestQuery{
-			Queries: &[]git.GitPullRequestQueryInput{
-				{
-					Items: &[]string{commit},
-					Type:  &git.GitPullRequestQueryTypeValues.LastMergeCommit,
+	prQuery, err := ratelimit.Retry(func() (*git.GitPullRequestQuery, error) {
+		return client.GetPullRequestQuery(ctx, git.GetPullRequestQueryArgs{
+			Queries: &git.GitPullRequestQuery{
+				Queries: &[]git.GitPullRequestQueryInput{
+					{
+						Items: &[]string{commit},
+						Type:  &git.GitPullRequestQueryTypeValues.LastMergeCommit,
+					},
 				},
 			},
-		},
-		RepositoryId: &c.Repository,
-		Project:      &c.Project,
-	})
+			RepositoryId: &c.Repository,
+			Project:      &c.Project,
+		})
+	}, rateLimited)
 	if err != nil {
 		return nil, err
 	}
@@ -225,6 +257,17 @@ func (c *AzureConfig) PullRequestsForCommit(commit string) ([]git.GitPullRequest
 	return []git.GitPullRequest{}, nil
 }
 
+// azureEmailAccount returns uniqueName when it is an email, which commit
+// authors can be matched with. The unique names of Azure DevOps Server
+// accounts are "DOMAIN\user" instead, which match no commit author, so they
+// are left out rather than taken for someone else.
+func azureEmailAccount(uniqueName string) string {
+	if !strings.Contains(uniqueName, "@") {
+		return ""
+	}
+	return uniqueName
+}
+
 // GetPullRequestApprovers returns a list of approvers for a given pull request
 func (c *AzureConfig) GetPullRequestApprovers(prNumber, version int) ([]any, error) {
 	var approvers []any
@@ -234,11 +277,13 @@ func (c *AzureConfig) GetPullRequestApprovers(prNumber, version int) ([]any, err
 		return approvers, err
 	}
 
-	reviewers, err := client.GetPullRequestReviewers(ctx, git.GetPullRequestReviewersArgs{
-		RepositoryId:  &c.Repository,
-		PullRequestId: &prNumber,
-		Project:       &c.Project,
-	})
+	reviewers, err := ratelimit.Retry(func() (*[]git.IdentityRefWithVote, error) {
+		return client.GetPullRequestReviewers(ctx, git.GetPullRequestReviewersArgs{
+			RepositoryId:  &c.Repository,
+			PullRequestId: &prNumber,
+			Project:       &c.Project,
+		})
+	}, rateLimited)
 	if err != nil {
 		return approvers, err
 	}
@@ -251,6 +296,7 @@ func (c *AzureConfig) GetPullRequestApprovers(prNumber, version int) ([]any, err
 			} else {
 				approvers = append(approvers, types.PRApprovals{
 					Username: approverName,
+					UserID:   azureEmailAccount(*r.UniqueName),
 					State:    "APPROVED",
 				})
 			}
@@ -190,23 +190,55 @@ func (azureClient *AzureClient) getBearerToken(logger *logger.Logger) (string, e
 }
 
 // downloadAppPackage downloads the zip package of a non-docker web app
-func downloadAppPackage(appName, bearerToken, destination string) error {
+func downloadAppPackage(appName, bearerToken, destination string, logger *logger.Logger) error {
 	kuduZipURL := fmt.Sprintf("https://%s.scm.azurewebsites.net/api/zip/site/wwwroot/", appName)
 	req, err := http.NewRequest("GET", kuduZipURL, nil)
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Authorization", "Bearer "+bearerToken)
+	return downloadPackage(req, appName, destination, logger)
+}
+
+// downloadPackageFromURL downloads the zip package of an app which runs from
+// a package URL, such as a blob URL with a SAS token
+func downloadPackageFromURL(appName, packageURL, destination string, logger *logger.Logger) error {
+	req, err := http.NewRequest("GET", packageURL, nil)
+	if err != nil {
+		// the error quotes the URL, and so its SAS token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("invalid package URL for app [%s]: %v", appName, err)
+	}
+	return downloadPackage(req, appName, destination, logger)
+}
 
+// withoutQuery returns u without its query, which holds the SAS token of a
+// package URL, so that it can be shown in errors and logs.
+func withoutQuery(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	redacted.RawQuery = ""
+	redacted.Fragment = ""
+	return redacted.String()
+}
+
+func downloadPackage(req *http.Request, appName, destination string, logger *logger.Logger) error {
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return err
+		// the error quotes the URL, and so the SAS token of a package URL
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to download package for app [%s] from %s: %v", appName, withoutQuery(req.URL), err)
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			// Log warning for cleanup error
-			fmt.Printf("warning: failed to close response body: %v\n", err)
+			logger.Warn("failed to close response body: %v", err)
 		}
 	}()
 	if resp.StatusCode == http.StatusServiceUnavailable {
@@ -222,8 +254,7 @@ func downloadAppPackage(appName, bearerToken, destination string) error {
 	}
 	defer func() {
 		if err := out.Close(); err != nil {
-			// Log warning for cleanup error
-			fmt.Printf("warning: failed to close file %s: %v\n", destination, err)
+			logger.Warn("failed to close file %s: %v", destination, err)
 		}
 	}()
 
@@ -252,7 +283,7 @@ func (azureClient *AzureClient) fingerprintZipService(app *armappservice.Site, l
 	}()
 
 	packagePath := filepath.Join(tmpDir, *app.Name+".zip")
-	err = downloadAppPackage(*app.Name, token, packagePath)
+	err = downloadAppPackage(*app.Name, token, packagePath, logger)
 	if err != nil {
 		if err == ErrAppUnavailable {
 			logger.Debug("app %s is unavailable (503), skipping", *app.Name)
@@ -261,24 +292,7 @@ func (azureClient *AzureClient) fingerprintZipService(app *armappservice.Site, l
 		return AppData{}, err
 	}
 
-	// unzip the downloaded package
-	destDir := filepath.Join(tmpDir, "extracted")
-	err = unzip(packagePath, destDir, logger)
-	if err != nil {
-		return AppData{}, fmt.Errorf("failed to unzip downloaded package for app [%s]: %v", *app.Name, err)
-	}
-
-	//  fingerprint the downloaded and unzipped package
-	ps := &server.PathsSpec{
-		Version: 1,
-		Artifacts: map[string]server.ArtifactPathSpec{
-			*app.Name: {
-				Path: destDir,
-			},
-		},
-	}
-
-	artifacts, err := server.CreatePathsArtifactsData(ps, logger)
+	digests, err := fingerprintAppPackage(*app.Name, packagePath, logger)
 	if err != nil {
 		return AppData{}, err
 	}
@@ -304,7 +318,34 @@ func (azureClient *AzureClient) fingerprintZipService(app *armappservice.Site, l
 	// if deploymentTime != nil {
 	// 	startedAt = deploymentTime.Unix()
 	// }
-	return AppData{*app.Name, *app.Kind, "kosli-cli", artifacts[0].Digests, 0}, nil
+	return AppData{*app.Name, *app.Kind, "kosli-cli", digests, 0}, nil
+}
+
+// fingerprintAppPackage fingerprints the content of the downloaded zip
+// package of an app, unzipped next to it.
+func fingerprintAppPackage(appName, packagePath string, logger *logger.Logger) (map[string]string, error) {
+	// unzip the downloaded package
+	destDir := filepath.Join(filepath.Dir(packagePath), "extracted")
+	err := unzip(packagePath, destDir, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unzip downloaded package for app [%s]: %v", appName, err)
+	}
+
+	//  fingerprint the downloaded and unzipped package
+	ps := &server.PathsSpec{
+		Version: 1,
+		Artifacts: map[string]server.ArtifactPathSpec{
+			appName: {
+				Path: destDir,
+			},
+		},
+	}
+
+	artifacts, err := server.CreatePathsArtifactsData(ps, logger)
+	if err != nil {
+		return nil, err
+	}
+	return artifacts[0].Digests, nil
 }
 
 // unzip extracts a zip archive to a specified destination directory.
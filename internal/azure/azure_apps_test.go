@@ -53,7 +53,7 @@ func (suite *AzureAppsTestSuite) TestCanDownloadAppZip() {
 		}
 	}()
 	dest := filepath.Join(tmpDir, appName+".zip")
-	err = downloadAppPackage(appName, token, dest)
+	err = downloadAppPackage(appName, token, dest, logger.NewStandardLogger())
 	require.NoError(suite.T(), err)
 
 	// check download file exists
@@ -0,0 +1,186 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/kosli-dev/cli/internal/logger"
+)
+
+// containerAppsAPIVersion is the version of the Microsoft.App resource
+// provider API the container apps are listed with.
+const containerAppsAPIVersion = "2024-03-01"
+
+// containerApp is an Azure Container App, as listed by the Microsoft.App
+// resource provider.
+type containerApp struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// containerAppRevision is a revision of an Azure Container App.
+type containerAppRevision struct {
+	Name       string `json:"name"`
+	Properties struct {
+		CreatedTime   time.Time `json:"createdTime"`
+		Active        bool      `json:"active"`
+		TrafficWeight int32     `json:"trafficWeight"`
+		Template      struct {
+			Containers []struct {
+				Name  string `json:"name"`
+				Image string `json:"image"`
+			} `json:"containers"`
+		} `json:"template"`
+	} `json:"properties"`
+}
+
+// containerAppsAPI is the unexported seam that lets tests substitute a fake
+// for the Microsoft.App resource provider.
+type containerAppsAPI interface {
+	// listContainerApps lists the container apps of resourceGroup, or of the
+	// subscription when resourceGroup is empty.
+	listContainerApps(ctx context.Context, resourceGroup string) ([]containerApp, error)
+	listRevisions(ctx context.Context, appID string) ([]containerAppRevision, error)
+}
+
+// GetAzureContainerAppsData returns the container apps of the resource group,
+// or of the subscription when no resource group is given, each with the
+// digests of the images of its revisions that receive traffic. The digests
+// of tag-pinned images are looked up in their Azure Container Registry.
+func (staticCreds *AzureStaticCredentials) GetAzureContainerAppsData(logger *logger.Logger) ([]*AppData, error) {
+	credentials, err := azidentity.NewClientSecretCredential(staticCreds.TenantId, staticCreds.ClientId, staticCreds.ClientSecret, nil)
+	if err != nil {
+		return nil, err
+	}
+	armClient, err := arm.NewClient("kosli-cli/containerapps", "v1.0.0", credentials, nil)
+	if err != nil {
+		return nil, err
+	}
+	api := &armContainerAppsAPI{client: armClient, subscriptionID: staticCreds.SubscriptionId}
+	azureClient := &AzureClient{Credentials: *staticCreds}
+	fingerprintImage := func(imageName string) (string, error) {
+		return azureClient.GetImageFingerprintFromRegistry(imageName, logger)
+	}
+	return getContainerAppsData(context.Background(), api, fingerprintImage, staticCreds.ResourceGroupName, logger)
+}
+
+func getContainerAppsData(ctx context.Context, api containerAppsAPI, fingerprintImage func(imageName string) (string, error),
+	resourceGroup string, logger *logger.Logger) ([]*AppData, error) {
+	apps, err := api.listContainerApps(ctx, resourceGroup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list container apps: %w", err)
+	}
+	logger.Debug("found %d container apps", len(apps))
+
+	appsData := make([]*AppData, 0, len(apps))
+	for _, app := range apps {
+		revisions, err := api.listRevisions(ctx, app.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the revisions of container app %s: %w", app.Name, err)
+		}
+		data := &AppData{
+			AppName:       app.Name,
+			AppKind:       "containerapp",
+			DigestsSource: "acr",
+			Digests:       map[string]string{},
+		}
+		slices.SortFunc(revisions, func(a, b containerAppRevision) int { return strings.Compare(a.Name, b.Name) })
+		for _, revision := range revisions {
+			if !revision.Properties.Active || revision.Properties.TrafficWeight == 0 {
+				logger.Debug("revision %s of container app %s receives no traffic, skipping from report", revision.Name, app.Name)
+				continue
+			}
+			for _, container := range revision.Properties.Template.Containers {
+				if _, ok := data.Digests[container.Image]; ok {
+					continue
+				}
+				fingerprint, err := containerImageFingerprint(container.Image, fingerprintImage)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get the digest of image %s of container app %s: %w", container.Image, app.Name, err)
+				}
+				data.Digests[container.Image] = fingerprint
+			}
+			data.StartedAt = max(data.StartedAt, revision.Properties.CreatedTime.Unix())
+		}
+		if len(data.Digests) == 0 {
+			logger.Debug("container app %s has no revision receiving traffic, skipping from report", app.Name)
+			continue
+		}
+		logger.Debug("For container app %s found: digests=%v, startedAt=%d", app.Name, data.Digests, data.StartedAt)
+		appsData = append(appsData, data)
+	}
+	return appsData, nil
+}
+
+// containerImageFingerprint returns the digest of a digest-pinned image from
+// its name, and looks up the digest of a tag-pinned image.
+func containerImageFingerprint(imageName string, fingerprintImage func(imageName string) (string, error)) (string, error) {
+	if _, digest, ok := strings.Cut(imageName, "@sha256:"); ok {
+		return digest, nil
+	}
+	return fingerprintImage(imageName)
+}
+
+// armContainerAppsAPI is the production containerAppsAPI backed by the
+// Microsoft.App resource provider REST API.
+type armContainerAppsAPI struct {
+	client         *arm.Client
+	subscriptionID string
+}
+
+func (a *armContainerAppsAPI) listContainerApps(ctx context.Context, resourceGroup string) ([]containerApp, error) {
+	path := "/subscriptions/" + url.PathEscape(a.subscriptionID)
+	if resourceGroup != "" {
+		path += "/resourceGroups/" + url.PathEscape(resourceGroup)
+	}
+	return listARMResources[containerApp](ctx, a.client, path+"/providers/Microsoft.App/containerApps")
+}
+
+func (a *armContainerAppsAPI) listRevisions(ctx context.Context, appID string) ([]containerAppRevision, error) {
+	return listARMResources[containerAppRevision](ctx, a.client, appID+"/revisions")
+}
+
+// listARMResources lists the resources of an Azure Resource Manager list
+// operation, following its pages.
+func listARMResources[T any](ctx context.Context, client *arm.Client, path string) ([]T, error) {
+	requestURL, err := url.Parse(strings.TrimSuffix(client.Endpoint(), "/") + path)
+	if err != nil {
+		return nil, err
+	}
+	query := requestURL.Query()
+	query.Set("api-version", containerAppsAPIVersion)
+	requestURL.RawQuery = query.Encode()
+
+	var resources []T
+	for next := requestURL.String(); next != ""; {
+		req, err := runtime.NewRequest(ctx, http.MethodGet, next)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Pipeline().Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if !runtime.HasStatusCode(resp, http.StatusOK) {
+			return nil, runtime.NewResponseError(resp)
+		}
+		var page struct {
+			Value    []T    `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := runtime.UnmarshalAsJSON(resp, &page); err != nil {
+			return nil, err
+		}
+		resources = append(resources, page.Value...)
+		next = page.NextLink
+	}
+	return resources, nil
+}
@@ -0,0 +1,157 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeContainerAppsAPI struct {
+	apps      []containerApp
+	revisions map[string][]containerAppRevision
+	err       error
+}
+
+func (f *fakeContainerAppsAPI) listContainerApps(_ context.Context, _ string) ([]containerApp, error) {
+	return f.apps, f.err
+}
+
+func (f *fakeContainerAppsAPI) listRevisions(_ context.Context, appID string) ([]containerAppRevision, error) {
+	return f.revisions[appID], nil
+}
+
+func containerAppRevisionOf(name string, active bool, trafficWeight int32, created time.Time, images ...string) containerAppRevision {
+	revision := containerAppRevision{Name: name}
+	revision.Properties.Active = active
+	revision.Properties.TrafficWeight = trafficWeight
+	revision.Properties.CreatedTime = created
+	for _, image := range images {
+		revision.Properties.Template.Containers = append(revision.Properties.Template.Containers, struct {
+			Name  string `json:"name"`
+			Image string `json:"image"`
+		}{Name: "main", Image: image})
+	}
+	return revision
+}
+
+func TestGetContainerAppsData(t *testing.T) {
+	const pinnedDigest = "1111111111111111111111111111111111111111111111111111111111111111"
+	created := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	api := &fakeContainerAppsAPI{
+		apps: []containerApp{{ID: "/apps/web", Name: "web"}, {ID: "/apps/idle", Name: "idle"}},
+		revisions: map[string][]containerAppRevision{
+			"/apps/web": {
+				containerAppRevisionOf("web--v2", true, 20, created.Add(time.Hour), "myregistry.azurecr.io/web:v2", "myregistry.azurecr.io/sidecar@sha256:"+pinnedDigest),
+				containerAppRevisionOf("web--v1", true, 80, created, "myregistry.azurecr.io/web:v1"),
+				containerAppRevisionOf("web--v0", false, 0, created.Add(-time.Hour), "myregistry.azurecr.io/web:v0"),
+			},
+			"/apps/idle": {
+				containerAppRevisionOf("idle--v1", true, 0, created, "myregistry.azurecr.io/idle:v1"),
+			},
+		},
+	}
+	var lookedUp []string
+	fingerprintImage := func(imageName string) (string, error) {
+		lookedUp = append(lookedUp, imageName)
+		return "digest-of-" + imageName, nil
+	}
+
+	appsData, err := getContainerAppsData(context.Background(), api, fingerprintImage, "", logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Equal(t, []*AppData{{
+		AppName:       "web",
+		AppKind:       "containerapp",
+		DigestsSource: "acr",
+		Digests: map[string]string{
+			"myregistry.azurecr.io/web:v1":                         "digest-of-myregistry.azurecr.io/web:v1",
+			"myregistry.azurecr.io/web:v2":                         "digest-of-myregistry.azurecr.io/web:v2",
+			"myregistry.azurecr.io/sidecar@sha256:" + pinnedDigest: pinnedDigest,
+		},
+		StartedAt: created.Add(time.Hour).Unix(),
+	}}, appsData)
+	// digest-pinned images and revisions without traffic are not looked up
+	require.Equal(t, []string{"myregistry.azurecr.io/web:v1", "myregistry.azurecr.io/web:v2"}, lookedUp)
+}
+
+func TestGetContainerAppsDataErrors(t *testing.T) {
+	created := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	_, err := getContainerAppsData(context.Background(), &fakeContainerAppsAPI{err: errors.New("forbidden")}, nil, "rg", logger.NewStandardLogger())
+	require.EqualError(t, err, "failed to list container apps: forbidden")
+
+	api := &fakeContainerAppsAPI{
+		apps: []containerApp{{ID: "/apps/web", Name: "web"}},
+		revisions: map[string][]containerAppRevision{
+			"/apps/web": {containerAppRevisionOf("web--v1", true, 100, created, "myregistry.azurecr.io/web:v1")},
+		},
+	}
+	fingerprintImage := func(imageName string) (string, error) { return "", errors.New("manifest unknown") }
+	_, err = getContainerAppsData(context.Background(), api, fingerprintImage, "rg", logger.NewStandardLogger())
+	require.EqualError(t, err, "failed to get the digest of image myregistry.azurecr.io/web:v1 of container app web: manifest unknown")
+}
+
+type fakeTokenCredential struct{}
+
+func (fakeTokenCredential) GetToken(_ context.Context, _ policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "test-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestArmContainerAppsAPIFollowsPages(t *testing.T) {
+	var paths []string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		require.Equal(t, containerAppsAPIVersion, r.URL.Query().Get("api-version"))
+		require.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "" {
+			_, _ = fmt.Fprintf(w, `{"value": [{"id": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.App/containerApps/a", "name": "a"}],
+				"nextLink": "%s%s?api-version=%s&page=2"}`, server.URL, r.URL.Path, containerAppsAPIVersion)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"value": [{"id": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.App/containerApps/b", "name": "b"}]}`)
+	}))
+	defer server.Close()
+
+	client, err := arm.NewClient("kosli-cli/containerapps", "v1.0.0", fakeTokenCredential{}, &arm.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Cloud: cloud.Configuration{
+				ActiveDirectoryAuthorityHost: server.URL,
+				Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+					cloud.ResourceManager: {Audience: "https://management.azure.com", Endpoint: server.URL},
+				},
+			},
+			InsecureAllowCredentialWithHTTP: true,
+			Retry:                           policy.RetryOptions{MaxRetries: -1},
+		},
+	})
+	require.NoError(t, err)
+
+	api := &armContainerAppsAPI{client: client, subscriptionID: "sub"}
+	apps, err := api.listContainerApps(context.Background(), "rg")
+	require.NoError(t, err)
+	require.Equal(t, []containerApp{
+		{ID: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.App/containerApps/a", Name: "a"},
+		{ID: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.App/containerApps/b", Name: "b"},
+	}, apps)
+	require.Equal(t, []string{
+		"/subscriptions/sub/resourceGroups/rg/providers/Microsoft.App/containerApps",
+		"/subscriptions/sub/resourceGroups/rg/providers/Microsoft.App/containerApps",
+	}, paths)
+
+	paths = nil
+	_, err = api.listContainerApps(context.Background(), "")
+	require.NoError(t, err)
+	require.Equal(t, "/subscriptions/sub/providers/Microsoft.App/containerApps", paths[0])
+}
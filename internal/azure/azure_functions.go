@@ -0,0 +1,199 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	armappservice "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice/v2"
+	"github.com/kosli-dev/cli/internal/logger"
+)
+
+// runFromPackageSetting is the app setting of a function app which runs from
+// a zip package. Its value is 1 for a package deployed to the app, or the URL
+// of the package, typically on a consumption plan.
+const runFromPackageSetting = "WEBSITE_RUN_FROM_PACKAGE"
+
+// functionAppsAPI is the unexported seam that lets tests substitute a fake for
+// the App Service web apps API.
+type functionAppsAPI interface {
+	// listSites lists the web apps and function apps of resourceGroup, or of
+	// the subscription when resourceGroup is empty.
+	listSites(ctx context.Context, resourceGroup string) ([]*armappservice.Site, error)
+	applicationSettings(ctx context.Context, resourceGroup, name string) (map[string]*string, error)
+}
+
+// functionAppFingerprinters fingerprint a function app from its container
+// image, from the package at its WEBSITE_RUN_FROM_PACKAGE URL, or from its
+// package downloaded from Kudu.
+type functionAppFingerprinters struct {
+	image      func(imageName string) (string, error)
+	packageURL func(appName, packageURL string) (map[string]string, error)
+	kudu       func(app *armappservice.Site) (AppData, error)
+}
+
+// GetAzureFunctionAppsData returns the running function apps of the resource
+// group, or of the subscription when no resource group is given. Containerized
+// function apps are fingerprinted from the digest of their image in Azure
+// Container Registry, and other function apps from the content of their zip
+// package.
+func (staticCreds *AzureStaticCredentials) GetAzureFunctionAppsData(logger *logger.Logger) ([]*AppData, error) {
+	azureClient, err := staticCreds.NewAzureClient()
+	if err != nil {
+		return nil, err
+	}
+	api := &armFunctionAppsAPI{client: azureClient.AppServiceFactory.NewWebAppsClient()}
+	fingerprinters := functionAppFingerprinters{
+		image: func(imageName string) (string, error) {
+			return azureClient.GetImageFingerprintFromRegistry(imageName, logger)
+		},
+		packageURL: func(appName, packageURL string) (map[string]string, error) {
+			return fingerprintPackageURL(appName, packageURL, logger)
+		},
+		kudu: func(app *armappservice.Site) (AppData, error) {
+			return azureClient.fingerprintZipService(app, logger)
+		},
+	}
+	return getFunctionAppsData(context.Background(), api, fingerprinters, staticCreds.ResourceGroupName, logger)
+}
+
+func getFunctionAppsData(ctx context.Context, api functionAppsAPI, fingerprinters functionAppFingerprinters,
+	resourceGroup string, logger *logger.Logger) ([]*AppData, error) {
+	sites, err := api.listSites(ctx, resourceGroup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list function apps: %w", err)
+	}
+
+	appsData := make([]*AppData, 0, len(sites))
+	for _, site := range sites {
+		if site.Name == nil || !isFunctionApp(site) {
+			continue
+		}
+		if site.Properties == nil || site.Properties.State == nil || strings.ToLower(*site.Properties.State) != "running" {
+			logger.Debug("function app %s is not running, skipping from report", *site.Name)
+			continue
+		}
+		data, err := newFunctionAppData(ctx, api, fingerprinters, site, resourceGroup)
+		if err != nil {
+			return nil, err
+		}
+		if data.IsEmpty() {
+			continue
+		}
+		logger.Debug("For function app %s found: digests=%v", *site.Name, data.Digests)
+		appsData = append(appsData, &data)
+	}
+	return appsData, nil
+}
+
+func newFunctionAppData(ctx context.Context, api functionAppsAPI, fingerprinters functionAppFingerprinters,
+	site *armappservice.Site, resourceGroup string) (AppData, error) {
+	appName, appKind := *site.Name, *site.Kind
+
+	if site.Properties.SiteConfig != nil && site.Properties.SiteConfig.LinuxFxVersion != nil {
+		linuxFxVersion := strings.Split(*site.Properties.SiteConfig.LinuxFxVersion, "|")
+		if len(linuxFxVersion) == 2 && linuxFxVersion[0] == "DOCKER" {
+			fingerprint, err := containerImageFingerprint(linuxFxVersion[1], fingerprinters.image)
+			if err != nil {
+				return AppData{}, fmt.Errorf("failed to get the digest of image %s of function app %s: %w", linuxFxVersion[1], appName, err)
+			}
+			return AppData{appName, appKind, "acr", map[string]string{linuxFxVersion[1]: fingerprint}, 0}, nil
+		}
+	}
+
+	if resourceGroup == "" && site.Properties.ResourceGroup != nil {
+		resourceGroup = *site.Properties.ResourceGroup
+	}
+	settings, err := api.applicationSettings(ctx, resourceGroup, appName)
+	if err != nil {
+		return AppData{}, fmt.Errorf("failed to get the app settings of function app %s: %w", appName, err)
+	}
+	if packageURL := settings[runFromPackageSetting]; packageURL != nil && isPackageURL(*packageURL) {
+		digests, err := fingerprinters.packageURL(appName, *packageURL)
+		if err != nil {
+			return AppData{}, err
+		}
+		return AppData{appName, appKind, "kosli-cli", digests, 0}, nil
+	}
+	return fingerprinters.kudu(site)
+}
+
+// isFunctionApp reports whether an App Service site is a function app. The
+// kind of a function app is a comma separated list including functionapp,
+// e.g. "functionapp,linux,container".
+func isFunctionApp(site *armappservice.Site) bool {
+	if site.Kind == nil {
+		return false
+	}
+	for kind := range strings.SplitSeq(strings.ToLower(*site.Kind), ",") {
+		if strings.TrimSpace(kind) == "functionapp" {
+			return true
+		}
+	}
+	return false
+}
+
+func isPackageURL(value string) bool {
+	value = strings.ToLower(value)
+	return strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "http://")
+}
+
+// fingerprintPackageURL downloads the zip package of a function app from the
+// URL it runs from and fingerprints its content.
+func fingerprintPackageURL(appName, packageURL string, logger *logger.Logger) (map[string]string, error) {
+	tmpDir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			logger.Warn("failed to remove temp dir %s: %v", tmpDir, err)
+		}
+	}()
+
+	packagePath := filepath.Join(tmpDir, appName+".zip")
+	if err := downloadPackageFromURL(appName, packageURL, packagePath, logger); err != nil {
+		return nil, err
+	}
+	return fingerprintAppPackage(appName, packagePath, logger)
+}
+
+// armFunctionAppsAPI is the production functionAppsAPI backed by the App
+// Service web apps API.
+type armFunctionAppsAPI struct {
+	client *armappservice.WebAppsClient
+}
+
+func (a *armFunctionAppsAPI) listSites(ctx context.Context, resourceGroup string) ([]*armappservice.Site, error) {
+	var sites []*armappservice.Site
+	if resourceGroup != "" {
+		pager := a.client.NewListByResourceGroupPager(resourceGroup, nil)
+		for pager.More() {
+			response, err := pager.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			sites = append(sites, response.Value...)
+		}
+		return sites, nil
+	}
+	pager := a.client.NewListPager(nil)
+	for pager.More() {
+		response, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		sites = append(sites, response.Value...)
+	}
+	return sites, nil
+}
+
+func (a *armFunctionAppsAPI) applicationSettings(ctx context.Context, resourceGroup, name string) (map[string]*string, error) {
+	response, err := a.client.ListApplicationSettings(ctx, resourceGroup, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return response.Properties, nil
+}
@@ -0,0 +1,130 @@
+package azure
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	armappservice "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice/v2"
+	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFunctionAppsAPI struct {
+	sites    []*armappservice.Site
+	settings map[string]map[string]*string
+	// settingsResourceGroups records the resource group the settings of each
+	// app were read from
+	settingsResourceGroups map[string]string
+}
+
+func (f *fakeFunctionAppsAPI) listSites(_ context.Context, _ string) ([]*armappservice.Site, error) {
+	return f.sites, nil
+}
+
+func (f *fakeFunctionAppsAPI) applicationSettings(_ context.Context, resourceGroup, name string) (map[string]*string, error) {
+	if f.settingsResourceGroups == nil {
+		f.settingsResourceGroups = map[string]string{}
+	}
+	f.settingsResourceGroups[name] = resourceGroup
+	return f.settings[name], nil
+}
+
+func site(name, kind, state, linuxFxVersion string) *armappservice.Site {
+	return &armappservice.Site{
+		Name: to.Ptr(name),
+		Kind: to.Ptr(kind),
+		Properties: &armappservice.SiteProperties{
+			State:         to.Ptr(state),
+			ResourceGroup: to.Ptr("rg-of-" + name),
+			SiteConfig:    &armappservice.SiteConfig{LinuxFxVersion: to.Ptr(linuxFxVersion)},
+		},
+	}
+}
+
+func TestGetFunctionAppsData(t *testing.T) {
+	api := &fakeFunctionAppsAPI{
+		sites: []*armappservice.Site{
+			site("container-func", "functionapp,linux,container", "Running", "DOCKER|myregistry.azurecr.io/func:v1"),
+			site("consumption-func", "functionapp,linux", "Running", "Python|3.11"),
+			site("kudu-func", "functionapp", "Running", ""),
+			site("stopped-func", "functionapp", "Stopped", ""),
+			site("web-app", "app,linux", "Running", "DOCKER|myregistry.azurecr.io/web:v1"),
+		},
+		settings: map[string]map[string]*string{
+			"consumption-func": {runFromPackageSetting: to.Ptr("https://storage.blob.core.windows.net/packages/func.zip?sig=x")},
+			"kudu-func":        {runFromPackageSetting: to.Ptr("1")},
+		},
+	}
+	fingerprinters := functionAppFingerprinters{
+		image: func(imageName string) (string, error) { return "digest-of-" + imageName, nil },
+		packageURL: func(appName, packageURL string) (map[string]string, error) {
+			return map[string]string{appName: "digest-of-" + packageURL}, nil
+		},
+		kudu: func(app *armappservice.Site) (AppData, error) {
+			return AppData{*app.Name, *app.Kind, "kosli-cli", map[string]string{*app.Name: "kudu-digest"}, 0}, nil
+		},
+	}
+
+	appsData, err := getFunctionAppsData(context.Background(), api, fingerprinters, "", logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Equal(t, []*AppData{
+		{"container-func", "functionapp,linux,container", "acr", map[string]string{"myregistry.azurecr.io/func:v1": "digest-of-myregistry.azurecr.io/func:v1"}, 0},
+		{"consumption-func", "functionapp,linux", "kosli-cli", map[string]string{"consumption-func": "digest-of-https://storage.blob.core.windows.net/packages/func.zip?sig=x"}, 0},
+		{"kudu-func", "functionapp", "kosli-cli", map[string]string{"kudu-func": "kudu-digest"}, 0},
+	}, appsData)
+	// without a resource group, the settings are read from the resource group of each app
+	require.Equal(t, map[string]string{"consumption-func": "rg-of-consumption-func", "kudu-func": "rg-of-kudu-func"}, api.settingsResourceGroups)
+
+	api.settingsResourceGroups = nil
+	_, err = getFunctionAppsData(context.Background(), api, fingerprinters, "my-rg", logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"consumption-func": "my-rg", "kudu-func": "my-rg"}, api.settingsResourceGroups)
+
+	fingerprinters.image = func(imageName string) (string, error) { return "", errors.New("manifest unknown") }
+	_, err = getFunctionAppsData(context.Background(), api, fingerprinters, "", logger.NewStandardLogger())
+	require.EqualError(t, err, "failed to get the digest of image myregistry.azurecr.io/func:v1 of function app container-func: manifest unknown")
+}
+
+func TestFingerprintPackageURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/packages/func.zip" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		archive := zip.NewWriter(w)
+		file, err := archive.Create("function_app.py")
+		require.NoError(t, err)
+		_, err = file.Write([]byte("import azure.functions\n"))
+		require.NoError(t, err)
+		require.NoError(t, archive.Close())
+	}))
+	defer server.Close()
+
+	digests, err := fingerprintPackageURL("func", server.URL+"/packages/func.zip", logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Len(t, digests, 1)
+	require.Regexp(t, `^[a-f0-9]{64}$`, digests["func"])
+
+	_, err = fingerprintPackageURL("func", server.URL+"/packages/missing.zip", logger.NewStandardLogger())
+	require.EqualError(t, err, "failed to download package for app [func]: 404 Not Found")
+}
+
+func TestFingerprintPackageURLDoesNotShowTheSASToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	packageURL := server.URL + "/packages/func.zip?sv=2022-11-02&sig=secret"
+	server.Close()
+
+	_, err := fingerprintPackageURL("func", packageURL, logger.NewStandardLogger())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to download package for app [func] from "+server.URL+"/packages/func.zip: ")
+	require.NotContains(t, err.Error(), "secret")
+
+	_, err = fingerprintPackageURL("func", "http://[::1%zz]/func.zip?sig=secret", logger.NewStandardLogger())
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "secret")
+}
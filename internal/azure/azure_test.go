@@ -222,6 +222,7 @@ func (suite *AzureTestSuite) TestGetPullRequestApproversV2() {
 				approvers: []types.PRApprovals{
 					{
 						Username:  "Ewelina (ewelina@merkely.onmicrosoft.com)",
+						UserID:    "ewelina@merkely.onmicrosoft.com",
 						State:     "APPROVED",
 						Timestamp: 0,
 					},
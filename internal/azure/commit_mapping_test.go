@@ -42,6 +42,8 @@ func TestCommitFromAzureCommit_RecordsAuthorIdentity(t *testing.T) {
 		"author must be name <email> of the git author")
 	require.Empty(t, c.AuthorUsername,
 		"Azure commits carry no login; author_username must be omitted, not the committer name")
+	require.Equal(t, "tooky@kosli.com", c.AuthorID,
+		"the author email is matched with the unique names of approvers")
 }
 
 // TestCommitFromAzureCommit_UsesAuthorDate is a regression test for server#5479:
@@ -52,3 +54,8 @@ func TestCommitFromAzureCommit_UsesAuthorDate(t *testing.T) {
 	require.Equal(t, int64(1772630000), c.Timestamp,
 		"timestamp must be the author date, not the committer date")
 }
+
+func TestAzureEmailAccount(t *testing.T) {
+	require.Equal(t, "tooky@kosli.com", azureEmailAccount("tooky@kosli.com"))
+	require.Empty(t, azureEmailAccount(`KOSLI\tooky`), "a domain account matches no commit author")
+}
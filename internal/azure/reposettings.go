@@ -0,0 +1,124 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kosli-dev/cli/internal/ratelimit"
+	"github.com/kosli-dev/cli/internal/reposettings"
+	"github.com/kosli-dev/cli/internal/types"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/policy"
+)
+
+// The IDs of the Azure DevOps branch policy types.
+const (
+	minimumReviewersPolicy  = "fa4e907d-c16b-4a4c-9dfa-4906e5d171dd"
+	requiredReviewersPolicy = "fd2167ab-b0be-447a-8ec8-39368250530e"
+	buildPolicy             = "0609b952-1397-4640-95ec-e00a01b2c241"
+	statusPolicy            = "cbdc66da-9728-4af8-aada-9a5a32e4a226"
+	mergeStrategyPolicy     = "fa4e907e-c16b-4a4c-9dfa-4916e5d171ab"
+)
+
+func (c *AzureConfig) RepoSettingsProvider() string {
+	return "azure"
+}
+
+// RepoSettings computes the settings of branch from the branch policies
+// applying to it.
+func (c *AzureConfig) RepoSettings(branch string) (*types.RepoSettings, error) {
+	ctx := context.Background()
+	client, err := NewAzureClientFromToken(ctx, c.Token, c.OrgURL)
+	if err != nil {
+		return nil, err
+	}
+
+	repository, err := ratelimit.Retry(func() (*git.GitRepository, error) {
+		return client.GetRepository(ctx, git.GetRepositoryArgs{
+			RepositoryId: &c.Repository,
+			Project:      &c.Project,
+		})
+	}, rateLimited)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository %s: %v", c.Repository, err)
+	}
+
+	refName := "refs/heads/" + branch
+	policies := []policy.PolicyConfiguration{}
+	var continuationToken *string
+	for {
+		response, err := ratelimit.Retry(func() (*git.GitPolicyConfigurationResponse, error) {
+			return client.GetPolicyConfigurations(ctx, git.GetPolicyConfigurationsArgs{
+				Project:           &c.Project,
+				RepositoryId:      repository.Id,
+				RefName:           &refName,
+				ContinuationToken: continuationToken,
+			})
+		}, rateLimited)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the policies of branch %s: %v", branch, err)
+		}
+		if response.PolicyConfigurations != nil {
+			policies = append(policies, *response.PolicyConfigurations...)
+		}
+		if response.ContinuationToken == nil || *response.ContinuationToken == "" {
+			break
+		}
+		continuationToken = response.ContinuationToken
+	}
+
+	settings := reposettings.Unprotected(c.Project+"/"+c.Repository, branch)
+	applyPolicies(settings, policies)
+	return settings, nil
+}
+
+// applyPolicies adds the enabled policies to settings. Only blocking policies
+// are enforced. A branch with a blocking policy can only be changed through
+// pull requests, so it cannot be force pushed to or deleted, except by those
+// allowed to bypass its policies. Required reviewers for paths are how Azure
+// DevOps has code owners review.
+func applyPolicies(settings *types.RepoSettings, policies []policy.PolicyConfiguration) {
+	for _, p := range policies {
+		if p.IsEnabled == nil || !*p.IsEnabled || (p.IsDeleted != nil && *p.IsDeleted) {
+			continue
+		}
+		settings.Rules = append(settings.Rules, p)
+		if p.IsBlocking == nil || !*p.IsBlocking || p.Type == nil || p.Type.Id == nil {
+			continue
+		}
+		settings.Protected = true
+		settings.AllowForcePushes = false
+		settings.AllowDeletions = false
+
+		values, _ := p.Settings.(map[string]any)
+		switch p.Type.Id.String() {
+		case minimumReviewersPolicy:
+			if count, ok := values["minimumApproverCount"].(float64); ok {
+				settings.RequiredApprovals = max(settings.RequiredApprovals, int(count))
+			}
+			if reset, ok := values["resetOnSourcePush"].(bool); ok && reset {
+				settings.DismissStaleApprovals = true
+			}
+		case requiredReviewersPolicy:
+			settings.RequireCodeOwnerReview = true
+		case buildPolicy:
+			name, _ := values["displayName"].(string)
+			if name == "" {
+				if id, ok := values["buildDefinitionId"].(float64); ok {
+					name = fmt.Sprintf("build definition %d", int(id))
+				}
+			}
+			reposettings.AddStatusChecks(settings, name)
+		case statusPolicy:
+			name, _ := values["statusName"].(string)
+			if genre, _ := values["statusGenre"].(string); genre != "" && name != "" {
+				name = genre + "/" + name
+			}
+			reposettings.AddStatusChecks(settings, name)
+		case mergeStrategyPolicy:
+			noFastForward, _ := values["allowNoFastForward"].(bool)
+			rebaseMerge, _ := values["allowRebaseMerge"].(bool)
+			settings.RequireLinearHistory = !noFastForward && !rebaseMerge
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package azure
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/reposettings"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPolicies(t *testing.T) {
+	policies := []policy.PolicyConfiguration{}
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"id": 1, "isEnabled": true, "isBlocking": true, "type": {"id": "fa4e907d-c16b-4a4c-9dfa-4906e5d171dd"},
+		 "settings": {"minimumApproverCount": 2, "resetOnSourcePush": true}},
+		{"id": 2, "isEnabled": true, "isBlocking": true, "type": {"id": "0609b952-1397-4640-95ec-e00a01b2c241"},
+		 "settings": {"buildDefinitionId": 7, "displayName": "CI"}},
+		{"id": 3, "isEnabled": true, "isBlocking": true, "type": {"id": "0609b952-1397-4640-95ec-e00a01b2c241"},
+		 "settings": {"buildDefinitionId": 8}},
+		{"id": 4, "isEnabled": true, "isBlocking": false, "type": {"id": "cbdc66da-9728-4af8-aada-9a5a32e4a226"},
+		 "settings": {"statusGenre": "sonar", "statusName": "quality"}},
+		{"id": 5, "isEnabled": false, "isBlocking": true, "type": {"id": "fd2167ab-b0be-447a-8ec8-39368250530e"}},
+		{"id": 6, "isEnabled": true, "isBlocking": true, "type": {"id": "fa4e907e-c16b-4a4c-9dfa-4916e5d171ab"},
+		 "settings": {"allowSquash": true, "allowNoFastForward": false}}
+	]`), &policies))
+
+	settings := reposettings.Unprotected("project/web", "main")
+	applyPolicies(settings, policies)
+	assert.True(t, settings.Protected)
+	assert.Equal(t, 2, settings.RequiredApprovals)
+	assert.True(t, settings.DismissStaleApprovals)
+	assert.False(t, settings.RequireCodeOwnerReview, "the required reviewers policy is disabled")
+	assert.True(t, settings.RequireStatusChecks)
+	assert.Equal(t, []string{"CI", "build definition 8"}, settings.RequiredStatusChecks, "the status policy is optional")
+	assert.True(t, settings.RequireLinearHistory)
+	assert.False(t, settings.AllowForcePushes)
+	assert.False(t, settings.AllowDeletions)
+	assert.Len(t, settings.Rules, 5)
+
+	settings = reposettings.Unprotected("project/web", "feature")
+	applyPolicies(settings, policies[3:5])
+	assert.False(t, settings.Protected)
+	assert.True(t, settings.AllowForcePushes)
+	assert.Len(t, settings.Rules, 1)
+}
@@ -34,6 +34,11 @@ func parseRFC3339NanoTimestamp(timestampStr, fieldName string) (int64, error) {
 	return parsedTime.Unix(), nil
 }
 
+// CacheScope identifies the repository for the pull request cache.
+func (c *Config) CacheScope() string {
+	return "bitbucket|" + c.Workspace + "/" + c.Repository
+}
+
 func (c *Config) ProviderAndLabel() (string, string) {
 	return "bitbucket", "pull request"
 }
@@ -159,6 +164,7 @@ func (c *Config) getPullRequestDetailsFromBitbucket(prApiUrl, prHtmlLink, commit
 						}
 						approvers = append(approvers, types.PRApprovals{
 							Username:  user["display_name"].(string),
+							UserID:    bitbucketUserUUID(user),
 							State:     p["state"].(string),
 							Timestamp: approvalTimestamp,
 						})
@@ -203,6 +209,13 @@ func bitbucketBranchName(prData map[string]any, side string) string {
 	return prData[side].(map[string]any)["branch"].(map[string]any)["name"].(string)
 }
 
+// bitbucketUserUUID returns the UUID of a Bitbucket account, which is empty
+// for a nil user.
+func bitbucketUserUUID(user map[string]any) string {
+	uuid, _ := user["uuid"].(string)
+	return uuid
+}
+
 // getPullRequestCommitsFromBitbucket gets the commits of a pull request from the Bitbucket API
 func (c *Config) getPullRequestCommitsFromBitbucket(prID int) ([]types.Commit, error) {
 	url, err := url.JoinPath("https://api.bitbucket.org/2.0/repositories", c.Workspace, c.Repository, "pullrequests", strconv.Itoa(prID), "commits")
@@ -247,10 +260,15 @@ func (c *Config) getPullRequestCommitsFromBitbucket(prID int) ([]types.Commit, e
 			if err != nil {
 				return nil, err
 			}
+			author := commit["author"].(map[string]any)
+			// the author of a commit has a Bitbucket account when the email of
+			// its raw "Name <email>" is one of the emails of the account
+			authorUser, _ := author["user"].(map[string]any)
 			allCommits = append(allCommits, types.Commit{
 				SHA:       commit["hash"].(string),
 				Message:   commit["message"].(string),
-				Author:    commit["author"].(map[string]any)["raw"].(string),
+				Author:    author["raw"].(string),
+				AuthorID:  bitbucketUserUUID(authorUser),
 				Timestamp: timestamp,
 				URL:       commit["links"].(map[string]any)["html"].(map[string]any)["href"].(string),
 			})
@@ -20,3 +20,9 @@ func TestBitbucketBranchName(t *testing.T) {
 	require.Equal(t, "feature-branch", bitbucketBranchName(prData, "source"))
 	require.Equal(t, "main", bitbucketBranchName(prData, "destination"))
 }
+
+func TestBitbucketUserUUID(t *testing.T) {
+	require.Equal(t, "{4e6c1f0a-6b1e-4c3f-9b7e-2f6f3c1e0a11}",
+		bitbucketUserUUID(map[string]any{"display_name": "Alice", "uuid": "{4e6c1f0a-6b1e-4c3f-9b7e-2f6f3c1e0a11}"}))
+	require.Empty(t, bitbucketUserUUID(nil), "the author of a commit without an account")
+}
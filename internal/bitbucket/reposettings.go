@@ -0,0 +1,155 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/reposettings"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+func (c *Config) RepoSettingsProvider() string {
+	return "bitbucket"
+}
+
+// branchRestriction is a branch permission or merge check of a repository.
+// Restrictions apply to the branches matching a glob pattern, or to a branch
+// type of the branching model, such as "production".
+type branchRestriction struct {
+	ID              int    `json:"id"`
+	Kind            string `json:"kind"`
+	Value           *int   `json:"value,omitempty"`
+	BranchMatchKind string `json:"branch_match_kind"`
+	Pattern         string `json:"pattern,omitempty"`
+	BranchType      string `json:"branch_type,omitempty"`
+	Users           []any  `json:"users,omitempty"`
+	Groups          []any  `json:"groups,omitempty"`
+}
+
+// branchingModel is the effective branching model of a repository.
+type branchingModel struct {
+	Development *struct {
+		Name string `json:"name"`
+	} `json:"development"`
+	Production *struct {
+		Name string `json:"name"`
+	} `json:"production"`
+	BranchTypes []struct {
+		Kind   string `json:"kind"`
+		Prefix string `json:"prefix"`
+	} `json:"branch_types"`
+}
+
+// RepoSettings computes the settings of branch from the branch restrictions
+// of the repository. Merge checks are only enforced on Bitbucket Premium
+// workspaces, elsewhere they are warnings.
+func (c *Config) RepoSettings(branch string) (*types.RepoSettings, error) {
+	restrictionsURL, err := url.JoinPath("https://api.bitbucket.org/2.0/repositories", c.Workspace, c.Repository, "branch-restrictions")
+	if err != nil {
+		return nil, err
+	}
+	restrictions := []*branchRestriction{}
+	for currentURL := restrictionsURL + "?pagelen=100"; currentURL != ""; {
+		var page struct {
+			Values []*branchRestriction `json:"values"`
+			Next   string               `json:"next"`
+		}
+		if err := c.getJSON(currentURL, &page); err != nil {
+			return nil, fmt.Errorf("failed to get the branch restrictions of %s/%s: %v", c.Workspace, c.Repository, err)
+		}
+		restrictions = append(restrictions, page.Values...)
+		currentURL = page.Next
+	}
+
+	branchType := ""
+	for _, restriction := range restrictions {
+		if restriction.BranchMatchKind == "branching_model" {
+			modelURL, err := url.JoinPath("https://api.bitbucket.org/2.0/repositories", c.Workspace, c.Repository, "effective-branching-model")
+			if err != nil {
+				return nil, err
+			}
+			model := &branchingModel{}
+			if err := c.getJSON(modelURL, model); err != nil {
+				return nil, fmt.Errorf("failed to get the branching model of %s/%s: %v", c.Workspace, c.Repository, err)
+			}
+			branchType = model.branchType(branch)
+			break
+		}
+	}
+
+	settings := reposettings.Unprotected(c.Workspace+"/"+c.Repository, branch)
+	applyBranchRestrictions(settings, branchType, restrictions)
+	return settings, nil
+}
+
+func (c *Config) getJSON(apiURL string, v any) error {
+	c.Logger.Debug("getting " + apiURL)
+	response, err := c.KosliClient.Do(&requests.RequestParams{
+		Method:   http.MethodGet,
+		URL:      apiURL,
+		Username: c.Username,
+		Password: c.Password,
+		Token:    c.AccessToken,
+	})
+	if err != nil {
+		return err
+	}
+	if response.Resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("got HTTP status %d", response.Resp.StatusCode)
+	}
+	return json.Unmarshal([]byte(response.Body), v)
+}
+
+// branchType returns the type of branch in the branching model, or "" when
+// it has none.
+func (m *branchingModel) branchType(branch string) string {
+	if m.Production != nil && m.Production.Name == branch {
+		return "production"
+	}
+	if m.Development != nil && m.Development.Name == branch {
+		return "development"
+	}
+	for _, branchType := range m.BranchTypes {
+		if branchType.Prefix != "" && strings.HasPrefix(branch, branchType.Prefix) {
+			return branchType.Kind
+		}
+	}
+	return ""
+}
+
+// applyBranchRestrictions adds the restrictions applying to the branch of
+// settings, whose type in the branching model is branchType, to settings.
+func applyBranchRestrictions(settings *types.RepoSettings, branchType string, restrictions []*branchRestriction) {
+	for _, restriction := range restrictions {
+		if restriction.BranchMatchKind == "branching_model" {
+			if branchType == "" || restriction.BranchType != branchType {
+				continue
+			}
+		} else if !reposettings.MatchBranch(restriction.Pattern, settings.Branch) {
+			continue
+		}
+
+		settings.Protected = true
+		settings.Rules = append(settings.Rules, restriction)
+		value := 0
+		if restriction.Value != nil {
+			value = *restriction.Value
+		}
+		switch restriction.Kind {
+		case "require_approvals_to_merge", "require_default_reviewer_approvals_to_merge":
+			settings.RequiredApprovals = max(settings.RequiredApprovals, value)
+		case "reset_pullrequest_approvals_on_change", "smart_reset_pullrequest_approvals":
+			settings.DismissStaleApprovals = true
+		case "require_passing_builds_to_merge":
+			reposettings.AddStatusChecks(settings)
+		case "force":
+			settings.AllowForcePushes = false
+		case "delete":
+			settings.AllowDeletions = false
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/reposettings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const branchRestrictionsJSON = `[
+	{"id": 1, "kind": "require_approvals_to_merge", "value": 2, "branch_match_kind": "glob", "pattern": "main"},
+	{"id": 2, "kind": "force", "branch_match_kind": "glob", "pattern": "*"},
+	{"id": 3, "kind": "delete", "branch_match_kind": "branching_model", "branch_type": "production"},
+	{"id": 4, "kind": "require_passing_builds_to_merge", "value": 1, "branch_match_kind": "glob", "pattern": "release/*"},
+	{"id": 5, "kind": "reset_pullrequest_approvals_on_change", "branch_match_kind": "glob", "pattern": "main"}
+]`
+
+func TestApplyBranchRestrictions(t *testing.T) {
+	restrictions := []*branchRestriction{}
+	require.NoError(t, json.Unmarshal([]byte(branchRestrictionsJSON), &restrictions))
+
+	settings := reposettings.Unprotected("acme/web", "main")
+	applyBranchRestrictions(settings, "production", restrictions)
+	assert.True(t, settings.Protected)
+	assert.Equal(t, 2, settings.RequiredApprovals)
+	assert.True(t, settings.DismissStaleApprovals)
+	assert.False(t, settings.RequireStatusChecks)
+	assert.False(t, settings.AllowForcePushes)
+	assert.False(t, settings.AllowDeletions)
+	assert.Len(t, settings.Rules, 4)
+
+	settings = reposettings.Unprotected("acme/web", "release/1.0")
+	applyBranchRestrictions(settings, "release", restrictions)
+	assert.True(t, settings.Protected)
+	assert.Equal(t, 0, settings.RequiredApprovals)
+	assert.True(t, settings.RequireStatusChecks)
+	assert.False(t, settings.AllowForcePushes)
+	assert.True(t, settings.AllowDeletions)
+}
+
+func TestBranchingModelBranchType(t *testing.T) {
+	model := &branchingModel{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"development": {"name": "develop"},
+		"production": {"name": "main"},
+		"branch_types": [{"kind": "release", "prefix": "release/"}, {"kind": "feature", "prefix": "feature/"}]
+	}`), model))
+
+	assert.Equal(t, "production", model.branchType("main"))
+	assert.Equal(t, "development", model.branchType("develop"))
+	assert.Equal(t, "release", model.branchType("release/1.0"))
+	assert.Equal(t, "", model.branchType("experiment"))
+	assert.Equal(t, "", (&branchingModel{}).branchType("main"))
+}
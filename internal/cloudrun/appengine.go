@@ -0,0 +1,130 @@
+package cloudrun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	appengine "cloud.google.com/go/appengine/apiv1"
+	"cloud.google.com/go/appengine/apiv1/appenginepb"
+	"github.com/kosli-dev/cli/internal/logger"
+	"google.golang.org/api/iterator"
+)
+
+// isAppEngineFlexible reports whether env, the Version.Env of an App Engine
+// version, is the flexible environment, which runs containers. Standard
+// versions run in a sandbox instead.
+func isAppEngineFlexible(env string) bool {
+	return env == "flexible" || env == "flex"
+}
+
+// appEngineAPI is the unexported seam that lets tests substitute a fake for
+// the App Engine Admin API v1.
+type appEngineAPI interface {
+	listServices(ctx context.Context, project string) ([]*appenginepb.Service, error)
+	getVersion(ctx context.Context, name string) (*appenginepb.Version, error)
+}
+
+// NewAppEngineClient returns a Client backed by the real App Engine Admin
+// API v1 using Application Default Credentials. Like New, it is wired with a
+// registry-lookup resolver for tag-pinned images. Callers should defer
+// Close().
+func NewAppEngineClient(ctx context.Context, log *logger.Logger) (*Client, error) {
+	services, err := appengine.NewServicesClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GCP client setup failed: %w", err)
+	}
+	versions, err := appengine.NewVersionsClient(ctx)
+	if err != nil {
+		_ = services.Close()
+		return nil, fmt.Errorf("GCP client setup failed: %w", err)
+	}
+
+	digestRes, _ := newRegistryResolvers(ctx, log, false)
+	return &Client{
+		appEngine: &gcpAppEngineAPI{services: services, versions: versions},
+		resolver:  digestRes,
+		log:       log,
+	}, nil
+}
+
+// ListAppEngineServices returns every App Engine service of the given
+// project, each populated with the flexible versions its traffic is split
+// to (any fraction, including 0), as revisions named after the version IDs.
+// Standard versions are not containers and are skipped.
+func (c *Client) ListAppEngineServices(ctx context.Context, project string) ([]Service, error) {
+	rawServices, err := c.appEngine.listServices(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Service, 0, len(rawServices))
+	for _, raw := range rawServices {
+		svc := Service{Name: raw.GetId()}
+		versionIDs := make([]string, 0, len(raw.GetSplit().GetAllocations()))
+		for versionID := range raw.GetSplit().GetAllocations() {
+			versionIDs = append(versionIDs, versionID)
+		}
+		slices.Sort(versionIDs)
+		for _, versionID := range versionIDs {
+			fullName := raw.GetName() + "/versions/" + versionID
+			version, err := c.appEngine.getVersion(ctx, fullName)
+			if err != nil {
+				return nil, fmt.Errorf("getting version %s: %w", fullName, err)
+			}
+			if !isAppEngineFlexible(version.GetEnv()) {
+				c.log.Warn("App Engine version [%s] of service [%s] runs in the standard environment and has no container image to report",
+					versionID, svc.Name)
+				continue
+			}
+			image := version.GetDeployment().GetContainer().GetImage()
+			if image == "" {
+				c.log.Warn("App Engine version [%s] of service [%s] has no container image to report", versionID, svc.Name)
+				continue
+			}
+			revision := Revision{
+				Name:    versionID,
+				Digests: map[string]string{image: parseDigest(image)},
+			}
+			if ts := version.GetCreateTime(); ts != nil {
+				revision.CreatedAt = ts.AsTime()
+			}
+			c.resolveTagPinnedDigests(revision.Digests)
+			svc.Revisions = append(svc.Revisions, revision)
+		}
+		out = append(out, svc)
+	}
+	return out, nil
+}
+
+// gcpAppEngineAPI is the production appEngineAPI backed by the App Engine
+// Admin API v1.
+type gcpAppEngineAPI struct {
+	services *appengine.ServicesClient
+	versions *appengine.VersionsClient
+}
+
+func (g *gcpAppEngineAPI) close() error {
+	return errors.Join(g.services.Close(), g.versions.Close())
+}
+
+func (g *gcpAppEngineAPI) listServices(ctx context.Context, project string) ([]*appenginepb.Service, error) {
+	parent := "apps/" + project
+	it := g.services.ListServices(ctx, &appenginepb.ListServicesRequest{Parent: parent})
+	var out []*appenginepb.Service
+	for {
+		svc, err := it.Next()
+		if err == iterator.Done {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("listing App Engine services in %s: %w", parent, err)
+		}
+		out = append(out, svc)
+	}
+}
+
+func (g *gcpAppEngineAPI) getVersion(ctx context.Context, name string) (*appenginepb.Version, error) {
+	// the deployment, with the container image, is only in the full view
+	return g.versions.GetVersion(ctx, &appenginepb.GetVersionRequest{Name: name, View: appenginepb.VersionView_FULL})
+}
@@ -0,0 +1,93 @@
+package cloudrun
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/appengine/apiv1/appenginepb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeAppEngineAPI is the in-memory test double for appEngineAPI.
+type fakeAppEngineAPI struct {
+	services []*appenginepb.Service
+	versions map[string]*appenginepb.Version
+	err      error
+}
+
+func (f *fakeAppEngineAPI) listServices(_ context.Context, _ string) ([]*appenginepb.Service, error) {
+	return f.services, f.err
+}
+
+func (f *fakeAppEngineAPI) getVersion(_ context.Context, name string) (*appenginepb.Version, error) {
+	version, ok := f.versions[name]
+	if !ok {
+		return nil, errNotFound{name: name}
+	}
+	return version, nil
+}
+
+func appEngineService(id string, allocations map[string]float64) *appenginepb.Service {
+	return &appenginepb.Service{
+		Name:  "apps/" + testProject + "/services/" + id,
+		Id:    id,
+		Split: &appenginepb.TrafficSplit{Allocations: allocations},
+	}
+}
+
+func flexibleVersion(image string, createdAt time.Time) *appenginepb.Version {
+	return &appenginepb.Version{
+		Env:        "flexible",
+		CreateTime: timestamppb.New(createdAt),
+		Deployment: &appenginepb.Deployment{Container: &appenginepb.ContainerInfo{Image: image}},
+	}
+}
+
+func TestListAppEngineServices_ReportsTheFlexibleVersionsOfTheTrafficSplit(t *testing.T) {
+	created := time.Date(2026, 4, 28, 12, 0, 0, 0, time.UTC)
+	digestPinned := "us.gcr.io/" + testProject + "/appengine/default.v2@sha256:def456"
+	tagPinned := "us.gcr.io/" + testProject + "/appengine/default.v1:latest"
+	versions := "apps/" + testProject + "/services/"
+
+	c := &Client{
+		appEngine: &fakeAppEngineAPI{
+			services: []*appenginepb.Service{
+				appEngineService("default", map[string]float64{"v2": 0.9, "v1": 0.1}),
+				appEngineService("legacy", map[string]float64{"std": 1}),
+			},
+			versions: map[string]*appenginepb.Version{
+				versions + "default/versions/v1": flexibleVersion(tagPinned, created),
+				versions + "default/versions/v2": flexibleVersion(digestPinned, created),
+				versions + "legacy/versions/std": {Env: "standard"},
+			},
+		},
+		resolver: &fakeResolver{digests: map[string]string{tagPinned: "abc123"}},
+		log:      newDiscardLogger(t),
+	}
+
+	got, err := c.ListAppEngineServices(context.Background(), testProject)
+	require.NoError(t, err)
+	require.Equal(t, []Service{
+		{
+			Name: "default",
+			Revisions: []Revision{
+				{Name: "v1", Digests: map[string]string{tagPinned: "abc123"}, CreatedAt: created},
+				{Name: "v2", Digests: map[string]string{digestPinned: "def456"}, CreatedAt: created},
+			},
+		},
+		{Name: "legacy"},
+	}, got)
+}
+
+func TestListAppEngineServices_PropagatesErrors(t *testing.T) {
+	listErr := errors.New("permission denied")
+	_, err := (&Client{appEngine: &fakeAppEngineAPI{err: listErr}}).ListAppEngineServices(context.Background(), testProject)
+	require.ErrorIs(t, err, listErr)
+
+	c := &Client{appEngine: &fakeAppEngineAPI{services: []*appenginepb.Service{appEngineService("default", map[string]float64{"v1": 1})}}}
+	_, err = c.ListAppEngineServices(context.Background(), testProject)
+	require.ErrorContains(t, err, "getting version apps/"+testProject+"/services/default/versions/v1")
+}
@@ -67,6 +67,8 @@ type apiClient interface {
 // original key/value stays in place if the lookup fails.
 type Client struct {
 	api         apiClient
+	functions   functionsAPI
+	appEngine   appEngineAPI
 	resolver    digestResolver
 	tagResolver tagResolver
 	log         *logger.Logger
@@ -103,27 +105,7 @@ func New(ctx context.Context, log *logger.Logger, resolveNames bool) (*Client, e
 		return nil, fmt.Errorf("GCP client setup failed: %w", err)
 	}
 
-	var digestRes digestResolver
-	var tagRes tagResolver
-	src, terr := google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/cloud-platform")
-	switch {
-	case terr != nil:
-		log.Debug("ADC token source unavailable; registry resolution disabled: %v", terr)
-	default:
-		// One HTTP client shared by both resolvers so that hundreds of
-		// per-artifact registry calls reuse a single connection pool
-		// (TCP + TLS handshakes amortise across the snapshot).
-		kosliClient, cerr := requests.NewKosliClient("", 1, log.DebugEnabled, log)
-		if cerr != nil {
-			log.Debug("registry HTTP client unavailable; registry resolution disabled: %v", cerr)
-			break
-		}
-		digestRes = &gcpRegistryResolver{tokens: src, client: kosliClient, log: log}
-		if resolveNames {
-			tagRes = &gcpArtifactRegistryTagResolver{tokens: src, client: kosliClient, log: log}
-		}
-	}
-
+	digestRes, tagRes := newRegistryResolvers(ctx, log, resolveNames)
 	return &Client{
 		api:         &gcpAPI{services: services, revisions: revisions, jobs: jobs},
 		resolver:    digestRes,
@@ -132,15 +114,41 @@ func New(ctx context.Context, log *logger.Logger, resolveNames bool) (*Client, e
 	}, nil
 }
 
+// newRegistryResolvers returns the registry-lookup resolver for tag-pinned
+// images, and the reverse tag resolver when resolveNames is true, both using
+// ADC. If ADC is unavailable both resolvers are nil.
+func newRegistryResolvers(ctx context.Context, log *logger.Logger, resolveNames bool) (digestResolver, tagResolver) {
+	src, err := google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		log.Debug("ADC token source unavailable; registry resolution disabled: %v", err)
+		return nil, nil
+	}
+	// One HTTP client shared by both resolvers so that hundreds of
+	// per-artifact registry calls reuse a single connection pool
+	// (TCP + TLS handshakes amortise across the snapshot).
+	kosliClient, err := requests.NewKosliClient("", 1, log.DebugEnabled, log)
+	if err != nil {
+		log.Debug("registry HTTP client unavailable; registry resolution disabled: %v", err)
+		return nil, nil
+	}
+	var tagRes tagResolver
+	if resolveNames {
+		tagRes = &gcpArtifactRegistryTagResolver{tokens: src, client: kosliClient, log: log}
+	}
+	return &gcpRegistryResolver{tokens: src, client: kosliClient, log: log}, tagRes
+}
+
 // Close releases the underlying gRPC connections. Safe to call on a Client
-// constructed with a fake apiClient (returns nil). All clients are always
-// closed; errors from each are joined so none are silently dropped.
+// constructed with fake APIs (returns nil). All clients are always closed;
+// errors from each are joined so none are silently dropped.
 func (c *Client) Close() error {
-	g, ok := c.api.(*gcpAPI)
-	if !ok {
-		return nil
+	errs := []error{}
+	for _, api := range []any{c.api, c.functions, c.appEngine} {
+		if g, ok := api.(interface{ close() error }); ok {
+			errs = append(errs, g.close())
+		}
 	}
-	return errors.Join(g.services.Close(), g.revisions.Close(), g.jobs.Close())
+	return errors.Join(errs...)
 }
 
 // ListServices returns every Cloud Run service in the given project+region,
@@ -339,12 +347,24 @@ func shortName(fullName string) string {
 }
 
 // gcpAPI is the production apiClient backed by the Cloud Run Admin API v2.
+// A Client of Cloud Functions only has the revisions client.
 type gcpAPI struct {
 	services  *run.ServicesClient
 	revisions *run.RevisionsClient
 	jobs      *run.JobsClient
 }
 
+func (g *gcpAPI) close() error {
+	errs := []error{g.revisions.Close()}
+	if g.services != nil {
+		errs = append(errs, g.services.Close())
+	}
+	if g.jobs != nil {
+		errs = append(errs, g.jobs.Close())
+	}
+	return errors.Join(errs...)
+}
+
 func (g *gcpAPI) listServices(ctx context.Context, project, region string) ([]*runpb.Service, error) {
 	parent := fmt.Sprintf("projects/%s/locations/%s", project, region)
 	it := g.services.ListServices(ctx, &runpb.ListServicesRequest{Parent: parent})
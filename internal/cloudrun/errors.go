@@ -14,6 +14,29 @@ import (
 // project and region are interpolated into messages where they help the user
 // localise the failure (e.g. NotFound on a misspelled project).
 func Classify(err error, project, region string) error {
+	return classify(err, project, region, "'roles/run.viewer'")
+}
+
+// ClassifyFunctions is Classify for Cloud Functions SDK errors. Listing 2nd
+// gen functions also reads the Cloud Run revisions they run as.
+func ClassifyFunctions(err error, project, region string) error {
+	return classify(err, project, region, "'roles/cloudfunctions.viewer' and 'roles/run.viewer'")
+}
+
+// ClassifyAppEngine is Classify for App Engine SDK errors. An App Engine
+// application has no region of its own to look into.
+func ClassifyAppEngine(err error, project string) error {
+	if s, ok := status.FromError(err); ok && s.Code() == codes.NotFound {
+		return fmt.Errorf(
+			"GCP project %q not found, not accessible, or without an App Engine application (underlying error: %w)",
+			project, err,
+		)
+	}
+	return classify(err, project, "", "'roles/appengine.appViewer'")
+}
+
+// classify is Classify with the roles the caller needs.
+func classify(err error, project, region, roles string) error {
 	if err == nil {
 		return nil
 	}
@@ -31,8 +54,8 @@ func Classify(err error, project, region string) error {
 		)
 	case codes.PermissionDenied:
 		return fmt.Errorf(
-			"GCP permission denied: the caller needs 'roles/run.viewer' on project %q (underlying error: %w)",
-			project, err,
+			"GCP permission denied: the caller needs %s on project %q (underlying error: %w)",
+			roles, project, err,
 		)
 	case codes.NotFound:
 		return fmt.Errorf(
@@ -59,3 +59,27 @@ func TestClassify_NotFoundNamesProjectAndRegion(t *testing.T) {
 	require.Contains(t, got.Error(), `"europe-west1"`)
 	require.ErrorIs(t, got, original)
 }
+
+func TestClassifyFunctions_PermissionDeniedNamesFunctionsAndRunRoles(t *testing.T) {
+	original := status.Error(codes.PermissionDenied, "missing iam role")
+	got := ClassifyFunctions(original, "proj-1", "europe-west1")
+
+	require.Contains(t, got.Error(), "roles/cloudfunctions.viewer")
+	require.Contains(t, got.Error(), "roles/run.viewer")
+	require.ErrorIs(t, got, original)
+}
+
+func TestClassifyAppEngine(t *testing.T) {
+	denied := status.Error(codes.PermissionDenied, "missing iam role")
+	got := ClassifyAppEngine(denied, "proj-1")
+	require.Contains(t, got.Error(), "roles/appengine.appViewer")
+	require.ErrorIs(t, got, denied)
+
+	notFound := status.Error(codes.NotFound, "no such application")
+	got = ClassifyAppEngine(notFound, "proj-1")
+	require.Contains(t, got.Error(), `"proj-1"`)
+	require.Contains(t, got.Error(), "without an App Engine application")
+	require.ErrorIs(t, got, notFound)
+
+	require.NoError(t, ClassifyAppEngine(nil, "proj-1"))
+}
@@ -0,0 +1,111 @@
+package cloudrun
+
+import (
+	"context"
+	"fmt"
+
+	functions "cloud.google.com/go/functions/apiv2"
+	"cloud.google.com/go/functions/apiv2/functionspb"
+	run "cloud.google.com/go/run/apiv2"
+	"github.com/kosli-dev/cli/internal/logger"
+	"google.golang.org/api/iterator"
+)
+
+// functionsAPI is the unexported seam that lets tests substitute a fake for
+// the Cloud Functions API v2.
+type functionsAPI interface {
+	listFunctions(ctx context.Context, project, region string) ([]*functionspb.Function, error)
+}
+
+// NewFunctionsClient returns a Client backed by the real Cloud Functions API
+// v2, and the Cloud Run Admin API v2 for the revisions 2nd gen functions run
+// as, using Application Default Credentials. Like New, it is wired with a
+// registry-lookup resolver for tag-pinned images. Callers should defer
+// Close().
+func NewFunctionsClient(ctx context.Context, log *logger.Logger) (*Client, error) {
+	functionsClient, err := functions.NewFunctionClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GCP client setup failed: %w", err)
+	}
+	revisions, err := run.NewRevisionsClient(ctx)
+	if err != nil {
+		_ = functionsClient.Close()
+		return nil, fmt.Errorf("GCP client setup failed: %w", err)
+	}
+
+	digestRes, _ := newRegistryResolvers(ctx, log, false)
+	return &Client{
+		api:       &gcpAPI{revisions: revisions},
+		functions: &gcpFunctionsAPI{functions: functionsClient},
+		resolver:  digestRes,
+		log:       log,
+	}, nil
+}
+
+// ListFunctions returns every active 2nd gen Cloud Function in the given
+// project+region as a Service named after the function, with the Cloud Run
+// revision the function runs as. 1st gen functions are not containers and
+// are skipped.
+func (c *Client) ListFunctions(ctx context.Context, project, region string) ([]Service, error) {
+	rawFunctions, err := c.functions.listFunctions(ctx, project, region)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Service, 0, len(rawFunctions))
+	for _, raw := range rawFunctions {
+		name := shortName(raw.GetName())
+		if raw.GetEnvironment() == functionspb.Environment_GEN_1 {
+			c.log.Warn("Cloud Function [%s] is 1st gen and has no container image to report", name)
+			continue
+		}
+		if raw.GetState() != functionspb.Function_ACTIVE {
+			c.log.Debug("skipping Cloud Function [%s] with state %s", name, raw.GetState())
+			continue
+		}
+		serviceConfig := raw.GetServiceConfig()
+		if serviceConfig.GetService() == "" || serviceConfig.GetRevision() == "" {
+			c.log.Warn("Cloud Function [%s] has no Cloud Run revision to report", name)
+			continue
+		}
+
+		fullName := serviceConfig.GetService() + "/revisions/" + serviceConfig.GetRevision()
+		rev, err := c.api.getRevision(ctx, fullName)
+		if err != nil {
+			return nil, fmt.Errorf("getting revision %s of Cloud Function %s: %w", fullName, name, err)
+		}
+		revision := toRevision(rev)
+		c.resolveTagPinnedDigests(revision.Digests)
+		out = append(out, Service{
+			Name:      name,
+			URI:       serviceConfig.GetUri(),
+			Revisions: []Revision{revision},
+		})
+	}
+	return out, nil
+}
+
+// gcpFunctionsAPI is the production functionsAPI backed by the Cloud
+// Functions API v2.
+type gcpFunctionsAPI struct {
+	functions *functions.FunctionClient
+}
+
+func (g *gcpFunctionsAPI) close() error {
+	return g.functions.Close()
+}
+
+func (g *gcpFunctionsAPI) listFunctions(ctx context.Context, project, region string) ([]*functionspb.Function, error) {
+	parent := fmt.Sprintf("projects/%s/locations/%s", project, region)
+	it := g.functions.ListFunctions(ctx, &functionspb.ListFunctionsRequest{Parent: parent})
+	var out []*functionspb.Function
+	for {
+		function, err := it.Next()
+		if err == iterator.Done {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("listing Cloud Functions in %s: %w", parent, err)
+		}
+		out = append(out, function)
+	}
+}
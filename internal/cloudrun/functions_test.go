@@ -0,0 +1,87 @@
+package cloudrun
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/functions/apiv2/functionspb"
+	"cloud.google.com/go/run/apiv2/runpb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeFunctionsAPI is the in-memory test double for functionsAPI.
+type fakeFunctionsAPI struct {
+	functions []*functionspb.Function
+	err       error
+}
+
+func (f *fakeFunctionsAPI) listFunctions(_ context.Context, _, _ string) ([]*functionspb.Function, error) {
+	return f.functions, f.err
+}
+
+func functionResource(name string) string {
+	return "projects/" + testProject + "/locations/" + testRegion + "/functions/" + name
+}
+
+// gen2Function is a small constructor for an active 2nd gen function that
+// runs as the revision rev of the Cloud Run service of the same name.
+func gen2Function(name, rev string) *functionspb.Function {
+	return &functionspb.Function{
+		Name:        functionResource(name),
+		Environment: functionspb.Environment_GEN_2,
+		State:       functionspb.Function_ACTIVE,
+		ServiceConfig: &functionspb.ServiceConfig{
+			Service:  svcResource(name),
+			Revision: rev,
+			Uri:      "https://" + name + ".run.app",
+		},
+	}
+}
+
+func TestListFunctions_ReportsTheRevisionOfEachGen2Function(t *testing.T) {
+	created := time.Date(2026, 4, 28, 12, 0, 0, 0, time.UTC)
+	tagPinned := "europe-west1-docker.pkg.dev/" + testProject + "/gcf-artifacts/hello:version_3"
+	gen1 := &functionspb.Function{Name: functionResource("legacy"), Environment: functionspb.Environment_GEN_1, State: functionspb.Function_ACTIVE}
+	deploying := gen2Function("deploying", "deploying-00001-abc")
+	deploying.State = functionspb.Function_DEPLOYING
+
+	c := &Client{
+		functions: &fakeFunctionsAPI{functions: []*functionspb.Function{gen2Function("hello", "hello-00003-xyz"), gen1, deploying}},
+		api: &fakeAPI{revisions: map[string]*runpb.Revision{
+			revResource("hello", "hello-00003-xyz"): {
+				Name:       revResource("hello", "hello-00003-xyz"),
+				CreateTime: timestamppb.New(created),
+				Containers: []*runpb.Container{{Image: tagPinned}},
+			},
+		}},
+		resolver: &fakeResolver{digests: map[string]string{tagPinned: "abc123"}},
+		log:      newDiscardLogger(t),
+	}
+
+	got, err := c.ListFunctions(context.Background(), testProject, testRegion)
+	require.NoError(t, err)
+	require.Equal(t, []Service{{
+		Name: "hello",
+		URI:  "https://hello.run.app",
+		Revisions: []Revision{
+			{Name: "hello-00003-xyz", Digests: map[string]string{tagPinned: "abc123"}, CreatedAt: created},
+		},
+	}}, got)
+}
+
+func TestListFunctions_PropagatesErrors(t *testing.T) {
+	listErr := errors.New("permission denied")
+	_, err := (&Client{functions: &fakeFunctionsAPI{err: listErr}}).ListFunctions(context.Background(), testProject, testRegion)
+	require.ErrorIs(t, err, listErr)
+
+	c := &Client{
+		functions: &fakeFunctionsAPI{functions: []*functionspb.Function{gen2Function("hello", "hello-00003-xyz")}},
+		api:       &fakeAPI{},
+		log:       newDiscardLogger(t),
+	}
+	_, err = c.ListFunctions(context.Background(), testProject, testRegion)
+	require.ErrorContains(t, err, "getting revision "+revResource("hello", "hello-00003-xyz")+" of Cloud Function hello")
+}
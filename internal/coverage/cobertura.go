@@ -0,0 +1,67 @@
+package coverage
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+type coberturaReport struct {
+	Packages []struct {
+		Name    string `xml:"name,attr"`
+		Classes []struct {
+			Filename string          `xml:"filename,attr"`
+			Lines    []coberturaLine `xml:"lines>line"`
+		} `xml:"classes>class"`
+	} `xml:"packages>package"`
+}
+
+type coberturaLine struct {
+	Number            int    `xml:"number,attr"`
+	Hits              int64  `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr"`
+	ConditionCoverage string `xml:"condition-coverage,attr"`
+}
+
+// conditionCoverage matches the branch counts of a line, e.g. "50% (1/2)".
+var conditionCoverage = regexp.MustCompile(`\((\d+)/(\d+)\)`)
+
+// parseCobertura reads a Cobertura XML report, as written by coverage.py,
+// gcovr, Istanbul, coverlet and the cobertura Maven plugin. Coverage is counted
+// from the lines of the classes rather than taken from the rate attributes,
+// which not every producer fills in. A line reported by several classes of the
+// same file (e.g. inner classes) is counted once.
+func parseCobertura(data []byte) (*Results, error) {
+	var report coberturaReport
+	if err := newXMLDecoder(data).Decode(&report); err != nil {
+		return nil, err
+	}
+
+	set := newPackageSet(true)
+	for _, p := range report.Packages {
+		pkg := set.get(p.Name)
+		seen := map[string]bool{}
+		for _, class := range p.Classes {
+			for _, line := range class.Lines {
+				key := fmt.Sprintf("%s:%d", class.Filename, line.Number)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				covered := 0
+				if line.Hits > 0 {
+					covered = 1
+				}
+				pkg.Lines.add(covered, 1)
+				if line.Branch {
+					if m := conditionCoverage.FindStringSubmatch(line.ConditionCoverage); m != nil {
+						hit, _ := strconv.Atoi(m[1])
+						total, _ := strconv.Atoi(m[2])
+						pkg.Branches.add(hit, total)
+					}
+				}
+			}
+		}
+	}
+	return set.results(FormatCobertura), nil
+}
@@ -0,0 +1,220 @@
+package coverage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Format is a code coverage report file format.
+type Format string
+
+const (
+	FormatAuto      Format = "auto"
+	FormatCobertura Format = "cobertura"
+	FormatLCOV      Format = "lcov"
+	FormatJaCoCo    Format = "jacoco"
+	FormatGo        Format = "go"
+)
+
+// Formats lists the accepted values for a coverage format, auto-detection first.
+var Formats = []Format{FormatAuto, FormatCobertura, FormatLCOV, FormatJaCoCo, FormatGo}
+
+// ParseFormat validates a format name.
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("%q is not a supported coverage format. Valid formats are: [%s]", name, strings.Join(names, ", "))
+}
+
+// Counts is the coverage of one kind of coverage item (lines or branches).
+type Counts struct {
+	Covered int     `json:"covered"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+func (c *Counts) add(covered, total int) {
+	c.Covered += covered
+	c.Total += total
+}
+
+// finish computes the percentage, rounded to two decimals. Nothing to cover
+// is reported as 0%, so that an empty report cannot meet a threshold.
+func (c *Counts) finish() {
+	if c.Total == 0 {
+		c.Percent = 0
+		return
+	}
+	c.Percent = math.Round(float64(c.Covered)/float64(c.Total)*10000) / 100
+}
+
+// PackageCoverage is the coverage of one package (a Java or Go package, a
+// Python module, or a source directory for LCOV).
+type PackageCoverage struct {
+	Name     string  `json:"name"`
+	Lines    Counts  `json:"lines"`
+	Branches *Counts `json:"branches,omitempty"`
+}
+
+// Results is the coverage recorded in one coverage report.
+// Branches is nil for formats that do not record branch coverage.
+type Results struct {
+	Format   Format             `json:"format"`
+	Lines    Counts             `json:"lines"`
+	Branches *Counts            `json:"branches,omitempty"`
+	Packages []*PackageCoverage `json:"packages"`
+}
+
+// packageSet accumulates per-package counts in a parser.
+type packageSet struct {
+	withBranches bool
+	packages     map[string]*PackageCoverage
+}
+
+func newPackageSet(withBranches bool) *packageSet {
+	return &packageSet{withBranches: withBranches, packages: map[string]*PackageCoverage{}}
+}
+
+func (s *packageSet) get(name string) *PackageCoverage {
+	pkg, ok := s.packages[name]
+	if !ok {
+		pkg = &PackageCoverage{Name: name}
+		if s.withBranches {
+			pkg.Branches = &Counts{}
+		}
+		s.packages[name] = pkg
+	}
+	return pkg
+}
+
+// results sums up the packages into totals, sorted by package name.
+func (s *packageSet) results(format Format) *Results {
+	results := &Results{Format: format, Packages: []*PackageCoverage{}}
+	if s.withBranches {
+		results.Branches = &Counts{}
+	}
+	for _, pkg := range s.packages {
+		pkg.Lines.finish()
+		results.Lines.add(pkg.Lines.Covered, pkg.Lines.Total)
+		if pkg.Branches != nil {
+			pkg.Branches.finish()
+			results.Branches.add(pkg.Branches.Covered, pkg.Branches.Total)
+		}
+		results.Packages = append(results.Packages, pkg)
+	}
+	sort.Slice(results.Packages, func(i, j int) bool {
+		return results.Packages[i].Name < results.Packages[j].Name
+	})
+	results.Lines.finish()
+	if results.Branches != nil {
+		results.Branches.finish()
+	}
+	return results
+}
+
+// packageOf returns the directory of a source file, which stands for its
+// package in formats that only record files.
+func packageOf(file string) string {
+	dir := path.Dir(strings.ReplaceAll(file, `\`, "/"))
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
+
+// Detect returns the format of a coverage report from its content, or an
+// empty Format if it is not a coverage report.
+func Detect(data []byte) Format {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if bytes.HasPrefix(trimmed, []byte("mode:")) {
+		return FormatGo
+	}
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		switch xmlRoot(trimmed) {
+		case "coverage":
+			return FormatCobertura
+		case "report":
+			return FormatJaCoCo
+		}
+		return ""
+	}
+	for _, line := range strings.SplitN(string(trimmed), "\n", 20) {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "SF:") || line == "end_of_record" {
+			return FormatLCOV
+		}
+	}
+	return ""
+}
+
+// xmlRoot returns the name of the root element of an XML document.
+func xmlRoot(data []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local
+		}
+	}
+}
+
+// Parse reads a coverage report in the given format. With FormatAuto the
+// format is detected from the content.
+func Parse(format Format, data []byte) (*Results, error) {
+	if format == FormatAuto || format == "" {
+		format = Detect(data)
+		if format == "" {
+			return nil, fmt.Errorf("unrecognized coverage report format, expected one of: [cobertura, lcov, jacoco, go]")
+		}
+	}
+	switch format {
+	case FormatCobertura:
+		return parseCobertura(data)
+	case FormatLCOV:
+		return parseLCOV(data)
+	case FormatJaCoCo:
+		return parseJaCoCo(data)
+	case FormatGo:
+		return parseGoCoverProfile(data)
+	default:
+		return nil, fmt.Errorf("%q is not a supported coverage format", format)
+	}
+}
+
+// ParseFile reads the coverage report at filePath.
+func ParseFile(format Format, filePath string) (*Results, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	results, err := Parse(format, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse coverage report %s: %w", filePath, err)
+	}
+	return results, nil
+}
+
+// newXMLDecoder returns a decoder for coverage XML, which commonly carries a
+// DOCTYPE referencing a DTD that is not available (and not needed).
+func newXMLDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	return decoder
+}
@@ -0,0 +1,132 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func packageByName(t *testing.T, results *Results, name string) *PackageCoverage {
+	t.Helper()
+	for _, pkg := range results.Packages {
+		if pkg.Name == name {
+			return pkg
+		}
+	}
+	require.Failf(t, "package not found", "no package %q in coverage results", name)
+	return nil
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range Formats {
+		got, err := ParseFormat(string(f))
+		require.NoError(t, err)
+		assert.Equal(t, f, got)
+	}
+	_, err := ParseFormat("clover")
+	require.EqualError(t, err, `"clover" is not a supported coverage format. Valid formats are: [auto, cobertura, lcov, jacoco, go]`)
+}
+
+func TestDetect(t *testing.T) {
+	for _, tc := range []struct {
+		file string
+		want Format
+	}{
+		{file: "cobertura.xml", want: FormatCobertura},
+		{file: "lcov.info", want: FormatLCOV},
+		{file: "jacoco.xml", want: FormatJaCoCo},
+		{file: "coverage.out", want: FormatGo},
+	} {
+		t.Run(tc.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tc.file))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, Detect(data))
+		})
+	}
+
+	t.Run("a file that is not a coverage report", func(t *testing.T) {
+		assert.Equal(t, Format(""), Detect([]byte(`<testsuites><testsuite name="a"/></testsuites>`)))
+		assert.Equal(t, Format(""), Detect([]byte("hello world")))
+	})
+}
+
+func TestParseCobertura(t *testing.T) {
+	results, err := ParseFile(FormatAuto, filepath.Join("testdata", "cobertura.xml"))
+	require.NoError(t, err)
+	assert.Equal(t, FormatCobertura, results.Format)
+	assert.Equal(t, Counts{Covered: 5, Total: 7, Percent: 71.43}, results.Lines)
+	assert.Equal(t, &Counts{Covered: 1, Total: 4, Percent: 25}, results.Branches)
+
+	require.Len(t, results.Packages, 2)
+	app := packageByName(t, results, "app")
+	assert.Equal(t, Counts{Covered: 3, Total: 4, Percent: 75}, app.Lines)
+	assert.Equal(t, &Counts{Covered: 1, Total: 4, Percent: 25}, app.Branches)
+
+	api := packageByName(t, results, "app.api")
+	assert.Equal(t, Counts{Covered: 2, Total: 3, Percent: 66.67}, api.Lines, "a line of an inner class is counted once")
+}
+
+func TestParseLCOV(t *testing.T) {
+	results, err := ParseFile(FormatLCOV, filepath.Join("testdata", "lcov.info"))
+	require.NoError(t, err)
+	assert.Equal(t, Counts{Covered: 14, Total: 15, Percent: 93.33}, results.Lines)
+	assert.Equal(t, &Counts{Covered: 2, Total: 4, Percent: 50}, results.Branches)
+
+	utils := packageByName(t, results, "src/utils")
+	assert.Equal(t, Counts{Covered: 5, Total: 5, Percent: 100}, utils.Lines, "records of the same file are merged")
+	assert.Equal(t, &Counts{Covered: 2, Total: 4, Percent: 50}, utils.Branches)
+
+	src := packageByName(t, results, "src")
+	assert.Equal(t, Counts{Covered: 9, Total: 10, Percent: 90}, src.Lines, "LF/LH are used without DA entries")
+}
+
+func TestParseLCOVRejectsInvalidEntries(t *testing.T) {
+	_, err := Parse(FormatLCOV, []byte("SF:a.js\nDA:x,1\nend_of_record\n"))
+	require.ErrorContains(t, err, `line 2: invalid DA entry "DA:x,1"`)
+
+	_, err = Parse(FormatLCOV, []byte("TN:\n"))
+	require.ErrorContains(t, err, "no source files found")
+}
+
+func TestParseJaCoCo(t *testing.T) {
+	results, err := ParseFile(FormatAuto, filepath.Join("testdata", "jacoco.xml"))
+	require.NoError(t, err)
+	assert.Equal(t, FormatJaCoCo, results.Format)
+	assert.Equal(t, Counts{Covered: 46, Total: 60, Percent: 76.67}, results.Lines)
+	assert.Equal(t, &Counts{Covered: 5, Total: 10, Percent: 50}, results.Branches)
+
+	billing := packageByName(t, results, "com/example/billing")
+	assert.Equal(t, Counts{Covered: 36, Total: 40, Percent: 90}, billing.Lines)
+	assert.Equal(t, &Counts{Covered: 5, Total: 8, Percent: 62.5}, billing.Branches)
+	assert.Equal(t, Counts{Covered: 10, Total: 20, Percent: 50}, packageByName(t, results, "com/example/api").Lines)
+}
+
+func TestParseGoCoverProfile(t *testing.T) {
+	results, err := ParseFile(FormatGo, filepath.Join("testdata", "coverage.out"))
+	require.NoError(t, err)
+	assert.Equal(t, Counts{Covered: 9, Total: 10, Percent: 90}, results.Lines)
+	assert.Nil(t, results.Branches, "go does not record branch coverage")
+
+	config := packageByName(t, results, "example.com/app/config")
+	assert.Equal(t, Counts{Covered: 5, Total: 5, Percent: 100}, config.Lines, "a block covered in any run is covered")
+	assert.Nil(t, config.Branches)
+	assert.Equal(t, Counts{Covered: 4, Total: 5, Percent: 80}, packageByName(t, results, "example.com/app/server").Lines)
+
+	_, err = Parse(FormatGo, []byte("mode: set\nnot a block\n"))
+	require.ErrorContains(t, err, `line 2: invalid coverage profile entry "not a block"`)
+}
+
+func TestParseUnrecognizedReport(t *testing.T) {
+	_, err := Parse(FormatAuto, []byte(`{"total": 80}`))
+	require.EqualError(t, err, "unrecognized coverage report format, expected one of: [cobertura, lcov, jacoco, go]")
+}
+
+func TestEmptyReportIsZeroPercent(t *testing.T) {
+	results, err := Parse(FormatGo, []byte("mode: set\n"))
+	require.NoError(t, err)
+	assert.Equal(t, Counts{}, results.Lines)
+	assert.Empty(t, results.Packages)
+}
@@ -0,0 +1,68 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type goBlock struct {
+	statements int
+	covered    bool
+}
+
+// parseGoCoverProfile reads a Go coverage profile, as written by
+// `go test -coverprofile`. Go measures statements rather than lines: they are
+// reported as the line coverage, the same figure as `go tool cover -func`, and
+// there is no branch coverage. A block listed more than once (profiles of
+// several runs concatenated together) is counted once, covered if any run
+// covered it.
+func parseGoCoverProfile(data []byte) (*Results, error) {
+	blocks := map[string]*goBlock{}
+	var order []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// e.g. example.com/app/config/load.go:12.34,14.2 3 1
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: invalid coverage profile entry %q", lineNumber, line)
+		}
+		statements, errS := strconv.Atoi(fields[1])
+		count, errC := strconv.ParseInt(fields[2], 10, 64)
+		if errS != nil || errC != nil || !strings.Contains(fields[0], ":") {
+			return nil, fmt.Errorf("line %d: invalid coverage profile entry %q", lineNumber, line)
+		}
+		block, ok := blocks[fields[0]]
+		if !ok {
+			block = &goBlock{statements: statements}
+			blocks[fields[0]] = block
+			order = append(order, fields[0])
+		}
+		block.covered = block.covered || count > 0
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read coverage profile: %w", err)
+	}
+
+	set := newPackageSet(false)
+	for _, id := range order {
+		block := blocks[id]
+		file := id[:strings.LastIndex(id, ":")]
+		covered := 0
+		if block.covered {
+			covered = block.statements
+		}
+		set.get(packageOf(file)).Lines.add(covered, block.statements)
+	}
+	return set.results(FormatGo), nil
+}
@@ -0,0 +1,52 @@
+package coverage
+
+import "encoding/xml"
+
+type jacocoCounter struct {
+	Type    string `xml:"type,attr"`
+	Missed  int    `xml:"missed,attr"`
+	Covered int    `xml:"covered,attr"`
+}
+
+type jacocoPackage struct {
+	Name     string          `xml:"name,attr"`
+	Counters []jacocoCounter `xml:"counter"`
+}
+
+// jacocoGroup is the report itself or a group of it; multi-module reports
+// (jacoco:report-aggregate) nest the packages of each module in a group.
+type jacocoGroup struct {
+	XMLName  xml.Name
+	Groups   []jacocoGroup   `xml:"group"`
+	Packages []jacocoPackage `xml:"package"`
+}
+
+// parseJaCoCo reads a JaCoCo XML report. The LINE and BRANCH counters of each
+// package are used as they are: JaCoCo already computes them per package.
+func parseJaCoCo(data []byte) (*Results, error) {
+	var report jacocoGroup
+	if err := newXMLDecoder(data).Decode(&report); err != nil {
+		return nil, err
+	}
+
+	set := newPackageSet(true)
+	var collect func(group jacocoGroup)
+	collect = func(group jacocoGroup) {
+		for _, p := range group.Packages {
+			pkg := set.get(p.Name)
+			for _, counter := range p.Counters {
+				switch counter.Type {
+				case "LINE":
+					pkg.Lines.add(counter.Covered, counter.Covered+counter.Missed)
+				case "BRANCH":
+					pkg.Branches.add(counter.Covered, counter.Covered+counter.Missed)
+				}
+			}
+		}
+		for _, g := range group.Groups {
+			collect(g)
+		}
+	}
+	collect(report)
+	return set.results(FormatJaCoCo), nil
+}
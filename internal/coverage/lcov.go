@@ -0,0 +1,130 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lcovFile is the coverage of one source file (one record) of an LCOV report.
+type lcovFile struct {
+	name string
+	// lines maps a line number to whether it was hit, from DA entries
+	lines map[int]bool
+	// branches maps a branch id to whether it was taken, from BRDA entries
+	branches map[string]bool
+	// the LF/LH/BRF/BRH summaries, which are used when there are no entries
+	found, hit, branchesFound, branchesHit int
+}
+
+// parseLCOV reads an LCOV tracefile, as written by lcov, Istanbul/nyc, c8,
+// cargo-llvm-cov and many others. Source files are grouped into packages by
+// directory. A file listed in several records (e.g. by several test runs
+// concatenated together) is counted once, with a line covered if any run
+// covered it.
+func parseLCOV(data []byte) (*Results, error) {
+	files := map[string]*lcovFile{}
+	var order []string
+	var current *lcovFile
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "end_of_record" {
+			current = nil
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		if key == "SF" {
+			current = files[value]
+			if current == nil {
+				current = &lcovFile{name: value, lines: map[int]bool{}, branches: map[string]bool{}}
+				files[value] = current
+				order = append(order, value)
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		fields := strings.Split(value, ",")
+		switch key {
+		case "DA":
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("line %d: invalid DA entry %q", lineNumber, line)
+			}
+			number, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid DA entry %q", lineNumber, line)
+			}
+			current.lines[number] = current.lines[number] || lcovHit(fields[1])
+		case "BRDA":
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("line %d: invalid BRDA entry %q", lineNumber, line)
+			}
+			id := strings.Join(fields[:3], ",")
+			current.branches[id] = current.branches[id] || lcovHit(fields[3])
+		case "LF":
+			current.found = max(current.found, atoi(value))
+		case "LH":
+			current.hit = max(current.hit, atoi(value))
+		case "BRF":
+			current.branchesFound = max(current.branchesFound, atoi(value))
+		case "BRH":
+			current.branchesHit = max(current.branchesHit, atoi(value))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read LCOV report: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no source files found in LCOV report")
+	}
+
+	set := newPackageSet(true)
+	for _, name := range order {
+		file := files[name]
+		pkg := set.get(packageOf(name))
+		if len(file.lines) > 0 {
+			pkg.Lines.add(countTrue(file.lines), len(file.lines))
+		} else {
+			pkg.Lines.add(file.hit, file.found)
+		}
+		if len(file.branches) > 0 {
+			pkg.Branches.add(countTrue(file.branches), len(file.branches))
+		} else {
+			pkg.Branches.add(file.branchesHit, file.branchesFound)
+		}
+	}
+	return set.results(FormatLCOV), nil
+}
+
+// lcovHit reports whether an execution count is non-zero. Branches that were
+// never evaluated have a count of "-".
+func lcovHit(count string) bool {
+	n, err := strconv.ParseFloat(count, 64)
+	return err == nil && n > 0
+}
+
+func atoi(value string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(value))
+	return n
+}
+
+func countTrue[K comparable](m map[K]bool) int {
+	n := 0
+	for _, v := range m {
+		if v {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,230 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Job is a task which a Scheduler runs periodically.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func() error
+	// Attrs are added to the log records of the runs of the job.
+	Attrs []any
+}
+
+// JobStatus is the status of a job, as reported by the health endpoint.
+type JobStatus struct {
+	Name                string     `json:"name"`
+	Interval            string     `json:"interval"`
+	Runs                int        `json:"runs"`
+	LastRun             *time.Time `json:"last_run,omitempty"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	NextRun             *time.Time `json:"next_run,omitempty"`
+}
+
+// Scheduler runs jobs concurrently, each at its own interval.
+type Scheduler struct {
+	// Jitter is the fraction of its interval by which the delay before each
+	// run of a job is randomly varied, so that jobs with the same interval
+	// do not all run at once.
+	Jitter float64
+	// MaxBackoff caps the delay before the next run of a failing job, which
+	// doubles with each consecutive failure from its interval.
+	MaxBackoff time.Duration
+	Logger     *slog.Logger
+	// Rand returns a random number in [0, 1). Defaults to rand.Float64.
+	Rand func() float64
+
+	mu       sync.Mutex
+	statuses []*JobStatus
+}
+
+// NextDelay returns how long to wait before running a job again after a run
+// which was its failures-th consecutive failure: its interval, doubled for each
+// consecutive failure up to maxBackoff, then varied by up to jitter of itself
+// according to r, a random number in [0, 1).
+func NextDelay(interval time.Duration, failures int, jitter float64, maxBackoff time.Duration, r float64) time.Duration {
+	delay := interval
+	for i := 0; i < failures && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if failures > 0 && delay > maxBackoff {
+		delay = max(maxBackoff, interval)
+	}
+	return delay + time.Duration((2*r-1)*jitter*float64(delay))
+}
+
+func (s *Scheduler) random() float64 {
+	if s.Rand != nil {
+		return s.Rand()
+	}
+	return rand.Float64()
+}
+
+// Run runs jobs until ctx is done, then waits for the runs in progress to
+// finish.
+func (s *Scheduler) Run(ctx context.Context, jobs []Job) {
+	s.mu.Lock()
+	s.statuses = make([]*JobStatus, len(jobs))
+	for i, job := range jobs {
+		s.statuses[i] = &JobStatus{Name: job.Name, Interval: job.Interval.String()}
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runJob(ctx, s.statuses[i], job)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) runJob(ctx context.Context, status *JobStatus, job Job) {
+	logger := s.Logger.With(job.Attrs...)
+	// spread the first runs of the jobs over their jitter, so that the jobs
+	// do not all run as soon as the daemon starts
+	delay := time.Duration(s.random() * s.Jitter * float64(job.Interval))
+	for {
+		next := time.Now().Add(delay)
+		s.update(func() { status.NextRun = &next })
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		start := time.Now()
+		err := runSafely(job.Run)
+		duration := time.Since(start)
+
+		var failures int
+		s.update(func() {
+			status.Runs++
+			status.LastRun = &start
+			if err == nil {
+				status.LastSuccess = &start
+				status.LastError = ""
+				status.ConsecutiveFailures = 0
+			} else {
+				status.LastError = err.Error()
+				status.ConsecutiveFailures++
+			}
+			failures = status.ConsecutiveFailures
+		})
+
+		delay = NextDelay(job.Interval, failures, s.Jitter, s.MaxBackoff, s.random())
+		if err != nil {
+			logger.Error("run failed", "error", err.Error(), "duration", duration,
+				"consecutive_failures", failures, "retry_in", delay.Round(time.Millisecond))
+		} else {
+			logger.Info("run succeeded", "duration", duration, "next_run_in", delay.Round(time.Millisecond))
+		}
+	}
+}
+
+// runSafely runs run, turning a panic into an error so that a job which
+// panics does not stop the other jobs.
+func runSafely(run func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run()
+}
+
+func (s *Scheduler) update(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f()
+}
+
+// Statuses returns a copy of the statuses of the jobs.
+func (s *Scheduler) Statuses() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]JobStatus, len(s.statuses))
+	for i, status := range s.statuses {
+		statuses[i] = *status
+	}
+	return statuses
+}
+
+// ServeHTTP reports the statuses of the jobs as JSON. The daemon is healthy
+// unless the last run of a job failed, in which case it responds with
+// 503 Service Unavailable.
+func (s *Scheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	statuses := s.Statuses()
+	health := "ok"
+	for _, status := range statuses {
+		if status.ConsecutiveFailures > 0 {
+			health = "failing"
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if health != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": health, "jobs": statuses})
+}
+
+// LogWriter is an io.Writer which logs each line written to it as a log
+// record, so that the output of code which writes lines of text is
+// structured like the rest of the logs.
+type LogWriter struct {
+	Logger *slog.Logger
+	// Level is the level of the lines which do not start with one of Prefixes.
+	Level slog.Level
+	// Prefixes are the prefixes which mark the level of a line. They are
+	// removed from the messages.
+	Prefixes map[string]slog.Level
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *LogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.log(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+}
+
+func (w *LogWriter) log(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	level := w.Level
+	for prefix, prefixLevel := range w.Prefixes {
+		if message, ok := strings.CutPrefix(line, prefix); ok {
+			line, level = message, prefixLevel
+			break
+		}
+	}
+	w.Logger.Log(context.Background(), level, line)
+}
@@ -0,0 +1,158 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextDelay(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		failures int
+		jitter   float64
+		r        float64
+		want     time.Duration
+	}{
+		{name: "the interval after a success", want: time.Minute},
+		{name: "doubled after a failure", failures: 1, want: 2 * time.Minute},
+		{name: "doubled for each consecutive failure", failures: 3, want: 8 * time.Minute},
+		{name: "capped at the max backoff", failures: 5, want: 10 * time.Minute},
+		{name: "less by up to the jitter", jitter: 0.1, r: 0, want: 54 * time.Second},
+		{name: "more by up to the jitter", jitter: 0.1, r: 0.75, want: 63 * time.Second},
+		{name: "jittered backoff", failures: 1, jitter: 0.5, r: 0.25, want: 90 * time.Second},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NextDelay(time.Minute, tt.failures, tt.jitter, 10*time.Minute, tt.r))
+		})
+	}
+	// a max backoff shorter than the interval does not shorten it
+	assert.Equal(t, time.Hour, NextDelay(time.Hour, 2, 0, time.Minute, 0))
+}
+
+func TestSchedulerRunsJobsAndBacksOffOnErrors(t *testing.T) {
+	var logs bytes.Buffer
+	scheduler := &Scheduler{
+		MaxBackoff: 40 * time.Millisecond,
+		Logger:     slog.New(slog.NewJSONHandler(&logs, nil)),
+	}
+
+	var okRuns, failingRuns atomic.Int32
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	scheduler.Run(ctx, []Job{
+		{Name: "ok", Interval: 10 * time.Millisecond, Run: func() error {
+			okRuns.Add(1)
+			return nil
+		}, Attrs: []any{"environment", "prod"}},
+		{Name: "failing", Interval: 10 * time.Millisecond, Run: func() error {
+			failingRuns.Add(1)
+			return errors.New("access denied")
+		}},
+		{Name: "panicking", Interval: time.Hour, Run: func() error {
+			panic("boom")
+		}},
+	})
+
+	// the failing job waits 20ms, 40ms, 40ms... after its first run at 0ms
+	assert.Greater(t, okRuns.Load(), failingRuns.Load()+5)
+	assert.GreaterOrEqual(t, failingRuns.Load(), int32(3))
+
+	statuses := scheduler.Statuses()
+	require.Len(t, statuses, 3)
+	assert.Equal(t, 0, statuses[0].ConsecutiveFailures)
+	assert.NotNil(t, statuses[0].LastSuccess)
+	assert.Equal(t, int(failingRuns.Load()), statuses[1].ConsecutiveFailures)
+	assert.Nil(t, statuses[1].LastSuccess)
+	assert.Equal(t, "access denied", statuses[1].LastError)
+	assert.Equal(t, "panic: boom", statuses[2].LastError)
+
+	var record map[string]any
+	firstLine, _, _ := strings.Cut(logs.String(), "\n")
+	require.NoError(t, json.Unmarshal([]byte(firstLine), &record))
+	assert.Contains(t, logs.String(), `"msg":"run succeeded","environment":"prod"`)
+	assert.Contains(t, logs.String(), `"level":"ERROR","msg":"run failed","error":"access denied"`)
+}
+
+func TestSchedulerServeHTTP(t *testing.T) {
+	scheduler := &Scheduler{Logger: slog.New(slog.DiscardHandler)}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	fail := atomic.Bool{}
+	ran := make(chan struct{}, 1)
+	go func() {
+		scheduler.Run(ctx, []Job{{Name: "job", Interval: 5 * time.Millisecond, Run: func() error {
+			defer func() {
+				select {
+				case ran <- struct{}{}:
+				default:
+				}
+			}()
+			if fail.Load() {
+				return errors.New("failed")
+			}
+			return nil
+		}}})
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	get := func() (int, map[string]any) {
+		recorder := httptest.NewRecorder()
+		scheduler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		return recorder.Code, body
+	}
+
+	<-ran
+	code, body := get()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body["status"])
+
+	fail.Store(true)
+	require.Eventually(t, func() bool {
+		code, body = get()
+		return code == http.StatusServiceUnavailable
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "failing", body["status"])
+	assert.Equal(t, "failed", body["jobs"].([]any)[0].(map[string]any)["last_error"])
+}
+
+func TestLogWriter(t *testing.T) {
+	var logs bytes.Buffer
+	w := &LogWriter{
+		Logger:   slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{ReplaceAttr: withoutTime})),
+		Level:    slog.LevelInfo,
+		Prefixes: map[string]slog.Level{"[warning] ": slog.LevelWarn},
+	}
+	_, err := w.Write([]byte("[3] pods were reported"))
+	require.NoError(t, err)
+	assert.Empty(t, logs.String(), "a partial line is not logged")
+
+	_, err = w.Write([]byte(" to environment prod\n\n[warning] slow\n"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"level":"INFO","msg":"[3] pods were reported to environment prod"}`+"\n"+
+		`{"level":"WARN","msg":"slow"}`+"\n", logs.String())
+}
+
+func withoutTime(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return a
+}
@@ -14,15 +14,18 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/containers/image/v5/docker"
 	"github.com/containers/image/v5/types"
 	"github.com/kosli-dev/cli/internal/logger"
 	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/telemetry"
 	"github.com/kosli-dev/cli/internal/utils"
 	"github.com/moby/moby/client"
 	"github.com/yargevad/filepathx"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
@@ -32,7 +35,9 @@ var (
 )
 
 // DirSha256 returns sha256 digest of a directory
-func DirSha256(dirPath string, excludePaths []string, logger *logger.Logger) (string, error) {
+func DirSha256(dirPath string, excludePaths []string, logger *logger.Logger) (fingerprint string, err error) {
+	_, span := telemetry.Start(context.Background(), "digest dir", attribute.String("kosli.artifact.name", dirPath))
+	defer func() { telemetry.End(span, err) }()
 	logger.Debug("calculating fingerprint for path [%s] -- excluding paths: %s", dirPath, excludePaths)
 	info, err := os.Stat(dirPath)
 	if err != nil {
@@ -79,13 +84,14 @@ func DirSha256(dirPath string, excludePaths []string, logger *logger.Logger) (st
 		return "", err
 	}
 
-	return FileSha256(digestsFile.Name(), logger)
+	return fileSha256(digestsFile.Name(), logger)
 }
 
 // OciSha256 gets the digest of a docker/OCI image from its registry
-func OciSha256(artifactName string, registryUsername string, registryPassword string) (string, error) {
+func OciSha256(artifactName string, registryUsername string, registryPassword string) (fingerprint string, err error) {
 	imageName := fmt.Sprintf("//%s", artifactName)
-	ctx := context.Background()
+	ctx, span := telemetry.Start(context.Background(), "digest oci", attribute.String("kosli.artifact.name", artifactName))
+	defer func() { telemetry.End(span, err) }()
 	sysCtx := &types.SystemContext{}
 	// Only set explicit credentials when provided. When DockerAuthConfig is nil,
 	// the containers/image library falls back to credential discovery from auth
@@ -192,7 +198,7 @@ func calculateDirContentSha256(digestsFile *os.File, dirPath, tmpDir string, exc
 		} else {
 			// File or symlink -> file
 			logger.Debug("file path: %s -- filename digest: %s", path, nameSha256)
-			fileContentSha256, err := FileSha256(path, logger)
+			fileContentSha256, err := fileSha256(path, logger)
 			if err != nil {
 				return err
 			}
@@ -214,7 +220,7 @@ func addNameDigest(tmpDir string, filename string, digestsFile *os.File, logger
 		return "", err
 	}
 
-	nameSha256, err := FileSha256(nameFilePath, logger)
+	nameSha256, err := fileSha256(nameFilePath, logger)
 	if err != nil {
 		return "", err
 	}
@@ -224,8 +230,82 @@ func addNameDigest(tmpDir string, filename string, digestsFile *os.File, logger
 	return nameSha256, nil
 }
 
+// digestTreeNode is a file or a directory of DirSha256FromFileDigests.
+type digestTreeNode struct {
+	children map[string]*digestTreeNode
+	digest   string
+}
+
+// DirSha256FromFileDigests returns the digest DirSha256 gives a directory
+// of files without reading them, from the sha256 digest of the content of
+// each file by its slash separated path within the directory. It does not
+// read .kosli_ignore, and the directory has no empty directories.
+func DirSha256FromFileDigests(fileDigests map[string]string) (string, error) {
+	root := &digestTreeNode{children: map[string]*digestTreeNode{}}
+	for path, fileDigest := range fileDigests {
+		if err := ValidateDigest(fileDigest); err != nil {
+			return "", fmt.Errorf("file %s: %v", path, err)
+		}
+		node := root
+		names := strings.Split(path, "/")
+		for i, name := range names {
+			if name == "" || name == "." || name == ".." {
+				return "", fmt.Errorf("invalid file path %q", path)
+			}
+			if node.children == nil {
+				return "", fmt.Errorf("file path %q is under a file", path)
+			}
+			child, ok := node.children[name]
+			if !ok {
+				child = &digestTreeNode{}
+				if i < len(names)-1 {
+					child.children = map[string]*digestTreeNode{}
+				}
+				node.children[name] = child
+			} else if i == len(names)-1 {
+				return "", fmt.Errorf("file path %q is a directory", path)
+			}
+			node = child
+		}
+		node.digest = fileDigest
+	}
+
+	hasher := sha256.New()
+	writeDigestTree(hasher, root)
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// writeDigestTree writes the digests of the content of node to w in the
+// order calculateDirContentSha256 writes them: names in lexical order, each
+// directory followed by its content.
+func writeDigestTree(w io.Writer, node *digestTreeNode) {
+	names := make([]string, 0, len(node.children))
+	for name := range node.children {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		child := node.children[name]
+		nameSha256 := sha256.Sum256([]byte(name))
+		_, _ = io.WriteString(w, hex.EncodeToString(nameSha256[:]))
+		if child.children == nil {
+			_, _ = io.WriteString(w, child.digest)
+		} else {
+			writeDigestTree(w, child)
+		}
+	}
+}
+
 // FileSha256 returns a sha256 digest of a file.
 func FileSha256(filepath string, logger *logger.Logger) (string, error) {
+	_, span := telemetry.Start(context.Background(), "digest file", attribute.String("kosli.artifact.name", filepath))
+	fingerprint, err := fileSha256(filepath, logger)
+	telemetry.End(span, err)
+	return fingerprint, err
+}
+
+// fileSha256 is FileSha256 without a span, for the files of a directory.
+func fileSha256(filepath string, logger *logger.Logger) (string, error) {
 	hasher := sha256.New()
 	f, err := os.Open(filepath)
 	if err != nil {
@@ -250,12 +330,14 @@ func FileSha256(filepath string, logger *logger.Logger) (string, error) {
 // imageID can be the image name or ID
 // It requires the docker daemon to be accessible and the docker image to be locally present.
 // The docker image must have been pushed into a registry to have a digest.
-func DockerImageSha256(imageID string) (string, error) {
+func DockerImageSha256(imageID string) (fingerprint string, err error) {
+	ctx, span := telemetry.Start(context.Background(), "digest docker", attribute.String("kosli.artifact.name", imageID))
+	defer func() { telemetry.End(span, err) }()
 	cli, err := client.New(client.FromEnv)
 	if err != nil {
 		return "", err
 	}
-	imageInspect, err := cli.ImageInspect(context.Background(), imageID)
+	imageInspect, err := cli.ImageInspect(ctx, imageID)
 	if err != nil {
 		if imageID == " " {
 			return "", fmt.Errorf("%s. The image ID is '%s'. https://docs.kosli.com/faq/#pathimage-name-is-a-single-whitespace-character", err, imageID)
@@ -325,7 +407,10 @@ func requestManifestFromRegistry(client *requests.Client, registryEndPoint, imag
 // Callers pass a reusable *requests.Client so a single connection pool can
 // be reused across many manifest lookups (e.g. when resolving digests for
 // every artifact in a Cloud Run snapshot).
-func RemoteDockerImageSha256(client *requests.Client, imageName, imageTag, registryEndPoint, registryToken string) (string, error) {
+func RemoteDockerImageSha256(client *requests.Client, imageName, imageTag, registryEndPoint, registryToken string) (fingerprint string, err error) {
+	_, span := telemetry.Start(context.Background(), "digest docker registry",
+		attribute.String("kosli.artifact.name", imageName+":"+imageTag))
+	defer func() { telemetry.End(span, err) }()
 	acceptHeader := strings.Join([]string{
 		"application/vnd.docker.distribution.manifest.list.v2+json",
 		"application/vnd.docker.distribution.manifest.v2+json",
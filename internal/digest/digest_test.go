@@ -1,6 +1,7 @@
 package digest
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -527,6 +528,41 @@ func (suite *DigestTestSuite) TestDirSha256() {
 	}
 }
 
+func (suite *DigestTestSuite) TestDirSha256FromFileDigests() {
+	files := map[string]string{
+		"a":           "content a",
+		"a-b/file":    "content a-b",
+		"b/c/d.txt":   "content d",
+		"b/c.txt":     "content c",
+		"b/c/e/f":     "content f",
+		"z/.hidden":   "",
+		"Z/uppercase": "upper",
+	}
+	dirPath := filepath.Join(suite.tmpDir, "fromFileDigests")
+	fileDigests := map[string]string{}
+	for path, content := range files {
+		suite.createFileWithContent(filepath.Join(dirPath, filepath.FromSlash(path)), content)
+		fileDigests[path] = fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+	}
+
+	want, err := DirSha256(dirPath, []string{}, logger.NewStandardLogger())
+	require.NoError(suite.T(), err)
+	got, err := DirSha256FromFileDigests(fileDigests)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), want, got)
+
+	for name, fileDigests := range map[string]map[string]string{
+		"invalid digest":     {"a": "not-a-digest"},
+		"empty path element": {"a//b": fileDigests["a"]},
+		"file under a file":  {"a": fileDigests["a"], "a/b": fileDigests["a"]},
+	} {
+		suite.Run(name, func() {
+			_, err := DirSha256FromFileDigests(fileDigests)
+			require.Error(suite.T(), err)
+		})
+	}
+}
+
 func (suite *DigestTestSuite) createNestedDir(path string, files []fileEntry, dirs []dirEntry) {
 	for _, f := range files {
 		filePath := filepath.Join(path, f.name)
@@ -0,0 +1,43 @@
+package gitea
+
+import (
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// FakeGiteaClient is an in-memory implementation of types.PRRetriever for
+// testing. Seed PRsByCommit with the commits and pull request evidence you
+// want returned. Set Err to simulate a network or API failure.
+type FakeGiteaClient struct {
+	// PRsByCommit maps a commit SHA to the pull request evidence returned
+	// for that commit.
+	PRsByCommit map[string][]*types.PREvidence
+	// Err, if set, is returned by all calls regardless of commit.
+	Err error
+}
+
+func (f *FakeGiteaClient) ProviderAndLabel() (string, string) {
+	return "gitea", "pull request"
+}
+
+// PREvidenceForCommitV2 mirrors the real client: a commit with no pull
+// requests yields an empty, non-nil result and no error.
+func (f *FakeGiteaClient) PREvidenceForCommitV2(commit string) ([]*types.PREvidence, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	prs := f.PRsByCommit[commit]
+	if prs == nil {
+		return []*types.PREvidence{}, nil
+	}
+	return prs, nil
+}
+
+// PREvidenceForCommitV1 serves the seeded evidence as V2 does.
+func (f *FakeGiteaClient) PREvidenceForCommitV1(commit string) ([]*types.PREvidence, error) {
+	return f.PREvidenceForCommitV2(commit)
+}
+
+// PREvidenceForCommitHybrid mirrors the real client, which always serves V2.
+func (f *FakeGiteaClient) PREvidenceForCommitHybrid(commit string) ([]*types.PREvidence, error) {
+	return f.PREvidenceForCommitV2(commit)
+}
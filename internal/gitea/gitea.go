@@ -0,0 +1,404 @@
+package gitea
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/ratelimit"
+	"github.com/kosli-dev/cli/internal/telemetry"
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// pageLimit is the page size used for paginated Gitea API calls. Gitea caps
+// it at the server's MAX_RESPONSE_ITEMS setting, which defaults to 50.
+const pageLimit = 50
+
+// GiteaConfig retrieves pull request evidence from the Gitea REST API. Forgejo
+// serves the same API, so it works for both.
+type GiteaConfig struct {
+	Token      string
+	BaseURL    string
+	Org        string
+	Repository string
+	HTTPClient *http.Client
+}
+
+// NewGiteaConfig returns a new GiteaConfig
+func NewGiteaConfig(token, baseURL, org, repository string) *GiteaConfig {
+	return &GiteaConfig{
+		Token:   token,
+		BaseURL: baseURL,
+		Org:     org,
+		// repository name must be extracted if a user is using the default value from
+		// ${GITHUB_REPOSITORY} in Gitea Actions, because the value is in the format of "org/repository"
+		Repository: extractRepoName(repository),
+	}
+}
+
+// extractRepoName returns repository name from 'org/repository_name' string
+func extractRepoName(fullRepositoryName string) string {
+	repoNameParts := strings.Split(fullRepositoryName, "/")
+	return repoNameParts[len(repoNameParts)-1]
+}
+
+// NewGiteaRetrieverFunc creates a types.PRRetriever from Gitea config
+// parameters. It can be replaced in tests to inject a FakeGiteaClient.
+var NewGiteaRetrieverFunc = defaultNewGiteaRetriever
+
+func defaultNewGiteaRetriever(token, baseURL, org, repository string) types.PRRetriever {
+	return NewGiteaConfig(token, baseURL, org, repository)
+}
+
+// ResetGiteaRetrieverFunc restores NewGiteaRetrieverFunc to its default.
+func ResetGiteaRetrieverFunc() {
+	NewGiteaRetrieverFunc = defaultNewGiteaRetriever
+}
+
+// CacheScope identifies the repository for the pull request cache.
+func (c *GiteaConfig) CacheScope() string {
+	return "gitea|" + c.BaseURL + "|" + c.Org + "/" + c.Repository
+}
+
+func (c *GiteaConfig) ProviderAndLabel() (string, string) {
+	return "gitea", "pull request"
+}
+
+type giteaUser struct {
+	Login string `json:"login"`
+}
+
+type giteaBranch struct {
+	Ref string `json:"ref"`
+	Sha string `json:"sha"`
+}
+
+type giteaPullRequest struct {
+	Number         int         `json:"number"`
+	HTMLURL        string      `json:"html_url"`
+	State          string      `json:"state"`
+	Title          string      `json:"title"`
+	User           *giteaUser  `json:"user"`
+	Merged         bool        `json:"merged"`
+	MergeCommitSha string      `json:"merge_commit_sha"`
+	CreatedAt      string      `json:"created_at"`
+	MergedAt       string      `json:"merged_at"`
+	Head           giteaBranch `json:"head"`
+	Base           giteaBranch `json:"base"`
+}
+
+type giteaReview struct {
+	User        *giteaUser `json:"user"`
+	State       string     `json:"state"`
+	SubmittedAt string     `json:"submitted_at"`
+	Dismissed   bool       `json:"dismissed"`
+}
+
+type giteaCommit struct {
+	Sha     string     `json:"sha"`
+	HTMLURL string     `json:"html_url"`
+	Author  *giteaUser `json:"author"`
+	Commit  struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+			Date  string `json:"date"`
+		} `json:"author"`
+		Committer struct {
+			Date string `json:"date"`
+		} `json:"committer"`
+		Verification *struct {
+			Verified  bool   `json:"verified"`
+			Reason    string `json:"reason"`
+			Signature string `json:"signature"`
+		} `json:"verification"`
+	} `json:"commit"`
+}
+
+// errNotFound is returned by get for a 404 response.
+var errNotFound = errors.New("not found")
+
+// get calls a Gitea API endpoint below the repository and decodes the JSON
+// response into out.
+func (c *GiteaConfig) get(path string, query url.Values, out any) error {
+	if c.BaseURL == "" {
+		return fmt.Errorf("a Gitea base URL is required")
+	}
+	u, err := url.JoinPath(c.BaseURL, "api/v1/repos", c.Org, c.Repository, path)
+	if err != nil {
+		return err
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "token "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second, Transport: telemetry.NewTransport(ratelimit.NewTransport(nil))}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Gitea API %s: %w", u, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response from Gitea API %s: %w", u, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case resp.StatusCode != http.StatusOK:
+		var apiError struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &apiError) == nil && apiError.Message != "" {
+			return fmt.Errorf("call to Gitea API %s returned HTTP status %d: %s", u, resp.StatusCode, apiError.Message)
+		}
+		return fmt.Errorf("call to Gitea API %s returned HTTP status %d", u, resp.StatusCode)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// getAllPages calls a paginated Gitea API endpoint until a page is not full.
+func getAllPages[T any](c *GiteaConfig, path string, query url.Values) ([]T, error) {
+	all := []T{}
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("limit", strconv.Itoa(pageLimit))
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var items []T
+		if err := c.get(path, query, &items); err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < pageLimit {
+			return all, nil
+		}
+	}
+}
+
+// PullRequestsForCommit returns the pull request merged with the given commit,
+// or failing that the open pull requests whose head is the given commit.
+func (c *GiteaConfig) PullRequestsForCommit(commit string) ([]*giteaPullRequest, error) {
+	var merged giteaPullRequest
+	err := c.get("commits/"+commit+"/pull", nil, &merged)
+	if err == nil {
+		return []*giteaPullRequest{&merged}, nil
+	}
+	if !errors.Is(err, errNotFound) {
+		return nil, err
+	}
+
+	open, err := getAllPages[*giteaPullRequest](c, "pulls", url.Values{"state": {"open"}})
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil, fmt.Errorf("repository %s/%s does not exist in Gitea or the token has no access to it", c.Org, c.Repository)
+		}
+		return nil, err
+	}
+	prs := []*giteaPullRequest{}
+	for _, pr := range open {
+		if pr.Head.Sha == commit {
+			prs = append(prs, pr)
+		}
+	}
+	return prs, nil
+}
+
+// approvals returns the reviews of a pull request that approve it. Dismissed
+// approvals are left out.
+func (c *GiteaConfig) approvals(number int) ([]*giteaReview, error) {
+	reviews, err := getAllPages[*giteaReview](c, fmt.Sprintf("pulls/%d/reviews", number), nil)
+	if err != nil {
+		return nil, err
+	}
+	approvals := []*giteaReview{}
+	for _, r := range reviews {
+		if r.State == "APPROVED" && !r.Dismissed {
+			approvals = append(approvals, r)
+		}
+	}
+	return approvals, nil
+}
+
+func (c *GiteaConfig) PREvidenceForCommitV1(commit string) ([]*types.PREvidence, error) {
+	pullRequestsEvidence := []*types.PREvidence{}
+	prs, err := c.PullRequestsForCommit(commit)
+	if err != nil {
+		return pullRequestsEvidence, err
+	}
+	for _, pr := range prs {
+		approvals, err := c.approvals(pr.Number)
+		if err != nil {
+			return pullRequestsEvidence, err
+		}
+		approvers := []any{}
+		for _, a := range approvals {
+			approvers = append(approvers, login(a.User))
+		}
+		pullRequestsEvidence = append(pullRequestsEvidence, &types.PREvidence{
+			URL:         pr.HTMLURL,
+			MergeCommit: pr.MergeCommitSha,
+			State:       pr.State,
+			Approvers:   approvers,
+		})
+	}
+	return pullRequestsEvidence, nil
+}
+
+func (c *GiteaConfig) PREvidenceForCommitV2(commit string) ([]*types.PREvidence, error) {
+	pullRequestsEvidence := []*types.PREvidence{}
+	prs, err := c.PullRequestsForCommit(commit)
+	if err != nil {
+		return pullRequestsEvidence, err
+	}
+	for _, pr := range prs {
+		evidence, err := c.newPREvidence(pr, commit)
+		if err != nil {
+			return pullRequestsEvidence, err
+		}
+		pullRequestsEvidence = append(pullRequestsEvidence, evidence)
+	}
+	return pullRequestsEvidence, nil
+}
+
+// PREvidenceForCommitHybrid serves V2. Gitea looks pull requests up from its
+// database, so there is no eventual consistency to fall back from.
+func (c *GiteaConfig) PREvidenceForCommitHybrid(commit string) ([]*types.PREvidence, error) {
+	return c.PREvidenceForCommitV2(commit)
+}
+
+func (c *GiteaConfig) newPREvidence(pr *giteaPullRequest, commit string) (*types.PREvidence, error) {
+	createdAt, err := parseTimestamp(pr.CreatedAt, "created_at")
+	if err != nil {
+		return nil, err
+	}
+	mergedAt, err := parseTimestamp(pr.MergedAt, "merged_at")
+	if err != nil {
+		return nil, err
+	}
+	evidence := &types.PREvidence{
+		URL:         pr.HTMLURL,
+		MergeCommit: commit,
+		State:       prState(pr),
+		Author:      login(pr.User),
+		CreatedAt:   createdAt,
+		MergedAt:    mergedAt,
+		Title:       pr.Title,
+		HeadRef:     pr.Head.Ref,
+		BaseRef:     pr.Base.Ref,
+		Approvers:   []any{},
+		Commits:     []types.Commit{},
+	}
+
+	approvals, err := c.approvals(pr.Number)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range approvals {
+		submittedAt, err := parseTimestamp(a.SubmittedAt, "submitted_at")
+		if err != nil {
+			return nil, err
+		}
+		evidence.Approvers = append(evidence.Approvers, types.PRApprovals{
+			Username:  login(a.User),
+			UserID:    login(a.User),
+			State:     a.State,
+			Timestamp: submittedAt,
+		})
+	}
+
+	commits, err := getAllPages[*giteaCommit](c, fmt.Sprintf("pulls/%d/commits", pr.Number),
+		url.Values{"verification": {"true"}, "files": {"false"}})
+	if err != nil {
+		return nil, err
+	}
+	for _, gc := range commits {
+		timestamp, err := parseTimestamp(gc.Commit.Author.Date, "date")
+		if err != nil {
+			return nil, err
+		}
+		committedAt, err := parseTimestamp(gc.Commit.Committer.Date, "date")
+		if err != nil {
+			return nil, err
+		}
+		verified, signatureState := signature(gc)
+		evidence.Commits = append(evidence.Commits, types.Commit{
+			SHA:            gc.Sha,
+			Message:        gc.Commit.Message,
+			Author:         fmt.Sprintf("%s <%s>", gc.Commit.Author.Name, gc.Commit.Author.Email),
+			AuthorUsername: login(gc.Author),
+			Timestamp:      timestamp,
+			CommittedAt:    committedAt,
+			Branch:         pr.Head.Ref,
+			URL:            gc.HTMLURL,
+			Verified:       verified,
+			SignatureState: signatureState,
+		})
+	}
+	return evidence, nil
+}
+
+// prState returns the pull request state in the same form as GitHub:
+// OPEN, CLOSED or MERGED. Gitea reports merged pull requests as closed.
+func prState(pr *giteaPullRequest) string {
+	if pr.Merged {
+		return "MERGED"
+	}
+	return strings.ToUpper(pr.State)
+}
+
+// signature returns the signature verification of a commit. An unsigned
+// commit has a nil verification, which must stay distinct from a present but
+// invalid signature (verified=false). A valid signature has the state VALID,
+// an invalid one the reason Gitea gives, e.g. "gpg.error.no_gpg_keys_found".
+func signature(gc *giteaCommit) (*bool, *string) {
+	v := gc.Commit.Verification
+	if v == nil || v.Signature == "" {
+		return nil, nil
+	}
+	verified := v.Verified
+	state := v.Reason
+	if verified {
+		state = "VALID"
+	}
+	return &verified, &state
+}
+
+func login(user *giteaUser) string {
+	if user == nil {
+		return ""
+	}
+	return user.Login
+}
+
+// parseTimestamp parses an RFC3339 timestamp from the Gitea API into a Unix
+// timestamp. An empty timestamp (e.g. merged_at of an open pull request) is 0.
+func parseTimestamp(timestamp, fieldName string) (int64, error) {
+	if timestamp == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s timestamp: %w", fieldName, err)
+	}
+	return t.Unix(), nil
+}
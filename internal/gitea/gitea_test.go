@@ -0,0 +1,223 @@
+package gitea
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mergeCommit = "4a1e7c0e8f5b3f6d1a2b3c4d5e6f708192a3b4c5"
+	headCommit  = "9f8e7d6c5b4a39281706f5e4d3c2b1a098765432"
+)
+
+const mergedPR = `{
+	"number": 7,
+	"html_url": "https://gitea.example.com/acme/web/pulls/7",
+	"state": "closed",
+	"title": "Add login form",
+	"user": {"login": "alice"},
+	"merged": true,
+	"merge_commit_sha": "` + mergeCommit + `",
+	"created_at": "2026-03-01T10:00:00Z",
+	"merged_at": "2026-03-02T12:00:00Z",
+	"head": {"ref": "login-form", "sha": "` + headCommit + `"},
+	"base": {"ref": "main", "sha": "0000000000000000000000000000000000000000"}
+}`
+
+const reviews = `[
+	{"user": {"login": "bob"}, "state": "APPROVED", "submitted_at": "2026-03-02T11:00:00Z"},
+	{"user": {"login": "carol"}, "state": "REQUEST_CHANGES", "submitted_at": "2026-03-02T11:10:00Z"},
+	{"user": {"login": "dave"}, "state": "APPROVED", "submitted_at": "2026-03-02T11:20:00Z", "dismissed": true}
+]`
+
+const commits = `[
+	{
+		"sha": "` + headCommit + `",
+		"html_url": "https://gitea.example.com/acme/web/commit/` + headCommit + `",
+		"author": {"login": "alice"},
+		"commit": {
+			"message": "Add login form",
+			"author": {"name": "Alice", "email": "alice@example.com", "date": "2026-03-01T09:00:00Z"},
+			"verification": {"verified": true, "reason": "alice / 3AA5C34371567BD2", "signature": "-----BEGIN PGP SIGNATURE-----"}
+		}
+	},
+	{
+		"sha": "1111111111111111111111111111111111111111",
+		"html_url": "https://gitea.example.com/acme/web/commit/1111111111111111111111111111111111111111",
+		"author": null,
+		"commit": {
+			"message": "Fix typo",
+			"author": {"name": "Mallory", "email": "mallory@example.com", "date": "2026-03-01T09:30:00Z"},
+			"committer": {"name": "Mallory", "email": "mallory@example.com", "date": "2026-03-01T10:30:00Z"},
+			"verification": {"verified": false, "reason": "gpg.error.no_gpg_keys_found", "signature": "-----BEGIN PGP SIGNATURE-----"}
+		}
+	},
+	{
+		"sha": "2222222222222222222222222222222222222222",
+		"html_url": "https://gitea.example.com/acme/web/commit/2222222222222222222222222222222222222222",
+		"author": {"login": "alice"},
+		"commit": {
+			"message": "Unsigned",
+			"author": {"name": "Alice", "email": "alice@example.com", "date": "2026-03-01T09:45:00Z"},
+			"verification": {"verified": false, "reason": "gpg.error.not_signed_commit", "signature": ""}
+		}
+	}
+]`
+
+func newGiteaTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token gitea-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message": "user does not exist"}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/repos/acme/web/commits/" + mergeCommit + "/pull":
+			fmt.Fprint(w, mergedPR)
+		case "/api/v1/repos/acme/web/pulls":
+			if r.URL.Query().Get("state") != "open" {
+				t.Errorf("unexpected pull request state filter %q", r.URL.Query().Get("state"))
+			}
+			if r.URL.Query().Get("page") == "1" {
+				fmt.Fprint(w, "["+strings.Replace(strings.Replace(mergedPR, `"merged": true`, `"merged": false`, 1), `"closed"`, `"open"`, 1)+"]")
+			} else {
+				fmt.Fprint(w, "[]")
+			}
+		case "/api/v1/repos/acme/web/pulls/7/reviews":
+			fmt.Fprint(w, reviews)
+		case "/api/v1/repos/acme/web/pulls/7/commits":
+			if r.URL.Query().Get("verification") != "true" {
+				t.Errorf("commits are requested without their verification")
+			}
+			fmt.Fprint(w, commits)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "The target couldn't be found."}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewGiteaConfigExtractsRepoName(t *testing.T) {
+	c := NewGiteaConfig("token", "https://gitea.example.com", "acme", "acme/web")
+	assert.Equal(t, "web", c.Repository)
+}
+
+func TestPREvidenceForCommitV2(t *testing.T) {
+	server := newGiteaTestServer(t)
+	c := NewGiteaConfig("gitea-token", server.URL, "acme", "web")
+
+	prs, err := c.PREvidenceForCommitV2(mergeCommit)
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+
+	verified, invalid := true, false
+	valid, noKey := "VALID", "gpg.error.no_gpg_keys_found"
+	assert.Equal(t, &types.PREvidence{
+		URL:         "https://gitea.example.com/acme/web/pulls/7",
+		MergeCommit: mergeCommit,
+		State:       "MERGED",
+		Author:      "alice",
+		CreatedAt:   1772359200,
+		MergedAt:    1772452800,
+		Title:       "Add login form",
+		HeadRef:     "login-form",
+		BaseRef:     "main",
+		Approvers: []any{
+			types.PRApprovals{Username: "bob", UserID: "bob", State: "APPROVED", Timestamp: 1772449200},
+		},
+		Commits: []types.Commit{
+			{
+				SHA:            headCommit,
+				Message:        "Add login form",
+				Author:         "Alice <alice@example.com>",
+				AuthorUsername: "alice",
+				Timestamp:      1772355600,
+				Branch:         "login-form",
+				URL:            "https://gitea.example.com/acme/web/commit/" + headCommit,
+				Verified:       &verified,
+				SignatureState: &valid,
+			},
+			{
+				SHA:            "1111111111111111111111111111111111111111",
+				Message:        "Fix typo",
+				Author:         "Mallory <mallory@example.com>",
+				Timestamp:      1772357400,
+				CommittedAt:    1772361000,
+				Branch:         "login-form",
+				URL:            "https://gitea.example.com/acme/web/commit/1111111111111111111111111111111111111111",
+				Verified:       &invalid,
+				SignatureState: &noKey,
+			},
+			{
+				SHA:            "2222222222222222222222222222222222222222",
+				Message:        "Unsigned",
+				Author:         "Alice <alice@example.com>",
+				AuthorUsername: "alice",
+				Timestamp:      1772358300,
+				Branch:         "login-form",
+				URL:            "https://gitea.example.com/acme/web/commit/2222222222222222222222222222222222222222",
+			},
+		},
+	}, prs[0])
+}
+
+func TestPREvidenceForCommitFindsOpenPullRequestsByHead(t *testing.T) {
+	server := newGiteaTestServer(t)
+	c := NewGiteaConfig("gitea-token", server.URL, "acme", "web")
+
+	prs, err := c.PREvidenceForCommitHybrid(headCommit)
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, "OPEN", prs[0].State)
+	assert.Equal(t, headCommit, prs[0].MergeCommit)
+}
+
+func TestPREvidenceForCommitV1(t *testing.T) {
+	server := newGiteaTestServer(t)
+	c := NewGiteaConfig("gitea-token", server.URL, "acme", "web")
+
+	prs, err := c.PREvidenceForCommitV1(mergeCommit)
+	require.NoError(t, err)
+	assert.Equal(t, []*types.PREvidence{{
+		URL:         "https://gitea.example.com/acme/web/pulls/7",
+		MergeCommit: mergeCommit,
+		State:       "closed",
+		Approvers:   []any{"bob"},
+	}}, prs)
+}
+
+func TestPREvidenceForCommitWithoutPullRequests(t *testing.T) {
+	server := newGiteaTestServer(t)
+	c := NewGiteaConfig("gitea-token", server.URL, "acme", "web")
+
+	prs, err := c.PREvidenceForCommitV2("3333333333333333333333333333333333333333")
+	require.NoError(t, err)
+	require.NotNil(t, prs, "must be an empty slice, not nil")
+	require.Empty(t, prs)
+}
+
+func TestPREvidenceForCommitErrors(t *testing.T) {
+	server := newGiteaTestServer(t)
+
+	c := NewGiteaConfig("gitea-token", server.URL, "acme", "missing")
+	_, err := c.PREvidenceForCommitV2(mergeCommit)
+	require.EqualError(t, err, "repository acme/missing does not exist in Gitea or the token has no access to it")
+
+	c = NewGiteaConfig("wrong", server.URL, "acme", "web")
+	_, err = c.PREvidenceForCommitV2(mergeCommit)
+	require.EqualError(t, err, fmt.Sprintf("call to Gitea API %s/api/v1/repos/acme/web/commits/%s/pull returned HTTP status 401: user does not exist", server.URL, mergeCommit))
+
+	c = NewGiteaConfig("gitea-token", "", "acme", "web")
+	_, err = c.PREvidenceForCommitV2(mergeCommit)
+	require.EqualError(t, err, "a Gitea base URL is required")
+}
@@ -0,0 +1,188 @@
+package github
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/ratelimit"
+	"github.com/kosli-dev/cli/internal/telemetry"
+	"golang.org/x/oauth2"
+)
+
+// AppConfig is a GitHub App installation to authenticate as, instead of
+// with a personal access token.
+type AppConfig struct {
+	// AppID is the App ID or the Client ID of the GitHub App.
+	AppID          string
+	InstallationID int64
+	// PrivateKey is the path to the private key file of the GitHub App, or
+	// the PEM encoded key itself.
+	PrivateKey string
+}
+
+// IsSet reports whether any of the GitHub App settings is set.
+func (a AppConfig) IsSet() bool {
+	return a.AppID != "" || a.InstallationID != 0 || a.PrivateKey != ""
+}
+
+// appTokenSource mints installation access tokens for a GitHub App. The
+// tokens are requested with a JWT signed by the private key of the App.
+type appTokenSource struct {
+	app        AppConfig
+	key        *rsa.PrivateKey
+	baseURL    string
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewAppTokenSource returns an oauth2.TokenSource with installation access
+// tokens of app, which are minted when first needed and again shortly
+// before they expire. baseURL is the GitHub Enterprise URL, or empty for
+// github.com.
+func NewAppTokenSource(app AppConfig, baseURL string) (oauth2.TokenSource, error) {
+	if app.AppID == "" || app.InstallationID == 0 || app.PrivateKey == "" {
+		return nil, fmt.Errorf("a GitHub App needs an App ID, an installation ID and a private key")
+	}
+	key, err := parseAppPrivateKey(app.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	src := &appTokenSource{
+		app:        app,
+		key:        key,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second, Transport: telemetry.NewTransport(ratelimit.NewTransport(nil))},
+		now:        time.Now,
+	}
+	return oauth2.ReuseTokenSource(nil, src), nil
+}
+
+// parseAppPrivateKey reads an RSA private key in PKCS #1 PEM form, which is
+// how GitHub generates them, or in PKCS #8 PEM form.
+func parseAppPrivateKey(privateKey string) (*rsa.PrivateKey, error) {
+	content := []byte(privateKey)
+	if !strings.HasPrefix(strings.TrimSpace(privateKey), "-----BEGIN") {
+		var err error
+		content, err = os.ReadFile(privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the GitHub App private key: %v", err)
+		}
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("the GitHub App private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the GitHub App private key: %v", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// jwt returns a JSON Web Token authenticating as the App, valid for 9
+// minutes. It is issued a minute in the past to allow for clock drift, as
+// GitHub recommends.
+func (s *appTokenSource) jwt() (string, error) {
+	now := s.now()
+	var issuer any = s.app.AppID
+	if _, err := strconv.ParseInt(s.app.AppID, 10, 64); err == nil {
+		issuer = json.Number(s.app.AppID)
+	}
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": issuer,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Token creates an installation access token for the App installation.
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := s.jwt()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign the GitHub App JWT: %v", err)
+	}
+	u := restEndpoint(s.baseURL) + fmt.Sprintf("app/installations/%d/access_tokens", s.app.InstallationID)
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(nil))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a GitHub App installation token: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		var apiError struct {
+			Message string `json:"message"`
+		}
+		message := strings.TrimSpace(string(body))
+		if json.Unmarshal(body, &apiError) == nil && apiError.Message != "" {
+			message = apiError.Message
+		}
+		return nil, fmt.Errorf("failed to create a GitHub App installation token: HTTP status %d: %s", resp.StatusCode, message)
+	}
+
+	var token struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse the GitHub App installation token: %v", err)
+	}
+	return &oauth2.Token{AccessToken: token.Token, Expiry: token.ExpiresAt}, nil
+}
+
+// restEndpoint returns the REST API URL for baseURL, with a trailing slash,
+// the same way go-github does for GitHub Enterprise.
+func restEndpoint(baseURL string) string {
+	if baseURL == "" || strings.TrimSuffix(baseURL, "/") == "https://api.github.com" {
+		return "https://api.github.com/"
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/") + "/"
+	if strings.HasSuffix(baseURL, "/api/v3/") {
+		return baseURL
+	}
+	return baseURL + "api/v3/"
+}
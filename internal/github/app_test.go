@@ -0,0 +1,143 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateAppKey(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return key, string(pemKey)
+}
+
+// newAppTestServer serves installation access tokens for installation 42 to
+// JWTs signed by key, each valid for expiresIn.
+func newAppTestServer(t *testing.T, key *rsa.PrivateKey, expiresIn time.Duration, minted *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v3/app/installations/42/access_tokens" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+			return
+		}
+		jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		parts := strings.Split(jwt, ".")
+		require.Len(t, parts, 3)
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+		if rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature) != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message": "A JSON web token could not be decoded"}`)
+			return
+		}
+		claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		var claims map[string]any
+		require.NoError(t, json.Unmarshal(claimsJSON, &claims))
+		assert.Equal(t, float64(1234), claims["iss"])
+		assert.Equal(t, float64(600), claims["exp"].(float64)-claims["iat"].(float64))
+
+		*minted++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": %q}`, *minted, time.Now().Add(expiresIn).Format(time.RFC3339))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAppTokenSourceMintsAndRefreshesInstallationTokens(t *testing.T) {
+	key, pemKey := generateAppKey(t)
+
+	minted := 0
+	server := newAppTestServer(t, key, time.Hour, &minted)
+	ts, err := NewAppTokenSource(AppConfig{AppID: "1234", InstallationID: 42, PrivateKey: pemKey}, server.URL)
+	require.NoError(t, err)
+	for range 2 {
+		token, err := ts.Token()
+		require.NoError(t, err)
+		assert.Equal(t, "ghs_1", token.AccessToken)
+	}
+	assert.Equal(t, 1, minted, "a token is reused until it expires")
+
+	minted = 0
+	server = newAppTestServer(t, key, time.Second, &minted)
+	ts, err = NewAppTokenSource(AppConfig{AppID: "1234", InstallationID: 42, PrivateKey: pemKey}, server.URL)
+	require.NoError(t, err)
+	for i := range 2 {
+		token, err := ts.Token()
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("ghs_%d", i+1), token.AccessToken)
+	}
+	assert.Equal(t, 2, minted, "a token about to expire is replaced")
+}
+
+func TestAppTokenSourceErrors(t *testing.T) {
+	key, pemKey := generateAppKey(t)
+	_, otherKey := generateAppKey(t)
+	minted := 0
+	server := newAppTestServer(t, key, time.Hour, &minted)
+
+	ts, err := NewAppTokenSource(AppConfig{AppID: "1234", InstallationID: 42, PrivateKey: otherKey}, server.URL)
+	require.NoError(t, err)
+	_, err = ts.Token()
+	require.EqualError(t, err, "failed to create a GitHub App installation token: HTTP status 401: A JSON web token could not be decoded")
+
+	ts, err = NewAppTokenSource(AppConfig{AppID: "1234", InstallationID: 7, PrivateKey: pemKey}, server.URL)
+	require.NoError(t, err)
+	_, err = ts.Token()
+	require.EqualError(t, err, "failed to create a GitHub App installation token: HTTP status 404: Not Found")
+
+	_, err = NewAppTokenSource(AppConfig{AppID: "1234", PrivateKey: pemKey}, server.URL)
+	require.EqualError(t, err, "a GitHub App needs an App ID, an installation ID and a private key")
+}
+
+func TestParseAppPrivateKey(t *testing.T) {
+	key, pemKey := generateAppKey(t)
+
+	parsed, err := parseAppPrivateKey(pemKey)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "app.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), 0600))
+	parsed, err = parseAppPrivateKey(path)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	_, err = parseAppPrivateKey(filepath.Join(t.TempDir(), "missing.pem"))
+	require.ErrorContains(t, err, "failed to read the GitHub App private key")
+
+	require.NoError(t, os.WriteFile(path, []byte("not a key"), 0600))
+	_, err = parseAppPrivateKey(path)
+	require.EqualError(t, err, "the GitHub App private key is not PEM encoded")
+}
+
+func TestRestEndpoint(t *testing.T) {
+	assert.Equal(t, "https://api.github.com/", restEndpoint(""))
+	assert.Equal(t, "https://api.github.com/", restEndpoint("https://api.github.com"))
+	assert.Equal(t, "https://github.acme.com/api/v3/", restEndpoint("https://github.acme.com"))
+	assert.Equal(t, "https://github.acme.com/api/v3/", restEndpoint("https://github.acme.com/api/v3/"))
+}
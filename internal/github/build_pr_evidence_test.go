@@ -80,6 +80,9 @@ func TestBuildPREvidence_UsesAuthoredDate(t *testing.T) {
 	wantAuthored, _ := time.Parse(time.RFC3339, "2026-03-01T10:00:00Z")
 	require.Equal(t, wantAuthored.Unix(), evidence.Commits[0].Timestamp,
 		"timestamp must be the author date, not the committer date")
+	wantCommitted, _ := time.Parse(time.RFC3339, "2026-03-01T12:00:00Z")
+	require.Equal(t, wantCommitted.Unix(), evidence.Commits[0].CommittedAt,
+		"the committer date is kept to find stale approvals")
 }
 
 // TestBuildPREvidence_FallsBackToCommittedDate ensures the timestamp falls back
@@ -12,6 +12,8 @@ import (
 	"time"
 
 	gh "github.com/google/go-github/v42/github"
+	"github.com/kosli-dev/cli/internal/ratelimit"
+	"github.com/kosli-dev/cli/internal/telemetry"
 	"github.com/kosli-dev/cli/internal/types"
 	"github.com/kosli-dev/cli/internal/utils"
 	"github.com/shurcooL/graphql"
@@ -25,6 +27,9 @@ type GithubConfig struct {
 	Org        string
 	Repository string
 	Debug      bool
+	// TokenSource, when set, is used instead of Token, such as for the
+	// installation tokens of a GitHub App (see NewAppTokenSource).
+	TokenSource oauth2.TokenSource
 	// Sleep is called between retries in PREvidenceByPRNumber. Defaults to
 	// time.Sleep when nil. Override in tests to avoid real delays.
 	Sleep func(time.Duration)
@@ -35,6 +40,7 @@ type GithubFlagsTempValueHolder struct {
 	BaseURL    string
 	Org        string
 	Repository string
+	App        AppConfig
 }
 
 // NewGithubConfig returns a new GithubConfig
@@ -58,26 +64,37 @@ func extractRepoName(fullRepositoryName string) string {
 }
 
 // NewGithubClientFromToken returns Github client with a token and context.
-// When debug is true the underlying transport is wrapped so every HTTP
-// request and response (REST and GraphQL) is dumped to stderr.
+// Requests rejected by the primary or secondary rate limits are retried once
+// the limit resets (see ratelimit.Transport). When debug is true the
+// underlying transport is wrapped so every HTTP request and response (REST
+// and GraphQL) is dumped to stderr; retries of rate limited requests are
+// not dumped separately.
 //
 // debugTransport is installed as oauth2.Transport.Base (not as a wrapper
 // around it) so the Authorization header that oauth2 attaches is visible
 // in the dump — otherwise debug logs the request before oauth2 adds the
 // header and we lose the ability to verify it was actually sent.
 func NewGithubClientFromToken(ctx context.Context, ghToken string, baseURL string, debug bool) (*gh.Client, error) {
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: ghToken},
-	)
+	return newGithubClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: ghToken}), baseURL, debug)
+}
+
+// newClient returns a Github client authenticated with TokenSource when it
+// is set, and with Token otherwise.
+func (c *GithubConfig) newClient(ctx context.Context) (*gh.Client, error) {
+	if c.TokenSource != nil {
+		return newGithubClient(ctx, c.TokenSource, c.BaseURL, c.Debug)
+	}
+	return NewGithubClientFromToken(ctx, c.Token, c.BaseURL, c.Debug)
+}
+
+func newGithubClient(ctx context.Context, ts oauth2.TokenSource, baseURL string, debug bool) (*gh.Client, error) {
 	tc := oauth2.NewClient(ctx, ts)
+	t := tc.Transport.(*oauth2.Transport)
+	var base http.RoundTripper = ratelimit.NewTransport(t.Base)
 	if debug {
-		t := tc.Transport.(*oauth2.Transport)
-		base := t.Base
-		if base == nil {
-			base = http.DefaultTransport
-		}
-		t.Base = &debugTransport{base: base, out: os.Stderr}
+		base = &debugTransport{base: base, out: os.Stderr}
 	}
+	t.Base = telemetry.NewTransport(base)
 	if baseURL != "" {
 		client, err := gh.NewEnterpriseClient(baseURL, baseURL, tc)
 		if err != nil {
@@ -208,6 +225,11 @@ func graphqlEndpoint(baseURL string) string {
 	return result
 }
 
+// CacheScope identifies the repository for the pull request cache.
+func (c *GithubConfig) CacheScope() string {
+	return "github|" + graphqlEndpoint(c.BaseURL) + "|" + c.Org + "/" + c.Repository
+}
+
 func (c *GithubConfig) ProviderAndLabel() (string, string) {
 	return "github", "pull request"
 }
@@ -334,6 +356,16 @@ func buildPREvidence(
 		if err != nil {
 			return nil, err
 		}
+		// the committed date moves when the commit is rebased or amended, so
+		// that approvals given before it was pushed again are stale
+		committedAt := int64(0)
+		if n.Commit.CommittedDate != "" {
+			committed, err := time.Parse(time.RFC3339, string(n.Commit.CommittedDate))
+			if err != nil {
+				return nil, err
+			}
+			committedAt = committed.Unix()
+		}
 		authorUsername := ""
 		if n.Commit.Author.User != nil {
 			authorUsername = string(n.Commit.Author.User.Login)
@@ -355,6 +387,7 @@ func buildPREvidence(
 			Author:         fmt.Sprintf("%s <%s>", string(n.Commit.Author.Name), string(n.Commit.Author.Email)),
 			AuthorUsername: authorUsername,
 			Timestamp:      timestamp.Unix(),
+			CommittedAt:    committedAt,
 			Branch:         headRef,
 			URL:            string(n.Commit.URL),
 			Verified:       verified,
@@ -369,6 +402,7 @@ func buildPREvidence(
 		}
 		evidence.Approvers = append(evidence.Approvers, types.PRApprovals{
 			Username:  string(r.Author.Login),
+			UserID:    string(r.Author.Login),
 			State:     string(r.State),
 			Timestamp: submittedAt.Unix(),
 		})
@@ -382,7 +416,7 @@ func buildPREvidence(
 func (c *GithubConfig) PREvidenceByPRNumber(prNumber int) (*types.PREvidence, error) {
 	ctx := context.Background()
 
-	ghClient, err := NewGithubClientFromToken(ctx, c.Token, c.BaseURL, c.Debug)
+	ghClient, err := c.newClient(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -470,7 +504,7 @@ func (c *GithubConfig) PREvidenceForCommitV2(commit string) ([]*types.PREvidence
 	ctx := context.Background()
 	pullRequestsEvidence := []*types.PREvidence{}
 
-	ghClient, err := NewGithubClientFromToken(ctx, c.Token, c.BaseURL, c.Debug)
+	ghClient, err := c.newClient(ctx)
 	if err != nil {
 		return pullRequestsEvidence, err
 	}
@@ -581,7 +615,7 @@ func (c *GithubConfig) PREvidenceForCommitV1(commit string) ([]*types.PREvidence
 // PullRequestsForCommit returns a list of pull requests for a specific commit
 func (c *GithubConfig) PullRequestsForCommit(commit string) ([]*gh.PullRequest, error) {
 	ctx := context.Background()
-	client, err := NewGithubClientFromToken(ctx, c.Token, c.BaseURL, c.Debug)
+	client, err := c.newClient(ctx)
 	if err != nil {
 		return []*gh.PullRequest{}, err
 	}
@@ -595,7 +629,7 @@ func (c *GithubConfig) PullRequestsForCommit(commit string) ([]*gh.PullRequest,
 func (c *GithubConfig) GetPullRequestApprovers(number int) ([]string, error) {
 	approvers := []string{}
 	ctx := context.Background()
-	client, err := NewGithubClientFromToken(ctx, c.Token, c.BaseURL, c.Debug)
+	client, err := c.newClient(ctx)
 	if err != nil {
 		return approvers, err
 	}
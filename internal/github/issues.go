@@ -0,0 +1,80 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/issues"
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// NewGithubIssueRetrieverFunc creates a types.IssueRetriever from GitHub config
+// parameters. It can be replaced in tests to inject a fake.
+var NewGithubIssueRetrieverFunc = defaultNewGithubIssueRetriever
+
+func defaultNewGithubIssueRetriever(token, baseURL, org, repository string, debug bool) types.IssueRetriever {
+	return NewGithubConfig(token, baseURL, org, repository, debug)
+}
+
+// ResetGithubIssueRetrieverFunc restores NewGithubIssueRetrieverFunc to its default.
+func ResetGithubIssueRetrieverFunc() {
+	NewGithubIssueRetrieverFunc = defaultNewGithubIssueRetriever
+}
+
+func (c *GithubConfig) IssueProvider() string {
+	return "github"
+}
+
+// FindIssueReferences finds "#12" and "owner/repo#12" references in text.
+// "#12" refers to an issue of the configured repository.
+func (c *GithubConfig) FindIssueReferences(text string) []string {
+	return issues.FindNumberedReferences(text, c.Org+"/"+c.Repository, 1)
+}
+
+// IssueInfo looks up a GitHub issue from an "owner/repo#12" reference. GitHub
+// numbers issues and pull requests together, and "#12" in a commit message is
+// as likely to name a pull request (e.g. in a squash-merge commit title); a
+// pull request is not an issue, so nil is returned for it.
+func (c *GithubConfig) IssueInfo(reference string) (*types.IssueInfo, error) {
+	ref, err := issues.ParseNumberedReference(reference)
+	if err != nil {
+		return nil, err
+	}
+	owner, repo, _ := strings.Cut(ref.Project, "/")
+	result := &types.IssueInfo{
+		IssueID:  reference,
+		IssueURL: c.issueURL(ref),
+	}
+
+	ctx := context.Background()
+	client, err := c.newClient(ctx)
+	if err != nil {
+		return result, err
+	}
+	issue, response, err := client.Issues.Get(ctx, owner, repo, ref.Number)
+	if err != nil {
+		if response != nil && (response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone) {
+			return result, nil
+		}
+		return result, err
+	}
+	if issue.IsPullRequest() {
+		return nil, nil
+	}
+	result.IssueExists = true
+	result.IssueURL = issue.GetHTMLURL()
+	result.Title = issue.GetTitle()
+	result.State = issue.GetState()
+	return result, nil
+}
+
+// issueURL returns the web URL of an issue, for issues that cannot be looked up.
+func (c *GithubConfig) issueURL(ref issues.Reference) string {
+	host := "https://github.com"
+	if c.BaseURL != "" {
+		host = strings.TrimSuffix(strings.TrimSuffix(c.BaseURL, "/"), "/api/v3")
+	}
+	return host + "/" + ref.Project + "/issues/" + strconv.Itoa(ref.Number)
+}
@@ -0,0 +1,60 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIssueTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/acme/web/issues/7", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number": 7, "title": "Login fails", "state": "closed", "html_url": "https://github.com/acme/web/issues/7"}`)
+	})
+	mux.HandleFunc("GET /api/v3/repos/acme/web/issues/12", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number": 12, "title": "Fix login", "state": "closed", "pull_request": {"url": "https://api.github.com/repos/acme/web/pulls/12"}}`)
+	})
+	mux.HandleFunc("GET /api/v3/repos/acme/web/issues/500", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGithubFindIssueReferences(t *testing.T) {
+	c := NewGithubConfig("token", "", "acme", "acme/web", false)
+	assert.Equal(t, []string{"acme/api#3", "acme/web#7"}, c.FindIssueReferences("Fix login, closes #7 and acme/api#3, not group/sub/repo#4"))
+}
+
+func TestGithubIssueInfo(t *testing.T) {
+	server := newIssueTestServer(t)
+	c := NewGithubConfig("token", server.URL, "acme", "web", false)
+
+	info, err := c.IssueInfo("acme/web#7")
+	require.NoError(t, err)
+	assert.Equal(t, &types.IssueInfo{
+		IssueID:     "acme/web#7",
+		IssueURL:    "https://github.com/acme/web/issues/7",
+		IssueExists: true,
+		Title:       "Login fails",
+		State:       "closed",
+	}, info)
+
+	info, err = c.IssueInfo("acme/web#8")
+	require.NoError(t, err)
+	assert.Equal(t, &types.IssueInfo{IssueID: "acme/web#8", IssueURL: server.URL + "/acme/web/issues/8"}, info)
+
+	info, err = c.IssueInfo("acme/web#12")
+	require.NoError(t, err)
+	assert.Nil(t, info, "a pull request is not an issue")
+
+	_, err = c.IssueInfo("acme/web#500")
+	require.Error(t, err)
+}
@@ -0,0 +1,165 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	gh "github.com/google/go-github/v42/github"
+	"github.com/kosli-dev/cli/internal/reposettings"
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// NewGithubRepoSettingsRetrieverFunc creates a types.RepoSettingsRetriever
+// from GitHub config parameters. It can be replaced in tests to inject a fake.
+var NewGithubRepoSettingsRetrieverFunc = defaultNewGithubRepoSettingsRetriever
+
+func defaultNewGithubRepoSettingsRetriever(token, baseURL, org, repository string, debug bool) types.RepoSettingsRetriever {
+	return NewGithubConfig(token, baseURL, org, repository, debug)
+}
+
+// ResetGithubRepoSettingsRetrieverFunc restores NewGithubRepoSettingsRetrieverFunc to its default.
+func ResetGithubRepoSettingsRetrieverFunc() {
+	NewGithubRepoSettingsRetrieverFunc = defaultNewGithubRepoSettingsRetriever
+}
+
+func (c *GithubConfig) RepoSettingsProvider() string {
+	return "github"
+}
+
+// githubRule is a rule of the rulesets applying to a branch.
+type githubRule struct {
+	Type              string          `json:"type"`
+	Parameters        json.RawMessage `json:"parameters,omitempty"`
+	RulesetSourceType string          `json:"ruleset_source_type,omitempty"`
+	RulesetSource     string          `json:"ruleset_source,omitempty"`
+	RulesetID         int64           `json:"ruleset_id,omitempty"`
+}
+
+type githubPullRequestRule struct {
+	RequiredApprovingReviewCount int  `json:"required_approving_review_count"`
+	DismissStaleReviewsOnPush    bool `json:"dismiss_stale_reviews_on_push"`
+	RequireCodeOwnerReview       bool `json:"require_code_owner_review"`
+}
+
+type githubStatusChecksRule struct {
+	RequiredStatusChecks []struct {
+		Context string `json:"context"`
+	} `json:"required_status_checks"`
+}
+
+// RepoSettings combines the classic branch protection of branch with the
+// rules of the rulesets applying to it. Reading the branch protection needs
+// admin access to the repository.
+func (c *GithubConfig) RepoSettings(branch string) (*types.RepoSettings, error) {
+	ctx := context.Background()
+	client, err := c.newClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings := reposettings.Unprotected(c.Org+"/"+c.Repository, branch)
+
+	protection, _, err := client.Repositories.GetBranchProtection(ctx, c.Org, c.Repository, branch)
+	switch {
+	case errors.Is(err, gh.ErrBranchNotProtected):
+	case err != nil:
+		return nil, fmt.Errorf("failed to get the protection of branch %s: %v", branch, err)
+	default:
+		signatures, _, err := client.Repositories.GetSignaturesProtectedBranch(ctx, c.Org, c.Repository, branch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the signature protection of branch %s: %v", branch, err)
+		}
+		applyGithubProtection(settings, protection, signatures.GetEnabled())
+	}
+
+	rules, err := c.branchRules(ctx, client, branch)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if err := applyGithubRule(settings, rule); err != nil {
+			return nil, err
+		}
+	}
+	return settings, nil
+}
+
+// branchRules returns the rules of all the active rulesets applying to
+// branch. GitHub Enterprise Server versions without rulesets have none.
+func (c *GithubConfig) branchRules(ctx context.Context, client *gh.Client, branch string) ([]*githubRule, error) {
+	rules := []*githubRule{}
+	for page := 1; page != 0; {
+		u := fmt.Sprintf("repos/%v/%v/rules/branches/%v?per_page=100&page=%d", c.Org, c.Repository, branch, page)
+		req, err := client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		pageRules := []*githubRule{}
+		response, err := client.Do(ctx, req, &pageRules)
+		if err != nil {
+			if response != nil && response.StatusCode == http.StatusNotFound {
+				return rules, nil
+			}
+			return nil, fmt.Errorf("failed to get the rules of branch %s: %v", branch, err)
+		}
+		rules = append(rules, pageRules...)
+		page = response.NextPage
+	}
+	return rules, nil
+}
+
+func applyGithubProtection(settings *types.RepoSettings, protection *gh.Protection, signedCommits bool) {
+	settings.Protected = true
+	settings.Rules = append(settings.Rules, map[string]any{"type": "branch_protection", "parameters": protection})
+	if reviews := protection.RequiredPullRequestReviews; reviews != nil {
+		settings.RequiredApprovals = max(settings.RequiredApprovals, reviews.RequiredApprovingReviewCount)
+		settings.DismissStaleApprovals = settings.DismissStaleApprovals || reviews.DismissStaleReviews
+		settings.RequireCodeOwnerReview = settings.RequireCodeOwnerReview || reviews.RequireCodeOwnerReviews
+	}
+	if checks := protection.RequiredStatusChecks; checks != nil {
+		reposettings.AddStatusChecks(settings, checks.Contexts...)
+	}
+	settings.RequireSignedCommits = signedCommits
+	settings.EnforceAdmins = protection.EnforceAdmins != nil && protection.EnforceAdmins.Enabled
+	settings.RequireLinearHistory = protection.RequireLinearHistory != nil && protection.RequireLinearHistory.Enabled
+	settings.AllowForcePushes = protection.AllowForcePushes != nil && protection.AllowForcePushes.Enabled
+	settings.AllowDeletions = protection.AllowDeletions != nil && protection.AllowDeletions.Enabled
+}
+
+// applyGithubRule adds a ruleset rule to settings. Rulesets only add
+// restrictions to those of the branch protection.
+func applyGithubRule(settings *types.RepoSettings, rule *githubRule) error {
+	settings.Protected = true
+	settings.Rules = append(settings.Rules, rule)
+	switch rule.Type {
+	case "pull_request":
+		var parameters githubPullRequestRule
+		if err := json.Unmarshal(rule.Parameters, &parameters); err != nil {
+			return fmt.Errorf("failed to parse the pull_request rule of ruleset %d: %v", rule.RulesetID, err)
+		}
+		settings.RequiredApprovals = max(settings.RequiredApprovals, parameters.RequiredApprovingReviewCount)
+		settings.DismissStaleApprovals = settings.DismissStaleApprovals || parameters.DismissStaleReviewsOnPush
+		settings.RequireCodeOwnerReview = settings.RequireCodeOwnerReview || parameters.RequireCodeOwnerReview
+	case "required_status_checks":
+		var parameters githubStatusChecksRule
+		if err := json.Unmarshal(rule.Parameters, &parameters); err != nil {
+			return fmt.Errorf("failed to parse the required_status_checks rule of ruleset %d: %v", rule.RulesetID, err)
+		}
+		contexts := []string{}
+		for _, check := range parameters.RequiredStatusChecks {
+			contexts = append(contexts, check.Context)
+		}
+		reposettings.AddStatusChecks(settings, contexts...)
+	case "required_signatures":
+		settings.RequireSignedCommits = true
+	case "required_linear_history":
+		settings.RequireLinearHistory = true
+	case "non_fast_forward":
+		settings.AllowForcePushes = false
+	case "deletion":
+		settings.AllowDeletions = false
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRepoSettingsTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/acme/web/branches/main/protection", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"required_status_checks": {"strict": true, "contexts": ["build"]},
+			"required_pull_request_reviews": {"dismiss_stale_reviews": true, "require_code_owner_reviews": false, "required_approving_review_count": 1},
+			"enforce_admins": {"enabled": true},
+			"required_linear_history": {"enabled": false},
+			"allow_force_pushes": {"enabled": false},
+			"allow_deletions": {"enabled": false}
+		}`)
+	})
+	mux.HandleFunc("GET /api/v3/repos/acme/web/branches/main/protection/required_signatures", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"enabled": false}`)
+	})
+	mux.HandleFunc("GET /api/v3/repos/acme/web/rules/branches/main", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"type": "pull_request", "ruleset_id": 4, "ruleset_source_type": "Organization", "ruleset_source": "acme",
+			 "parameters": {"required_approving_review_count": 2, "dismiss_stale_reviews_on_push": false, "require_code_owner_review": true}},
+			{"type": "required_status_checks", "ruleset_id": 4, "parameters": {"required_status_checks": [{"context": "test"}, {"context": "build"}]}},
+			{"type": "required_signatures", "ruleset_id": 4}
+		]`)
+	})
+	mux.HandleFunc("GET /api/v3/repos/acme/web/branches/dev/protection", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Branch not protected"}`)
+	})
+	mux.HandleFunc("GET /api/v3/repos/acme/web/rules/branches/dev", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("GET /api/v3/repos/acme/web/branches/secret/protection", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGithubRepoSettings(t *testing.T) {
+	server := newRepoSettingsTestServer(t)
+	c := NewGithubConfig("token", server.URL, "acme", "acme/web", false)
+
+	settings, err := c.RepoSettings("main")
+	require.NoError(t, err)
+	assert.Equal(t, "acme/web", settings.Repository)
+	assert.True(t, settings.Protected)
+	assert.Equal(t, 2, settings.RequiredApprovals, "the strictest of the branch protection and the rulesets")
+	assert.True(t, settings.DismissStaleApprovals)
+	assert.True(t, settings.RequireCodeOwnerReview)
+	assert.True(t, settings.RequireStatusChecks)
+	assert.Equal(t, []string{"build", "test"}, settings.RequiredStatusChecks)
+	assert.True(t, settings.RequireSignedCommits)
+	assert.False(t, settings.RequireLinearHistory)
+	assert.False(t, settings.AllowForcePushes)
+	assert.False(t, settings.AllowDeletions)
+	assert.True(t, settings.EnforceAdmins)
+	assert.Len(t, settings.Rules, 4)
+
+	settings, err = c.RepoSettings("dev")
+	require.NoError(t, err)
+	assert.False(t, settings.Protected)
+	assert.True(t, settings.AllowForcePushes)
+	assert.True(t, settings.AllowDeletions)
+	assert.Empty(t, settings.Rules)
+
+	_, err = c.RepoSettings("secret")
+	require.ErrorContains(t, err, "failed to get the protection of branch secret")
+}
@@ -135,3 +135,14 @@ func TestGitlabMRRefs(t *testing.T) {
 	require.Equal(t, "feature-branch", head)
 	require.Equal(t, "main", base)
 }
+
+func TestGitlabUserIDByEmail(t *testing.T) {
+	users := []*gitlab.User{
+		{ID: 7, Username: "tooky", PublicEmail: "Tooky@kosli.com"},
+		{ID: 8, Username: "tooky-fan", PublicEmail: "fan@example.com"},
+	}
+	require.Equal(t, "7", gitlabUserIDByEmail(users, "tooky@kosli.com"))
+	require.Empty(t, gitlabUserIDByEmail(users, "private@kosli.com"), "a user with a private email is not found")
+	require.Empty(t, gitlabUserIDByEmail(append(users, &gitlab.User{ID: 9, Email: "tooky@kosli.com"}), "tooky@kosli.com"),
+		"an email of several users does not identify one")
+}
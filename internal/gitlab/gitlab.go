@@ -3,7 +3,11 @@ package gitlab
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/kosli-dev/cli/internal/telemetry"
 	"github.com/kosli-dev/cli/internal/types"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
@@ -21,10 +25,16 @@ func (c *GitlabConfig) GetClientOptFns() []gitlab.ClientOptionFunc {
 	if c.BaseURL != "" {
 		clientOptFns = append(clientOptFns, gitlab.WithBaseURL(c.BaseURL))
 	}
+	if telemetry.Enabled() {
+		clientOptFns = append(clientOptFns, gitlab.WithHTTPClient(&http.Client{
+			Transport: telemetry.NewTransport(cleanhttp.DefaultPooledTransport()),
+		}))
+	}
 	return clientOptFns
 }
 
-// NewGitlabClientFromToken returns an API client from GitlabConfig
+// NewGitlabClientFromToken returns an API client from GitlabConfig. The client
+// retries rate limited requests itself, waiting for the RateLimit-Reset time.
 func (c *GitlabConfig) NewGitlabClientFromToken() (*gitlab.Client, error) {
 	client, err := gitlab.NewClient(c.Token, c.GetClientOptFns()...)
 	if err != nil {
@@ -39,6 +49,11 @@ func (c *GitlabConfig) ProjectID() string {
 	return fmt.Sprintf("%s/%s", c.Org, c.Repository)
 }
 
+// CacheScope identifies the repository for the pull request cache.
+func (c *GitlabConfig) CacheScope() string {
+	return "gitlab|" + c.BaseURL + "|" + c.ProjectID()
+}
+
 func (c *GitlabConfig) ProviderAndLabel() (string, string) {
 	return "gitlab", "merge request"
 }
@@ -174,6 +189,7 @@ func (c *GitlabConfig) GetMergeRequestApprovers(mrIID, version int64) ([]any, er
 		} else {
 			approvers = append(approvers, types.PRApprovals{
 				Username: approverName,
+				UserID:   strconv.FormatInt(approver.User.ID, 10),
 			})
 		}
 	}
@@ -192,8 +208,21 @@ func (c *GitlabConfig) GetMergeRequestCommits(mr *gitlab.BasicMergeRequest) ([]t
 	if err != nil {
 		return commits, err
 	}
+	// commits have no account, so the account of the author is found from
+	// its email, once per email
+	authorIDs := map[string]string{}
 	for _, commit := range glCommits {
 		mappedCommit := commitFromGitlabCommit(commit, mr.SourceBranch)
+		authorID, ok := authorIDs[commit.AuthorEmail]
+		if !ok && commit.AuthorEmail != "" {
+			users, _, err := client.Users.ListUsers(&gitlab.ListUsersOptions{Search: gitlab.Ptr(commit.AuthorEmail)})
+			if err != nil {
+				return commits, fmt.Errorf("failed to find the GitLab user of %s: %v", commit.AuthorEmail, err)
+			}
+			authorID = gitlabUserIDByEmail(users, commit.AuthorEmail)
+			authorIDs[commit.AuthorEmail] = authorID
+		}
+		mappedCommit.AuthorID = authorID
 		verified, signatureState, err := resolveGitlabSignature(
 			client.Commits.GetGPGSignature(c.ProjectID(), commit.ID),
 		)
@@ -207,6 +236,22 @@ func (c *GitlabConfig) GetMergeRequestCommits(mr *gitlab.BasicMergeRequest) ([]t
 	return commits, nil
 }
 
+// gitlabUserIDByEmail returns the ID of the only one of users with email. It
+// is empty when none of them has it, as when the email of a user is private,
+// which only administrators can search.
+func gitlabUserIDByEmail(users []*gitlab.User, email string) string {
+	id := ""
+	for _, user := range users {
+		if strings.EqualFold(user.Email, email) || strings.EqualFold(user.PublicEmail, email) {
+			if id != "" {
+				return ""
+			}
+			id = strconv.FormatInt(user.ID, 10)
+		}
+	}
+	return id
+}
+
 // commitFromGitlabCommit maps a GitLab API commit to a types.Commit.
 func commitFromGitlabCommit(commit *gitlab.Commit, branch string) types.Commit {
 	// Use the authored date to match the recorded author identity; fall back to
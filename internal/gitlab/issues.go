@@ -0,0 +1,77 @@
+package gitlab
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/issues"
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// NewGitlabIssueRetrieverFunc creates a types.IssueRetriever from GitLab config
+// parameters. It can be replaced in tests to inject a fake.
+var NewGitlabIssueRetrieverFunc = defaultNewGitlabIssueRetriever
+
+func defaultNewGitlabIssueRetriever(token, baseURL, org, repository string) types.IssueRetriever {
+	return &GitlabConfig{
+		Token:      token,
+		BaseURL:    baseURL,
+		Org:        org,
+		Repository: repository,
+	}
+}
+
+// ResetGitlabIssueRetrieverFunc restores NewGitlabIssueRetrieverFunc to its default.
+func ResetGitlabIssueRetrieverFunc() {
+	NewGitlabIssueRetrieverFunc = defaultNewGitlabIssueRetriever
+}
+
+func (c *GitlabConfig) IssueProvider() string {
+	return "gitlab"
+}
+
+// FindIssueReferences finds "#12" and "group/project#12" references in text,
+// with projects in nested groups. "#12" refers to an issue of the configured
+// project. Merge requests ("!12") use a different syntax and are not matched.
+func (c *GitlabConfig) FindIssueReferences(text string) []string {
+	return issues.FindNumberedReferences(text, c.ProjectID(), 0)
+}
+
+// IssueInfo looks up a GitLab issue from a "group/project#12" reference.
+func (c *GitlabConfig) IssueInfo(reference string) (*types.IssueInfo, error) {
+	ref, err := issues.ParseNumberedReference(reference)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.IssueInfo{
+		IssueID:  reference,
+		IssueURL: c.issueURL(ref),
+	}
+
+	client, err := c.NewGitlabClientFromToken()
+	if err != nil {
+		return result, err
+	}
+	issue, response, err := client.Issues.GetIssue(ref.Project, int64(ref.Number))
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return result, nil
+		}
+		return result, err
+	}
+	result.IssueExists = true
+	result.IssueURL = issue.WebURL
+	result.Title = issue.Title
+	result.State = issue.State
+	return result, nil
+}
+
+// issueURL returns the web URL of an issue, for issues that cannot be looked up.
+func (c *GitlabConfig) issueURL(ref issues.Reference) string {
+	host := "https://gitlab.com"
+	if c.BaseURL != "" {
+		host = strings.TrimSuffix(strings.TrimSuffix(c.BaseURL, "/"), "/api/v4")
+	}
+	return host + "/" + ref.Project + "/-/issues/" + strconv.Itoa(ref.Number)
+}
@@ -0,0 +1,46 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitlabFindIssueReferences(t *testing.T) {
+	c := &GitlabConfig{Org: "acme", Repository: "web"}
+	assert.Equal(t, []string{"acme/web#7", "group/sub/api#3"},
+		c.FindIssueReferences("Fix login, closes #7 and group/sub/api#3, see !12"))
+}
+
+func TestGitlabIssueInfo(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/group%2Fsub%2Fapi/issues/3", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 1003, "iid": 3, "title": "Timeouts", "state": "opened", "web_url": "https://gitlab.example.com/group/sub/api/-/issues/3"}`)
+	})
+	mux.HandleFunc("GET /api/v4/projects/group%2Fsub%2Fapi/issues/4", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "404 Not found"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := &GitlabConfig{Token: "token", BaseURL: server.URL, Org: "acme", Repository: "web"}
+
+	info, err := c.IssueInfo("group/sub/api#3")
+	require.NoError(t, err)
+	assert.Equal(t, &types.IssueInfo{
+		IssueID:     "group/sub/api#3",
+		IssueURL:    "https://gitlab.example.com/group/sub/api/-/issues/3",
+		IssueExists: true,
+		Title:       "Timeouts",
+		State:       "opened",
+	}, info)
+
+	info, err = c.IssueInfo("group/sub/api#4")
+	require.NoError(t, err)
+	assert.Equal(t, &types.IssueInfo{IssueID: "group/sub/api#4", IssueURL: server.URL + "/group/sub/api/-/issues/4"}, info)
+}
@@ -0,0 +1,169 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kosli-dev/cli/internal/reposettings"
+	"github.com/kosli-dev/cli/internal/types"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// NewGitlabRepoSettingsRetrieverFunc creates a types.RepoSettingsRetriever
+// from GitLab config parameters. It can be replaced in tests to inject a fake.
+var NewGitlabRepoSettingsRetrieverFunc = defaultNewGitlabRepoSettingsRetriever
+
+func defaultNewGitlabRepoSettingsRetriever(token, baseURL, org, repository string) types.RepoSettingsRetriever {
+	return &GitlabConfig{
+		Token:      token,
+		BaseURL:    baseURL,
+		Org:        org,
+		Repository: repository,
+	}
+}
+
+// ResetGitlabRepoSettingsRetrieverFunc restores NewGitlabRepoSettingsRetrieverFunc to its default.
+func ResetGitlabRepoSettingsRetrieverFunc() {
+	NewGitlabRepoSettingsRetrieverFunc = defaultNewGitlabRepoSettingsRetriever
+}
+
+func (c *GitlabConfig) RepoSettingsProvider() string {
+	return "gitlab"
+}
+
+// unavailable reports whether response says an API is not available to the
+// project, such as the approval and push rule APIs of GitLab Premium on
+// GitLab Free.
+func unavailable(response *gitlab.Response) bool {
+	return response != nil && (response.StatusCode == http.StatusForbidden || response.StatusCode == http.StatusNotFound)
+}
+
+// RepoSettings combines the protected branch rules matching branch with the
+// merge request approval rules applying to it and the merge request and push
+// rule settings of the project. When several protected branch rules match
+// branch, force pushes are only allowed if all of them allow it.
+func (c *GitlabConfig) RepoSettings(branch string) (*types.RepoSettings, error) {
+	client, err := c.NewGitlabClientFromToken()
+	if err != nil {
+		return nil, err
+	}
+	settings := reposettings.Unprotected(c.ProjectID(), branch)
+
+	protectedBranches, err := c.protectedBranches(client)
+	if err != nil {
+		return nil, err
+	}
+	protectedNames := map[string]bool{}
+	for _, protectedBranch := range protectedBranches {
+		if !reposettings.MatchBranch(protectedBranch.Name, branch) {
+			continue
+		}
+		if !settings.Protected {
+			settings.Protected = true
+			settings.AllowDeletions = false
+		}
+		protectedNames[protectedBranch.Name] = true
+		settings.AllowForcePushes = settings.AllowForcePushes && protectedBranch.AllowForcePush
+		settings.RequireCodeOwnerReview = settings.RequireCodeOwnerReview || protectedBranch.CodeOwnerApprovalRequired
+		settings.Rules = append(settings.Rules, map[string]any{"type": "protected_branch", "parameters": protectedBranch})
+	}
+
+	approvalRules, err := c.approvalRules(client)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range approvalRules {
+		if !approvalRuleApplies(rule, settings.Protected, protectedNames) {
+			continue
+		}
+		settings.RequiredApprovals = max(settings.RequiredApprovals, int(rule.ApprovalsRequired))
+		settings.Rules = append(settings.Rules, map[string]any{"type": "approval_rule", "parameters": rule})
+	}
+
+	approvals, response, err := client.Projects.GetApprovalConfiguration(c.ProjectID())
+	if err != nil && !unavailable(response) {
+		return nil, fmt.Errorf("failed to get the merge request approval settings of project %s: %v", c.ProjectID(), err)
+	}
+	if err == nil {
+		settings.DismissStaleApprovals = approvals.ResetApprovalsOnPush
+		settings.Rules = append(settings.Rules, map[string]any{"type": "approval_settings", "parameters": approvals})
+	}
+
+	pushRules, response, err := client.Projects.GetProjectPushRules(c.ProjectID())
+	if err != nil && !unavailable(response) {
+		return nil, fmt.Errorf("failed to get the push rules of project %s: %v", c.ProjectID(), err)
+	}
+	if err == nil && pushRules != nil {
+		settings.RequireSignedCommits = pushRules.RejectUnsignedCommits
+		settings.Rules = append(settings.Rules, map[string]any{"type": "push_rules", "parameters": pushRules})
+	}
+
+	project, _, err := client.Projects.GetProject(c.ProjectID(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project %s: %v", c.ProjectID(), err)
+	}
+	if project.OnlyAllowMergeIfPipelineSucceeds {
+		reposettings.AddStatusChecks(settings)
+	}
+	settings.RequireLinearHistory = project.MergeMethod == gitlab.FastForwardMerge
+	settings.Rules = append(settings.Rules, map[string]any{"type": "merge_request_settings", "parameters": map[string]any{
+		"only_allow_merge_if_pipeline_succeeds": project.OnlyAllowMergeIfPipelineSucceeds,
+		"merge_method":                          project.MergeMethod,
+	}})
+	return settings, nil
+}
+
+// approvalRuleApplies reports whether a project approval rule applies to a
+// branch. A rule without protected branches applies to all branches.
+func approvalRuleApplies(rule *gitlab.ProjectApprovalRule, protected bool, protectedNames map[string]bool) bool {
+	if rule.RuleType == "report_approver" {
+		return false
+	}
+	if rule.AppliesToAllProtectedBranches {
+		return protected
+	}
+	if len(rule.ProtectedBranches) == 0 {
+		return true
+	}
+	for _, protectedBranch := range rule.ProtectedBranches {
+		if protectedNames[protectedBranch.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *GitlabConfig) protectedBranches(client *gitlab.Client) ([]*gitlab.ProtectedBranch, error) {
+	result := []*gitlab.ProtectedBranch{}
+	opt := &gitlab.ListProtectedBranchesOptions{ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1}}
+	for {
+		page, response, err := client.ProtectedBranches.ListProtectedBranches(c.ProjectID(), opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the protected branches of project %s: %v", c.ProjectID(), err)
+		}
+		result = append(result, page...)
+		if response.NextPage == 0 {
+			return result, nil
+		}
+		opt.Page = response.NextPage
+	}
+}
+
+func (c *GitlabConfig) approvalRules(client *gitlab.Client) ([]*gitlab.ProjectApprovalRule, error) {
+	result := []*gitlab.ProjectApprovalRule{}
+	opt := &gitlab.GetProjectApprovalRulesListsOptions{ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1}}
+	for {
+		page, response, err := client.Projects.GetProjectApprovalRules(c.ProjectID(), opt)
+		if err != nil {
+			if unavailable(response) {
+				return result, nil
+			}
+			return nil, fmt.Errorf("failed to list the approval rules of project %s: %v", c.ProjectID(), err)
+		}
+		result = append(result, page...)
+		if response.NextPage == 0 {
+			return result, nil
+		}
+		opt.Page = response.NextPage
+	}
+}
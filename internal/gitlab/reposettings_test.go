@@ -0,0 +1,67 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitlabRepoSettings(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/acme%2Fweb/protected_branches", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"id": 1, "name": "main", "allow_force_push": false, "code_owner_approval_required": true},
+			{"id": 2, "name": "release/*", "allow_force_push": true}
+		]`)
+	})
+	mux.HandleFunc("GET /api/v4/projects/acme%2Fweb/approval_rules", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"id": 10, "name": "All members", "rule_type": "any_approver", "approvals_required": 1},
+			{"id": 11, "name": "Maintainers", "rule_type": "regular", "approvals_required": 2, "protected_branches": [{"id": 1, "name": "main"}]},
+			{"id": 12, "name": "Security", "rule_type": "report_approver", "approvals_required": 3}
+		]`)
+	})
+	mux.HandleFunc("GET /api/v4/projects/acme%2Fweb/approvals", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"reset_approvals_on_push": true}`)
+	})
+	mux.HandleFunc("GET /api/v4/projects/acme%2Fweb/push_rule", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "404 Not found"}`)
+	})
+	mux.HandleFunc("GET /api/v4/projects/acme%2Fweb", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 5, "only_allow_merge_if_pipeline_succeeds": true, "merge_method": "ff"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := &GitlabConfig{Token: "token", BaseURL: server.URL, Org: "acme", Repository: "web"}
+
+	settings, err := c.RepoSettings("main")
+	require.NoError(t, err)
+	assert.Equal(t, "acme/web", settings.Repository)
+	assert.True(t, settings.Protected)
+	assert.Equal(t, 2, settings.RequiredApprovals)
+	assert.True(t, settings.DismissStaleApprovals)
+	assert.True(t, settings.RequireCodeOwnerReview)
+	assert.True(t, settings.RequireStatusChecks)
+	assert.Empty(t, settings.RequiredStatusChecks)
+	assert.False(t, settings.RequireSignedCommits)
+	assert.True(t, settings.RequireLinearHistory)
+	assert.False(t, settings.AllowForcePushes)
+	assert.False(t, settings.AllowDeletions)
+
+	settings, err = c.RepoSettings("release/1.0")
+	require.NoError(t, err)
+	assert.True(t, settings.Protected)
+	assert.Equal(t, 1, settings.RequiredApprovals)
+	assert.False(t, settings.RequireCodeOwnerReview)
+	assert.True(t, settings.AllowForcePushes)
+
+	settings, err = c.RepoSettings("feature")
+	require.NoError(t, err)
+	assert.False(t, settings.Protected)
+	assert.True(t, settings.AllowDeletions)
+}
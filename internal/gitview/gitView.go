@@ -284,3 +284,71 @@ func (gv *GitView) ResolveRevision(commitSHAOrRef string) (string, error) {
 	}
 	return hash.String(), nil
 }
+
+// ChangedFiles returns the paths of the files a commit changes compared to
+// its first parent, or all its files if it is a root commit. For a merge
+// commit that is everything the merged branch brought in.
+func (gv *GitView) ChangedFiles(commitSHAOrRef string) ([]string, error) {
+	hash, err := gv.repository.ResolveRevision(plumbing.Revision(commitSHAOrRef))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve git reference %s: %v", commitSHAOrRef, err)
+	}
+	commit, err := gv.repository.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve commit for %s: %v", *hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	parentTree := &object.Tree{}
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve the parent of commit %s: %v", *hash, err)
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff commit %s: %v", *hash, err)
+	}
+	files := []string{}
+	for _, change := range changes {
+		// a renamed file is a change to both its old and its new path
+		if change.From.Name != "" {
+			files = append(files, change.From.Name)
+		}
+		if change.To.Name != "" && change.To.Name != change.From.Name {
+			files = append(files, change.To.Name)
+		}
+	}
+	return files, nil
+}
+
+// FileContent returns the content of a file as of a commit, and false if the
+// file does not exist in that commit.
+func (gv *GitView) FileContent(commitSHAOrRef, path string) (string, bool, error) {
+	hash, err := gv.repository.ResolveRevision(plumbing.Revision(commitSHAOrRef))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to resolve git reference %s: %v", commitSHAOrRef, err)
+	}
+	commit, err := gv.repository.CommitObject(*hash)
+	if err != nil {
+		return "", false, fmt.Errorf("could not retrieve commit for %s: %v", *hash, err)
+	}
+	file, err := commit.File(path)
+	if err == object.ErrFileNotFound {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return "", false, err
+	}
+	return content, true, nil
+}
@@ -444,6 +444,35 @@ func (suite *GitViewTestSuite) TestResolveRevision() {
 	}
 }
 
+func (suite *GitViewTestSuite) TestChangedFilesAndFileContent() {
+	dirPath := filepath.Join(suite.tmpDir, "repoName")
+	_, _, err := initializeRepoAndCommit(dirPath, 3)
+	require.NoError(suite.T(), err)
+
+	gitView, err := New(dirPath)
+	require.NoError(suite.T(), err)
+
+	files, err := gitView.ChangedFiles("HEAD")
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), []string{"file-3.txt"}, files)
+
+	files, err = gitView.ChangedFiles("HEAD~2")
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), []string{"file-1.txt"}, files, "a root commit changes all its files")
+
+	content, found, err := gitView.FileContent("HEAD", "file-2.txt")
+	require.NoError(suite.T(), err)
+	require.True(suite.T(), found)
+	require.Equal(suite.T(), "this is a dummy line", content)
+
+	_, found, err = gitView.FileContent("HEAD~2", "file-2.txt")
+	require.NoError(suite.T(), err)
+	require.False(suite.T(), found, "file-2.txt is not in the first commit")
+
+	_, err = gitView.ChangedFiles("123456")
+	require.Error(suite.T(), err)
+}
+
 func initializeRepoAndCommit(repoPath string, commitsNumber int) (*git.Repository, *git.Worktree, error) {
 	// the repo worktree filesystem. It has to be osfs so that we can give it a path
 	fs := osfs.New(repoPath)
@@ -0,0 +1,54 @@
+package issues
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// FakeIssueRetriever is an in-memory implementation of types.IssueRetriever
+// for testing. Seed Issues with the issues the tracker knows about, keyed by
+// reference; a reference found in the searched text is any key of Issues or
+// entry of Missing that appears in it. Set Err to simulate a network or API
+// failure.
+type FakeIssueRetriever struct {
+	Provider string
+	// Issues maps a reference to the issue returned for it. A nil issue is a
+	// reference that is not an issue (e.g. a pull request).
+	Issues map[string]*types.IssueInfo
+	// Missing lists references to issues that do not exist.
+	Missing []string
+	// Err, if set, is returned by IssueInfo regardless of reference.
+	Err error
+}
+
+func (f *FakeIssueRetriever) IssueProvider() string {
+	return f.Provider
+}
+
+func (f *FakeIssueRetriever) FindIssueReferences(text string) []string {
+	var found []string
+	for reference := range f.Issues {
+		if strings.Contains(text, reference) {
+			found = append(found, reference)
+		}
+	}
+	for _, reference := range f.Missing {
+		if strings.Contains(text, reference) {
+			found = append(found, reference)
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+func (f *FakeIssueRetriever) IssueInfo(reference string) (*types.IssueInfo, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if issue, ok := f.Issues[reference]; ok {
+		return issue, nil
+	}
+	return &types.IssueInfo{IssueID: reference}, nil
+}
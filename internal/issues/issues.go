@@ -0,0 +1,70 @@
+package issues
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// numberedReference matches issue references of the form "#12" and
+// "group/project#12". The reference must not follow a word character, a
+// slash, a dot or a hyphen, so that URL fragments (https://host/page#12),
+// HTML entities (&#123;) and words such as "abc#1" are not taken as
+// references.
+var numberedReference = regexp.MustCompile(`(?:^|[^\w/#&.-])((?:[\w.-]+/)+[\w.-]+)?#(\d+)\b`)
+
+// Reference is an issue reference to a numbered issue of a project.
+type Reference struct {
+	// Project is the full path of the project, e.g. "org/repo" or "group/subgroup/project".
+	Project string
+	Number  int
+}
+
+func (r Reference) String() string {
+	return fmt.Sprintf("%s#%d", r.Project, r.Number)
+}
+
+// FindNumberedReferences finds the "#12" and "group/project#12" issue
+// references in text, as used by GitHub and GitLab. References without a
+// project are given defaultProject. With maxDepth above zero, references to
+// projects nested more than maxDepth levels deep (GitHub repositories are
+// always "owner/repo", one level deep) are ignored. The references are
+// returned in canonical "project#number" form, deduplicated and sorted.
+func FindNumberedReferences(text, defaultProject string, maxDepth int) []string {
+	seen := map[string]struct{}{}
+	result := []string{}
+	for _, m := range numberedReference.FindAllStringSubmatch(text, -1) {
+		project := m[1]
+		if project == "" {
+			project = defaultProject
+		} else if maxDepth > 0 && strings.Count(project, "/") > maxDepth {
+			continue
+		}
+		number, err := strconv.Atoi(m[2])
+		if err != nil || number == 0 || project == "" {
+			continue
+		}
+		ref := Reference{Project: project, Number: number}.String()
+		if _, ok := seen[ref]; !ok {
+			seen[ref] = struct{}{}
+			result = append(result, ref)
+		}
+	}
+	sort.Strings(result)
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// ParseNumberedReference parses a canonical "project#number" reference.
+func ParseNumberedReference(reference string) (Reference, error) {
+	project, number, found := strings.Cut(reference, "#")
+	n, err := strconv.Atoi(number)
+	if !found || project == "" || err != nil || n <= 0 {
+		return Reference{}, fmt.Errorf("invalid issue reference %q, expected the form project#number", reference)
+	}
+	return Reference{Project: project, Number: n}, nil
+}
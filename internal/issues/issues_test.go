@@ -0,0 +1,58 @@
+package issues
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindNumberedReferences(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		text     string
+		maxDepth int
+		want     []string
+	}{
+		{
+			name: "references without a project get the default project",
+			text: "fix the login form (#12), closes #7 and #12",
+			want: []string{"acme/web#12", "acme/web#7"},
+		},
+		{
+			name: "references with a project keep it",
+			text: "see acme/api#3 and group/sub/project#41",
+			want: []string{"acme/api#3", "group/sub/project#41"},
+		},
+		{
+			name:     "references nested deeper than maxDepth are ignored",
+			text:     "see acme/api#3 and group/sub/project#41",
+			maxDepth: 1,
+			want:     []string{"acme/api#3"},
+		},
+		{
+			name: "a reference at the start of a line or a branch name",
+			text: "#5 first\nfeature/#6-login",
+			want: []string{"acme/web#5"},
+		},
+		{
+			name: "URL fragments, HTML entities, words and #0 are not references",
+			text: "https://example.com/docs#12 &#123; abc#4 C#7 #0",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, FindNumberedReferences(tc.text, "acme/web", tc.maxDepth))
+		})
+	}
+}
+
+func TestParseNumberedReference(t *testing.T) {
+	ref, err := ParseNumberedReference("group/sub/project#41")
+	require.NoError(t, err)
+	assert.Equal(t, Reference{Project: "group/sub/project", Number: 41}, ref)
+
+	for _, invalid := range []string{"#41", "project", "project#", "project#x", "project#-1"} {
+		_, err := ParseNumberedReference(invalid)
+		assert.EqualError(t, err, `invalid issue reference "`+invalid+`", expected the form project#number`)
+	}
+}
@@ -239,3 +239,76 @@ func isPartialMultiSegment(text, match string) bool {
 	}
 	return true
 }
+
+// AddIssueComment posts a comment with the given body on a Jira issue.
+// The body is Jira wiki markup, in which plain URLs are rendered as links.
+func (jc *JiraConfig) AddIssueComment(issueID, body string) error {
+	jiraClient, err := jc.NewJiraClient()
+	if err != nil {
+		return err
+	}
+	_, _, err = jiraClient.Issue.AddComment(issueID, &jira.Comment{Body: body})
+	if err != nil {
+		return fmt.Errorf("failed to add a comment to Jira issue %s: %w", issueID, err)
+	}
+	return nil
+}
+
+// TransitionIssue moves a Jira issue through the workflow transition with the
+// given name, or to the given status, whichever matches first (case-insensitive).
+// It returns false without doing anything if the issue already has that status,
+// so that attesting the same issue again is harmless.
+func (jc *JiraConfig) TransitionIssue(issueID, transition string) (bool, error) {
+	jiraClient, err := jc.NewJiraClient()
+	if err != nil {
+		return false, err
+	}
+
+	issue, _, err := jiraClient.Issue.Get(issueID, &jira.GetQueryOptions{Fields: "status"})
+	if err != nil {
+		return false, fmt.Errorf("failed to get the status of Jira issue %s: %w", issueID, err)
+	}
+	currentStatus := ""
+	if issue.Fields != nil && issue.Fields.Status != nil {
+		currentStatus = issue.Fields.Status.Name
+	}
+	if strings.EqualFold(currentStatus, transition) {
+		return false, nil
+	}
+
+	transitions, _, err := jiraClient.Issue.GetTransitions(issueID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get the transitions of Jira issue %s: %w", issueID, err)
+	}
+	match := findTransition(transitions, transition)
+	if match == nil {
+		available := make([]string, len(transitions))
+		for i, t := range transitions {
+			available[i] = fmt.Sprintf("%s (to %s)", t.Name, t.To.Name)
+		}
+		return false, fmt.Errorf("Jira issue %s in status '%s' has no transition named '%s' or leading to status '%s'. Available transitions: [%s]",
+			issueID, currentStatus, transition, transition, strings.Join(available, ", "))
+	}
+
+	_, err = jiraClient.Issue.DoTransition(issueID, match.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to transition Jira issue %s to '%s': %w", issueID, match.To.Name, err)
+	}
+	return true, nil
+}
+
+// findTransition returns the transition with the given name or, failing
+// that, the first transition leading to a status of that name.
+func findTransition(transitions []jira.Transition, name string) *jira.Transition {
+	for i, t := range transitions {
+		if strings.EqualFold(t.Name, name) {
+			return &transitions[i]
+		}
+	}
+	for i, t := range transitions {
+		if strings.EqualFold(t.To.Name, name) {
+			return &transitions[i]
+		}
+	}
+	return nil
+}
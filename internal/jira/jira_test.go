@@ -1,10 +1,15 @@
 package jira
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMakeJiraIssueKey(t *testing.T) {
@@ -296,3 +301,86 @@ func BenchmarkFindJiraIssueKeys(b *testing.B) {
 		}
 	})
 }
+
+// newFakeJiraServer serves the issue, comment and transition endpoints of the
+// Jira REST API for the issue PROJ-1 in the given status. The comments posted
+// and the transitions done are recorded in the returned slices.
+func newFakeJiraServer(t *testing.T, status string) (*httptest.Server, *[]string, *[]string) {
+	t.Helper()
+	comments, done := &[]string{}, &[]string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/2/issue/PROJ-1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"key": "PROJ-1", "fields": {"status": {"name": %q}}}`, status)
+	})
+	mux.HandleFunc("POST /rest/api/2/issue/PROJ-1/comment", func(w http.ResponseWriter, r *http.Request) {
+		var comment struct{ Body string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&comment))
+		*comments = append(*comments, comment.Body)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": "10000"}`)
+	})
+	mux.HandleFunc("GET /rest/api/2/issue/PROJ-1/transitions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"transitions": [
+			{"id": "11", "name": "Start work", "to": {"name": "In Progress"}},
+			{"id": "31", "name": "Release", "to": {"name": "In Release"}}
+		]}`)
+	})
+	mux.HandleFunc("POST /rest/api/2/issue/PROJ-1/transitions", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Transition struct{ ID string } `json:"transition"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		*done = append(*done, body.Transition.ID)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, comments, done
+}
+
+func TestAddIssueComment(t *testing.T) {
+	server, comments, _ := newFakeJiraServer(t, "To Do")
+	jc := NewJiraConfig(server.URL, "user@example.com", "token", "")
+
+	require.NoError(t, jc.AddIssueComment("PROJ-1", "Attested in Kosli: https://app.kosli.com/org/flows/f/trails/t"))
+	assert.Equal(t, []string{"Attested in Kosli: https://app.kosli.com/org/flows/f/trails/t"}, *comments)
+
+	err := jc.AddIssueComment("PROJ-2", "body")
+	require.ErrorContains(t, err, "failed to add a comment to Jira issue PROJ-2")
+}
+
+func TestTransitionIssue(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		status           string
+		transition       string
+		wantTransitioned bool
+		wantDone         []string
+		wantErr          string
+	}{
+		{name: "by transition name", status: "In Progress", transition: "release", wantTransitioned: true, wantDone: []string{"31"}},
+		{name: "by target status", status: "To Do", transition: "In Progress", wantTransitioned: true, wantDone: []string{"11"}},
+		{name: "already in the target status", status: "In Release", transition: "In Release", wantDone: []string{}},
+		{
+			name:       "no matching transition",
+			status:     "To Do",
+			transition: "Done",
+			wantDone:   []string{},
+			wantErr:    "Jira issue PROJ-1 in status 'To Do' has no transition named 'Done' or leading to status 'Done'. Available transitions: [Start work (to In Progress), Release (to In Release)]",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server, _, done := newFakeJiraServer(t, tc.status)
+			jc := NewJiraConfig(server.URL, "", "", "pat")
+
+			transitioned, err := jc.TransitionIssue("PROJ-1", tc.transition)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantTransitioned, transitioned)
+			assert.Equal(t, tc.wantDone, *done)
+		})
+	}
+}
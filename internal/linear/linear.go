@@ -0,0 +1,158 @@
+package linear
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/jira"
+	"github.com/kosli-dev/cli/internal/ratelimit"
+	"github.com/kosli-dev/cli/internal/telemetry"
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// DefaultAPIURL is the Linear GraphQL API endpoint.
+const DefaultAPIURL = "https://api.linear.app/graphql"
+
+type LinearConfig struct {
+	APIKey string
+	// TeamKeys restricts the issue references to those of the given teams.
+	TeamKeys []string
+	// APIURL defaults to DefaultAPIURL when empty.
+	APIURL     string
+	HTTPClient *http.Client
+}
+
+// NewLinearIssueRetrieverFunc creates a types.IssueRetriever from Linear config
+// parameters. It can be replaced in tests to inject a fake.
+var NewLinearIssueRetrieverFunc = defaultNewLinearIssueRetriever
+
+func defaultNewLinearIssueRetriever(apiKey string, teamKeys []string) types.IssueRetriever {
+	return &LinearConfig{APIKey: apiKey, TeamKeys: teamKeys}
+}
+
+// ResetLinearIssueRetrieverFunc restores NewLinearIssueRetrieverFunc to its default.
+func ResetLinearIssueRetrieverFunc() {
+	NewLinearIssueRetrieverFunc = defaultNewLinearIssueRetriever
+}
+
+func (c *LinearConfig) IssueProvider() string {
+	return "linear"
+}
+
+// FindIssueReferences finds Linear issue identifiers such as "ENG-42" in text.
+// They have the same form as Jira issue keys (a team key, a dash and a number),
+// and are matched the same way.
+func (c *LinearConfig) FindIssueReferences(text string) []string {
+	return jira.FindJiraIssueKeys(text, c.TeamKeys)
+}
+
+const issueQuery = `query Issue($id: String!) {
+  issue(id: $id) {
+    identifier
+    title
+    url
+    state {
+      name
+    }
+  }
+}`
+
+type graphqlRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+type issueResponse struct {
+	Data struct {
+		Issue *struct {
+			Identifier string `json:"identifier"`
+			Title      string `json:"title"`
+			URL        string `json:"url"`
+			State      struct {
+				Name string `json:"name"`
+			} `json:"state"`
+		} `json:"issue"`
+	} `json:"data"`
+	Errors []struct {
+		Message    string `json:"message"`
+		Extensions struct {
+			Code string `json:"code"`
+		} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// IssueInfo looks up a Linear issue by its identifier.
+func (c *LinearConfig) IssueInfo(reference string) (*types.IssueInfo, error) {
+	result := &types.IssueInfo{IssueID: reference}
+
+	body, err := json.Marshal(graphqlRequest{Query: issueQuery, Variables: map[string]any{"id": reference}})
+	if err != nil {
+		return result, err
+	}
+	apiURL := c.APIURL
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	req, err := http.NewRequest(http.MethodPost, apiURL, bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// personal API keys are sent as they are, OAuth tokens as bearer tokens
+	if strings.HasPrefix(c.APIKey, "lin_oauth_") {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	} else {
+		req.Header.Set("Authorization", c.APIKey)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second, Transport: telemetry.NewTransport(ratelimit.NewTransport(nil))}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return result, fmt.Errorf("failed to get Linear issue %s: %w", reference, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return result, fmt.Errorf("failed to get Linear issue %s: %w", reference, err)
+	}
+
+	var parsed issueResponse
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return result, fmt.Errorf("failed to get Linear issue %s: status %d: %s", reference, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if parsed.Data.Issue != nil {
+		issue := parsed.Data.Issue
+		result.IssueExists = true
+		result.IssueURL = issue.URL
+		result.Title = issue.Title
+		result.State = issue.State.Name
+		return result, nil
+	}
+	for _, e := range parsed.Errors {
+		if isNotFound(e.Message, e.Extensions.Code) {
+			return result, nil
+		}
+	}
+	if len(parsed.Errors) > 0 {
+		return result, fmt.Errorf("failed to get Linear issue %s: %s", reference, parsed.Errors[0].Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("failed to get Linear issue %s: status %d", reference, resp.StatusCode)
+	}
+	// a null issue without errors is an issue that does not exist
+	return result, nil
+}
+
+// isNotFound reports whether a GraphQL error means the issue does not exist.
+// Linear reports it as an "Entity not found" error rather than a null issue.
+func isNotFound(message, code string) bool {
+	return strings.Contains(strings.ToLower(message), "not found") || code == "NOT_FOUND"
+}
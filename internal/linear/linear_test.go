@@ -0,0 +1,65 @@
+package linear
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLinearTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphqlRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if r.Header.Get("Authorization") != "lin_api_key" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errors": [{"message": "Authentication required, not authenticated", "extensions": {"code": "AUTHENTICATION_ERROR"}}]}`)
+			return
+		}
+		switch req.Variables["id"] {
+		case "ENG-42":
+			fmt.Fprint(w, `{"data": {"issue": {"identifier": "ENG-42", "title": "Rate limit the API", "url": "https://linear.app/acme/issue/ENG-42/rate-limit-the-api", "state": {"name": "In Progress"}}}}`)
+		default:
+			fmt.Fprint(w, `{"data": null, "errors": [{"message": "Entity not found: Issue", "extensions": {"code": "INVALID_INPUT"}}]}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLinearFindIssueReferences(t *testing.T) {
+	c := &LinearConfig{}
+	assert.Equal(t, []string{"ENG-42", "OPS-7"}, c.FindIssueReferences("eng-42: fix rate limit\nalso OPS-7, not CVE-2026-41284"))
+
+	c.TeamKeys = []string{"ENG"}
+	assert.Equal(t, []string{"ENG-42"}, c.FindIssueReferences("eng-42: fix rate limit\nalso OPS-7"))
+}
+
+func TestLinearIssueInfo(t *testing.T) {
+	server := newLinearTestServer(t)
+	c := &LinearConfig{APIKey: "lin_api_key", APIURL: server.URL}
+
+	info, err := c.IssueInfo("ENG-42")
+	require.NoError(t, err)
+	assert.Equal(t, &types.IssueInfo{
+		IssueID:     "ENG-42",
+		IssueURL:    "https://linear.app/acme/issue/ENG-42/rate-limit-the-api",
+		IssueExists: true,
+		Title:       "Rate limit the API",
+		State:       "In Progress",
+	}, info)
+
+	info, err = c.IssueInfo("ENG-43")
+	require.NoError(t, err)
+	assert.Equal(t, &types.IssueInfo{IssueID: "ENG-43"}, info)
+
+	c.APIKey = "wrong"
+	_, err = c.IssueInfo("ENG-42")
+	require.EqualError(t, err, "failed to get Linear issue ENG-42: Authentication required, not authenticated")
+}
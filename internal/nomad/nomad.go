@@ -0,0 +1,285 @@
+package nomad
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/logger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultAddress   = "http://127.0.0.1:4646"
+	defaultNamespace = "default"
+)
+
+// Annotations of the reported allocations.
+const (
+	JobAnnotation       = "nomad/job"
+	TaskGroupAnnotation = "nomad/task-group"
+	NodeAnnotation      = "nomad/node"
+	// TaskAnnotationPrefix prefixes the name of a task, in the annotation
+	// with the image of the task.
+	TaskAnnotationPrefix = "nomad/task/"
+)
+
+// NomadEnvRequest represents the PUT request body to be sent to kosli from Nomad
+type NomadEnvRequest struct {
+	Artifacts []*AllocationData `json:"artifacts"`
+}
+
+// AllocationData represents the harvested data of a running Nomad allocation.
+// It follows the structure of kube.PodData, with the job as owner, and the
+// job, task group, node and task names as annotations.
+type AllocationData struct {
+	PodName           string                  `json:"podName"`
+	Namespace         string                  `json:"namespace"`
+	Digests           map[string]string       `json:"digests"`
+	CreationTimestamp int64                   `json:"creationTimestamp"`
+	Owners            []metav1.OwnerReference `json:"owners"`
+	Annotations       map[string]string       `json:"annotations"`
+}
+
+// Config is the configuration of a Nomad API client. Empty fields default to
+// the standard NOMAD_* environment variables, like the nomad CLI.
+type Config struct {
+	Address   string
+	Token     string
+	Namespace string
+	Region    string
+	// CACert, ClientCert and ClientKey are paths to PEM files for TLS.
+	CACert     string
+	ClientCert string
+	ClientKey  string
+	SkipVerify bool
+}
+
+// withEnvDefaults returns the config with its empty fields set from the
+// NOMAD_* environment variables, or from the nomad CLI defaults.
+func (c Config) withEnvDefaults() Config {
+	fallback := func(value *string, envVar, defaultValue string) {
+		if *value == "" {
+			*value = os.Getenv(envVar)
+		}
+		if *value == "" {
+			*value = defaultValue
+		}
+	}
+	fallback(&c.Address, "NOMAD_ADDR", defaultAddress)
+	fallback(&c.Token, "NOMAD_TOKEN", "")
+	fallback(&c.Namespace, "NOMAD_NAMESPACE", defaultNamespace)
+	fallback(&c.Region, "NOMAD_REGION", "")
+	fallback(&c.CACert, "NOMAD_CACERT", "")
+	fallback(&c.ClientCert, "NOMAD_CLIENT_CERT", "")
+	fallback(&c.ClientKey, "NOMAD_CLIENT_KEY", "")
+	if !c.SkipVerify {
+		c.SkipVerify, _ = strconv.ParseBool(os.Getenv("NOMAD_SKIP_VERIFY"))
+	}
+	return c
+}
+
+// Client lists the running allocations of a Nomad cluster
+type Client struct {
+	config     Config
+	httpClient *http.Client
+	// fingerprintImage gets the digest of a tag-pinned image
+	fingerprintImage func(imageName string) (string, error)
+}
+
+// NewClient returns a client of the Nomad HTTP API. fingerprintImage gets
+// the digests of the images of tasks which are not pinned by digest.
+func NewClient(config Config, fingerprintImage func(imageName string) (string, error)) (*Client, error) {
+	config = config.withEnvDefaults()
+	if _, err := url.Parse(config.Address); err != nil {
+		return nil, fmt.Errorf("invalid Nomad address %q: %w", config.Address, err)
+	}
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &Client{
+		config:           config,
+		httpClient:       &http.Client{Transport: transport, Timeout: 30 * time.Second},
+		fingerprintImage: fingerprintImage,
+	}, nil
+}
+
+func (c Config) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.SkipVerify}
+	if c.CACert != "" {
+		pem, err := os.ReadFile(c.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Nomad CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in Nomad CA certificate %s", c.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.ClientCert != "" || c.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Nomad client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// allocationStub is an allocation as listed by /v1/allocations
+type allocationStub struct {
+	ID           string
+	Name         string
+	Namespace    string
+	JobID        string
+	TaskGroup    string
+	NodeName     string
+	ClientStatus string
+	// CreateTime is in nanoseconds since the epoch
+	CreateTime int64
+	TaskStates map[string]struct {
+		State string
+	}
+}
+
+// allocation is an allocation as read from /v1/allocation/:id, with the
+// version of the job it runs.
+type allocation struct {
+	Job struct {
+		TaskGroups []struct {
+			Name  string
+			Tasks []struct {
+				Name   string
+				Driver string
+				Config map[string]any
+			}
+		}
+	}
+}
+
+// GetAllocationsData returns the data of the running allocations of the
+// configured namespace, with the digests of the images of their running
+// tasks.
+func (c *Client) GetAllocationsData(logger *logger.Logger) ([]*AllocationData, error) {
+	var stubs []allocationStub
+	if err := c.get("/v1/allocations", &stubs); err != nil {
+		return nil, fmt.Errorf("failed to list Nomad allocations: %w", err)
+	}
+
+	allocationsData := []*AllocationData{}
+	for _, stub := range stubs {
+		if stub.ClientStatus != "running" {
+			logger.Debug("allocation %s is %s, skipping from report", stub.Name, stub.ClientStatus)
+			continue
+		}
+		data, err := c.newAllocationData(stub, logger)
+		if err != nil {
+			return nil, err
+		}
+		if len(data.Digests) == 0 {
+			logger.Debug("allocation %s runs no container task, skipping from report", stub.Name)
+			continue
+		}
+		allocationsData = append(allocationsData, data)
+	}
+	return allocationsData, nil
+}
+
+func (c *Client) newAllocationData(stub allocationStub, logger *logger.Logger) (*AllocationData, error) {
+	var alloc allocation
+	if err := c.get("/v1/allocation/"+url.PathEscape(stub.ID), &alloc); err != nil {
+		return nil, fmt.Errorf("failed to get Nomad allocation %s: %w", stub.Name, err)
+	}
+
+	data := &AllocationData{
+		PodName:           stub.Name,
+		Namespace:         stub.Namespace,
+		Digests:           map[string]string{},
+		CreationTimestamp: time.Unix(0, stub.CreateTime).Unix(),
+		Owners:            []metav1.OwnerReference{{APIVersion: "nomad/v1", Kind: "Job", Name: stub.JobID}},
+		Annotations: map[string]string{
+			JobAnnotation:       stub.JobID,
+			TaskGroupAnnotation: stub.TaskGroup,
+			NodeAnnotation:      stub.NodeName,
+		},
+	}
+	for _, group := range alloc.Job.TaskGroups {
+		if group.Name != stub.TaskGroup {
+			continue
+		}
+		for _, task := range group.Tasks {
+			imageName, _ := task.Config["image"].(string)
+			if imageName == "" {
+				logger.Debug("task %s of allocation %s uses the %s driver and has no image", task.Name, stub.Name, task.Driver)
+				continue
+			}
+			if stub.TaskStates[task.Name].State != "running" {
+				logger.Debug("task %s of allocation %s is not running, skipping from report", task.Name, stub.Name)
+				continue
+			}
+			fingerprint, err := c.imageFingerprint(imageName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get the digest of image %s of task %s of allocation %s: %w", imageName, task.Name, stub.Name, err)
+			}
+			data.Digests[imageName] = fingerprint
+			data.Annotations[TaskAnnotationPrefix+task.Name] = imageName
+		}
+	}
+	return data, nil
+}
+
+// imageFingerprint returns the digest of a digest-pinned image from its name,
+// and looks up the digest of a tag-pinned image.
+func (c *Client) imageFingerprint(imageName string) (string, error) {
+	if _, digest, ok := strings.Cut(imageName, "@sha256:"); ok {
+		return digest, nil
+	}
+	return c.fingerprintImage(imageName)
+}
+
+// get reads the JSON response of a GET of path from the Nomad HTTP API into
+// out.
+func (c *Client) get(path string, out any) error {
+	requestURL, err := url.JoinPath(c.config.Address, path)
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set("namespace", c.config.Namespace)
+	if c.config.Region != "" {
+		query.Set("region", c.config.Region)
+	}
+	req, err := http.NewRequest(http.MethodGet, requestURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if c.config.Token != "" {
+		req.Header.Set("X-Nomad-Token", c.config.Token)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, out)
+}
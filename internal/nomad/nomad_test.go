@@ -0,0 +1,144 @@
+package nomad
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const pinnedDigest = "1111111111111111111111111111111111111111111111111111111111111111"
+
+var allocationCreateTime = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// newStubNomadServer returns a stub of the Nomad HTTP API with a running
+// allocation of a web job, with an app and a sidecar task and a completed
+// prestart task, a running allocation of a batch job with a raw_exec task,
+// and a failed allocation.
+func newStubNomadServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		if r.Header.Get("X-Nomad-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = fmt.Fprint(w, "Permission denied")
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/allocations":
+			_, _ = fmt.Fprintf(w, `[
+				{"ID": "a1", "Name": "web.frontend[0]", "Namespace": "prod", "JobID": "web", "TaskGroup": "frontend",
+				 "NodeName": "node-1", "ClientStatus": "running", "CreateTime": %d,
+				 "TaskStates": {"app": {"State": "running"}, "proxy": {"State": "running"}, "migrate": {"State": "dead"}}},
+				{"ID": "a2", "Name": "cron.batch[0]", "Namespace": "prod", "JobID": "cron", "TaskGroup": "batch",
+				 "NodeName": "node-2", "ClientStatus": "running", "CreateTime": %d,
+				 "TaskStates": {"script": {"State": "running"}}},
+				{"ID": "a3", "Name": "web.frontend[1]", "Namespace": "prod", "JobID": "web", "TaskGroup": "frontend",
+				 "NodeName": "node-2", "ClientStatus": "failed", "CreateTime": %d}
+			]`, allocationCreateTime.UnixNano(), allocationCreateTime.UnixNano(), allocationCreateTime.UnixNano())
+		case "/v1/allocation/a1":
+			_, _ = fmt.Fprintf(w, `{"Job": {"TaskGroups": [
+				{"Name": "other", "Tasks": [{"Name": "other", "Driver": "docker", "Config": {"image": "registry.example.com/other:v1"}}]},
+				{"Name": "frontend", "Tasks": [
+					{"Name": "migrate", "Driver": "docker", "Config": {"image": "registry.example.com/migrate:v1"}},
+					{"Name": "app", "Driver": "docker", "Config": {"image": "registry.example.com/app:v1"}},
+					{"Name": "proxy", "Driver": "podman", "Config": {"image": "registry.example.com/proxy@sha256:%s"}}
+				]}
+			]}}`, pinnedDigest)
+		case "/v1/allocation/a2":
+			_, _ = fmt.Fprint(w, `{"Job": {"TaskGroups": [
+				{"Name": "batch", "Tasks": [{"Name": "script", "Driver": "raw_exec", "Config": {"command": "run.sh"}}]}
+			]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requested
+}
+
+func TestGetAllocationsData(t *testing.T) {
+	server, requested := newStubNomadServer(t)
+
+	var lookedUp []string
+	client, err := NewClient(Config{Address: server.URL, Token: "test-token", Namespace: "prod", Region: "eu"},
+		func(imageName string) (string, error) {
+			lookedUp = append(lookedUp, imageName)
+			return "digest-of-" + imageName, nil
+		})
+	require.NoError(t, err)
+
+	allocationsData, err := client.GetAllocationsData(logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Equal(t, []*AllocationData{{
+		PodName:   "web.frontend[0]",
+		Namespace: "prod",
+		Digests: map[string]string{
+			"registry.example.com/app:v1":                       "digest-of-registry.example.com/app:v1",
+			"registry.example.com/proxy@sha256:" + pinnedDigest: pinnedDigest,
+		},
+		CreationTimestamp: allocationCreateTime.Unix(),
+		Owners:            []metav1.OwnerReference{{APIVersion: "nomad/v1", Kind: "Job", Name: "web"}},
+		Annotations: map[string]string{
+			"nomad/job":        "web",
+			"nomad/task-group": "frontend",
+			"nomad/node":       "node-1",
+			"nomad/task/app":   "registry.example.com/app:v1",
+			"nomad/task/proxy": "registry.example.com/proxy@sha256:" + pinnedDigest,
+		},
+	}}, allocationsData)
+	// digest-pinned images and tasks which are not running are not looked up
+	require.Equal(t, []string{"registry.example.com/app:v1"}, lookedUp)
+	require.Equal(t, []string{
+		"/v1/allocations?namespace=prod&region=eu",
+		"/v1/allocation/a1?namespace=prod&region=eu",
+		"/v1/allocation/a2?namespace=prod&region=eu",
+	}, *requested)
+}
+
+func TestGetAllocationsDataErrors(t *testing.T) {
+	server, _ := newStubNomadServer(t)
+	fingerprintImage := func(imageName string) (string, error) { return "", errors.New("manifest unknown") }
+
+	client, err := NewClient(Config{Address: server.URL, Token: "wrong-token"}, fingerprintImage)
+	require.NoError(t, err)
+	_, err = client.GetAllocationsData(logger.NewStandardLogger())
+	require.EqualError(t, err, "failed to list Nomad allocations: 403 Forbidden: Permission denied")
+
+	client, err = NewClient(Config{Address: server.URL, Token: "test-token"}, fingerprintImage)
+	require.NoError(t, err)
+	_, err = client.GetAllocationsData(logger.NewStandardLogger())
+	require.EqualError(t, err, "failed to get the digest of image registry.example.com/app:v1 of task app of allocation web.frontend[0]: manifest unknown")
+}
+
+func TestConfigWithEnvDefaults(t *testing.T) {
+	t.Setenv("NOMAD_ADDR", "https://nomad.example.com:4646")
+	t.Setenv("NOMAD_TOKEN", "env-token")
+	t.Setenv("NOMAD_NAMESPACE", "")
+	t.Setenv("NOMAD_REGION", "")
+	t.Setenv("NOMAD_CACERT", "")
+	t.Setenv("NOMAD_CLIENT_CERT", "")
+	t.Setenv("NOMAD_CLIENT_KEY", "")
+	t.Setenv("NOMAD_SKIP_VERIFY", "true")
+
+	require.Equal(t, Config{
+		Address:    "https://nomad.example.com:4646",
+		Token:      "flag-token",
+		Namespace:  "default",
+		SkipVerify: true,
+	}, Config{Token: "flag-token"}.withEnvDefaults())
+
+	t.Setenv("NOMAD_ADDR", "")
+	t.Setenv("NOMAD_NAMESPACE", "*")
+	config := Config{}.withEnvDefaults()
+	require.Equal(t, "http://127.0.0.1:4646", config.Address)
+	require.Equal(t, "*", config.Namespace)
+	require.Equal(t, "env-token", config.Token)
+}
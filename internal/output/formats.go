@@ -0,0 +1,234 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	goTemplatePrefix = "go-template="
+	jsonPathPrefix   = "jsonpath="
+)
+
+// GenericFormats are the output formats every command printing a raw JSON
+// response supports, on top of its own print functions.
+var GenericFormats = []string{"yaml", "jsonl", "csv", goTemplatePrefix + "TEMPLATE", jsonPathPrefix + "EXPRESSION"}
+
+// genericPrintFunc returns the function printing a raw JSON response in
+// outputFormat, when it is one of the GenericFormats.
+func genericPrintFunc(outputFormat string) (FormatOutputFunc, bool) {
+	switch outputFormat {
+	case "yaml":
+		return PrintYaml, true
+	case "jsonl":
+		return PrintJsonLines, true
+	case "csv":
+		return PrintCsv, true
+	}
+	if text, ok := strings.CutPrefix(outputFormat, goTemplatePrefix); ok {
+		return func(raw string, out io.Writer, page int) error {
+			return printGoTemplate(text, raw, out)
+		}, true
+	}
+	if expression, ok := strings.CutPrefix(outputFormat, jsonPathPrefix); ok {
+		return func(raw string, out io.Writer, page int) error {
+			return printJsonPath(expression, raw, out)
+		}, true
+	}
+	return nil, false
+}
+
+// IsSupported reports whether FormattedPrint can print in outputFormat with
+// printFunctions.
+func IsSupported(outputFormat string, printFunctions map[string]FormatOutputFunc) bool {
+	if _, ok := printFunctions[outputFormat]; ok {
+		return true
+	}
+	_, ok := genericPrintFunc(outputFormat)
+	return ok
+}
+
+// decodeJson decodes raw, keeping its numbers as they are written so that
+// timestamps are not printed in exponent notation.
+func decodeJson(raw string) (any, error) {
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	var data any
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to parse the response as JSON: %v", err)
+	}
+	return data, nil
+}
+
+// PrintYaml prints a raw json as YAML, keeping the order of its keys.
+func PrintYaml(raw string, out io.Writer, page int) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &node); err != nil {
+		return fmt.Errorf("failed to parse the response as JSON: %v", err)
+	}
+	resetYamlStyle(&node)
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	if _, err := out.Write(buffer.Bytes()); err != nil {
+		return fmt.Errorf("failed to write YAML output: %v", err)
+	}
+	return nil
+}
+
+// resetYamlStyle drops the flow and quoting styles of JSON from node, so it
+// is encoded in the block style of YAML.
+func resetYamlStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYamlStyle(child)
+	}
+}
+
+// PrintJsonLines prints a raw json list with one item per line, or any other
+// raw json on a single line.
+func PrintJsonLines(raw string, out io.Writer, page int) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		items = []json.RawMessage{json.RawMessage(raw)}
+	}
+	for _, item := range items {
+		var line bytes.Buffer
+		if err := json.Compact(&line, item); err != nil {
+			return fmt.Errorf("failed to parse the response as JSON: %v", err)
+		}
+		line.WriteByte('\n')
+		if _, err := out.Write(line.Bytes()); err != nil {
+			return fmt.Errorf("failed to write JSON Lines output: %v", err)
+		}
+	}
+	return nil
+}
+
+// PrintCsv prints a raw json list of objects as CSV, with a row per object
+// and a column per key, or a raw json object as a single row. Nested objects
+// are flattened to dotted keys and lists are written as JSON.
+func PrintCsv(raw string, out io.Writer, page int) error {
+	data, err := decodeJson(raw)
+	if err != nil {
+		return err
+	}
+	items, ok := data.([]any)
+	if !ok {
+		items = []any{data}
+	}
+
+	rows := []map[string]string{}
+	columns := []string{}
+	for _, item := range items {
+		row := map[string]string{}
+		if object, ok := item.(map[string]any); ok {
+			flattenCsv("", object, row)
+		} else {
+			row["value"] = csvValue(item)
+		}
+		for column := range row {
+			if !slices.Contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+		rows = append(rows, row)
+	}
+	slices.Sort(columns)
+
+	writer := csv.NewWriter(out)
+	if len(columns) > 0 {
+		if err := writer.Write(columns); err != nil {
+			return fmt.Errorf("failed to write CSV output: %v", err)
+		}
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = row[column]
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV output: %v", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV output: %v", err)
+	}
+	return nil
+}
+
+func flattenCsv(prefix string, object map[string]any, row map[string]string) {
+	for key, value := range object {
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			flattenCsv(prefix+key+".", nested, row)
+			continue
+		}
+		row[prefix+key] = csvValue(value)
+	}
+}
+
+func csvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number, bool:
+		return fmt.Sprint(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+}
+
+// printGoTemplate prints a raw json with the Go template text.
+func printGoTemplate(text, raw string, out io.Writer) error {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse the go-template: %v", err)
+	}
+	data, err := decodeJson(raw)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(out, data); err != nil {
+		return fmt.Errorf("failed to execute the go-template: %v", err)
+	}
+	return nil
+}
+
+// printJsonPath prints the values at the JSONPath expression of a raw json.
+// Like kubectl, the braces around the expression are optional.
+func printJsonPath(expression, raw string, out io.Writer) error {
+	if !strings.Contains(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+	parser := jsonpath.New("output")
+	if err := parser.Parse(expression); err != nil {
+		return fmt.Errorf("failed to parse the jsonpath expression: %v", err)
+	}
+	data, err := decodeJson(raw)
+	if err != nil {
+		return err
+	}
+	if err := parser.Execute(out, data); err != nil {
+		return fmt.Errorf("failed to execute the jsonpath expression: %v", err)
+	}
+	return nil
+}
@@ -9,11 +9,15 @@ import (
 
 type FormatOutputFunc func(string, io.Writer, int) error
 
-// FormattedPrint prints output according to the chosen format using the passed functions
+// FormattedPrint prints output according to the chosen format using the passed functions.
+// The GenericFormats without a passed function are printed from the raw json.
 func FormattedPrint(raw string, outputFormat string, out io.Writer, page int, printFunctions map[string]FormatOutputFunc) error {
 	if v, ok := printFunctions[outputFormat]; ok {
 		return v(raw, out, page)
 	}
+	if v, ok := genericPrintFunc(outputFormat); ok {
+		return v(raw, out, page)
+	}
 	return fmt.Errorf("unsupported output format: %s", outputFormat)
 }
 
@@ -1,9 +1,121 @@
 package output_test
 
-import "testing"
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/output"
+	"github.com/stretchr/testify/require"
+)
 
 func TestPlaceholder(t *testing.T) {
 	// We use the tool cover for coverage, but if there is no _test.go file, then
 	// Go use the tool covdata. At the same time, they removed covdata as a precompiled
 	// binary in the distribution. This made the coverage calculation fail for some of us.
 }
+
+func TestFormattedPrint(t *testing.T) {
+	raw := `[{"name":"backend","state":{"compliant":true,"since":1700000000.123},"tags":["a","b"]},{"name":"web, app","count":"3"}]`
+	printFunctions := map[string]output.FormatOutputFunc{
+		"table": func(raw string, out io.Writer, page int) error {
+			_, err := fmt.Fprintf(out, "table of page %d", page)
+			return err
+		},
+		"json": output.PrintJson,
+	}
+
+	for _, tc := range []struct {
+		format  string
+		want    string
+		wantErr string
+	}{
+		{
+			format: "table",
+			want:   "table of page 2",
+		},
+		{
+			format: "yaml",
+			want: `- name: backend
+  state:
+    compliant: true
+    since: 1700000000.123
+  tags:
+    - a
+    - b
+- name: web, app
+  count: "3"
+`,
+		},
+		{
+			format: "jsonl",
+			want: `{"name":"backend","state":{"compliant":true,"since":1700000000.123},"tags":["a","b"]}
+{"name":"web, app","count":"3"}
+`,
+		},
+		{
+			format: "csv",
+			want: `count,name,state.compliant,state.since,tags
+,backend,true,1700000000.123,"[""a"",""b""]"
+3,"web, app",,,
+`,
+		},
+		{
+			format: "go-template={{range .}}{{.name}}: {{.state.since}}\n{{end}}",
+			want:   "backend: 1700000000.123\nweb, app: <no value>\n",
+		},
+		{
+			format: `jsonpath={range [*]}{.name}{"\n"}{end}`,
+			want:   "backend\nweb, app\n",
+		},
+		{
+			format: "jsonpath=[0].tags[1]",
+			want:   "b",
+		},
+		{
+			format:  "go-template={{.name",
+			wantErr: "failed to parse the go-template: ",
+		},
+		{
+			format:  "jsonpath={.missing}",
+			wantErr: "failed to execute the jsonpath expression: ",
+		},
+		{
+			format:  "xml",
+			wantErr: "unsupported output format: xml",
+		},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			err := output.FormattedPrint(raw, tc.format, out, 2, printFunctions)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, out.String())
+		})
+	}
+}
+
+func TestGenericFormatsPrintAnObject(t *testing.T) {
+	raw := `{"name":"prod","type":"K8S"}`
+
+	out := &bytes.Buffer{}
+	require.NoError(t, output.FormattedPrint(raw, "jsonl", out, 0, nil))
+	require.Equal(t, "{\"name\":\"prod\",\"type\":\"K8S\"}\n", out.String())
+
+	out.Reset()
+	require.NoError(t, output.FormattedPrint(raw, "csv", out, 0, nil))
+	require.Equal(t, "name,type\nprod,K8S\n", out.String())
+}
+
+func TestIsSupported(t *testing.T) {
+	printFunctions := map[string]output.FormatOutputFunc{"markdown": output.PrintJson}
+	for _, format := range []string{"markdown", "yaml", "jsonl", "csv", "go-template={{.}}", "jsonpath={.}"} {
+		require.True(t, output.IsSupported(format, printFunctions), format)
+	}
+	require.False(t, output.IsSupported("table", printFunctions))
+	require.False(t, output.IsSupported("xml", printFunctions))
+}
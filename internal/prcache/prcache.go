@@ -0,0 +1,238 @@
+package prcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+const (
+	// TTLEnvVar turns the cache on. It is a duration such as "10m".
+	TTLEnvVar = "KOSLI_PR_CACHE_TTL"
+	// DirEnvVar overrides the cache directory, so that CI jobs can share it.
+	DirEnvVar = "KOSLI_PR_CACHE_DIR"
+)
+
+// Scoped is implemented by the pull request retrievers whose lookups can be
+// cached. CacheScope identifies the repository the retriever looks up pull
+// requests in, including the provider and its URL.
+type Scoped interface {
+	CacheScope() string
+}
+
+// Cache is an on-disk cache of the pull requests found for commits. Entries
+// older than TTL are not used, and are deleted by the first Put of a Cache so
+// that the directory of long-lived CI runners does not grow without bound.
+type Cache struct {
+	Dir string
+	TTL time.Duration
+	// Now defaults to time.Now.
+	Now func() time.Time
+
+	prune sync.Once
+}
+
+type entry struct {
+	StoredAt     int64               `json:"stored_at"`
+	PullRequests []*types.PREvidence `json:"pull_requests"`
+}
+
+// FromEnvironment returns the cache configured by KOSLI_PR_CACHE_TTL and
+// KOSLI_PR_CACHE_DIR, or nil when KOSLI_PR_CACHE_TTL is not a positive
+// duration. The cache directory defaults to kosli/pull-requests in the user
+// cache directory.
+func FromEnvironment() *Cache {
+	ttl, err := time.ParseDuration(os.Getenv(TTLEnvVar))
+	if err != nil || ttl <= 0 {
+		return nil
+	}
+	dir := os.Getenv(DirEnvVar)
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil
+		}
+		dir = filepath.Join(userCacheDir, "kosli", "pull-requests")
+	}
+	return &Cache{Dir: dir, TTL: ttl}
+}
+
+func (c *Cache) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get returns the pull requests stored for key, if they are younger than TTL.
+func (c *Cache) Get(key string) ([]*types.PREvidence, bool) {
+	content, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var e entry
+	if err := json.Unmarshal(content, &e); err != nil {
+		return nil, false
+	}
+	if c.now().Sub(time.Unix(e.StoredAt, 0)) > c.TTL {
+		return nil, false
+	}
+	for _, pr := range e.PullRequests {
+		pr.Approvers = decodeApprovers(pr.Approvers)
+	}
+	return e.PullRequests, true
+}
+
+// Put stores the pull requests found for key.
+func (c *Cache) Put(key string, pullRequests []*types.PREvidence) error {
+	content, err := json.Marshal(entry{StoredAt: c.now().Unix(), PullRequests: pullRequests})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	c.prune.Do(c.deleteExpired)
+	// write to a temporary file first, so that concurrent jobs sharing the
+	// directory never read a partly written entry
+	tmp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// decodeApprovers turns the approvals decoded from JSON as maps back into
+// types.PRApprovals. Approvers stored as names are left as they are.
+func decodeApprovers(approvers []any) []any {
+	result := make([]any, 0, len(approvers))
+	for _, approver := range approvers {
+		if m, ok := approver.(map[string]any); ok {
+			var approval types.PRApprovals
+			if content, err := json.Marshal(m); err == nil && json.Unmarshal(content, &approval) == nil {
+				approver = approval
+			}
+		}
+		result = append(result, approver)
+	}
+	return result
+}
+
+// entryFile matches the names of the entries and of the temporary files they
+// are written to, so that no other file of a shared directory is deleted.
+var entryFile = regexp.MustCompile(`^([0-9a-f]{64}\.json|\.tmp-[0-9]+)$`)
+
+// deleteExpired deletes the entries older than TTL, and the temporary files
+// left behind by jobs which stopped while writing an entry. Failing to delete
+// one only leaves it for the next time.
+func (c *Cache) deleteExpired() {
+	files, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		if !entryFile.MatchString(file.Name()) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if c.now().Sub(info.ModTime()) > c.TTL {
+			_ = os.Remove(filepath.Join(c.Dir, file.Name()))
+		}
+	}
+}
+
+// Retriever is a types.PRRetriever whose lookups are cached. Only lookups
+// that find pull requests, all of them merged or closed, are cached, so that
+// a pull request opened after a lookup is found by the next one, and the
+// approvals and state of an open pull request are not stale.
+type Retriever struct {
+	types.PRRetriever
+	Cache *Cache
+	Scope string
+}
+
+// Wrap returns r with its lookups cached in the cache configured by the
+// environment. It returns r itself when caching is off or r does not
+// implement Scoped.
+func Wrap(r types.PRRetriever) types.PRRetriever {
+	scoped, ok := r.(Scoped)
+	if !ok {
+		return r
+	}
+	cache := FromEnvironment()
+	if cache == nil {
+		return r
+	}
+	return &Retriever{PRRetriever: r, Cache: cache, Scope: scoped.CacheScope()}
+}
+
+func (r *Retriever) cached(method, commit string, lookup func(string) ([]*types.PREvidence, error)) ([]*types.PREvidence, error) {
+	key := r.Scope + "|" + method + "|" + commit
+	if prs, ok := r.Cache.Get(key); ok {
+		return prs, nil
+	}
+	prs, err := lookup(commit)
+	if err != nil || len(prs) == 0 || !allFinal(prs) {
+		return prs, err
+	}
+	// failing to write the cache only costs a lookup next time
+	_ = r.Cache.Put(key, prs)
+	return prs, nil
+}
+
+// finalStates are the states, in lower case, of the pull requests which are
+// merged or closed in each provider, and so do not change anymore.
+var finalStates = map[string]bool{
+	"merged":     true,
+	"closed":     true,
+	"locked":     true,
+	"declined":   true,
+	"superseded": true,
+	"completed":  true,
+	"abandoned":  true,
+}
+
+func allFinal(prs []*types.PREvidence) bool {
+	for _, pr := range prs {
+		if !finalStates[strings.ToLower(pr.State)] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Retriever) PREvidenceForCommitV1(commit string) ([]*types.PREvidence, error) {
+	return r.cached("v1", commit, r.PRRetriever.PREvidenceForCommitV1)
+}
+
+func (r *Retriever) PREvidenceForCommitV2(commit string) ([]*types.PREvidence, error) {
+	return r.cached("v2", commit, r.PRRetriever.PREvidenceForCommitV2)
+}
+
+func (r *Retriever) PREvidenceForCommitHybrid(commit string) ([]*types.PREvidence, error) {
+	return r.cached("hybrid", commit, r.PRRetriever.PREvidenceForCommitHybrid)
+}
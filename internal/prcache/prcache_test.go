@@ -0,0 +1,158 @@
+package prcache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRetriever is a scoped types.PRRetriever which counts its lookups.
+type countingRetriever struct {
+	prs   map[string][]*types.PREvidence
+	err   error
+	calls int
+}
+
+func (r *countingRetriever) lookup(commit string) ([]*types.PREvidence, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.prs[commit], nil
+}
+
+func (r *countingRetriever) PREvidenceForCommitV1(commit string) ([]*types.PREvidence, error) {
+	return r.lookup(commit)
+}
+
+func (r *countingRetriever) PREvidenceForCommitV2(commit string) ([]*types.PREvidence, error) {
+	return r.lookup(commit)
+}
+
+func (r *countingRetriever) PREvidenceForCommitHybrid(commit string) ([]*types.PREvidence, error) {
+	return r.lookup(commit)
+}
+
+func (r *countingRetriever) ProviderAndLabel() (string, string) {
+	return "github", "pull request"
+}
+
+func (r *countingRetriever) CacheScope() string {
+	return "github|https://api.github.com/graphql|acme/web"
+}
+
+func testPR() *types.PREvidence {
+	return &types.PREvidence{
+		URL:         "https://github.com/acme/web/pull/1",
+		MergeCommit: "abc",
+		State:       "MERGED",
+		Approvers: []any{
+			types.PRApprovals{Username: "bob", State: "APPROVED", Timestamp: 1772449200},
+			"carol",
+		},
+		Commits: []types.Commit{{SHA: "abc", Author: "Alice <alice@example.com>", Timestamp: 1772355600}},
+	}
+}
+
+func TestWrap(t *testing.T) {
+	t.Setenv(TTLEnvVar, "")
+	r := &countingRetriever{}
+	assert.Same(t, r, Wrap(r), "caching is off without a TTL")
+
+	t.Setenv(TTLEnvVar, "10m")
+	t.Setenv(DirEnvVar, t.TempDir())
+	wrapped, ok := Wrap(r).(*Retriever)
+	require.True(t, ok)
+	assert.Equal(t, 10*time.Minute, wrapped.Cache.TTL)
+	assert.Equal(t, r.CacheScope(), wrapped.Scope)
+
+	type unscoped struct{ types.PRRetriever }
+	u := unscoped{r}
+	assert.Equal(t, u, Wrap(u), "retrievers without a scope are not cached")
+}
+
+func TestRetrieverCachesPullRequestsUntilTheTTL(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := &countingRetriever{prs: map[string][]*types.PREvidence{"abc": {testPR()}}}
+	cached := &Retriever{
+		PRRetriever: r,
+		Cache:       &Cache{Dir: t.TempDir(), TTL: time.Minute, Now: func() time.Time { return now }},
+		Scope:       r.CacheScope(),
+	}
+
+	prs, err := cached.PREvidenceForCommitHybrid("abc")
+	require.NoError(t, err)
+	assert.Equal(t, []*types.PREvidence{testPR()}, prs)
+
+	prs, err = cached.PREvidenceForCommitHybrid("abc")
+	require.NoError(t, err)
+	assert.Equal(t, []*types.PREvidence{testPR()}, prs, "cached approvals are types.PRApprovals again")
+	assert.Equal(t, 1, r.calls)
+
+	_, err = cached.PREvidenceForCommitV1("abc")
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.calls, "each lookup method is cached separately")
+
+	now = now.Add(2 * time.Minute)
+	_, err = cached.PREvidenceForCommitHybrid("abc")
+	require.NoError(t, err)
+	assert.Equal(t, 3, r.calls, "expired entries are looked up again")
+}
+
+func TestRetrieverDoesNotCacheMissesOrErrors(t *testing.T) {
+	r := &countingRetriever{}
+	cached := &Retriever{PRRetriever: r, Cache: &Cache{Dir: t.TempDir(), TTL: time.Hour}, Scope: r.CacheScope()}
+
+	for range 2 {
+		prs, err := cached.PREvidenceForCommitV2("def")
+		require.NoError(t, err)
+		assert.Empty(t, prs)
+	}
+	assert.Equal(t, 2, r.calls)
+
+	r.err = errors.New("rate limited")
+	_, err := cached.PREvidenceForCommitV2("abc")
+	require.EqualError(t, err, "rate limited")
+}
+
+func TestRetrieverDoesNotCacheOpenPullRequests(t *testing.T) {
+	open := testPR()
+	open.State = "opened"
+	r := &countingRetriever{prs: map[string][]*types.PREvidence{"abc": {testPR(), open}}}
+	cached := &Retriever{PRRetriever: r, Cache: &Cache{Dir: t.TempDir(), TTL: time.Hour}, Scope: r.CacheScope()}
+
+	for range 2 {
+		prs, err := cached.PREvidenceForCommitV2("abc")
+		require.NoError(t, err)
+		assert.Len(t, prs, 2)
+	}
+	assert.Equal(t, 2, r.calls)
+}
+
+func TestCachePutDeletesExpiredEntries(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	cache := &Cache{Dir: dir, TTL: time.Hour, Now: func() time.Time { return now }}
+	require.NoError(t, cache.Put("old", []*types.PREvidence{testPR()}))
+	require.NoError(t, cache.Put("recent", []*types.PREvidence{testPR()}))
+	other := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(other, []byte("not an entry"), 0600))
+	for _, path := range []string{cache.path("old"), other} {
+		require.NoError(t, os.Chtimes(path, now.Add(-2*time.Hour), now.Add(-2*time.Hour)))
+	}
+	require.NoError(t, os.Chtimes(cache.path("recent"), now.Add(-time.Minute), now.Add(-time.Minute)))
+
+	// the entries expire in the directory a later job shares
+	later := &Cache{Dir: dir, TTL: time.Hour, Now: func() time.Time { return now }}
+	require.NoError(t, later.Put("new", []*types.PREvidence{testPR()}))
+	assert.NoFileExists(t, cache.path("old"))
+	assert.FileExists(t, cache.path("recent"))
+	assert.FileExists(t, cache.path("new"))
+	assert.FileExists(t, other, "files which are not entries are kept")
+}
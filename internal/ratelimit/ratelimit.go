@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is how many times a rate limited request is retried.
+	DefaultMaxRetries = 3
+	// DefaultMaxWait is the longest wait for a rate limit to reset. A limit
+	// that resets later than that is reported to the caller instead.
+	DefaultMaxWait = 2 * time.Minute
+)
+
+// sleep is replaced in tests to avoid real delays.
+var sleep = time.Sleep
+
+// Exceeded reports whether resp says the rate limit of the API is exceeded:
+// HTTP 429, or HTTP 403 with a Retry-After header or no remaining requests,
+// which is how GitHub reports its primary and secondary rate limits.
+func Exceeded(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return resp.Header.Get("Retry-After") != "" || remaining(resp.Header) == "0"
+	}
+	return false
+}
+
+// Wait returns how long to wait before retrying a rate limited request. It is
+// the Retry-After header when there is one, then the time until the reset
+// given by the X-RateLimit-Reset or RateLimit-Reset header, and otherwise an
+// exponential backoff for attempt, counted from 0.
+func Wait(resp *http.Response, attempt int, now time.Time) time.Duration {
+	if resp != nil {
+		if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+			if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+				return time.Duration(seconds) * time.Second
+			}
+			if at, err := http.ParseTime(retryAfter); err == nil {
+				return max(at.Sub(now), 0)
+			}
+		}
+		if reset := resetHeader(resp.Header); reset != "" {
+			if epoch, err := strconv.ParseInt(reset, 10, 64); err == nil {
+				// one more second, as the reset time is rounded down
+				return max(time.Unix(epoch, 0).Sub(now)+time.Second, 0)
+			}
+		}
+	}
+	return Backoff(attempt)
+}
+
+// Backoff returns 1s, 2s, 4s, ... for attempts 0, 1, 2, ...
+func Backoff(attempt int) time.Duration {
+	return time.Second << min(attempt, 6)
+}
+
+func remaining(header http.Header) string {
+	if v := header.Get("X-RateLimit-Remaining"); v != "" {
+		return v
+	}
+	return header.Get("RateLimit-Remaining")
+}
+
+func resetHeader(header http.Header) string {
+	if v := header.Get("X-RateLimit-Reset"); v != "" {
+		return v
+	}
+	return header.Get("RateLimit-Reset")
+}
+
+// Transport is an http.RoundTripper that retries requests the API rejects
+// because the rate limit is exceeded, after waiting for it to reset.
+type Transport struct {
+	Base       http.RoundTripper
+	MaxRetries int
+	MaxWait    time.Duration
+	// Now and Sleep default to time.Now and time.Sleep.
+	Now   func() time.Time
+	Sleep func(time.Duration)
+}
+
+// NewTransport returns a Transport sending requests with base, or with
+// http.DefaultTransport when base is nil.
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		Base:       base,
+		MaxRetries: DefaultMaxRetries,
+		MaxWait:    DefaultMaxWait,
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	now, wait := t.Now, t.Sleep
+	if now == nil {
+		now = time.Now
+	}
+	if wait == nil {
+		wait = sleep
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := t.Base.RoundTrip(req)
+		if err != nil || !Exceeded(resp) || attempt >= t.MaxRetries {
+			return resp, err
+		}
+		d := Wait(resp, attempt, now())
+		if d > t.MaxWait {
+			return resp, nil
+		}
+		// a request with a body can only be sent again if the body can be read again
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		_ = resp.Body.Close()
+		if err := req.Context().Err(); err != nil {
+			return nil, err
+		}
+		wait(d)
+	}
+}
+
+// Retry calls fn until it returns an error for which exceeded is false, or
+// until it was retried DefaultMaxRetries times, with an exponential backoff
+// between calls. It is for API clients which do not accept a Transport.
+func Retry[T any](fn func() (T, error), exceeded func(error) bool) (T, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := fn()
+		if err == nil || !exceeded(err) || attempt >= DefaultMaxRetries {
+			return result, err
+		}
+		sleep(Backoff(attempt))
+	}
+}
@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func response(status int, headers ...string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	for i := 0; i < len(headers); i += 2 {
+		resp.Header.Set(headers[i], headers[i+1])
+	}
+	return resp
+}
+
+func TestExceeded(t *testing.T) {
+	assert.True(t, Exceeded(response(http.StatusTooManyRequests)))
+	assert.True(t, Exceeded(response(http.StatusForbidden, "Retry-After", "60")))
+	assert.True(t, Exceeded(response(http.StatusForbidden, "X-RateLimit-Remaining", "0")))
+	assert.False(t, Exceeded(response(http.StatusForbidden, "X-RateLimit-Remaining", "12")))
+	assert.False(t, Exceeded(response(http.StatusOK, "X-RateLimit-Remaining", "0")))
+	assert.False(t, Exceeded(nil))
+}
+
+func TestWait(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 30*time.Second, Wait(response(429, "Retry-After", "30"), 0, now))
+	assert.Equal(t, 90*time.Second, Wait(response(429, "Retry-After", now.Add(90*time.Second).Format(http.TimeFormat)), 0, now))
+	reset := strconv.FormatInt(now.Add(10*time.Second).Unix(), 10)
+	assert.Equal(t, 11*time.Second, Wait(response(403, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", reset), 0, now))
+	assert.Equal(t, 11*time.Second, Wait(response(429, "RateLimit-Reset", reset), 0, now))
+	assert.Equal(t, 4*time.Second, Wait(response(429), 2, now))
+}
+
+func TestTransportRetriesRateLimitedRequests(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if calls < 3 {
+			w.Header().Set("Retry-After", "5")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, "ok %s", body)
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	transport := NewTransport(nil)
+	transport.Sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	client := &http.Client{Transport: transport}
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("query"))
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ok query", string(body))
+	assert.Equal(t, []time.Duration{5 * time.Second, 5 * time.Second}, waits)
+}
+
+func TestTransportGivesUp(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		retryAfter string
+		calls      int
+	}{
+		{name: "after MaxRetries", retryAfter: "1", calls: DefaultMaxRetries + 1},
+		{name: "when the wait is longer than MaxWait", retryAfter: "3600", calls: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Retry-After", tc.retryAfter)
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+			defer server.Close()
+
+			transport := NewTransport(nil)
+			transport.Sleep = func(time.Duration) {}
+
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+			assert.Equal(t, tc.calls, calls)
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	defer func(s func(time.Duration)) { sleep = s }(sleep)
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	errLimited := errors.New("too many requests")
+	exceeded := func(err error) bool { return errors.Is(err, errLimited) }
+
+	calls := 0
+	result, err := Retry(func() (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errLimited
+		}
+		return 42, nil
+	}, exceeded)
+	require.NoError(t, err)
+	assert.Equal(t, 42, result)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, waits)
+
+	calls = 0
+	_, err = Retry(func() (int, error) {
+		calls++
+		return 0, errors.New("not found")
+	}, exceeded)
+	require.EqualError(t, err, "not found")
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,30 @@
+package reposettings
+
+import (
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// FakeRepoSettingsRetriever is an in-memory implementation of
+// types.RepoSettingsRetriever for testing. Seed Settings with the settings of
+// the protected branches, keyed by branch name; any other branch is reported
+// as unprotected. Set Err to simulate a network or API failure.
+type FakeRepoSettingsRetriever struct {
+	Provider   string
+	Repository string
+	Settings   map[string]*types.RepoSettings
+	Err        error
+}
+
+func (f *FakeRepoSettingsRetriever) RepoSettingsProvider() string {
+	return f.Provider
+}
+
+func (f *FakeRepoSettingsRetriever) RepoSettings(branch string) (*types.RepoSettings, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if settings, ok := f.Settings[branch]; ok {
+		return settings, nil
+	}
+	return Unprotected(f.Repository, branch), nil
+}
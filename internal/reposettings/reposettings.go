@@ -0,0 +1,119 @@
+package reposettings
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Unprotected returns the settings of a branch no rule applies to: anyone
+// who can push to it can force push to it and delete it.
+func Unprotected(repository, branch string) *types.RepoSettings {
+	return &types.RepoSettings{
+		Repository:           repository,
+		Branch:               branch,
+		RequiredStatusChecks: []string{},
+		AllowForcePushes:     true,
+		AllowDeletions:       true,
+		Rules:                []any{},
+	}
+}
+
+// AddStatusChecks adds checks to the required status checks of s, once each,
+// and marks status checks as required.
+func AddStatusChecks(s *types.RepoSettings, checks ...string) {
+	s.RequireStatusChecks = true
+	for _, check := range checks {
+		if check != "" && !slices.Contains(s.RequiredStatusChecks, check) {
+			s.RequiredStatusChecks = append(s.RequiredStatusChecks, check)
+		}
+	}
+}
+
+// MatchBranch reports whether branch matches pattern, a branch name in which
+// "*" matches any characters, including "/", as GitLab and Bitbucket wildcard
+// branch patterns do.
+func MatchBranch(pattern, branch string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == branch
+	}
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$").MatchString(branch)
+}
+
+// Expected are the settings a branch is expected to have, read from an
+// expected-settings file. A setting that is not given is not checked.
+type Expected struct {
+	Protected *bool `json:"protected,omitempty" yaml:"protected"`
+	// RequiredApprovals is the minimum number of approvals required.
+	RequiredApprovals      *int  `json:"required_approvals,omitempty" yaml:"required_approvals"`
+	DismissStaleApprovals  *bool `json:"dismiss_stale_approvals,omitempty" yaml:"dismiss_stale_approvals"`
+	RequireCodeOwnerReview *bool `json:"require_code_owner_review,omitempty" yaml:"require_code_owner_review"`
+	RequireStatusChecks    *bool `json:"require_status_checks,omitempty" yaml:"require_status_checks"`
+	// RequiredStatusChecks must all be required, among any others.
+	RequiredStatusChecks []string `json:"required_status_checks,omitempty" yaml:"required_status_checks"`
+	RequireSignedCommits *bool    `json:"require_signed_commits,omitempty" yaml:"require_signed_commits"`
+	RequireLinearHistory *bool    `json:"require_linear_history,omitempty" yaml:"require_linear_history"`
+	AllowForcePushes     *bool    `json:"allow_force_pushes,omitempty" yaml:"allow_force_pushes"`
+	AllowDeletions       *bool    `json:"allow_deletions,omitempty" yaml:"allow_deletions"`
+	EnforceAdmins        *bool    `json:"enforce_admins,omitempty" yaml:"enforce_admins"`
+}
+
+// LoadExpected reads an expected-settings file, in YAML or JSON. Unknown
+// settings are an error, so that a misspelled setting is not silently
+// left unchecked.
+func LoadExpected(path string) (*Expected, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read expected settings file: %v", err)
+	}
+	expected := &Expected{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(expected); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse expected settings file %s: %v", path, err)
+	}
+	return expected, nil
+}
+
+// Violations returns how s differs from the expected settings, or an empty
+// list when s complies with them.
+func (e *Expected) Violations(s *types.RepoSettings) []string {
+	violations := []string{}
+	checkBool := func(name string, expected *bool, actual bool) {
+		if expected != nil && *expected != actual {
+			violations = append(violations, fmt.Sprintf("%s is %t, expected %t", name, actual, *expected))
+		}
+	}
+
+	checkBool("protected", e.Protected, s.Protected)
+	if e.RequiredApprovals != nil && s.RequiredApprovals < *e.RequiredApprovals {
+		violations = append(violations, fmt.Sprintf("required_approvals is %d, expected at least %d",
+			s.RequiredApprovals, *e.RequiredApprovals))
+	}
+	checkBool("dismiss_stale_approvals", e.DismissStaleApprovals, s.DismissStaleApprovals)
+	checkBool("require_code_owner_review", e.RequireCodeOwnerReview, s.RequireCodeOwnerReview)
+	checkBool("require_status_checks", e.RequireStatusChecks, s.RequireStatusChecks)
+	for _, check := range e.RequiredStatusChecks {
+		if !slices.Contains(s.RequiredStatusChecks, check) {
+			violations = append(violations, fmt.Sprintf("status check %q is not required", check))
+		}
+	}
+	checkBool("require_signed_commits", e.RequireSignedCommits, s.RequireSignedCommits)
+	checkBool("require_linear_history", e.RequireLinearHistory, s.RequireLinearHistory)
+	checkBool("allow_force_pushes", e.AllowForcePushes, s.AllowForcePushes)
+	checkBool("allow_deletions", e.AllowDeletions, s.AllowDeletions)
+	checkBool("enforce_admins", e.EnforceAdmins, s.EnforceAdmins)
+	return violations
+}
@@ -0,0 +1,104 @@
+package reposettings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchBranch(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, branch string
+		want            bool
+	}{
+		{"main", "main", true},
+		{"main", "main2", false},
+		{"release/*", "release/1.0", true},
+		{"release/*", "release/1.0/hotfix", true},
+		{"*-stable", "13-stable", true},
+		{"*-stable", "13-stable-x", false},
+		{"v1.*", "v1x0", false},
+	} {
+		assert.Equal(t, tc.want, MatchBranch(tc.pattern, tc.branch), "%s ~ %s", tc.pattern, tc.branch)
+	}
+}
+
+func TestAddStatusChecks(t *testing.T) {
+	s := Unprotected("acme/web", "main")
+	AddStatusChecks(s)
+	assert.True(t, s.RequireStatusChecks)
+	assert.Empty(t, s.RequiredStatusChecks)
+
+	AddStatusChecks(s, "build", "", "test", "build")
+	assert.Equal(t, []string{"build", "test"}, s.RequiredStatusChecks)
+}
+
+func TestLoadExpected(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	expected, err := LoadExpected(write("expected.yaml", `
+protected: true
+required_approvals: 2
+required_status_checks: [build]
+allow_force_pushes: false
+`))
+	require.NoError(t, err)
+	require.NotNil(t, expected.Protected)
+	assert.True(t, *expected.Protected)
+	require.NotNil(t, expected.RequiredApprovals)
+	assert.Equal(t, 2, *expected.RequiredApprovals)
+	assert.Equal(t, []string{"build"}, expected.RequiredStatusChecks)
+	require.NotNil(t, expected.AllowForcePushes)
+	assert.False(t, *expected.AllowForcePushes)
+	assert.Nil(t, expected.EnforceAdmins)
+
+	expected, err = LoadExpected(write("expected.json", `{"require_signed_commits": true}`))
+	require.NoError(t, err)
+	require.NotNil(t, expected.RequireSignedCommits)
+	assert.True(t, *expected.RequireSignedCommits)
+
+	expected, err = LoadExpected(write("empty.yaml", ""))
+	require.NoError(t, err)
+	assert.Equal(t, &Expected{}, expected)
+
+	_, err = LoadExpected(write("typo.yaml", "required_approval: 2\n"))
+	require.ErrorContains(t, err, "required_approval")
+
+	_, err = LoadExpected(filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+}
+
+func TestViolations(t *testing.T) {
+	yes, no, two := true, false, 2
+	expected := &Expected{
+		Protected:            &yes,
+		RequiredApprovals:    &two,
+		RequiredStatusChecks: []string{"build", "test"},
+		AllowForcePushes:     &no,
+	}
+
+	settings := Unprotected("acme/web", "main")
+	assert.Equal(t, []string{
+		"protected is false, expected true",
+		"required_approvals is 0, expected at least 2",
+		`status check "build" is not required`,
+		`status check "test" is not required`,
+		"allow_force_pushes is true, expected false",
+	}, expected.Violations(settings))
+
+	settings.Protected = true
+	settings.RequiredApprovals = 3
+	settings.AllowForcePushes = false
+	AddStatusChecks(settings, "test", "build", "lint")
+	assert.Empty(t, expected.Violations(settings))
+
+	assert.Empty(t, (&Expected{}).Violations(Unprotected("acme/web", "main")))
+}
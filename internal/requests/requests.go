@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -14,10 +15,15 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"time"
 
 	retryablehttp "github.com/hashicorp/go-retryablehttp"
 	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/kosli-dev/cli/internal/ratelimit"
+	"github.com/kosli-dev/cli/internal/telemetry"
 	"github.com/kosli-dev/cli/internal/version"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type FormItem struct {
@@ -73,6 +79,8 @@ func NewKosliClient(httpProxyURL string, maxAPIRetries int, debug bool, logger *
 	retryClient := retryablehttp.NewClient()
 	retryClient.RetryMax = maxAPIRetries
 	retryClient.CheckRetry = customCheckRetry
+	retryClient.Backoff = rateLimitBackoff
+	retryClient.RequestLogHook = countResends
 	if debug {
 		retryClient.Logger = &CustomLogger{
 			Logger: log.New(os.Stderr, "[debug]", log.Lmsgprefix),
@@ -115,11 +123,19 @@ type RequestParams struct {
 	// conflict), but some endpoints use 409 for a permanent client error
 	// (e.g. a duplicate identifier) that should be surfaced immediately.
 	DisableConflictRetry bool
+	// Context, when not nil, cancels the request and its retries when it is
+	// done.
+	Context context.Context
 }
 
 type contextKey string
 
-const disableConflictRetryKey contextKey = "disableConflictRetry"
+const (
+	disableConflictRetryKey contextKey = "disableConflictRetry"
+	// resendCountKey holds a pointer to the number of times a traced request
+	// was sent again, which is set by countResends.
+	resendCountKey contextKey = "resendCount"
+)
 
 func (p *RequestParams) newHTTPRequest() (*http.Request, map[string]any, error) {
 	if len(p.AdditionalHeaders) == 0 {
@@ -149,7 +165,11 @@ func (p *RequestParams) newHTTPRequest() (*http.Request, map[string]any, error)
 		}
 	}
 
-	req, err := http.NewRequest(p.Method, p.URL, body)
+	ctx := p.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, p.Method, p.URL, body)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create %s request to %s : %v", p.Method, p.URL, err)
 	}
@@ -245,7 +265,7 @@ func createMultipartRequestBody(items []FormItem) (string, *bytes.Buffer, map[st
 	return contentType, body, jsonFields, nil
 }
 
-func (c *Client) Do(p *RequestParams) (*HTTPResponse, error) {
+func (c *Client) Do(p *RequestParams) (response *HTTPResponse, err error) {
 	req, jsonFields, err := p.newHTTPRequest()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create a %s request to %s : %v", p.Method, p.URL, err)
@@ -274,10 +294,23 @@ func (c *Client) Do(p *RequestParams) (*HTTPResponse, error) {
 				c.Logger.Warn("failed to log payload: %v \nContinuing with the request...", err)
 			}
 		}
+		if telemetry.Enabled() {
+			resends := new(int)
+			var span trace.Span
+			req, span = telemetry.StartHTTP(req.WithContext(context.WithValue(req.Context(), resendCountKey, resends)))
+			defer func() {
+				span.SetAttributes(attribute.Int("http.request.resend_count", *resends))
+				var apiErr *APIError
+				if errors.As(err, &apiErr) {
+					span.SetAttributes(attribute.Int("http.response.status_code", apiErr.StatusCode))
+				}
+				telemetry.EndHTTP(span, responseOf(response), err)
+			}()
+		}
 		resp, err := c.HttpClient.Do(req)
 		if err != nil {
 			// err from retryable client is detailed enough
-			return nil, fmt.Errorf("%v", err)
+			return nil, fmt.Errorf("%w", err)
 		}
 
 		defer func() {
@@ -362,6 +395,31 @@ func (c *Client) PayloadOutput(req *http.Request, jsonFields map[string]any, mes
 	return nil
 }
 
+// countResends is the retryablehttp.RequestLogHook recording how many times
+// a traced request was sent again. It is called before each attempt.
+func countResends(_ retryablehttp.Logger, req *http.Request, attempt int) {
+	if resends, ok := req.Context().Value(resendCountKey).(*int); ok {
+		*resends = attempt
+	}
+}
+
+func responseOf(response *HTTPResponse) *http.Response {
+	if response == nil {
+		return nil
+	}
+	return response.Resp
+}
+
+// rateLimitBackoff waits for an exceeded rate limit to reset, as given by the
+// Retry-After or X-RateLimit-Reset headers, up to maxWait. Other retries back
+// off exponentially.
+func rateLimitBackoff(minWait, maxWait time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	if ratelimit.Exceeded(resp) {
+		return min(ratelimit.Wait(resp, attemptNum, time.Now()), maxWait)
+	}
+	return retryablehttp.DefaultBackoff(minWait, maxWait, attemptNum, resp)
+}
+
 func customCheckRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
 	// Get the default retry policy for errors and certain status codes.
 	// It will retry on 5xx, 429 (rate limit) and we add 409 (lock conflict) via a custom check.
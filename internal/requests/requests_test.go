@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/kosli-dev/cli/internal/telemetry"
 	"github.com/kosli-dev/cli/internal/version"
 	"github.com/maxcnunes/httpfake"
 	"github.com/stretchr/testify/require"
@@ -632,6 +636,35 @@ func (suite *RequestsTestSuite) TestNonMultipartJSON_IsCompact() {
 	require.NotContains(suite.T(), bodyStr, "\n", "non-multipart JSON body should be on a single line")
 }
 
+func (suite *RequestsTestSuite) TestTracedRequestRecordsResendCount() {
+	path := filepath.Join(suite.T().TempDir(), "traces.jsonl")
+	suite.T().Setenv(telemetry.TracesEndpointEnvVar, "file://"+path)
+	require.NoError(suite.T(), telemetry.Setup("test"))
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client, err := NewKosliClient("", 3, false, logger.NewStandardLogger())
+	require.NoError(suite.T(), err)
+	_, err = client.Do(&RequestParams{Method: http.MethodPut, URL: server.URL + "/api/v2/environments", Payload: map[string]string{}})
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), telemetry.Shutdown())
+
+	content, err := os.ReadFile(path)
+	require.NoError(suite.T(), err)
+	require.Contains(suite.T(), string(content), `"name":"HTTP PUT"`)
+	require.Contains(suite.T(), string(content), `{"key":"http.request.resend_count","value":{"intValue":"1"}}`)
+	require.Contains(suite.T(), string(content), `{"key":"http.response.status_code","value":{"intValue":"201"}}`)
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestRequestsTestSuite(t *testing.T) {
@@ -0,0 +1,113 @@
+package review
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CodeOwnersLocations are the paths a CODEOWNERS file is looked up at, in
+// order. GitHub reads .github/, the root and docs/; GitLab reads the root,
+// docs/ and .gitlab/; Bitbucket Data Center reads .bitbucket/.
+var CodeOwnersLocations = []string{
+	".github/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+	".bitbucket/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// CodeOwners is a parsed CODEOWNERS file.
+type CodeOwners struct {
+	File  string
+	Rules []*Rule
+}
+
+// Rule is a line of a CODEOWNERS file: a path pattern and its owners. A rule
+// without owners leaves the paths it matches without an owner.
+type Rule struct {
+	Pattern string
+	Owners  []string
+	re      *regexp.Regexp
+}
+
+// ParseCodeOwners parses the content of a CODEOWNERS file. Patterns follow the
+// gitignore rules used by GitHub and GitLab. GitLab section headers such as
+// "[Docs]" are skipped, together with the default owners they may list, so
+// the rules of all sections are read as one list.
+func ParseCodeOwners(file, content string) (*CodeOwners, error) {
+	codeOwners := &CodeOwners{File: file}
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") ||
+			strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		fields := strings.Fields(line)
+		rule := &Rule{Pattern: fields[0], Owners: []string{}}
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			rule.Owners = append(rule.Owners, owner)
+		}
+		re, err := patternRegexp(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: invalid pattern %q: %v", file, i+1, rule.Pattern, err)
+		}
+		rule.re = re
+		codeOwners.Rules = append(codeOwners.Rules, rule)
+	}
+	return codeOwners, nil
+}
+
+// RuleFor returns the rule that owns path, which is the last rule whose
+// pattern matches it, or nil if no rule does.
+func (c *CodeOwners) RuleFor(path string) *Rule {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(c.Rules) - 1; i >= 0; i-- {
+		if c.Rules[i].re.MatchString(path) {
+			return c.Rules[i]
+		}
+	}
+	return nil
+}
+
+// patternRegexp turns a CODEOWNERS path pattern into a regular expression
+// matching the paths it owns. A pattern is anchored at the repository root
+// when it starts with, or contains, a slash, and otherwise matches at any
+// depth. A pattern matching a directory owns everything below it, except
+// that "dir/*" owns only the files directly in dir.
+func patternRegexp(pattern string) (*regexp.Regexp, error) {
+	p := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	if p == "*" || !strings.HasSuffix(p, "/*") {
+		b.WriteString("(?:/.*)?")
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,65 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const codeOwnersFile = `# default owners
+*                  @acme/everyone
+
+[Docs]
+docs/              @alice   # documentation
+/build/logs/       bob@example.com
+apps/*             @carol
+**/generated/**    @dave
+*.go               @erin @acme/go
+/config/secrets.yml
+`
+
+func TestParseCodeOwners(t *testing.T) {
+	codeOwners, err := ParseCodeOwners(".github/CODEOWNERS", codeOwnersFile)
+	require.NoError(t, err)
+	assert.Equal(t, ".github/CODEOWNERS", codeOwners.File)
+	require.Len(t, codeOwners.Rules, 7)
+	assert.Equal(t, "docs/", codeOwners.Rules[1].Pattern)
+	assert.Equal(t, []string{"@alice"}, codeOwners.Rules[1].Owners)
+	assert.Equal(t, []string{"@erin", "@acme/go"}, codeOwners.Rules[5].Owners)
+	assert.Empty(t, codeOwners.Rules[6].Owners)
+}
+
+func TestRuleFor(t *testing.T) {
+	codeOwners, err := ParseCodeOwners("CODEOWNERS", codeOwnersFile)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		path    string
+		pattern string
+	}{
+		{path: "README.md", pattern: "*"},
+		{path: "docs/index.md", pattern: "docs/"},
+		{path: "src/docs/index.md", pattern: "docs/"},
+		{path: "build/logs/out.txt", pattern: "/build/logs/"},
+		{path: "src/build/logs/out.txt", pattern: "*"},
+		{path: "apps/main.js", pattern: "apps/*"},
+		{path: "apps/web/main.js", pattern: "*"},
+		{path: "src/generated/api.js", pattern: "**/generated/**"},
+		{path: "src/generated/api.go", pattern: "*.go"},
+		{path: "/cmd/main.go", pattern: "*.go"},
+		{path: "config/secrets.yml", pattern: "/config/secrets.yml"},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			rule := codeOwners.RuleFor(tc.path)
+			require.NotNil(t, rule)
+			assert.Equal(t, tc.pattern, rule.Pattern)
+		})
+	}
+}
+
+func TestRuleForWithoutMatchingRule(t *testing.T) {
+	codeOwners, err := ParseCodeOwners("CODEOWNERS", "docs/ @alice\n")
+	require.NoError(t, err)
+	assert.Nil(t, codeOwners.RuleFor("main.go"))
+}
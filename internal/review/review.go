@@ -0,0 +1,211 @@
+package review
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// approval is an approver of a pull request, with its provider account and
+// when it approved when the provider reports them.
+type approval struct {
+	name       string
+	account    string
+	identities map[string]bool
+	timestamp  int64
+}
+
+// identityPattern splits the "Name <email>", "Name (@username)" and
+// "Name (unique name)" forms providers use for commit authors and approvers.
+var identityPattern = regexp.MustCompile(`^(.*?)\s*[<(]([^>)]*)[>)]$`)
+
+// identities returns the lower-cased names a person is known by in s, so that
+// approvers can be matched with the owners of a CODEOWNERS file.
+func identities(s string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	ids := []string{strings.ToLower(s)}
+	if m := identityPattern.FindStringSubmatch(s); m != nil {
+		for _, id := range []string{m[1], strings.TrimPrefix(m[2], "@")} {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, strings.ToLower(id))
+			}
+		}
+	}
+	return ids
+}
+
+func intersects(a, b map[string]bool) bool {
+	for id := range a {
+		if b[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// approvals returns the approvers of pr in the order they are listed, keeping
+// the latest approval of anyone listed more than once. Approvers are either
+// names (V1 payloads and some providers) or types.PRApprovals.
+func approvals(pr *types.PREvidence) []*approval {
+	result := []*approval{}
+	byName := map[string]*approval{}
+	for _, a := range pr.Approvers {
+		var name, account string
+		var timestamp int64
+		switch v := a.(type) {
+		case string:
+			name = v
+		case types.PRApprovals:
+			name, account, timestamp = v.Username, v.UserID, v.Timestamp
+		case *types.PRApprovals:
+			name, account, timestamp = v.Username, v.UserID, v.Timestamp
+		default:
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		if existing, ok := byName[name]; ok {
+			if timestamp > existing.timestamp {
+				existing.timestamp = timestamp
+			}
+			continue
+		}
+		ap := &approval{name: name, account: strings.ToLower(account), identities: map[string]bool{}, timestamp: timestamp}
+		for _, id := range append(identities(name), ap.account) {
+			if id != "" {
+				ap.identities[id] = true
+			}
+		}
+		byName[name] = ap
+		result = append(result, ap)
+	}
+	return result
+}
+
+// Analyze computes the four-eyes analysis of pr: which approvers also
+// authored commits of the pull request, which approved before its last
+// commit, and, when codeOwners is not nil, whether the owners of
+// changedFiles approved it. Only independent approvals that are not stale
+// count towards FourEyes and code owner approval.
+func Analyze(pr *types.PREvidence, codeOwners *CodeOwners, changedFiles []string) *types.ReviewAnalysis {
+	analysis := &types.ReviewAnalysis{
+		IndependentApprovers: []string{},
+		SelfApprovers:        []string{},
+		StaleApprovers:       []string{},
+	}
+
+	// self-approvals are found from the provider accounts of the commit
+	// authors and the approvers, as their names are free text which neither
+	// identifies nor distinguishes people. Without the account of all of
+	// them, no approver is known to be independent, so the pull request does
+	// not pass four-eyes.
+	analysis.SelfApprovalsChecked = len(pr.Commits) > 0
+	authors := map[string]bool{}
+	for _, commit := range pr.Commits {
+		account := commit.AuthorID
+		if account == "" {
+			account = commit.AuthorUsername
+		}
+		if account == "" {
+			analysis.SelfApprovalsChecked = false
+		}
+		authors[strings.ToLower(account)] = true
+		// a commit authored before an approval can be rebased or amended
+		// after it, so the later of its dates is the one that counts
+		analysis.LastCommitTimestamp = max(analysis.LastCommitTimestamp, commit.Timestamp, commit.CommittedAt)
+	}
+
+	approvers := approvals(pr)
+	analysis.StaleApprovalsChecked = analysis.LastCommitTimestamp > 0
+	for _, ap := range approvers {
+		if ap.timestamp == 0 {
+			analysis.StaleApprovalsChecked = false
+		}
+		if ap.account == "" {
+			analysis.SelfApprovalsChecked = false
+		}
+	}
+
+	valid := []*approval{}
+	for _, ap := range approvers {
+		self := analysis.SelfApprovalsChecked && authors[ap.account]
+		if self {
+			analysis.SelfApprovers = append(analysis.SelfApprovers, ap.name)
+		} else if analysis.SelfApprovalsChecked {
+			analysis.IndependentApprovers = append(analysis.IndependentApprovers, ap.name)
+		}
+		stale := analysis.StaleApprovalsChecked && ap.timestamp < analysis.LastCommitTimestamp
+		if stale {
+			analysis.StaleApprovers = append(analysis.StaleApprovers, ap.name)
+		}
+		if !self && !stale {
+			valid = append(valid, ap)
+		}
+	}
+	analysis.FourEyes = analysis.SelfApprovalsChecked && len(valid) > 0
+
+	if codeOwners != nil {
+		analysis.CodeOwners = reviewCodeOwners(codeOwners, changedFiles, valid)
+	}
+	return analysis
+}
+
+// reviewCodeOwners groups changedFiles by the CODEOWNERS rule owning them and
+// checks that one of the approvers is a personal owner of each rule.
+func reviewCodeOwners(codeOwners *CodeOwners, changedFiles []string, approvers []*approval) *types.CodeOwnersReview {
+	result := &types.CodeOwnersReview{
+		File:     codeOwners.File,
+		Approved: true,
+		Rules:    []types.CodeOwnersRuleReview{},
+	}
+
+	files := append([]string{}, changedFiles...)
+	sort.Strings(files)
+	index := map[*Rule]int{}
+	for _, file := range files {
+		rule := codeOwners.RuleFor(file)
+		if rule == nil || len(rule.Owners) == 0 {
+			continue
+		}
+		i, ok := index[rule]
+		if !ok {
+			i = len(result.Rules)
+			index[rule] = i
+			result.Rules = append(result.Rules, types.CodeOwnersRuleReview{
+				Pattern:    rule.Pattern,
+				Owners:     rule.Owners,
+				Files:      []string{},
+				ApprovedBy: []string{},
+			})
+		}
+		result.Rules[i].Files = append(result.Rules[i].Files, file)
+	}
+
+	for i := range result.Rules {
+		rule := &result.Rules[i]
+		owners := map[string]bool{}
+		for _, owner := range rule.Owners {
+			if strings.HasPrefix(owner, "@") && strings.Contains(owner, "/") {
+				rule.TeamOwners = true
+				continue
+			}
+			owners[strings.ToLower(strings.TrimPrefix(owner, "@"))] = true
+		}
+		for _, ap := range approvers {
+			if intersects(ap.identities, owners) {
+				rule.ApprovedBy = append(rule.ApprovedBy, ap.name)
+			}
+		}
+		rule.Approved = len(rule.ApprovedBy) > 0
+		if !rule.Approved {
+			result.Approved = false
+		}
+	}
+	return result
+}
@@ -0,0 +1,209 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commits returns a commit per author account, in order.
+func commits(accounts ...string) []types.Commit {
+	result := []types.Commit{}
+	for i, account := range accounts {
+		result = append(result, types.Commit{Author: account, AuthorID: account, Timestamp: int64(100 * (i + 1))})
+	}
+	return result
+}
+
+func TestAnalyzeGitHubApprovals(t *testing.T) {
+	pr := &types.PREvidence{
+		Approvers: []any{
+			types.PRApprovals{Username: "alice", UserID: "alice", State: "APPROVED", Timestamp: 150},
+			types.PRApprovals{Username: "bob", UserID: "bob", State: "APPROVED", Timestamp: 250},
+			types.PRApprovals{Username: "carol", UserID: "carol", State: "APPROVED", Timestamp: 50},
+		},
+		Commits: []types.Commit{
+			{Author: "Alice <alice@example.com>", AuthorUsername: "alice", Timestamp: 100},
+			{Author: "Dave <dave@example.com>", AuthorUsername: "dave", Timestamp: 200},
+		},
+	}
+
+	analysis := Analyze(pr, nil, nil)
+	assert.Equal(t, &types.ReviewAnalysis{
+		IndependentApprovers:  []string{"bob", "carol"},
+		SelfApprovers:         []string{"alice"},
+		SelfApprovalsChecked:  true,
+		StaleApprovers:        []string{"alice", "carol"},
+		StaleApprovalsChecked: true,
+		LastCommitTimestamp:   200,
+		FourEyes:              true,
+	}, analysis)
+}
+
+func TestAnalyzeApprovalBeforeARebasedCommit(t *testing.T) {
+	// the commit was authored before the approval, then rebased after it
+	pr := &types.PREvidence{
+		Approvers: []any{types.PRApprovals{Username: "bob", UserID: "bob", State: "APPROVED", Timestamp: 200}},
+		Commits: []types.Commit{
+			{Author: "Alice <alice@example.com>", AuthorUsername: "alice", Timestamp: 100, CommittedAt: 300},
+		},
+	}
+
+	analysis := Analyze(pr, nil, nil)
+	assert.Equal(t, int64(300), analysis.LastCommitTimestamp)
+	assert.Equal(t, []string{"bob"}, analysis.StaleApprovers)
+	assert.False(t, analysis.FourEyes)
+}
+
+func TestAnalyzeWithoutIndependentCurrentApproval(t *testing.T) {
+	pr := &types.PREvidence{
+		Approvers: []any{
+			types.PRApprovals{Username: "alice", UserID: "alice", Timestamp: 300},
+			types.PRApprovals{Username: "bob", UserID: "bob", Timestamp: 150},
+		},
+		Commits: commits("alice", "carol"),
+	}
+
+	analysis := Analyze(pr, nil, nil)
+	assert.Equal(t, []string{"alice"}, analysis.SelfApprovers)
+	assert.Equal(t, []string{"bob"}, analysis.StaleApprovers)
+	assert.False(t, analysis.FourEyes)
+}
+
+func TestAnalyzeApprovalsWithoutTimestamps(t *testing.T) {
+	pr := &types.PREvidence{
+		// Azure DevOps reports "Name (unique name)", and accounts by their
+		// unique name, which the author name of a commit does not match
+		Approvers: []any{
+			types.PRApprovals{Username: "Alice Smith (asmith@example.com)", UserID: "ASmith@example.com", State: "APPROVED"},
+			types.PRApprovals{Username: "Alice Smith (alice.smith@example.com)", UserID: "alice.smith@example.com", State: "APPROVED"},
+		},
+		Commits: []types.Commit{
+			{Author: "alice <asmith@example.com>", AuthorID: "asmith@example.com", Timestamp: 100},
+		},
+	}
+
+	analysis := Analyze(pr, nil, nil)
+	assert.Equal(t, []string{"Alice Smith (asmith@example.com)"}, analysis.SelfApprovers)
+	assert.Equal(t, []string{"Alice Smith (alice.smith@example.com)"}, analysis.IndependentApprovers,
+		"a namesake of the author is independent")
+	assert.False(t, analysis.StaleApprovalsChecked)
+	assert.Empty(t, analysis.StaleApprovers)
+	assert.True(t, analysis.FourEyes)
+}
+
+func TestAnalyzeWithoutAccounts(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		pr   *types.PREvidence
+	}{
+		{
+			name: "an approver without an account",
+			pr: &types.PREvidence{
+				Approvers: []any{"Bob (bob@example.com)", types.PRApprovals{Username: "carol", UserID: "carol"}},
+				Commits:   commits("alice"),
+			},
+		},
+		{
+			// an author whose git name is not their display name is not
+			// mistaken for an independent approver
+			name: "a commit author without an account",
+			pr: &types.PREvidence{
+				Approvers: []any{types.PRApprovals{Username: "Alice Smith (@asmith)", UserID: "42"}},
+				Commits:   []types.Commit{{Author: "alice <alice@example.com>", Timestamp: 100}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := Analyze(tc.pr, nil, nil)
+			assert.False(t, analysis.SelfApprovalsChecked)
+			assert.Empty(t, analysis.IndependentApprovers)
+			assert.Empty(t, analysis.SelfApprovers)
+			assert.False(t, analysis.FourEyes)
+		})
+	}
+}
+
+func TestAnalyzeWithoutCommits(t *testing.T) {
+	// the pull requests found by some lookups have no commits, so their
+	// authors are unknown
+	pr := &types.PREvidence{
+		Approvers: []any{types.PRApprovals{Username: "alice", State: "APPROVED", Timestamp: 150}},
+		Commits:   []types.Commit{},
+	}
+
+	analysis := Analyze(pr, nil, nil)
+	assert.False(t, analysis.SelfApprovalsChecked)
+	assert.Empty(t, analysis.IndependentApprovers)
+	assert.Empty(t, analysis.SelfApprovers)
+	assert.False(t, analysis.FourEyes)
+}
+
+func TestAnalyzeWithoutApprovals(t *testing.T) {
+	analysis := Analyze(&types.PREvidence{Approvers: []any{}, Commits: commits("alice")}, nil, nil)
+	assert.Empty(t, analysis.IndependentApprovers)
+	assert.True(t, analysis.StaleApprovalsChecked)
+	assert.False(t, analysis.FourEyes)
+}
+
+func TestAnalyzeCodeOwners(t *testing.T) {
+	codeOwners, err := ParseCodeOwners("CODEOWNERS", `
+*.go        @bob @acme/backend
+docs/       alice@example.com
+/deploy/    @acme/ops
+/vendor/
+`)
+	require.NoError(t, err)
+
+	pr := &types.PREvidence{
+		Approvers: []any{
+			types.PRApprovals{Username: "bob", UserID: "bob", Timestamp: 300},
+			types.PRApprovals{Username: "alice", UserID: "alice", Timestamp: 300},
+		},
+		Commits: []types.Commit{
+			{Author: "Alice <alice@example.com>", AuthorUsername: "alice", Timestamp: 200},
+		},
+	}
+	changed := []string{"main.go", "docs/index.md", "cmd/run.go", "deploy/app.yaml", "vendor/lib.go", "README.md"}
+
+	analysis := Analyze(pr, codeOwners, changed)
+	assert.Equal(t, &types.CodeOwnersReview{
+		File:     "CODEOWNERS",
+		Approved: false,
+		Rules: []types.CodeOwnersRuleReview{
+			{
+				Pattern:    "*.go",
+				Owners:     []string{"@bob", "@acme/backend"},
+				Files:      []string{"cmd/run.go", "main.go"},
+				ApprovedBy: []string{"bob"},
+				Approved:   true,
+				TeamOwners: true,
+			},
+			{
+				Pattern:    "/deploy/",
+				Owners:     []string{"@acme/ops"},
+				Files:      []string{"deploy/app.yaml"},
+				ApprovedBy: []string{},
+				TeamOwners: true,
+			},
+			{
+				// alice owns docs/ but authored the pull request
+				Pattern:    "docs/",
+				Owners:     []string{"alice@example.com"},
+				Files:      []string{"docs/index.md"},
+				ApprovedBy: []string{},
+			},
+		},
+	}, analysis.CodeOwners)
+}
+
+func TestAnalyzeCodeOwnersWithoutOwnedFiles(t *testing.T) {
+	codeOwners, err := ParseCodeOwners("CODEOWNERS", "docs/ @alice\n")
+	require.NoError(t, err)
+
+	analysis := Analyze(&types.PREvidence{}, codeOwners, []string{"main.go"})
+	assert.True(t, analysis.CodeOwners.Approved)
+	assert.Empty(t, analysis.CodeOwners.Rules)
+}
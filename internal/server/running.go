@@ -0,0 +1,197 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/logger"
+)
+
+// RunningSpec represents specification for how to find the artifacts which
+// are running on a server: as systemd services, or as processes
+type RunningSpec struct {
+	// SystemdUnits are patterns of the names of the running systemd services
+	// to report, e.g. "myapp-*.service"
+	SystemdUnits []string
+	// ProcessPatterns are regular expressions matched against the command
+	// lines of the running processes to report
+	ProcessPatterns []string
+	// WorkingDirectory fingerprints the working directory of each service or
+	// process, instead of its executable
+	WorkingDirectory bool
+	// Exclude are the paths to exclude when fingerprinting working directories
+	Exclude []string
+}
+
+// runSystemctl runs systemctl with args and returns its output.
+// Tests override it to fake systemd.
+var runSystemctl = func(args ...string) ([]byte, error) {
+	out, err := exec.Command("systemctl", args...).Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return out, fmt.Errorf("systemctl %s failed: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return out, err
+}
+
+// procRoot is the mount point of the proc filesystem.
+// Tests override it with a fake one.
+var procRoot = "/proc"
+
+// CreateRunningPathsSpec finds the services and processes of rs which are
+// running, and returns the spec of the paths of their executables, or of
+// their working directories, to fingerprint them with CreatePathsArtifactsData.
+// Services are named after their unit, and processes after the absolute path
+// of their executable or working directory.
+func CreateRunningPathsSpec(rs *RunningSpec, logger *logger.Logger) (*PathsSpec, error) {
+	ps := &PathsSpec{Version: 1, Artifacts: map[string]ArtifactPathSpec{}}
+	var exclude []string
+	if rs.WorkingDirectory {
+		exclude = rs.Exclude
+	}
+	if len(rs.SystemdUnits) > 0 {
+		if err := addSystemdUnits(ps, rs, exclude, logger); err != nil {
+			return nil, err
+		}
+	}
+	if len(rs.ProcessPatterns) > 0 {
+		if err := addProcesses(ps, rs, exclude, logger); err != nil {
+			return nil, err
+		}
+	}
+	if len(ps.Artifacts) == 0 {
+		return nil, fmt.Errorf("no running systemd services or processes found for %v", slices.Concat(rs.SystemdUnits, rs.ProcessPatterns))
+	}
+	return ps, nil
+}
+
+func addSystemdUnits(ps *PathsSpec, rs *RunningSpec, exclude []string, logger *logger.Logger) error {
+	args := append([]string{"list-units", "--type=service", "--state=running", "--no-legend", "--plain", "--no-pager"}, rs.SystemdUnits...)
+	out, err := runSystemctl(args...)
+	if err != nil {
+		return fmt.Errorf("failed to list running systemd services: %v", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		unit := fields[0]
+		path, err := systemdUnitPath(unit, rs.WorkingDirectory)
+		if err != nil {
+			return err
+		}
+		logger.Debug("systemd service [%s] runs from [%s]", unit, path)
+		ps.Artifacts[unit] = ArtifactPathSpec{Path: path, Exclude: exclude}
+	}
+	return nil
+}
+
+// systemdUnitPath returns the path of the ExecStart executable of a running
+// systemd unit, or its working directory.
+func systemdUnitPath(unit string, workingDirectory bool) (string, error) {
+	out, err := runSystemctl("show", unit, "--property=ExecStart,WorkingDirectory,MainPID", "--no-pager")
+	if err != nil {
+		return "", fmt.Errorf("failed to get the properties of systemd service [%s]: %v", unit, err)
+	}
+	properties := map[string]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			properties[key] = value
+		}
+	}
+
+	if workingDirectory {
+		// systemd prefixes a working directory which may be missing with -
+		if dir := strings.TrimPrefix(properties["WorkingDirectory"], "-"); dir != "" && dir != "~" {
+			return dir, nil
+		}
+		// without a WorkingDirectory, the service runs in the directory of its
+		// main process
+		if pid, err := strconv.Atoi(properties["MainPID"]); err == nil && pid > 0 {
+			return processLink(strconv.Itoa(pid), "cwd")
+		}
+		return "", fmt.Errorf("systemd service [%s] has no working directory", unit)
+	}
+
+	// ExecStart is like { path=/usr/bin/app ; argv[]=/usr/bin/app --flag ; ... }
+	for _, field := range strings.Split(properties["ExecStart"], ";") {
+		if path, ok := strings.CutPrefix(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(field), "{")), "path="); ok {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("systemd service [%s] has no ExecStart executable", unit)
+}
+
+func addProcesses(ps *PathsSpec, rs *RunningSpec, exclude []string, logger *logger.Logger) error {
+	patterns := make([]*regexp.Regexp, 0, len(rs.ProcessPatterns))
+	for _, pattern := range rs.ProcessPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid process pattern %s: %v", pattern, err)
+		}
+		patterns = append(patterns, re)
+	}
+
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return fmt.Errorf("failed to list running processes: %v", err)
+	}
+	for _, entry := range entries {
+		pid := entry.Name()
+		// the command line of this process includes the patterns
+		if n, err := strconv.Atoi(pid); err != nil || n == os.Getpid() {
+			continue
+		}
+		cmdline, err := os.ReadFile(filepath.Join(procRoot, pid, "cmdline"))
+		if err != nil {
+			// the process has exited since it was listed
+			continue
+		}
+		commandLine := strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+		if commandLine == "" || !matchesAny(patterns, commandLine) {
+			continue
+		}
+
+		link := "exe"
+		if rs.WorkingDirectory {
+			link = "cwd"
+		}
+		path, err := processLink(pid, link)
+		if err != nil {
+			logger.Warn("skipping process %s [%s]: %v", pid, commandLine, err)
+			continue
+		}
+		logger.Debug("process %s [%s] runs from [%s]", pid, commandLine, path)
+		ps.Artifacts[path] = ArtifactPathSpec{Path: path, Exclude: exclude}
+	}
+	return nil
+}
+
+// processLink returns the path of the exe or cwd link of a running process.
+func processLink(pid, link string) (string, error) {
+	path, err := os.Readlink(filepath.Join(procRoot, pid, link))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the %s of process %s: %v", link, pid, err)
+	}
+	// the kernel marks a link to a file which was replaced since the process
+	// started, so what runs is no longer on disk
+	if strings.HasSuffix(path, " (deleted)") {
+		return "", fmt.Errorf("the %s of process %s was deleted or replaced since it started: %s", link, pid, path)
+	}
+	return path, nil
+}
+
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
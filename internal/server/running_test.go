@@ -0,0 +1,137 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSystemctl fakes systemctl with a running api.service which runs
+// bin/api in apiDir, and a running worker.service which runs
+// /opt/worker/worker as workerPID without a working directory.
+func fakeSystemctl(t *testing.T, apiDir, workerPID string) *[][]string {
+	t.Helper()
+	var calls [][]string
+	origRunSystemctl := runSystemctl
+	t.Cleanup(func() { runSystemctl = origRunSystemctl })
+	runSystemctl = func(args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		switch args[0] {
+		case "list-units":
+			var out []byte
+			for _, unit := range []string{"api.service", "worker.service"} {
+				for _, pattern := range args[6:] {
+					if matched, _ := filepath.Match(pattern, unit); matched {
+						out = fmt.Appendf(out, "%s loaded active running %s\n", unit, unit)
+						break
+					}
+				}
+			}
+			return out, nil
+		case "show":
+			switch args[1] {
+			case "api.service":
+				return fmt.Appendf(nil, "MainPID=100\nExecStart={ path=%s/bin/api ; argv[]=%s/bin/api --port 80 ; ignore_errors=no ; start_time=[n/a] ; stop_time=[n/a] ; pid=0 ; code=(null) ; status=0/0 }\nWorkingDirectory=%s\n",
+					apiDir, apiDir, apiDir), nil
+			case "worker.service":
+				return fmt.Appendf(nil, "MainPID=%s\nExecStart={ path=/opt/worker/worker ; argv[]=/opt/worker/worker ; ignore_errors=no }\nWorkingDirectory=\n", workerPID), nil
+			}
+		}
+		return nil, fmt.Errorf("unexpected systemctl %s", strings.Join(args, " "))
+	}
+	return &calls
+}
+
+// fakeProc creates a fake proc filesystem with a process per command line,
+// each with its exe and cwd links.
+func fakeProc(t *testing.T, processes map[string]struct{ cmdline, exe, cwd string }) {
+	t.Helper()
+	root := t.TempDir()
+	for pid, process := range processes {
+		dir := filepath.Join(root, pid)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "cmdline"), []byte(strings.ReplaceAll(process.cmdline, " ", "\x00")+"\x00"), 0o644))
+		require.NoError(t, os.Symlink(process.exe, filepath.Join(dir, "exe")))
+		require.NoError(t, os.Symlink(process.cwd, filepath.Join(dir, "cwd")))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sys"), 0o755))
+
+	origProcRoot := procRoot
+	t.Cleanup(func() { procRoot = origProcRoot })
+	procRoot = root
+}
+
+func TestCreateRunningPathsSpecForSystemdUnits(t *testing.T) {
+	fakeProc(t, map[string]struct{ cmdline, exe, cwd string }{
+		"200": {cmdline: "/opt/worker/worker", exe: "/opt/worker/worker", cwd: "/var/lib/worker"},
+	})
+	calls := fakeSystemctl(t, "/opt/api", "200")
+
+	ps, err := CreateRunningPathsSpec(&RunningSpec{SystemdUnits: []string{"api.service", "worker*"}, Exclude: []string{"logs"}}, logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Equal(t, &PathsSpec{Version: 1, Artifacts: map[string]ArtifactPathSpec{
+		"api.service":    {Path: "/opt/api/bin/api"},
+		"worker.service": {Path: "/opt/worker/worker"},
+	}}, ps)
+	require.Equal(t, []string{"list-units", "--type=service", "--state=running", "--no-legend", "--plain", "--no-pager", "api.service", "worker*"}, (*calls)[0])
+
+	ps, err = CreateRunningPathsSpec(&RunningSpec{SystemdUnits: []string{"*"}, WorkingDirectory: true, Exclude: []string{"logs"}}, logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Equal(t, &PathsSpec{Version: 1, Artifacts: map[string]ArtifactPathSpec{
+		"api.service": {Path: "/opt/api", Exclude: []string{"logs"}},
+		// without a WorkingDirectory, the working directory of the main process
+		"worker.service": {Path: "/var/lib/worker", Exclude: []string{"logs"}},
+	}}, ps)
+}
+
+func TestCreateRunningPathsSpecForProcesses(t *testing.T) {
+	fakeProc(t, map[string]struct{ cmdline, exe, cwd string }{
+		"10": {cmdline: "/usr/bin/python3 /srv/app/main.py --serve", exe: "/usr/bin/python3.12", cwd: "/srv/app"},
+		"11": {cmdline: "/srv/api/api --port 80", exe: "/srv/api/api", cwd: "/srv/api"},
+		"12": {cmdline: "/srv/api/api --port 81", exe: "/srv/api/api", cwd: "/srv/api"},
+		"13": {cmdline: "/usr/sbin/sshd -D", exe: "/usr/sbin/sshd", cwd: "/"},
+		"14": {cmdline: "/srv/old/api", exe: "/srv/old/api (deleted)", cwd: "/srv/old"},
+	})
+
+	ps, err := CreateRunningPathsSpec(&RunningSpec{ProcessPatterns: []string{`main\.py`, `^/srv/[a-z]+/api\b`}}, logger.NewStandardLogger())
+	require.NoError(t, err)
+	// a process running a replaced executable is skipped
+	require.Equal(t, &PathsSpec{Version: 1, Artifacts: map[string]ArtifactPathSpec{
+		"/usr/bin/python3.12": {Path: "/usr/bin/python3.12"},
+		"/srv/api/api":        {Path: "/srv/api/api"},
+	}}, ps)
+
+	ps, err = CreateRunningPathsSpec(&RunningSpec{ProcessPatterns: []string{`main\.py`}, WorkingDirectory: true}, logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Equal(t, &PathsSpec{Version: 1, Artifacts: map[string]ArtifactPathSpec{
+		"/srv/app": {Path: "/srv/app"},
+	}}, ps)
+
+	_, err = CreateRunningPathsSpec(&RunningSpec{ProcessPatterns: []string{`nginx`}}, logger.NewStandardLogger())
+	require.EqualError(t, err, "no running systemd services or processes found for [nginx]")
+
+	_, err = CreateRunningPathsSpec(&RunningSpec{ProcessPatterns: []string{`(`}}, logger.NewStandardLogger())
+	require.ErrorContains(t, err, "invalid process pattern (")
+}
+
+func TestCreateRunningPathsSpecFingerprintsRunningExecutables(t *testing.T) {
+	apiDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(apiDir, "bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(apiDir, "bin", "api"), []byte("api binary"), 0o755))
+	fakeSystemctl(t, apiDir, "0")
+	fakeProc(t, nil)
+
+	ps, err := CreateRunningPathsSpec(&RunningSpec{SystemdUnits: []string{"api.service"}}, logger.NewStandardLogger())
+	require.NoError(t, err)
+
+	artifacts, err := CreatePathsArtifactsData(ps, logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Len(t, artifacts, 1)
+	// sha256 of "api binary"
+	require.Equal(t, map[string]string{"api.service": "01396ea76a78329dce10cd71f60869e543be723194b0920ef6488ac84d97731c"}, artifacts[0].Digests)
+}
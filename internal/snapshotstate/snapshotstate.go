@@ -0,0 +1,152 @@
+package snapshotstate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Store is an on-disk record of the last snapshot reported to each
+// environment, so that a snapshot which has not changed since can be
+// told apart from one which has.
+type Store struct {
+	Dir string
+	// Now defaults to time.Now.
+	Now func() time.Time
+}
+
+// State is what is recorded about the last snapshot reported to an
+// environment.
+type State struct {
+	// Environment is the URL the snapshot was reported to, which names the
+	// host, the org, the environment and its type.
+	Environment string `json:"environment"`
+	// Digest is the SHA-256 digest of the reported payload.
+	Digest string `json:"digest"`
+	// ReportedAt is when the snapshot was last sent, as a Unix timestamp.
+	ReportedAt int64 `json:"reported_at"`
+}
+
+// DefaultDir returns kosli/snapshots in the user cache directory.
+func DefaultDir() (string, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userCacheDir, "kosli", "snapshots"), nil
+}
+
+// Digest returns the SHA-256 digest of the JSON encoding of a report payload.
+// The artifacts of a snapshot are a set, listed in whatever order they were
+// found in, so arrays are sorted first for reordered artifacts to have the
+// same digest.
+func Digest(payload any) (string, error) {
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	var decoded any
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		return "", err
+	}
+	content, err = json.Marshal(sortArrays(decoded))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// sortArrays sorts the arrays in a decoded JSON value by the JSON encoding of
+// their elements. Objects are encoded with their keys in order already.
+func sortArrays(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, element := range v {
+			v[key] = sortArrays(element)
+		}
+	case []any:
+		encoded := make([]string, len(v))
+		for i, element := range v {
+			// a decoded JSON value always encodes
+			content, _ := json.Marshal(sortArrays(element))
+			encoded[i] = string(content)
+		}
+		slices.Sort(encoded)
+		sorted := make([]json.RawMessage, len(encoded))
+		for i, element := range encoded {
+			sorted[i] = json.RawMessage(element)
+		}
+		return sorted
+	}
+	return value
+}
+
+func (s *Store) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+func (s *Store) path(environment string) string {
+	sum := sha256.Sum256([]byte(environment))
+	return filepath.Join(s.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get returns the state of the last snapshot reported to environment, if any.
+func (s *Store) Get(environment string) (*State, bool) {
+	content, err := os.ReadFile(s.path(environment))
+	if err != nil {
+		return nil, false
+	}
+	var state State
+	if err := json.Unmarshal(content, &state); err != nil || state.Environment != environment {
+		return nil, false
+	}
+	return &state, true
+}
+
+// Unchanged returns the state of the last snapshot reported to environment
+// when it has the same digest and was reported less than heartbeat ago.
+// A heartbeat of 0 never expires.
+func (s *Store) Unchanged(environment, digest string, heartbeat time.Duration) (*State, bool) {
+	state, ok := s.Get(environment)
+	if !ok || state.Digest != digest {
+		return nil, false
+	}
+	if heartbeat > 0 && s.now().Sub(time.Unix(state.ReportedAt, 0)) >= heartbeat {
+		return nil, false
+	}
+	return state, true
+}
+
+// Put records that a snapshot with digest was reported to environment now.
+func (s *Store) Put(environment, digest string) error {
+	content, err := json.Marshal(State{Environment: environment, Digest: digest, ReportedAt: s.now().Unix()})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return err
+	}
+	// write to a temporary file first, so that snapshots of the same
+	// environment run concurrently never read a partly written state
+	tmp, err := os.CreateTemp(s.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(environment))
+}
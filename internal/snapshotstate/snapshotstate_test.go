@@ -0,0 +1,79 @@
+package snapshotstate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEnvironment = "https://app.kosli.com/api/v2/environments/acme/prod/report/K8S"
+
+func TestDigest(t *testing.T) {
+	type payload struct {
+		Artifacts []map[string]string `json:"artifacts"`
+	}
+	first, err := Digest(payload{Artifacts: []map[string]string{{"a": "1", "b": "2"}}})
+	require.NoError(t, err)
+	// map keys are encoded in order, so equal maps have the same digest
+	second, err := Digest(payload{Artifacts: []map[string]string{{"b": "2", "a": "1"}}})
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	// artifacts found in another order are the same snapshot
+	reordered, err := Digest(payload{Artifacts: []map[string]string{{"c": "3"}, {"a": "1", "b": "2"}}})
+	require.NoError(t, err)
+	unordered, err := Digest(payload{Artifacts: []map[string]string{{"a": "1", "b": "2"}, {"c": "3"}}})
+	require.NoError(t, err)
+	assert.Equal(t, reordered, unordered)
+	assert.NotEqual(t, first, reordered)
+
+	changed, err := Digest(payload{Artifacts: []map[string]string{{"a": "1", "b": "3"}}})
+	require.NoError(t, err)
+	assert.NotEqual(t, first, changed)
+	assert.Len(t, first, 64)
+}
+
+func TestStoreUnchangedUntilTheHeartbeat(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := &Store{Dir: filepath.Join(t.TempDir(), "snapshots"), Now: func() time.Time { return now }}
+
+	_, unchanged := store.Unchanged(testEnvironment, "d1", time.Hour)
+	assert.False(t, unchanged, "nothing was reported yet")
+
+	require.NoError(t, store.Put(testEnvironment, "d1"))
+
+	now = now.Add(59 * time.Minute)
+	state, unchanged := store.Unchanged(testEnvironment, "d1", time.Hour)
+	assert.True(t, unchanged)
+	assert.Equal(t, &State{Environment: testEnvironment, Digest: "d1", ReportedAt: now.Add(-59 * time.Minute).Unix()}, state)
+
+	_, unchanged = store.Unchanged(testEnvironment, "d2", time.Hour)
+	assert.False(t, unchanged, "the snapshot changed")
+
+	_, unchanged = store.Unchanged(testEnvironment+"-other", "d1", time.Hour)
+	assert.False(t, unchanged, "the state is per environment")
+
+	now = now.Add(time.Minute)
+	_, unchanged = store.Unchanged(testEnvironment, "d1", time.Hour)
+	assert.False(t, unchanged, "a heartbeat is due")
+
+	_, unchanged = store.Unchanged(testEnvironment, "d1", 0)
+	assert.True(t, unchanged, "a heartbeat of 0 never expires")
+}
+
+func TestStoreIgnoresUnreadableState(t *testing.T) {
+	store := &Store{Dir: t.TempDir()}
+	require.NoError(t, os.WriteFile(store.path(testEnvironment), []byte("not json"), 0600))
+
+	_, ok := store.Get(testEnvironment)
+	assert.False(t, ok)
+
+	require.NoError(t, store.Put(testEnvironment, "d1"))
+	state, ok := store.Get(testEnvironment)
+	require.True(t, ok)
+	assert.Equal(t, "d1", state.Digest)
+}
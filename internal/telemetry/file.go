@@ -0,0 +1,95 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// fileClient is an otlptrace.Client appending each batch of spans to a file
+// as a line of OTLP JSON, the format read by the otlpjsonfile receiver of
+// the OpenTelemetry Collector.
+type fileClient struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (c *fileClient) Start(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open the OTLP traces file: %v", err)
+	}
+	c.file = file
+	return nil
+}
+
+func (c *fileClient) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.file.Close()
+}
+
+func (c *fileClient) UploadTraces(ctx context.Context, protoSpans []*tracepb.ResourceSpans) error {
+	line, err := marshalOTLPJSON(&collectorpb.ExportTraceServiceRequest{ResourceSpans: protoSpans})
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = c.file.Write(append(line, '\n'))
+	return err
+}
+
+// marshalOTLPJSON encodes request as OTLP JSON on a single line. OTLP JSON
+// differs from the protobuf JSON mapping: enums are numbers, and trace and
+// span IDs are hex strings instead of base64 ones.
+func marshalOTLPJSON(request *collectorpb.ExportTraceServiceRequest) ([]byte, error) {
+	content, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	hexIDs(v)
+	return json.Marshal(v)
+}
+
+func hexIDs(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			switch key {
+			case "traceId", "spanId", "parentSpanId":
+				if id, ok := value.(string); ok {
+					if decoded, err := base64.StdEncoding.DecodeString(id); err == nil {
+						v[key] = hex.EncodeToString(decoded)
+					}
+				}
+			default:
+				hexIDs(value)
+			}
+		}
+	case []any:
+		for _, value := range v {
+			hexIDs(value)
+		}
+	}
+}
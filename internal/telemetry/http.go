@@ -0,0 +1,70 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartHTTP starts a client span for req and returns req with the span in
+// its context and the trace context in its headers. The span has the method
+// and the URL of req, without its query and credentials.
+func StartHTTP(req *http.Request, attrs ...attribute.KeyValue) (*http.Request, trace.Span) {
+	if !Enabled() {
+		return req, trace.SpanFromContext(context.Background())
+	}
+	u := url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: req.URL.Path}
+	attrs = append(attrs,
+		attribute.String("http.request.method", req.Method),
+		attribute.String("url.full", u.String()),
+		attribute.String("server.address", req.URL.Hostname()),
+	)
+	ctx, span := start(req.Context(), "HTTP "+req.Method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	req = req.WithContext(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return req, span
+}
+
+// EndHTTP ends a span started by StartHTTP, with the status code of resp.
+// The span of a request answered with a 4xx or 5xx status has an error status.
+func EndHTTP(span trace.Span, resp *http.Response, err error) {
+	if resp != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if err == nil && resp.StatusCode >= http.StatusBadRequest {
+			span.SetStatus(codes.Error, resp.Status)
+		}
+	}
+	End(span, err)
+}
+
+// NewTransport returns an http.RoundTripper tracing the requests it sends
+// with base, or base itself when tracing is off. A nil base is
+// http.DefaultTransport.
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if !Enabled() {
+		return base
+	}
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the headers of the request given to a RoundTripper must not be changed
+	req = req.Clone(req.Context())
+	req, span := StartHTTP(req)
+	resp, err := t.base.RoundTrip(req)
+	EndHTTP(span, resp, err)
+	return resp, err
+}
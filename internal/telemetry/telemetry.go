@@ -0,0 +1,185 @@
+// Package telemetry traces the CLI with OpenTelemetry. Tracing is off unless
+// an OTLP endpoint is set with the standard OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variables. When it is off,
+// starting a span only returns a no-op span.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	EndpointEnvVar       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	TracesEndpointEnvVar = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	ProtocolEnvVar       = "OTEL_EXPORTER_OTLP_PROTOCOL"
+	TracesProtocolEnvVar = "OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"
+
+	serviceName = "kosli-cli"
+	// shutdownTimeout bounds how long exiting waits for the spans to be exported.
+	shutdownTimeout = 5 * time.Second
+)
+
+var (
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+	// commandCtx holds the span of the command being run, the parent of the
+	// spans started without one.
+	commandCtx = context.Background()
+)
+
+// Enabled reports whether Setup turned tracing on.
+func Enabled() bool {
+	return provider != nil
+}
+
+// Setup turns tracing on when an OTLP endpoint is set in the environment and
+// OTEL_SDK_DISABLED is not true. The spans are exported with the protocol
+// set by OTEL_EXPORTER_OTLP_TRACES_PROTOCOL or OTEL_EXPORTER_OTLP_PROTOCOL,
+// http/protobuf by default, or appended as OTLP JSON lines to a local file
+// when the endpoint is a file:// URL. The other OTEL_* variables, such as
+// OTEL_EXPORTER_OTLP_HEADERS and OTEL_RESOURCE_ATTRIBUTES, are honoured too.
+func Setup(version string) error {
+	if Enabled() {
+		return nil
+	}
+	if disabled, _ := strconv.ParseBool(os.Getenv("OTEL_SDK_DISABLED")); disabled {
+		return nil
+	}
+	endpoint := firstEnv(TracesEndpointEnvVar, EndpointEnvVar)
+	if endpoint == "" {
+		return nil
+	}
+
+	ctx := context.Background()
+	exporter, err := newExporter(ctx, endpoint, firstEnv(TracesProtocolEnvVar, ProtocolEnvVar))
+	if err != nil {
+		return err
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			attribute.String("service.name", serviceName),
+			attribute.String("service.version", version),
+		),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithOS(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create the OpenTelemetry resource: %v", err)
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	tracer = provider.Tracer("github.com/kosli-dev/cli")
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return nil
+}
+
+func newExporter(ctx context.Context, endpoint, protocol string) (*otlptrace.Exporter, error) {
+	if strings.HasPrefix(endpoint, "file:") {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Path == "" {
+			return nil, fmt.Errorf("invalid OTLP file endpoint %s, it must look like file:///path/to/traces.jsonl", endpoint)
+		}
+		return otlptrace.New(ctx, &fileClient{path: u.Path})
+	}
+	switch protocol {
+	case "", "http/protobuf":
+		return otlptracehttp.New(ctx)
+	case "grpc":
+		return otlptracegrpc.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %s, it must be one of: grpc, http/protobuf", protocol)
+	}
+}
+
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Shutdown exports the spans not exported yet and turns tracing off. It must
+// be called before the process exits.
+func Shutdown() error {
+	if !Enabled() {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := provider.Shutdown(ctx)
+	provider, tracer, commandCtx = nil, nil, context.Background()
+	return err
+}
+
+// StartCommand starts the span of the command run by the process. The trace
+// continues the one given by the TRACEPARENT environment variable, so that
+// CI systems propagating their trace context can link the CLI spans to
+// their jobs.
+func StartCommand(name string) trace.Span {
+	if !Enabled() {
+		return trace.SpanFromContext(context.Background())
+	}
+	parent := otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier{
+		"traceparent": os.Getenv("TRACEPARENT"),
+		"tracestate":  os.Getenv("TRACESTATE"),
+	})
+	var span trace.Span
+	commandCtx, span = tracer.Start(parent, name)
+	return span
+}
+
+// CommandSpan returns the span started by StartCommand, or a no-op span.
+func CommandSpan() trace.Span {
+	return trace.SpanFromContext(commandCtx)
+}
+
+// Start starts a span named name. Its parent is the span in ctx, or the
+// command span when ctx has none. The returned context holds the new span
+// and the values of ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !Enabled() {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	return start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+func start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = trace.ContextWithSpan(ctx, CommandSpan())
+	}
+	return tracer.Start(ctx, name, opts...)
+}
+
+// End ends span, recording err as its error when it is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,143 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type exportedSpan struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Kind         int    `json:"kind"`
+	Status       struct {
+		Code int `json:"code"`
+	} `json:"status"`
+}
+
+// readSpans returns the spans of the OTLP JSON lines of path by name.
+func readSpans(t *testing.T, path string) map[string]exportedSpan {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	spans := map[string]exportedSpan{}
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		var request struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []exportedSpan `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(line), &request))
+		for _, resourceSpans := range request.ResourceSpans {
+			for _, scopeSpans := range resourceSpans.ScopeSpans {
+				for _, span := range scopeSpans.Spans {
+					spans[span.Name] = span
+				}
+			}
+		}
+	}
+	return spans
+}
+
+func TestTracingIsOffWithoutEndpoint(t *testing.T) {
+	t.Setenv(EndpointEnvVar, "")
+	t.Setenv(TracesEndpointEnvVar, "")
+	require.NoError(t, Setup("test"))
+	assert.False(t, Enabled())
+
+	ctx := context.Background()
+	spanCtx, span := Start(ctx, "digest file", attribute.String("kosli.artifact.name", "a.txt"))
+	assert.Equal(t, ctx, spanCtx)
+	assert.False(t, span.IsRecording())
+	assert.False(t, StartCommand("kosli").IsRecording())
+
+	base := http.DefaultTransport
+	assert.Equal(t, base, NewTransport(base))
+	require.NoError(t, Shutdown())
+}
+
+func TestTracingIsOffWhenSDKIsDisabled(t *testing.T) {
+	t.Setenv(EndpointEnvVar, "file://"+filepath.Join(t.TempDir(), "traces.jsonl"))
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+	require.NoError(t, Setup("test"))
+	assert.False(t, Enabled())
+}
+
+func TestSetupFailsForUnsupportedProtocol(t *testing.T) {
+	t.Setenv(EndpointEnvVar, "http://localhost:4318")
+	t.Setenv(TracesProtocolEnvVar, "http/json")
+	err := Setup("test")
+	require.EqualError(t, err, "unsupported OTLP protocol http/json, it must be one of: grpc, http/protobuf")
+	assert.False(t, Enabled())
+}
+
+func TestSpansAreWrittenToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces", "traces.jsonl")
+	t.Setenv(EndpointEnvVar, "http://localhost:4318")
+	t.Setenv(TracesEndpointEnvVar, "file://"+path)
+	t.Setenv("TRACEPARENT", "")
+	require.NoError(t, Setup("test"))
+	require.True(t, Enabled())
+
+	traceparent := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	command := StartCommand("kosli")
+	CommandSpan().SetName("kosli attest pullrequest github")
+	_, digest := Start(context.Background(), "digest dir")
+	End(digest, errors.New("no such directory"))
+	resp, err := (&http.Client{Transport: NewTransport(nil)}).Get(server.URL + "/repos?page=2")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	End(command, nil)
+	require.NoError(t, Shutdown())
+	assert.False(t, Enabled())
+
+	spans := readSpans(t, path)
+	require.Len(t, spans, 3)
+	root := spans["kosli attest pullrequest github"]
+	assert.Len(t, root.TraceID, 32)
+	assert.Len(t, root.SpanID, 16)
+	assert.Empty(t, root.ParentSpanID)
+
+	assert.Equal(t, root.TraceID, spans["digest dir"].TraceID)
+	assert.Equal(t, root.SpanID, spans["digest dir"].ParentSpanID)
+	assert.Equal(t, 2, spans["digest dir"].Status.Code)
+
+	request := spans["HTTP GET"]
+	assert.Equal(t, root.SpanID, request.ParentSpanID)
+	assert.Equal(t, 3, request.Kind)
+	assert.Equal(t, 2, request.Status.Code)
+	assert.Equal(t, "00-"+request.TraceID+"-"+request.SpanID+"-01", traceparent)
+}
+
+func TestCommandSpanContinuesTraceparent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	t.Setenv(TracesEndpointEnvVar, "file://"+path)
+	t.Setenv("TRACEPARENT", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	require.NoError(t, Setup("test"))
+
+	End(StartCommand("kosli"), nil)
+	require.NoError(t, Shutdown())
+
+	span := readSpans(t, path)["kosli"]
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.TraceID)
+	assert.Equal(t, "b7ad6b7169203331", span.ParentSpanID)
+}
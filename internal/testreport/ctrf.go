@@ -0,0 +1,110 @@
+package testreport
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	junit "github.com/joshdk/go-junit"
+)
+
+// ctrfReport is a Common Test Report Format (https://ctrf.io) document.
+type ctrfReport struct {
+	Results struct {
+		Tool struct {
+			Name string `json:"name"`
+		} `json:"tool"`
+		Summary struct {
+			Start int64 `json:"start"`
+		} `json:"summary"`
+		Tests []ctrfTest `json:"tests"`
+	} `json:"results"`
+}
+
+type ctrfTest struct {
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	Duration float64  `json:"duration"`
+	Message  string   `json:"message"`
+	Trace    string   `json:"trace"`
+	Suite    string   `json:"suite"`
+	FilePath string   `json:"filePath"`
+	Retries  int      `json:"retries"`
+	Flaky    bool     `json:"flaky"`
+	Stdout   []string `json:"stdout"`
+}
+
+// parseCTRF reads a CTRF JSON report into one suite per test suite, falling
+// back to the test file and then to the tool name for tests without one.
+// CTRF records the retries of a test on the test itself, so they are returned
+// as reruns rather than test cases.
+func parseCTRF(data []byte) (*Report, error) {
+	var doc ctrfReport
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if !isCTRF(data) {
+		return nil, errors.New(`not a CTRF report: expected a "results" object with "tool" and "tests"`)
+	}
+
+	var timestamp string
+	if doc.Results.Summary.Start > 0 {
+		timestamp = formatTimestamp(time.UnixMilli(doc.Results.Summary.Start))
+	}
+
+	builder := newSuiteBuilder()
+	reruns := map[string]Reruns{}
+	for _, t := range doc.Results.Tests {
+		suite := t.Suite
+		if suite == "" {
+			suite = t.FilePath
+		}
+		if suite == "" {
+			suite = doc.Results.Tool.Name
+		}
+		test := junit.Test{
+			Name:      t.Name,
+			Classname: suite,
+			Duration:  time.Duration(t.Duration * float64(time.Millisecond)),
+			Status:    ctrfStatus(t.Status),
+		}
+		switch test.Status {
+		case junit.StatusFailed, junit.StatusError:
+			test.Message = t.Message
+			test.Error = failure(t.Message, t.Trace)
+		case junit.StatusSkipped:
+			test.Message = t.Message
+		}
+		builder.add(suite, test)
+
+		if t.Retries > 0 {
+			r := Reruns{}
+			if test.Status == junit.StatusPassed && t.Flaky {
+				for i := 0; i < t.Retries; i++ {
+					r.FlakyMessages = append(r.FlakyMessages, t.Message)
+				}
+			} else if test.Status != junit.StatusPassed {
+				r.RerunCount = t.Retries
+			}
+			if r.RerunCount > 0 || len(r.FlakyMessages) > 0 {
+				reruns[TestKey(suite, t.Name)] = r
+			}
+		}
+	}
+	return &Report{Suites: builder.suites(timestamp), Reruns: reruns}, nil
+}
+
+// ctrfStatus maps a CTRF status to a test status. "other" is used for any
+// outcome a tool could not place, which cannot be taken as a pass.
+func ctrfStatus(status string) junit.Status {
+	switch status {
+	case "passed":
+		return junit.StatusPassed
+	case "failed":
+		return junit.StatusFailed
+	case "skipped", "pending":
+		return junit.StatusSkipped
+	default:
+		return junit.StatusError
+	}
+}
@@ -0,0 +1,160 @@
+package testreport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	junit "github.com/joshdk/go-junit"
+)
+
+// goTestEvent is one line of `go test -json` (test2json) output.
+type goTestEvent struct {
+	Time    time.Time `json:"Time"`
+	Action  string    `json:"Action"`
+	Package string    `json:"Package"`
+	Test    string    `json:"Test"`
+	Elapsed float64   `json:"Elapsed"`
+	Output  string    `json:"Output"`
+}
+
+type goTestRun struct {
+	test   junit.Test
+	output strings.Builder
+	// finished is set by the pass, fail or skip event of the run. A run
+	// without one was cut short, by a timeout or a panic of another test.
+	finished bool
+}
+
+type goTestPackage struct {
+	start  time.Time
+	failed bool
+	output strings.Builder
+	runs   []*goTestRun
+	// running maps a test name to its latest run, which is where its events
+	// go. A test run again (go test -count, gotestsum --rerun-fails) starts a
+	// new run rather than overwriting the previous one.
+	running map[string]*goTestRun
+}
+
+// parseGoTestJSON reads the output of `go test -json` into one suite per
+// package. Lines that are not test2json events, such as build output mixed
+// into the same file, are ignored. A package that fails without any of its
+// tests failing (a build failure, a panic in TestMain) is reported as a single
+// errored test so that it cannot go unnoticed, and so is a test which never
+// finished (a timeout).
+func parseGoTestJSON(data []byte) (*Report, error) {
+	var order []string
+	packages := map[string]*goTestPackage{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var event goTestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Action == "" {
+			continue
+		}
+		pkg, ok := packages[event.Package]
+		if !ok {
+			pkg = &goTestPackage{start: event.Time, running: map[string]*goTestRun{}}
+			packages[event.Package] = pkg
+			order = append(order, event.Package)
+		}
+
+		if event.Test == "" {
+			switch event.Action {
+			case "output":
+				pkg.output.WriteString(event.Output)
+			case "fail":
+				pkg.failed = true
+			}
+			continue
+		}
+
+		run := pkg.running[event.Test]
+		if event.Action == "run" || run == nil {
+			run = &goTestRun{test: junit.Test{Name: event.Test, Classname: event.Package, Status: junit.StatusPassed}}
+			pkg.running[event.Test] = run
+			pkg.runs = append(pkg.runs, run)
+		}
+		switch event.Action {
+		case "output":
+			run.output.WriteString(event.Output)
+		case "pass":
+			run.finished = true
+			run.test.Duration = elapsed(event.Elapsed)
+		case "skip":
+			run.finished = true
+			run.test.Status = junit.StatusSkipped
+			run.test.Duration = elapsed(event.Elapsed)
+		case "fail":
+			run.finished = true
+			run.test.Status = junit.StatusFailed
+			run.test.Duration = elapsed(event.Elapsed)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read go test output: %w", err)
+	}
+
+	report := &Report{}
+	for _, name := range order {
+		pkg := packages[name]
+		tests := []junit.Test{}
+		testFailed := false
+		for _, run := range pkg.runs {
+			if !run.finished {
+				run.test.Status = junit.StatusError
+				run.test.Message = "test did not finish"
+			}
+			switch run.test.Status {
+			case junit.StatusFailed:
+				testFailed = true
+				run.test.Error = failure("", run.output.String())
+			case junit.StatusError:
+				testFailed = true
+				run.test.Error = failure(run.test.Message, run.output.String())
+			case junit.StatusSkipped:
+				run.test.Message = skipReason(run.output.String())
+			}
+			tests = append(tests, run.test)
+		}
+		if pkg.failed && !testFailed {
+			tests = append(tests, junit.Test{
+				Name:      name,
+				Classname: name,
+				Status:    junit.StatusError,
+				Message:   "package failed",
+				Error:     failure("package failed", pkg.output.String()),
+			})
+		}
+		if len(tests) == 0 {
+			continue
+		}
+		report.Suites = append(report.Suites, newSuite(name, tests, formatTimestamp(pkg.start)))
+	}
+	return report, nil
+}
+
+func elapsed(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// skipReason returns the message given to t.Skip, which is the output line
+// that follows the "--- SKIP" line of the test.
+func skipReason(output string) string {
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		if strings.Contains(line, "--- SKIP") && i+1 < len(lines) {
+			reason := strings.TrimSpace(lines[i+1])
+			if _, after, found := strings.Cut(reason, ": "); found && strings.Contains(reason, ".go:") {
+				reason = after
+			}
+			return reason
+		}
+	}
+	return ""
+}
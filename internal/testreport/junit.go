@@ -0,0 +1,125 @@
+package testreport
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	junit "github.com/joshdk/go-junit"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// xmlEncodingDecl matches the encoding declaration of an XML prolog.
+var xmlEncodingDecl = regexp.MustCompile(`^(\s*<\?xml[^>]*?encoding\s*=\s*)(["'])([^"']+)(["'])`)
+
+// UnsupportedEncodingError is returned for an XML file declaring a character
+// encoding that cannot be converted to UTF-8.
+type UnsupportedEncodingError struct {
+	Encoding string
+}
+
+func (e *UnsupportedEncodingError) Error() string {
+	return fmt.Sprintf("unsupported character encoding %q", e.Encoding)
+}
+
+// toUTF8 converts an XML document to UTF-8 according to the encoding in its
+// prolog (e.g. ISO-8859-1 as written by older Ant and Gradle versions), and
+// rewrites the prolog to match. encoding/xml refuses any declared encoding
+// other than UTF-8 unless it is given a charset reader, which go-junit does
+// not do.
+func toUTF8(data []byte) ([]byte, error) {
+	match := xmlEncodingDecl.FindSubmatchIndex(data)
+	if match == nil {
+		return data, nil
+	}
+	label := string(data[match[6]:match[7]])
+	switch strings.ToLower(label) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return data, nil
+	}
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return nil, &UnsupportedEncodingError{Encoding: label}
+	}
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s content: %w", label, err)
+	}
+	// the prolog is plain ASCII in every encoding htmlindex knows about, so the
+	// offsets found in the original data still hold in the decoded data
+	var out bytes.Buffer
+	out.Write(decoded[:match[6]])
+	out.WriteString("UTF-8")
+	out.Write(decoded[match[7]:])
+	return out.Bytes(), nil
+}
+
+func parseJUnit(data []byte) (*Report, error) {
+	data, err := toUTF8(data)
+	if err != nil {
+		return nil, err
+	}
+	suites, err := junit.Ingest(data)
+	if err != nil {
+		return nil, err
+	}
+	reruns, err := junitReruns(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Report{Suites: suites, Reruns: reruns}, nil
+}
+
+type junitRerunElement struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitRerunTestCase struct {
+	Name         string              `xml:"name,attr"`
+	Classname    string              `xml:"classname,attr"`
+	FlakyFailure []junitRerunElement `xml:"flakyFailure"`
+	FlakyError   []junitRerunElement `xml:"flakyError"`
+	RerunFailure []junitRerunElement `xml:"rerunFailure"`
+	RerunError   []junitRerunElement `xml:"rerunError"`
+}
+
+// junitReruns scans a JUnit XML document for the retries that go-junit drops.
+// Maven surefire (with rerunFailingTestsCount) writes them as child elements
+// of the final <testcase>: <flakyFailure>/<flakyError> when the test passed
+// on a retry, <rerunFailure>/<rerunError> when every retry failed too.
+// Test cases without retries are left out.
+func junitReruns(data []byte) (map[string]Reruns, error) {
+	reruns := map[string]Reruns{}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return reruns, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read test reruns: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "testcase" {
+			continue
+		}
+		var testCase junitRerunTestCase
+		if err := decoder.DecodeElement(&testCase, &start); err != nil {
+			return nil, fmt.Errorf("failed to read test reruns: %w", err)
+		}
+		r := Reruns{RerunCount: len(testCase.RerunFailure) + len(testCase.RerunError)}
+		for _, e := range testCase.FlakyFailure {
+			r.FlakyMessages = append(r.FlakyMessages, e.Message)
+		}
+		for _, e := range testCase.FlakyError {
+			r.FlakyMessages = append(r.FlakyMessages, e.Message)
+		}
+		if r.RerunCount > 0 || len(r.FlakyMessages) > 0 {
+			reruns[TestKey(testCase.Classname, testCase.Name)] = r
+		}
+	}
+}
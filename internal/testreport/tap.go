@@ -0,0 +1,129 @@
+package testreport
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	junit "github.com/joshdk/go-junit"
+)
+
+// tapTestLine matches a TAP test point, e.g. "not ok 3 - handles empty input # TODO".
+var tapTestLine = regexp.MustCompile(`^(not ok|ok)\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(.*))?$`)
+
+// tapPlan matches a TAP plan, e.g. "1..4" or "1..0 # skipped on windows".
+var tapPlan = regexp.MustCompile(`^1\.\.\d+\b`)
+
+// parseTAP reads a TAP (Test Anything Protocol) stream, versions 12 to 14.
+// Only top-level test points are reported: indented subtests of TAP 14 are
+// summarized by the test point that closes them. The YAML diagnostics block
+// that may follow a test point is scanned for a message and a duration, as
+// written by node-tap and most other producers.
+func parseTAP(name string, data []byte) (*Report, error) {
+	suiteName := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	tests := []junit.Test{}
+	inYAML := false
+	bailedOut, bailOutReason := false, ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if inYAML {
+			if trimmed == "..." {
+				inYAML = false
+				continue
+			}
+			if len(tests) > 0 {
+				applyTAPDiagnostic(&tests[len(tests)-1], trimmed)
+			}
+			continue
+		}
+		if trimmed == "---" && len(tests) > 0 && line != trimmed {
+			inYAML = true
+			continue
+		}
+		if line != trimmed {
+			// indented lines belong to subtests
+			continue
+		}
+		if strings.HasPrefix(trimmed, "Bail out!") {
+			bailedOut = true
+			bailOutReason = strings.TrimSpace(strings.TrimPrefix(trimmed, "Bail out!"))
+			break
+		}
+
+		m := tapTestLine.FindStringSubmatch(trimmed)
+		if m == nil {
+			continue
+		}
+		test := junit.Test{
+			Name:      m[3],
+			Classname: suiteName,
+			Status:    junit.StatusPassed,
+		}
+		if test.Name == "" {
+			test.Name = "test " + m[2]
+		}
+		directive := strings.TrimSpace(m[4])
+		upper := strings.ToUpper(directive)
+		switch {
+		case strings.HasPrefix(upper, "SKIP"):
+			test.Status = junit.StatusSkipped
+			test.Message = strings.TrimSpace(directive[len("SKIP"):])
+		case strings.HasPrefix(upper, "TODO"):
+			// TODO tests are expected to fail and never count as failures
+			test.Status = junit.StatusSkipped
+			test.Message = strings.TrimSpace(directive[len("TODO"):])
+		case m[1] == "not ok":
+			test.Status = junit.StatusFailed
+			test.Error = failure("", "")
+		}
+		tests = append(tests, test)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read TAP stream: %w", err)
+	}
+	if bailedOut {
+		// a bailed out run did not finish, which must not pass for success
+		tests = append(tests, junit.Test{
+			Name:      "Bail out!",
+			Classname: suiteName,
+			Status:    junit.StatusError,
+			Message:   bailOutReason,
+			Error:     failure(bailOutReason, ""),
+		})
+	}
+	if len(tests) == 0 {
+		return &Report{}, nil
+	}
+	return &Report{Suites: []junit.Suite{newSuite(suiteName, tests, "")}}, nil
+}
+
+// applyTAPDiagnostic picks the message and duration out of one line of a
+// YAML diagnostics block.
+func applyTAPDiagnostic(test *junit.Test, line string) {
+	key, value, found := strings.Cut(line, ":")
+	if !found {
+		return
+	}
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	switch strings.TrimSpace(key) {
+	case "message":
+		if test.Status == junit.StatusFailed && test.Message == "" {
+			test.Message = value
+			test.Error = failure(value, "")
+		}
+	case "duration_ms":
+		if ms, err := strconv.ParseFloat(value, 64); err == nil {
+			test.Duration = time.Duration(ms * float64(time.Millisecond))
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package testreport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	junit "github.com/joshdk/go-junit"
+)
+
+// Format is a test report file format that can be turned into test suites.
+type Format string
+
+const (
+	FormatAuto   Format = "auto"
+	FormatJUnit  Format = "junit"
+	FormatTAP    Format = "tap"
+	FormatGoTest Format = "go-test-json"
+	FormatTRX    Format = "trx"
+	FormatCTRF   Format = "ctrf"
+)
+
+// Formats lists the accepted values for a report format, auto-detection first.
+var Formats = []Format{FormatAuto, FormatJUnit, FormatTAP, FormatGoTest, FormatTRX, FormatCTRF}
+
+// extensions lists, per format, the file extensions read from a results
+// directory. Everything else in the directory is ignored.
+var extensions = map[Format][]string{
+	FormatJUnit:  {".xml"},
+	FormatTAP:    {".tap", ".txt"},
+	FormatGoTest: {".json", ".jsonl", ".ndjson"},
+	FormatTRX:    {".trx"},
+	FormatCTRF:   {".json"},
+	FormatAuto:   {".xml", ".tap", ".txt", ".json", ".jsonl", ".ndjson", ".trx"},
+}
+
+// ParseFormat validates a format name.
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("%q is not a supported test report format. Valid formats are: [%s]", name, strings.Join(names, ", "))
+}
+
+// Accepts reports whether a file with this name is read for the format.
+func (f Format) Accepts(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, e := range extensions[f] {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Reruns records the retries of one test case which are not visible as test
+// cases of their own: failed attempts before the test passed (FlakyMessages,
+// one per attempt) and failed attempts of a test that never passed.
+type Reruns struct {
+	FlakyMessages []string
+	RerunCount    int
+}
+
+// Report is the content of one test report file.
+// Reruns is keyed by TestKey.
+type Report struct {
+	Suites []junit.Suite
+	Reruns map[string]Reruns
+}
+
+// TestKey identifies a test case across its attempts.
+func TestKey(classname, name string) string {
+	return classname + "\x00" + name
+}
+
+// Detect returns the format of a report file from its name and content, or an
+// empty Format when the file is not a test report this package can read.
+func Detect(filename string, data []byte) Format {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".trx":
+		return FormatTRX
+	case ".tap":
+		return FormatTAP
+	case ".txt":
+		if isTAP(data) {
+			return FormatTAP
+		}
+	case ".xml":
+		if bytes.Contains(data, []byte("<TestRun")) && bytes.Contains(data, []byte(trxNamespace)) {
+			return FormatTRX
+		}
+		return FormatJUnit
+	case ".json", ".jsonl", ".ndjson":
+		if isCTRF(data) {
+			return FormatCTRF
+		}
+		if isGoTestJSON(data) {
+			return FormatGoTest
+		}
+	}
+	return ""
+}
+
+// Parse reads a report file of the given format. name is used for suites
+// when the format does not name them itself (TAP).
+func Parse(format Format, name string, data []byte) (*Report, error) {
+	switch format {
+	case FormatJUnit:
+		return parseJUnit(data)
+	case FormatTAP:
+		return parseTAP(name, data)
+	case FormatGoTest:
+		return parseGoTestJSON(data)
+	case FormatTRX:
+		return parseTRX(data)
+	case FormatCTRF:
+		return parseCTRF(data)
+	}
+	return nil, fmt.Errorf("cannot parse test report format %q", format)
+}
+
+func isCTRF(data []byte) bool {
+	var probe struct {
+		Results *struct {
+			Tool  json.RawMessage `json:"tool"`
+			Tests json.RawMessage `json:"tests"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return probe.Results != nil && probe.Results.Tool != nil && probe.Results.Tests != nil
+}
+
+// isTAP reports whether data starts like a TAP stream: with its version, its
+// plan or a test point.
+func isTAP(data []byte) bool {
+	line, _, _ := bytes.Cut(bytes.TrimSpace(data), []byte("\n"))
+	line = bytes.TrimSpace(line)
+	return bytes.HasPrefix(line, []byte("TAP version ")) || tapPlan.Match(line) || tapTestLine.Match(line)
+}
+
+func isGoTestJSON(data []byte) bool {
+	line, _, _ := bytes.Cut(bytes.TrimSpace(data), []byte("\n"))
+	var probe goTestEvent
+	if err := json.Unmarshal(line, &probe); err != nil {
+		return false
+	}
+	return probe.Action != ""
+}
+
+// formatTimestamp renders a suite start time the way suites carry it in
+// their "timestamp" property.
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// newSuite builds a suite from its tests and computes its totals.
+func newSuite(name string, tests []junit.Test, timestamp string) junit.Suite {
+	suite := junit.Suite{
+		Name:       name,
+		Tests:      tests,
+		Properties: map[string]string{},
+	}
+	if timestamp != "" {
+		suite.Properties["timestamp"] = timestamp
+	}
+	suite.Aggregate()
+	return suite
+}
+
+// suiteBuilder collects tests into suites, keeping the suites in the order
+// they were first seen.
+type suiteBuilder struct {
+	order []string
+	tests map[string][]junit.Test
+}
+
+func newSuiteBuilder() *suiteBuilder {
+	return &suiteBuilder{tests: map[string][]junit.Test{}}
+}
+
+func (b *suiteBuilder) add(suite string, test junit.Test) {
+	if _, seen := b.tests[suite]; !seen {
+		b.order = append(b.order, suite)
+	}
+	b.tests[suite] = append(b.tests[suite], test)
+}
+
+func (b *suiteBuilder) suites(timestamp string) []junit.Suite {
+	suites := []junit.Suite{}
+	for _, name := range b.order {
+		suites = append(suites, newSuite(name, b.tests[name], timestamp))
+	}
+	return suites
+}
+
+// failure builds the error of a failed or errored test.
+func failure(message, body string) error {
+	return junit.Error{Message: message, Body: body}
+}
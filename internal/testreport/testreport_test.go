@@ -0,0 +1,251 @@
+package testreport
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	junit "github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseFixture(t *testing.T, format Format, name string) *Report {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	report, err := Parse(format, path, data)
+	require.NoError(t, err)
+	return report
+}
+
+func testByName(t *testing.T, suite junit.Suite, name string) junit.Test {
+	t.Helper()
+	for _, test := range suite.Tests {
+		if test.Name == name {
+			return test
+		}
+	}
+	require.Failf(t, "test not found", "no test %q in suite %q", name, suite.Name)
+	return junit.Test{}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range Formats {
+		got, err := ParseFormat(string(f))
+		require.NoError(t, err)
+		assert.Equal(t, f, got)
+	}
+	_, err := ParseFormat("xunit")
+	require.EqualError(t, err, `"xunit" is not a supported test report format. Valid formats are: [auto, junit, tap, go-test-json, trx, ctrf]`)
+}
+
+func TestFormatAccepts(t *testing.T) {
+	assert.True(t, FormatJUnit.Accepts("report.xml"))
+	assert.False(t, FormatJUnit.Accepts("report.json"))
+	assert.True(t, FormatTAP.Accepts("results.TAP"))
+	assert.True(t, FormatGoTest.Accepts("go-test.jsonl"))
+	assert.True(t, FormatTRX.Accepts("run.trx"))
+	assert.False(t, FormatCTRF.Accepts("run.trx"))
+	assert.True(t, FormatAuto.Accepts("ctrf-report.json"))
+	assert.True(t, FormatAuto.Accepts("results.txt"))
+	assert.False(t, FormatAuto.Accepts("README.md"))
+}
+
+func TestDetect(t *testing.T) {
+	for _, tc := range []struct {
+		file string
+		want Format
+	}{
+		{file: "results.tap", want: FormatTAP},
+		{file: "gotest.json", want: FormatGoTest},
+		{file: "results.trx", want: FormatTRX},
+		{file: "ctrf.json", want: FormatCTRF},
+		{file: "latin1.xml", want: FormatJUnit},
+	} {
+		t.Run(tc.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tc.file))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, Detect(tc.file, data))
+		})
+	}
+
+	t.Run("a TRX file saved as xml", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "results.trx"))
+		require.NoError(t, err)
+		assert.Equal(t, FormatTRX, Detect("results.xml", data))
+	})
+
+	t.Run("a TAP file saved as txt", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "results.tap"))
+		require.NoError(t, err)
+		assert.Equal(t, FormatTAP, Detect("results.txt", data))
+		assert.Equal(t, Format(""), Detect("notes.txt", []byte("remember to run the tests\n")))
+	})
+
+	t.Run("a json file that is not a test report", func(t *testing.T) {
+		assert.Equal(t, Format(""), Detect("package.json", []byte(`{"name": "app", "version": "1.0.0"}`)))
+	})
+}
+
+func TestParseJUnitConvertsDeclaredEncoding(t *testing.T) {
+	report := parseFixture(t, FormatJUnit, "latin1.xml")
+	require.Len(t, report.Suites, 1)
+	assert.Equal(t, "café", report.Suites[0].Name)
+	require.Len(t, report.Suites[0].Tests, 1)
+	assert.Equal(t, "crème brûlée", report.Suites[0].Tests[0].Name)
+}
+
+func TestParseJUnitRejectsUnknownEncoding(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "unknown-encoding.xml"))
+	require.NoError(t, err)
+	_, err = Parse(FormatJUnit, "unknown-encoding.xml", data)
+	var encodingErr *UnsupportedEncodingError
+	require.ErrorAs(t, err, &encodingErr)
+	assert.Equal(t, "x-klingon", encodingErr.Encoding)
+}
+
+func TestParseTAP(t *testing.T) {
+	report := parseFixture(t, FormatTAP, "results.tap")
+	require.Len(t, report.Suites, 1)
+	suite := report.Suites[0]
+	assert.Equal(t, "results", suite.Name)
+	assert.Equal(t, junit.Totals{Tests: 5, Passed: 2, Skipped: 2, Failed: 1, Duration: 12500 * time.Microsecond}, suite.Totals)
+
+	failed := testByName(t, suite, "rejects an unknown key")
+	assert.Equal(t, junit.StatusFailed, failed.Status)
+	assert.Equal(t, "expected an error, got nil", failed.Message)
+	assert.Equal(t, 12500*time.Microsecond, failed.Duration)
+
+	skipped := testByName(t, suite, "reads the config from disk")
+	assert.Equal(t, junit.StatusSkipped, skipped.Status)
+	assert.Equal(t, "no fixture on windows", skipped.Message)
+
+	todo := testByName(t, suite, "merges nested maps")
+	assert.Equal(t, junit.StatusSkipped, todo.Status)
+
+	assert.Equal(t, junit.StatusPassed, testByName(t, suite, "defaults").Status)
+}
+
+func TestParseTAPBailOutIsAnError(t *testing.T) {
+	report := parseFixture(t, FormatTAP, "bailout.tap")
+	require.Len(t, report.Suites, 1)
+	suite := report.Suites[0]
+	assert.Equal(t, 2, suite.Totals.Tests)
+	assert.Equal(t, 1, suite.Totals.Error)
+	assert.Equal(t, "database unreachable", testByName(t, suite, "Bail out!").Message)
+}
+
+func TestParseGoTestJSON(t *testing.T) {
+	report := parseFixture(t, FormatGoTest, "gotest.json")
+	require.Len(t, report.Suites, 2, "a package without tests is not a suite")
+
+	config := report.Suites[0]
+	assert.Equal(t, "example.com/app/config", config.Name)
+	assert.Equal(t, "2024-05-01T08:00:00Z", config.Properties["timestamp"])
+	assert.Equal(t, 3, config.Totals.Tests)
+	assert.Equal(t, 1, config.Totals.Failed)
+	assert.Equal(t, 1, config.Totals.Skipped)
+
+	failed := testByName(t, config, "TestMerge")
+	assert.Equal(t, "example.com/app/config", failed.Classname)
+	assert.Equal(t, 50*time.Millisecond, failed.Duration)
+	require.Error(t, failed.Error)
+	assert.Contains(t, failed.Error.Error(), "expected 2 keys, got 1")
+
+	assert.Equal(t, "only runs on windows", testByName(t, config, "TestWindowsPaths").Message)
+
+	server := report.Suites[1]
+	assert.Equal(t, "example.com/app/server", server.Name)
+	assert.Equal(t, 1, server.Totals.Error, "a build failure is reported as an error")
+	assert.Contains(t, server.Tests[0].Error.Error(), "undefined: newRouter")
+}
+
+func TestParseGoTestJSONKeepsEveryRun(t *testing.T) {
+	data := []byte(`{"Action":"run","Package":"p","Test":"TestFlaky"}
+{"Action":"fail","Package":"p","Test":"TestFlaky","Elapsed":0.1}
+not json: ignored
+{"Action":"run","Package":"p","Test":"TestFlaky"}
+{"Action":"pass","Package":"p","Test":"TestFlaky","Elapsed":0.2}
+`)
+	report, err := Parse(FormatGoTest, "rerun.json", data)
+	require.NoError(t, err)
+	require.Len(t, report.Suites, 1)
+	require.Len(t, report.Suites[0].Tests, 2)
+	assert.Equal(t, junit.StatusFailed, report.Suites[0].Tests[0].Status)
+	assert.Equal(t, junit.StatusPassed, report.Suites[0].Tests[1].Status)
+}
+
+func TestParseGoTestJSONUnfinishedTestIsAnError(t *testing.T) {
+	data := []byte(`{"Action":"run","Package":"p","Test":"TestQuick"}
+{"Action":"pass","Package":"p","Test":"TestQuick","Elapsed":0.1}
+{"Action":"run","Package":"p","Test":"TestSlow"}
+{"Action":"output","Package":"p","Test":"TestSlow","Output":"panic: test timed out after 10m0s\n"}
+{"Action":"fail","Package":"p","Elapsed":600}
+`)
+	report, err := Parse(FormatGoTest, "timeout.json", data)
+	require.NoError(t, err)
+	require.Len(t, report.Suites, 1)
+	suite := report.Suites[0]
+	require.Len(t, suite.Tests, 2, "the package failure is the unfinished test")
+	assert.Equal(t, junit.StatusPassed, testByName(t, suite, "TestQuick").Status)
+
+	slow := testByName(t, suite, "TestSlow")
+	assert.Equal(t, junit.StatusError, slow.Status)
+	assert.Equal(t, "test did not finish", slow.Message)
+	require.Error(t, slow.Error)
+	assert.Contains(t, slow.Error.Error(), "test timed out")
+}
+
+func TestParseTRX(t *testing.T) {
+	report := parseFixture(t, FormatTRX, "results.trx")
+	require.Len(t, report.Suites, 3)
+
+	invoices := report.Suites[0]
+	assert.Equal(t, "Billing.Tests.InvoiceTests", invoices.Name)
+	assert.Equal(t, "2024-05-01T08:00:00Z", invoices.Properties["timestamp"])
+	assert.Equal(t, 2, invoices.Totals.Tests)
+	assert.Equal(t, 1, invoices.Totals.Failed)
+	failed := testByName(t, invoices, "RejectsNegativeAmount")
+	assert.Equal(t, 1250*time.Millisecond, failed.Duration)
+	assert.Equal(t, "Assert.Throws() Failure: No exception was thrown", failed.Message)
+	assert.Contains(t, failed.Error.Error(), "InvoiceTests.cs:line 31")
+
+	reminders := report.Suites[1]
+	assert.Equal(t, "Billing.Tests.ReminderTests", reminders.Name)
+	assert.Equal(t, 1, reminders.Totals.Skipped)
+	assert.Equal(t, "Skipped until the mail stub is fixed", reminders.Tests[0].Message)
+
+	currency := report.Suites[2]
+	assert.Equal(t, 2, currency.Totals.Tests, "each data row is a test")
+	assert.Equal(t, 1, currency.Totals.Error, "a timeout is an error")
+}
+
+func TestTRXDuration(t *testing.T) {
+	assert.Equal(t, time.Hour+2*time.Minute+3500*time.Millisecond, trxDuration("01:02:03.5"))
+	assert.Equal(t, time.Duration(0), trxDuration("not a duration"))
+}
+
+func TestParseCTRF(t *testing.T) {
+	report := parseFixture(t, FormatCTRF, "ctrf.json")
+	require.Len(t, report.Suites, 3)
+
+	header := report.Suites[0]
+	assert.Equal(t, "Header", header.Name)
+	assert.Equal(t, "2024-05-01T10:00:00Z", header.Properties["timestamp"])
+	assert.Equal(t, 2, header.Totals.Passed)
+	assert.Equal(t, 460*time.Millisecond, header.Totals.Duration)
+
+	form := report.Suites[1]
+	assert.Equal(t, 1, form.Totals.Failed)
+	assert.Equal(t, "expected 200, received 500", form.Tests[0].Message)
+
+	assert.Equal(t, "upload.test.js", report.Suites[2].Name, "the file path names a test without a suite")
+
+	assert.Equal(t, map[string]Reruns{
+		TestKey("Header", "opens the menu"): {FlakyMessages: []string{"", ""}},
+		TestKey("Form", "submits the form"): {RerunCount: 1},
+	}, report.Reruns)
+}
@@ -0,0 +1,140 @@
+package testreport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	junit "github.com/joshdk/go-junit"
+)
+
+// trxNamespace is the XML namespace of Visual Studio test result (.trx) files.
+const trxNamespace = "http://microsoft.com/schemas/VisualStudio/TeamTest/2010"
+
+type trxTestRun struct {
+	Times struct {
+		Start string `xml:"start,attr"`
+	} `xml:"Times"`
+	Results struct {
+		UnitTestResults []trxUnitTestResult `xml:"UnitTestResult"`
+	} `xml:"Results"`
+	TestDefinitions struct {
+		UnitTests []struct {
+			ID         string `xml:"id,attr"`
+			TestMethod struct {
+				ClassName string `xml:"className,attr"`
+				Name      string `xml:"name,attr"`
+			} `xml:"TestMethod"`
+		} `xml:"UnitTest"`
+	} `xml:"TestDefinitions"`
+}
+
+type trxUnitTestResult struct {
+	TestID   string `xml:"testId,attr"`
+	TestName string `xml:"testName,attr"`
+	Duration string `xml:"duration,attr"`
+	Outcome  string `xml:"outcome,attr"`
+	Output   struct {
+		StdOut    string `xml:"StdOut"`
+		ErrorInfo struct {
+			Message    string `xml:"Message"`
+			StackTrace string `xml:"StackTrace"`
+		} `xml:"ErrorInfo"`
+	} `xml:"Output"`
+	// data-driven tests nest the result of each data row
+	InnerResults struct {
+		UnitTestResults []trxUnitTestResult `xml:"UnitTestResult"`
+	} `xml:"InnerResults"`
+}
+
+// parseTRX reads a Visual Studio test results file, as written by
+// `dotnet test --logger trx`, into one suite per test class.
+func parseTRX(data []byte) (*Report, error) {
+	data, err := toUTF8(data)
+	if err != nil {
+		return nil, err
+	}
+	var run trxTestRun
+	if err := xml.Unmarshal(data, &run); err != nil {
+		return nil, err
+	}
+
+	classNames := map[string]string{}
+	for _, def := range run.TestDefinitions.UnitTests {
+		classNames[def.ID] = def.TestMethod.ClassName
+	}
+
+	var timestamp string
+	if run.Times.Start != "" {
+		start, err := time.Parse(time.RFC3339Nano, run.Times.Start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRX start time %q: %w", run.Times.Start, err)
+		}
+		timestamp = formatTimestamp(start)
+	}
+
+	builder := newSuiteBuilder()
+	for _, result := range run.Results.UnitTestResults {
+		className := classNames[result.TestID]
+		if len(result.InnerResults.UnitTestResults) > 0 {
+			for _, inner := range result.InnerResults.UnitTestResults {
+				builder.add(className, trxTest(className, inner))
+			}
+			continue
+		}
+		builder.add(className, trxTest(className, result))
+	}
+	return &Report{Suites: builder.suites(timestamp)}, nil
+}
+
+func trxTest(className string, result trxUnitTestResult) junit.Test {
+	test := junit.Test{
+		Name:      result.TestName,
+		Classname: className,
+		Duration:  trxDuration(result.Duration),
+		Status:    trxStatus(result.Outcome),
+		SystemOut: result.Output.StdOut,
+	}
+	errorInfo := result.Output.ErrorInfo
+	switch test.Status {
+	case junit.StatusFailed, junit.StatusError:
+		test.Message = strings.TrimSpace(errorInfo.Message)
+		test.Error = failure(test.Message, errorInfo.StackTrace)
+	case junit.StatusSkipped:
+		test.Message = strings.TrimSpace(errorInfo.Message)
+	}
+	return test
+}
+
+// trxStatus maps a TRX outcome to a test status. Outcomes meaning the test did
+// not run to completion for reasons other than its own assertions are errors.
+func trxStatus(outcome string) junit.Status {
+	switch outcome {
+	case "Passed", "PassedButRunAborted", "Warning":
+		return junit.StatusPassed
+	case "Failed":
+		return junit.StatusFailed
+	case "Error", "Timeout", "Aborted", "Disconnected":
+		return junit.StatusError
+	default: // NotExecuted, NotRunnable, Inconclusive, Pending, InProgress, Completed
+		return junit.StatusSkipped
+	}
+}
+
+// trxDuration parses a TRX duration, e.g. "00:00:01.2345678".
+func trxDuration(value string) time.Duration {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0
+	}
+	hours, errH := strconv.Atoi(parts[0])
+	minutes, errM := strconv.Atoi(parts[1])
+	seconds, errS := strconv.ParseFloat(parts[2], 64)
+	if errH != nil || errM != nil || errS != nil {
+		return 0
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second))
+}
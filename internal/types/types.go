@@ -14,6 +14,9 @@ type PREvidence struct {
 	HeadRef     string   `json:"head_ref,omitempty"`
 	BaseRef     string   `json:"base_ref,omitempty"`
 	Commits     []Commit `json:"commits"`
+	// ReviewAnalysis is computed by the CLI from the other fields, so that
+	// policies can require approvals that are independent and current.
+	ReviewAnalysis *ReviewAnalysis `json:"review_analysis,omitempty"`
 }
 
 // MarshalJSON keeps "commits" in the payload even when a provider returns no
@@ -30,17 +33,73 @@ func (e PREvidence) MarshalJSON() ([]byte, error) {
 }
 
 type PRApprovals struct {
-	Username  string `json:"username"`
+	Username string `json:"username"`
+	// UserID is the provider account of the approver, matched against the
+	// AuthorID of commits to find self-approvals. Display names are not
+	// unique, so they are not matched.
+	UserID    string `json:"user_id,omitempty"`
 	State     string `json:"state,omitempty"`
 	Timestamp int64  `json:"timestamp,omitempty"`
 }
 
+// ReviewAnalysis is the four-eyes analysis of the approvals of a pull request.
+type ReviewAnalysis struct {
+	// IndependentApprovers approved the pull request without authoring any of its commits.
+	// It is only known when SelfApprovalsChecked is true, which needs the
+	// provider account of every commit author and approver.
+	IndependentApprovers []string `json:"independent_approvers"`
+	// SelfApprovers approved the pull request and authored some of its commits.
+	SelfApprovers        []string `json:"self_approvers"`
+	SelfApprovalsChecked bool     `json:"self_approvals_checked"`
+	// StaleApprovers approved before the last commit of the pull request, as
+	// committed rather than authored when the provider reports both.
+	// It is only known when StaleApprovalsChecked is true, which needs the
+	// provider to report when each approval was given.
+	StaleApprovers        []string `json:"stale_approvers"`
+	StaleApprovalsChecked bool     `json:"stale_approvals_checked"`
+	LastCommitTimestamp   int64    `json:"last_commit_timestamp,omitempty"`
+	// FourEyes is true when at least one approver is known to be independent
+	// and, where it can be checked, did not approve before the last commit.
+	FourEyes bool `json:"four_eyes"`
+	// CodeOwners is nil when the repository has no CODEOWNERS file.
+	CodeOwners *CodeOwnersReview `json:"code_owners,omitempty"`
+}
+
+// CodeOwnersReview is whether the code owners of the files changed by a pull
+// request approved it.
+type CodeOwnersReview struct {
+	File string `json:"file"`
+	// Approved is true when every rule is approved.
+	Approved bool                   `json:"approved"`
+	Rules    []CodeOwnersRuleReview `json:"rules"`
+}
+
+// CodeOwnersRuleReview is the review of the files owned by one CODEOWNERS rule.
+type CodeOwnersRuleReview struct {
+	Pattern    string   `json:"pattern"`
+	Owners     []string `json:"owners"`
+	Files      []string `json:"files"`
+	ApprovedBy []string `json:"approved_by"`
+	Approved   bool     `json:"approved"`
+	// TeamOwners is true when some owners are teams. Team membership is not
+	// part of the pull request evidence, so approvals by team members do not
+	// count towards Approved.
+	TeamOwners bool `json:"team_owners,omitempty"`
+}
+
 type Commit struct {
-	SHA            string  `json:"sha1"`
-	Message        string  `json:"message"`
-	Author         string  `json:"author"`
-	AuthorUsername string  `json:"author_username,omitempty"`
-	Timestamp      int64   `json:"timestamp"`
+	SHA            string `json:"sha1"`
+	Message        string `json:"message"`
+	Author         string `json:"author"`
+	AuthorUsername string `json:"author_username,omitempty"`
+	// AuthorID is the provider account of the author, when the provider
+	// reports one other than AuthorUsername.
+	AuthorID  string `json:"author_id,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+	// CommittedAt is when the commit was last committed, which a rebase or
+	// an amend moves past its Timestamp, the author date. It is 0 when the
+	// provider does not report it.
+	CommittedAt    int64   `json:"committed_at,omitempty"`
 	Branch         string  `json:"branch"`
 	URL            string  `json:"url,omitempty"`
 	Verified       *bool   `json:"verified,omitempty"`
@@ -59,3 +118,56 @@ type PRRetriever interface {
 	// for a pull request (e.g. "pull request", or "merge request" for GitLab).
 	ProviderAndLabel() (string, string)
 }
+
+// IssueInfo is the state of an issue referenced by a commit, in an issue
+// tracker other than Jira. It mirrors jira.JiraIssueInfo.
+type IssueInfo struct {
+	IssueID     string `json:"issue_id"`
+	IssueURL    string `json:"issue_url"`
+	IssueExists bool   `json:"issue_exists"`
+	Title       string `json:"title,omitempty"`
+	State       string `json:"state,omitempty"`
+}
+
+type IssueRetriever interface {
+	// FindIssueReferences returns the issue references found in text, in the
+	// canonical form IssueInfo accepts (e.g. "org/repo#12" or "ENG-42").
+	FindIssueReferences(text string) []string
+	// IssueInfo looks an issue reference up. An issue that does not exist is
+	// reported with IssueExists set to false rather than as an error. A nil
+	// IssueInfo means the reference is not an issue (e.g. a GitHub pull
+	// request, which shares the numbering of issues) and should be ignored.
+	IssueInfo(reference string) (*IssueInfo, error)
+	// IssueProvider returns the issue tracker name (e.g. "github").
+	IssueProvider() string
+}
+
+// RepoSettings are the settings protecting a branch of a repository, as
+// reported by its git provider. A setting the provider does not have is left
+// at its zero value.
+type RepoSettings struct {
+	Repository             string   `json:"repository"`
+	Branch                 string   `json:"branch"`
+	Protected              bool     `json:"protected"`
+	RequiredApprovals      int      `json:"required_approvals"`
+	DismissStaleApprovals  bool     `json:"dismiss_stale_approvals"`
+	RequireCodeOwnerReview bool     `json:"require_code_owner_review"`
+	RequireStatusChecks    bool     `json:"require_status_checks"`
+	RequiredStatusChecks   []string `json:"required_status_checks"`
+	RequireSignedCommits   bool     `json:"require_signed_commits"`
+	RequireLinearHistory   bool     `json:"require_linear_history"`
+	AllowForcePushes       bool     `json:"allow_force_pushes"`
+	AllowDeletions         bool     `json:"allow_deletions"`
+	EnforceAdmins          bool     `json:"enforce_admins"`
+	// Rules are the branch protection rules, rulesets, branch restrictions
+	// or policies the settings are computed from, as the provider reports them.
+	Rules []any `json:"rules"`
+}
+
+type RepoSettingsRetriever interface {
+	// RepoSettings looks up the settings protecting branch. An unprotected
+	// branch is reported with Protected set to false rather than as an error.
+	RepoSettings(branch string) (*RepoSettings, error)
+	// RepoSettingsProvider returns the git provider name (e.g. "github").
+	RepoSettingsProvider() string
+}
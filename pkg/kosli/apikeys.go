@@ -0,0 +1,84 @@
+package kosli
+
+import (
+	"context"
+	"net/http"
+)
+
+// APIKey is an API key of a service account. Its value is only returned
+// when it is created or rotated, in a NewAPIKey.
+type APIKey struct {
+	ID          string     `json:"id"`
+	Description string     `json:"description"`
+	CreatedAt   Timestamp  `json:"created_at"`
+	ExpiresAt   *Timestamp `json:"expires_at,omitempty"`
+	LastUsedAt  *Timestamp `json:"last_used_at,omitempty"`
+}
+
+// NewAPIKey is an API key that was just created or rotated, with its value.
+type NewAPIKey struct {
+	ID          string     `json:"id"`
+	Key         string     `json:"key"`
+	Description string     `json:"description"`
+	CreatedAt   Timestamp  `json:"created_at"`
+	ExpiresAt   *Timestamp `json:"expires_at,omitempty"`
+	// GracePeriodExpiresAt is when the key a rotation replaced stops working.
+	GracePeriodExpiresAt *Timestamp `json:"grace_period_expires_at,omitempty"`
+}
+
+// APIKeyInput creates an API key.
+type APIKeyInput struct {
+	Description string `json:"description"`
+	// ExpiresAt is when the key expires, in seconds since the Unix epoch.
+	// The key never expires when it is nil.
+	ExpiresAt *int64 `json:"expires_at,omitempty"`
+}
+
+// RotateAPIKeyInput rotates an API key.
+type RotateAPIKeyInput struct {
+	// GracePeriodHours is how long the rotated key keeps working.
+	GracePeriodHours *int `json:"grace_period_hours,omitempty"`
+	// ExpiresAt is when the new key expires, in seconds since the Unix epoch.
+	ExpiresAt *int64 `json:"expires_at,omitempty"`
+}
+
+// ListAPIKeys returns the API keys of serviceAccount.
+func (c *Client) ListAPIKeys(ctx context.Context, serviceAccount string) ([]APIKey, error) {
+	keys := []APIKey{}
+	if err := c.get(ctx, &keys, "service-accounts", nil, serviceAccount, "api-keys"); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GetAPIKey returns the API key with id of serviceAccount.
+func (c *Client) GetAPIKey(ctx context.Context, serviceAccount, id string) (*APIKey, error) {
+	key := &APIKey{}
+	if err := c.get(ctx, key, "service-accounts", nil, serviceAccount, "api-keys", id); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// CreateAPIKey creates an API key for serviceAccount.
+func (c *Client) CreateAPIKey(ctx context.Context, serviceAccount string, input APIKeyInput) (*NewAPIKey, error) {
+	key := &NewAPIKey{}
+	if err := c.send(ctx, http.MethodPost, input, key, "service-accounts", serviceAccount, "api-keys"); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// RotateAPIKey replaces the API key with id of serviceAccount with a new one.
+func (c *Client) RotateAPIKey(ctx context.Context, serviceAccount, id string, input RotateAPIKeyInput) (*NewAPIKey, error) {
+	key := &NewAPIKey{}
+	if err := c.send(ctx, http.MethodPost, input, key, "service-accounts", serviceAccount, "api-keys", id, "rotate"); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// DeleteAPIKey deletes the API key with id of serviceAccount.
+func (c *Client) DeleteAPIKey(ctx context.Context, serviceAccount, id string) error {
+	return c.send(ctx, http.MethodDelete, nil, nil, "service-accounts", serviceAccount, "api-keys", id)
+}
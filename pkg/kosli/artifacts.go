@@ -0,0 +1,101 @@
+package kosli
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Artifact is a build output of a trail, identified by its SHA-256
+// fingerprint.
+type Artifact struct {
+	Fingerprint string `json:"fingerprint"`
+	Filename    string `json:"filename"`
+	FlowName    string `json:"flow_name"`
+	TrailName   string `json:"trail_name,omitempty"`
+	// TemplateReferenceName is the name of the artifact in the template of
+	// its trail.
+	TemplateReferenceName string    `json:"template_reference_name,omitempty"`
+	GitCommit             string    `json:"git_commit"`
+	CommitURL             string    `json:"commit_url"`
+	BuildURL              string    `json:"build_url"`
+	HTMLURL               string    `json:"html_url"`
+	State                 string    `json:"state"`
+	CreatedAt             Timestamp `json:"created_at"`
+	// Running and Exited are the environment snapshots the artifact is
+	// running in, and has exited from.
+	Running []ArtifactSnapshot `json:"running,omitempty"`
+	Exited  []ArtifactSnapshot `json:"exited,omitempty"`
+	History []map[string]any   `json:"history,omitempty"`
+}
+
+// ArtifactSnapshot is an environment snapshot an artifact is in.
+type ArtifactSnapshot struct {
+	EnvironmentName string `json:"environment_name"`
+	SnapshotIndex   int    `json:"snapshot_index"`
+}
+
+// ArtifactInput reports an artifact to a trail.
+type ArtifactInput struct {
+	Fingerprint   string      `json:"fingerprint"`
+	Filename      string      `json:"filename"`
+	GitCommit     string      `json:"git_commit"`
+	GitCommitInfo *CommitInfo `json:"git_commit_info,omitempty"`
+	BuildURL      string      `json:"build_url"`
+	CommitURL     string      `json:"commit_url"`
+	RepoURL       string      `json:"repo_url,omitempty"`
+	// TemplateReferenceName is the name of the artifact in the template of
+	// the trail.
+	TemplateReferenceName string              `json:"template_reference_name"`
+	TrailName             string              `json:"trail_name"`
+	ExternalURLs          map[string]*URLInfo `json:"external_urls,omitempty"`
+	Annotations           map[string]string   `json:"annotations,omitempty"`
+}
+
+// ListArtifactsOptions filters the artifacts returned by ListArtifacts.
+type ListArtifactsOptions struct {
+	PageOptions
+	Flow string
+	// Repo only returns the artifacts built from this git repository.
+	Repo string
+}
+
+// ListArtifacts returns a page of the artifacts of the organization.
+func (c *Client) ListArtifacts(ctx context.Context, options ListArtifactsOptions) ([]Artifact, error) {
+	query := url.Values{}
+	options.set(query)
+	if options.Flow != "" {
+		query.Set("flow_name", options.Flow)
+	}
+	if options.Repo != "" {
+		query.Set("repo_name", options.Repo)
+	}
+	artifacts := []Artifact{}
+	if err := c.get(ctx, &artifacts, "artifacts", query); err != nil {
+		return nil, err
+	}
+	return artifacts, nil
+}
+
+// GetArtifact returns the artifact of flow with fingerprint.
+func (c *Client) GetArtifact(ctx context.Context, flow, fingerprint string) (*Artifact, error) {
+	artifact := &Artifact{}
+	if err := c.get(ctx, artifact, "artifacts", nil, flow, "fingerprint", fingerprint); err != nil {
+		return nil, err
+	}
+	return artifact, nil
+}
+
+// ArtifactsForCommit returns the artifacts of flow built from commit.
+func (c *Client) ArtifactsForCommit(ctx context.Context, flow, commit string) ([]Artifact, error) {
+	artifacts := []Artifact{}
+	if err := c.get(ctx, &artifacts, "artifacts", nil, flow, "commit_sha", commit); err != nil {
+		return nil, err
+	}
+	return artifacts, nil
+}
+
+// ReportArtifact reports an artifact to a trail of flow.
+func (c *Client) ReportArtifact(ctx context.Context, flow string, input ArtifactInput) error {
+	return c.send(ctx, http.MethodPost, input, nil, "artifacts", flow)
+}
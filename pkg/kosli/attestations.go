@@ -0,0 +1,125 @@
+package kosli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/requests"
+)
+
+// Attestation is evidence reported to a trail, or to an artifact of a trail.
+type Attestation struct {
+	ID                  string      `json:"attestation_id,omitempty"`
+	Name                string      `json:"attestation_name"`
+	Type                string      `json:"attestation_type"`
+	IsCompliant         bool        `json:"is_compliant"`
+	ArtifactFingerprint string      `json:"artifact_fingerprint,omitempty"`
+	CreatedAt           Timestamp   `json:"created_at"`
+	GitCommitInfo       *CommitInfo `json:"git_commit_info,omitempty"`
+	HTMLURL             string      `json:"html_url"`
+}
+
+// AttestationInput has the fields common to all the types of attestation.
+// An attestation is for an artifact when ArtifactFingerprint is set, or when
+// AttestationName is artifact-name.attestation-name and the artifact name is
+// in the template of the trail.
+type AttestationInput struct {
+	AttestationName     string              `json:"attestation_name"`
+	ArtifactFingerprint string              `json:"artifact_fingerprint,omitempty"`
+	TargetArtifacts     []string            `json:"target_artifacts,omitempty"`
+	GitCommitInfo       *CommitInfo         `json:"git_commit_info,omitempty"`
+	ExternalURLs        map[string]*URLInfo `json:"external_urls,omitempty"`
+	OriginURL           string              `json:"origin_url,omitempty"`
+	UserData            any                 `json:"user_data,omitempty"`
+	Description         string              `json:"description,omitempty"`
+	Annotations         map[string]string   `json:"annotations,omitempty"`
+}
+
+// GenericAttestation is an attestation whose compliance is given directly.
+type GenericAttestation struct {
+	AttestationInput
+	IsCompliant bool `json:"is_compliant"`
+}
+
+// CustomAttestation is an attestation of a custom attestation type. Its
+// compliance is evaluated against the type from AttestationData.
+type CustomAttestation struct {
+	AttestationInput
+	TypeName        string `json:"type_name"`
+	AttestationData any    `json:"attestation_data"`
+}
+
+// Attest reports an attestation of attestationType, such as "generic" or
+// "junit", to a trail of flow. input is the JSON payload of the attestation,
+// such as a GenericAttestation for the "generic" type. attachmentFile, when
+// it is not empty, is the path to a file attached to the attestation, such
+// as a tar archive of several files.
+func (c *Client) Attest(ctx context.Context, flow, trail, attestationType string, input any, attachmentFile string) error {
+	endpoint, err := c.endpoint("attestations", nil, flow, "trail", trail, attestationType)
+	if err != nil {
+		return err
+	}
+	form := []requests.FormItem{{Type: "field", FieldName: "data_json", Content: input}}
+	if attachmentFile != "" {
+		form = append(form, requests.FormItem{Type: "file", FieldName: "attachment_file", Content: attachmentFile})
+	}
+	return c.do(ctx, http.MethodPost, endpoint, nil, form, nil)
+}
+
+// AttestGeneric reports a generic attestation to a trail of flow.
+func (c *Client) AttestGeneric(ctx context.Context, flow, trail string, input GenericAttestation) error {
+	return c.Attest(ctx, flow, trail, "generic", input, "")
+}
+
+// AttestCustom reports an attestation of a custom type to a trail of flow.
+func (c *Client) AttestCustom(ctx context.Context, flow, trail string, input CustomAttestation) error {
+	return c.Attest(ctx, flow, trail, "custom", input, "")
+}
+
+// GetAttestations returns the attestations called name of a trail of flow,
+// the latest first.
+func (c *Client) GetAttestations(ctx context.Context, flow, trail, name string) ([]Attestation, error) {
+	var raw json.RawMessage
+	if err := c.get(ctx, &raw, "attestations", nil, flow, "trail", trail, name); err != nil {
+		return nil, err
+	}
+	return parseAttestations(raw)
+}
+
+// GetAttestation returns the attestation with id.
+func (c *Client) GetAttestation(ctx context.Context, id string) (*Attestation, error) {
+	var raw json.RawMessage
+	if err := c.get(ctx, &raw, "attestations", url.Values{"attestation_id": {id}}); err != nil {
+		return nil, err
+	}
+	attestations, err := parseAttestations(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(attestations) == 0 {
+		return nil, &APIError{StatusCode: http.StatusNotFound, Message: "attestation " + id + " not found"}
+	}
+	return &attestations[0], nil
+}
+
+// parseAttestations reads the attestations of a response, which is either
+// a list of attestations or a page of them.
+func parseAttestations(raw json.RawMessage) ([]Attestation, error) {
+	if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+		attestations := []Attestation{}
+		if err := json.Unmarshal(raw, &attestations); err != nil {
+			return nil, err
+		}
+		return attestations, nil
+	}
+	page := struct {
+		Data []Attestation `json:"data"`
+	}{Data: []Attestation{}}
+	if err := json.Unmarshal(raw, &page); err != nil {
+		return nil, err
+	}
+	return page.Data, nil
+}
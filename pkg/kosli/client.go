@@ -0,0 +1,220 @@
+// Package kosli is a Go client for the Kosli API. It has typed methods and
+// models for the flows, trails, artifacts, attestations, environments,
+// snapshots, policies and API keys of a Kosli organization, and sends its
+// requests like the Kosli CLI does, retrying them when the API is busy or
+// rate limited.
+//
+//	client, err := kosli.NewClient(kosli.Config{Org: "my-org", APIToken: token})
+//	if err != nil {
+//		return err
+//	}
+//	trail, err := client.GetTrail(ctx, "backend", "v1.2.3")
+//
+// Every method takes a context, which cancels the request and its retries.
+package kosli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/kosli-dev/cli/internal/requests"
+)
+
+const (
+	// DefaultHost is the Kosli app host used when Config.Host is empty.
+	DefaultHost = "https://app.kosli.com"
+	// DefaultMaxAPIRetries is how many times a failed request is retried
+	// when Config.MaxAPIRetries is 0.
+	DefaultMaxAPIRetries = 3
+)
+
+// Config configures a Client.
+type Config struct {
+	// Host is the Kosli app host, DefaultHost by default.
+	Host string
+	// Org is the Kosli organization the requests are for.
+	Org string
+	// APIToken authenticates the requests.
+	APIToken string
+	// MaxAPIRetries is how many times a failed request is retried,
+	// DefaultMaxAPIRetries by default. A negative value disables retries.
+	MaxAPIRetries int
+	// HTTPProxy is the URL of an HTTP proxy to send the requests through.
+	HTTPProxy string
+	// Debug logs the requests and their responses to stderr.
+	Debug bool
+}
+
+// Client sends requests to the Kosli API. It is safe for concurrent use.
+type Client struct {
+	host     string
+	org      string
+	apiToken string
+	http     *requests.Client
+}
+
+// NewClient returns a Client for the organization and API token of config.
+func NewClient(config Config) (*Client, error) {
+	if config.Org == "" {
+		return nil, fmt.Errorf("a Kosli organization is required")
+	}
+	if config.APIToken == "" {
+		return nil, fmt.Errorf("a Kosli API token is required")
+	}
+	host := config.Host
+	if host == "" {
+		host = DefaultHost
+	}
+	maxAPIRetries := config.MaxAPIRetries
+	if maxAPIRetries == 0 {
+		maxAPIRetries = DefaultMaxAPIRetries
+	}
+	httpClient, err := requests.NewKosliClient(config.HTTPProxy, max(maxAPIRetries, 0), config.Debug,
+		logger.NewLogger(io.Discard, os.Stderr, config.Debug))
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		host:     host,
+		org:      config.Org,
+		apiToken: config.APIToken,
+		http:     httpClient,
+	}, nil
+}
+
+// Org returns the organization of c.
+func (c *Client) Org() string {
+	return c.org
+}
+
+// APIError is the error returned for a request the Kosli API answered with
+// an error status.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// IsNotFound reports whether err is an APIError for a resource that does not
+// exist.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Timestamp is a time given by the Kosli API, in seconds since the Unix epoch.
+type Timestamp float64
+
+// Time returns t as a time.Time, or the zero time when t is 0.
+func (t Timestamp) Time() time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	seconds := int64(t)
+	return time.Unix(seconds, int64((float64(t)-float64(seconds))*1e9))
+}
+
+// Pagination describes the page of a paginated list.
+type Pagination struct {
+	Page      int `json:"page"`
+	PageCount int `json:"page_count"`
+	Total     int `json:"total"`
+}
+
+// CommitInfo describes a git commit.
+type CommitInfo struct {
+	Sha1      string    `json:"sha1"`
+	Message   string    `json:"message"`
+	Author    string    `json:"author"`
+	Timestamp Timestamp `json:"timestamp"`
+	Branch    string    `json:"branch"`
+	URL       string    `json:"url,omitempty"`
+}
+
+// PageOptions selects a page of a paginated list. Zero values select the
+// defaults of the API.
+type PageOptions struct {
+	Page    int
+	PerPage int
+}
+
+func (o PageOptions) set(query url.Values) {
+	if o.Page > 0 {
+		query.Set("page", fmt.Sprint(o.Page))
+	}
+	if o.PerPage > 0 {
+		query.Set("per_page", fmt.Sprint(o.PerPage))
+	}
+}
+
+// endpoint returns the URL of the API endpoint at path in the organization
+// of c, with query.
+func (c *Client) endpoint(resource string, query url.Values, path ...string) (string, error) {
+	u, err := url.JoinPath(c.host, append([]string{"api/v2", resource, c.org}, path...)...)
+	if err != nil {
+		return "", err
+	}
+	if encoded := query.Encode(); encoded != "" {
+		u += "?" + encoded
+	}
+	return u, nil
+}
+
+// do sends a request to endpoint and decodes its response into result, when
+// result is not nil. The request has payload as its JSON body, or form as
+// its multipart body.
+func (c *Client) do(ctx context.Context, method, endpoint string, payload any, form []requests.FormItem, result any) error {
+	response, err := c.http.Do(&requests.RequestParams{
+		Method:  method,
+		URL:     endpoint,
+		Payload: payload,
+		Form:    form,
+		Token:   c.apiToken,
+		Context: ctx,
+	})
+	if err != nil {
+		var apiErr *requests.APIError
+		if errors.As(err, &apiErr) {
+			return &APIError{StatusCode: apiErr.StatusCode, Message: apiErr.Message}
+		}
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(response.Body), result); err != nil {
+		return fmt.Errorf("failed to parse the response of %s %s: %v", method, endpoint, err)
+	}
+	return nil
+}
+
+// get decodes the response to a GET request to the endpoint of resource
+// into result.
+func (c *Client) get(ctx context.Context, result any, resource string, query url.Values, path ...string) error {
+	endpoint, err := c.endpoint(resource, query, path...)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodGet, endpoint, nil, nil, result)
+}
+
+// send sends payload to the endpoint of resource with method, and decodes
+// the response into result when it is not nil.
+func (c *Client) send(ctx context.Context, method string, payload, result any, resource string, path ...string) error {
+	endpoint, err := c.endpoint(resource, nil, path...)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, method, endpoint, payload, nil, result)
+}
@@ -0,0 +1,72 @@
+package kosli
+
+import (
+	"context"
+	"net/http"
+)
+
+// Environment is a Kosli environment, a runtime whose snapshots are reported.
+type Environment struct {
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	Description    string            `json:"description"`
+	LastReportedAt *Timestamp        `json:"last_reported_at,omitempty"`
+	LastModifiedAt *Timestamp        `json:"last_modified_at,omitempty"`
+	State          bool              `json:"state"`
+	Tags           map[string]string `json:"tags,omitempty"`
+	Policies       []string          `json:"policies,omitempty"`
+	IncludeScaling bool              `json:"include_scaling"`
+	// RequireProvenance makes an artifact without provenance non-compliant.
+	RequireProvenance bool `json:"require_provenance"`
+	// IncludedEnvironments are the environments a logical environment
+	// aggregates.
+	IncludedEnvironments []string `json:"included_environments,omitempty"`
+}
+
+// EnvironmentInput creates or updates an environment.
+type EnvironmentInput struct {
+	Name string `json:"name"`
+	// Type is one of K8S, ECS, S3, lambda, server, docker, azure-apps,
+	// cloud-run or logical.
+	Type                 string   `json:"type"`
+	Description          string   `json:"description"`
+	IncludeScaling       *bool    `json:"include_scaling,omitempty"`
+	RequireProvenance    *bool    `json:"require_provenance,omitempty"`
+	IncludedEnvironments []string `json:"included_environments,omitempty"`
+}
+
+// ListEnvironments returns the environments of the organization.
+func (c *Client) ListEnvironments(ctx context.Context) ([]Environment, error) {
+	environments := []Environment{}
+	if err := c.get(ctx, &environments, "environments", nil); err != nil {
+		return nil, err
+	}
+	return environments, nil
+}
+
+// GetEnvironment returns the environment called name.
+func (c *Client) GetEnvironment(ctx context.Context, name string) (*Environment, error) {
+	environment := &Environment{}
+	if err := c.get(ctx, environment, "environments", nil, name); err != nil {
+		return nil, err
+	}
+	return environment, nil
+}
+
+// CreateEnvironment creates an environment, or updates it when it exists.
+func (c *Client) CreateEnvironment(ctx context.Context, input EnvironmentInput) error {
+	return c.send(ctx, http.MethodPut, input, nil, "environments")
+}
+
+// ArchiveEnvironment archives the environment called name.
+func (c *Client) ArchiveEnvironment(ctx context.Context, name string) error {
+	return c.send(ctx, http.MethodPut, nil, nil, "environments", name, "archive")
+}
+
+// ReportSnapshot reports a snapshot of what is running in environment.
+// reportType is the report endpoint of the type of the environment, one of
+// K8S, ECS, S3, lambda, server, docker, azure-apps or cloud-run, and payload
+// is the snapshot in the format of that endpoint.
+func (c *Client) ReportSnapshot(ctx context.Context, environment, reportType string, payload any) error {
+	return c.send(ctx, http.MethodPut, payload, nil, "environments", environment, "report", reportType)
+}
@@ -0,0 +1,80 @@
+package kosli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/kosli-dev/cli/internal/requests"
+)
+
+// Flow is a Kosli flow, the process whose trails are tracked.
+type Flow struct {
+	Name             string            `json:"name"`
+	Description      string            `json:"description"`
+	Visibility       string            `json:"visibility,omitempty"`
+	Template         any               `json:"template,omitempty"`
+	Tags             map[string]string `json:"tags,omitempty"`
+	LastDeploymentAt *Timestamp        `json:"last_deployment_at,omitempty"`
+}
+
+// FlowInput creates or updates a flow.
+type FlowInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// TemplateFile is the path to the YAML template of the flow.
+	TemplateFile string `json:"-"`
+}
+
+// ListFlowsOptions filters the flows returned by ListFlows.
+type ListFlowsOptions struct {
+	// Name only returns the flows whose name contains it.
+	Name       string
+	IgnoreCase bool
+}
+
+// ListFlows returns the flows of the organization.
+func (c *Client) ListFlows(ctx context.Context, options ListFlowsOptions) ([]Flow, error) {
+	query := url.Values{}
+	if options.Name != "" {
+		query.Set("search_by_name", options.Name)
+		if options.IgnoreCase {
+			query.Set("case_sensitive", "false")
+		}
+	}
+	flows := []Flow{}
+	if err := c.get(ctx, &flows, "flows", query); err != nil {
+		return nil, err
+	}
+	return flows, nil
+}
+
+// GetFlow returns the flow called name.
+func (c *Client) GetFlow(ctx context.Context, name string) (*Flow, error) {
+	flow := &Flow{}
+	if err := c.get(ctx, flow, "flows", nil, name); err != nil {
+		return nil, err
+	}
+	return flow, nil
+}
+
+// CreateFlow creates a flow, or updates it when it exists.
+func (c *Client) CreateFlow(ctx context.Context, input FlowInput) error {
+	if input.TemplateFile == "" {
+		return fmt.Errorf("a template file is required to create flow %s", input.Name)
+	}
+	endpoint, err := c.endpoint("flows", nil, "template_file")
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPut, endpoint, nil, []requests.FormItem{
+		{Type: "field", FieldName: "data_json", Content: input},
+		{Type: "file", FieldName: "template_file", Content: input.TemplateFile},
+	}, nil)
+}
+
+// ArchiveFlow archives the flow called name.
+func (c *Client) ArchiveFlow(ctx context.Context, name string) error {
+	return c.send(ctx, http.MethodPut, nil, nil, "flows", name, "archive")
+}
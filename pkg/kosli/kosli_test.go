@@ -0,0 +1,265 @@
+package kosli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// request is what the fake Kosli API received.
+type request struct {
+	method   string
+	path     string
+	query    string
+	auth     string
+	body     string
+	dataJSON string
+	files    map[string]string
+}
+
+// newTestClient returns a Client for a fake Kosli API answering every
+// request with status and body, and the requests it received.
+func newTestClient(t *testing.T, status int, body string) (*Client, *[]request) {
+	t.Helper()
+	received := &[]request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{
+			method: r.Method,
+			path:   r.URL.Path,
+			query:  r.URL.RawQuery,
+			auth:   r.Header.Get("Authorization"),
+			files:  map[string]string{},
+		}
+		if err := r.ParseMultipartForm(1 << 20); err == nil {
+			req.dataJSON = r.FormValue("data_json")
+			if req.dataJSON == "" {
+				req.dataJSON = r.FormValue("payload")
+			}
+			for field, headers := range r.MultipartForm.File {
+				req.files[field] = headers[0].Filename
+			}
+		} else {
+			content, _ := io.ReadAll(r.Body)
+			req.body = string(content)
+		}
+		*received = append(*received, req)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(Config{Host: server.URL, Org: "acme", APIToken: "secret", MaxAPIRetries: -1})
+	require.NoError(t, err)
+	return client, received
+}
+
+func TestNewClientRequiresOrgAndToken(t *testing.T) {
+	_, err := NewClient(Config{APIToken: "secret"})
+	require.EqualError(t, err, "a Kosli organization is required")
+	_, err = NewClient(Config{Org: "acme"})
+	require.EqualError(t, err, "a Kosli API token is required")
+
+	client, err := NewClient(Config{Org: "acme", APIToken: "secret"})
+	require.NoError(t, err)
+	require.Equal(t, "acme", client.Org())
+	require.Equal(t, DefaultHost, client.host)
+}
+
+func TestGetRequests(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		body      string
+		call      func(*Client) (any, error)
+		wantPath  string
+		wantQuery string
+		want      any
+	}{
+		{
+			name: "ListFlows filters by name",
+			body: `[{"name":"backend","description":"the backend"}]`,
+			call: func(c *Client) (any, error) {
+				return c.ListFlows(context.Background(), ListFlowsOptions{Name: "back", IgnoreCase: true})
+			},
+			wantPath:  "/api/v2/flows/acme",
+			wantQuery: "case_sensitive=false&search_by_name=back",
+			want:      []Flow{{Name: "backend", Description: "the backend"}},
+		},
+		{
+			name: "ListTrails decodes a page",
+			body: `{"data":[{"name":"v1","compliance_state":"COMPLIANT"}],"pagination":{"page":2,"page_count":3,"total":25}}`,
+			call: func(c *Client) (any, error) {
+				return c.ListTrails(context.Background(), ListTrailsOptions{PageOptions: PageOptions{Page: 2, PerPage: 10}, Flow: "backend"})
+			},
+			wantPath:  "/api/v2/trails/acme",
+			wantQuery: "flow=backend&page=2&per_page=10",
+			want: &TrailList{
+				Trails:     []Trail{{Name: "v1", ComplianceState: "COMPLIANT"}},
+				Pagination: Pagination{Page: 2, PageCount: 3, Total: 25},
+			},
+		},
+		{
+			name: "GetArtifact",
+			body: `{"fingerprint":"abc","filename":"app","flow_name":"backend","created_at":1700000000.5}`,
+			call: func(c *Client) (any, error) {
+				return c.GetArtifact(context.Background(), "backend", "abc")
+			},
+			wantPath: "/api/v2/artifacts/acme/backend/fingerprint/abc",
+			want:     &Artifact{Fingerprint: "abc", Filename: "app", FlowName: "backend", CreatedAt: 1700000000.5},
+		},
+		{
+			name: "GetAttestations reads a page of attestations",
+			body: `{"data":[{"attestation_name":"tests","attestation_type":"junit","is_compliant":true}]}`,
+			call: func(c *Client) (any, error) {
+				return c.GetAttestations(context.Background(), "backend", "v1", "tests")
+			},
+			wantPath: "/api/v2/attestations/acme/backend/trail/v1/tests",
+			want:     []Attestation{{Name: "tests", Type: "junit", IsCompliant: true}},
+		},
+		{
+			name: "ListSnapshots",
+			body: `[{"index":3,"from":1700000000,"duration":60,"compliant":true}]`,
+			call: func(c *Client) (any, error) {
+				return c.ListSnapshots(context.Background(), "prod", ListSnapshotsOptions{Interval: "1..3", Reverse: true})
+			},
+			wantPath:  "/api/v2/snapshots/acme/prod",
+			wantQuery: "interval=1..3&reverse=true",
+			want:      []SnapshotSummary{{Index: 3, From: 1700000000, Duration: 60, Compliant: true}},
+		},
+		{
+			name: "GetSnapshot",
+			body: `{"index":3,"type":"K8S","artifacts":[{"name":"app:1","fingerprint":"abc","annotation":{"type":"started","was":0,"now":2}}]}`,
+			call: func(c *Client) (any, error) {
+				return c.GetSnapshot(context.Background(), "prod", "-1")
+			},
+			wantPath: "/api/v2/snapshots/acme/prod/-1",
+			want: &Snapshot{Index: 3, Type: "K8S", Artifacts: []SnapshotArtifact{
+				{Name: "app:1", Fingerprint: "abc", Annotation: SnapshotAnnotation{Type: "started", Now: 2}},
+			}},
+		},
+		{
+			name: "ListAPIKeys",
+			body: `[{"id":"k1","description":"ci","created_at":1700000000}]`,
+			call: func(c *Client) (any, error) {
+				return c.ListAPIKeys(context.Background(), "ci-bot")
+			},
+			wantPath: "/api/v2/service-accounts/acme/ci-bot/api-keys",
+			want:     []APIKey{{ID: "k1", Description: "ci", CreatedAt: 1700000000}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client, received := newTestClient(t, http.StatusOK, tc.body)
+			got, err := tc.call(client)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+			require.Len(t, *received, 1)
+			req := (*received)[0]
+			require.Equal(t, http.MethodGet, req.method)
+			require.Equal(t, tc.wantPath, req.path)
+			require.Equal(t, tc.wantQuery, req.query)
+			require.Equal(t, "Bearer secret", req.auth)
+		})
+	}
+}
+
+func TestSendRequests(t *testing.T) {
+	client, received := newTestClient(t, http.StatusOK, `{"id":"k2","key":"the-key","description":"ci"}`)
+
+	require.NoError(t, client.CreateEnvironment(context.Background(), EnvironmentInput{Name: "prod", Type: "K8S", Description: "production"}))
+	require.NoError(t, client.ReportSnapshot(context.Background(), "prod", "K8S", map[string]any{"artifacts": []any{}}))
+	require.NoError(t, client.AttachPolicies(context.Background(), "prod", "provenance"))
+	expiresAt := int64(1800000000)
+	key, err := client.CreateAPIKey(context.Background(), "ci-bot", APIKeyInput{Description: "ci", ExpiresAt: &expiresAt})
+	require.NoError(t, err)
+	require.Equal(t, &NewAPIKey{ID: "k2", Key: "the-key", Description: "ci"}, key)
+
+	require.Equal(t, []request{
+		{method: http.MethodPut, path: "/api/v2/environments/acme", auth: "Bearer secret", files: map[string]string{},
+			body: `{"name":"prod","type":"K8S","description":"production"}`},
+		{method: http.MethodPut, path: "/api/v2/environments/acme/prod/report/K8S", auth: "Bearer secret", files: map[string]string{},
+			body: `{"artifacts":[]}`},
+		{method: http.MethodPost, path: "/api/v2/environments/acme/prod/policies", auth: "Bearer secret", files: map[string]string{},
+			body: `{"policy_names":["provenance"]}`},
+		{method: http.MethodPost, path: "/api/v2/service-accounts/acme/ci-bot/api-keys", auth: "Bearer secret", files: map[string]string{},
+			body: `{"description":"ci","expires_at":1800000000}`},
+	}, *received)
+}
+
+func TestFormRequests(t *testing.T) {
+	dir := t.TempDir()
+	templateFile := filepath.Join(dir, "template.yml")
+	require.NoError(t, os.WriteFile(templateFile, []byte("version: 1\n"), 0o600))
+	attachment := filepath.Join(dir, "report.tgz")
+	require.NoError(t, os.WriteFile(attachment, []byte("data"), 0o600))
+
+	client, received := newTestClient(t, http.StatusOK, `{}`)
+
+	require.NoError(t, client.CreateFlow(context.Background(), FlowInput{Name: "backend", TemplateFile: templateFile}))
+	require.NoError(t, client.Attest(context.Background(), "backend", "v1", "generic",
+		GenericAttestation{AttestationInput: AttestationInput{AttestationName: "review"}, IsCompliant: true}, attachment))
+	require.NoError(t, client.CreatePolicy(context.Background(), PolicyInput{Name: "provenance"}, templateFile))
+
+	require.Len(t, *received, 3)
+
+	flow := (*received)[0]
+	require.Equal(t, http.MethodPut, flow.method)
+	require.Equal(t, "/api/v2/flows/acme/template_file", flow.path)
+	require.JSONEq(t, `{"name":"backend","description":""}`, flow.dataJSON)
+	require.Equal(t, map[string]string{"template_file": "template.yml"}, flow.files)
+
+	attestation := (*received)[1]
+	require.Equal(t, http.MethodPost, attestation.method)
+	require.Equal(t, "/api/v2/attestations/acme/backend/trail/v1/generic", attestation.path)
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal([]byte(attestation.dataJSON), &payload))
+	require.Equal(t, map[string]any{"attestation_name": "review", "is_compliant": true}, payload)
+	require.Equal(t, map[string]string{"attachment_file": "report.tgz"}, attestation.files)
+
+	policy := (*received)[2]
+	require.Equal(t, "/api/v2/policies/acme", policy.path)
+	require.JSONEq(t, `{"name":"provenance","description":"","comment":"","type":"env"}`, policy.dataJSON)
+	require.Equal(t, map[string]string{"policy_file": "template.yml"}, policy.files)
+}
+
+func TestCreateFlowRequiresATemplateFile(t *testing.T) {
+	client, received := newTestClient(t, http.StatusOK, `{}`)
+	require.EqualError(t, client.CreateFlow(context.Background(), FlowInput{Name: "backend"}),
+		"a template file is required to create flow backend")
+	require.Empty(t, *received)
+}
+
+func TestAPIErrors(t *testing.T) {
+	client, _ := newTestClient(t, http.StatusNotFound, `{"message":"Flow named 'missing' does not exist for organization 'acme'"}`)
+	_, err := client.GetFlow(context.Background(), "missing")
+	require.Error(t, err)
+	require.True(t, IsNotFound(err))
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	require.Contains(t, apiErr.Message, "Flow named 'missing' does not exist")
+
+	client, _ = newTestClient(t, http.StatusBadRequest, `{"message":"bad request"}`)
+	err = client.ArchiveFlow(context.Background(), "backend")
+	require.Error(t, err)
+	require.False(t, IsNotFound(err))
+}
+
+func TestCancelledContext(t *testing.T) {
+	client, received := newTestClient(t, http.StatusOK, `[]`)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.ListFlows(ctx, ListFlowsOptions{})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Empty(t, *received)
+}
+
+func TestTimestampTime(t *testing.T) {
+	require.True(t, Timestamp(0).Time().IsZero())
+	require.Equal(t, int64(1700000000500), Timestamp(1700000000.5).Time().UnixMilli())
+}
@@ -0,0 +1,82 @@
+package kosli
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/kosli-dev/cli/internal/requests"
+)
+
+// Policy is an environment policy, the YAML rules the artifacts running in
+// the environments it is attached to must follow.
+type Policy struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	CreatedAt   Timestamp       `json:"created_at"`
+	Versions    []PolicyVersion `json:"versions"`
+	// ConsumingEnvs are the environments the policy is attached to.
+	ConsumingEnvs []string `json:"consuming_envs"`
+}
+
+// PolicyVersion is a version of a policy, the oldest first in Policy.Versions.
+type PolicyVersion struct {
+	PolicyYAML string `json:"policy_yaml"`
+}
+
+// PolicyInput creates a policy, or a new version of it when it exists.
+type PolicyInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Comment describes the change of a new version.
+	Comment string `json:"comment"`
+	// Type is env, the only type of policy.
+	Type string `json:"type"`
+}
+
+type policyNames struct {
+	PolicyNames []string `json:"policy_names"`
+}
+
+// ListPolicies returns the policies of the organization.
+func (c *Client) ListPolicies(ctx context.Context) ([]Policy, error) {
+	policies := []Policy{}
+	if err := c.get(ctx, &policies, "policies", nil); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// GetPolicy returns the policy called name.
+func (c *Client) GetPolicy(ctx context.Context, name string) (*Policy, error) {
+	policy := &Policy{}
+	if err := c.get(ctx, policy, "policies", nil, name); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// CreatePolicy creates a policy from the YAML file at policyFile, or adds a
+// version to it when it exists. input.Type defaults to env.
+func (c *Client) CreatePolicy(ctx context.Context, input PolicyInput, policyFile string) error {
+	if input.Type == "" {
+		input.Type = "env"
+	}
+	endpoint, err := c.endpoint("policies", nil)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPut, endpoint, nil, []requests.FormItem{
+		{Type: "field", FieldName: "payload", Content: input},
+		{Type: "file", FieldName: "policy_file", Content: policyFile},
+	}, nil)
+}
+
+// AttachPolicies attaches policies to environment.
+func (c *Client) AttachPolicies(ctx context.Context, environment string, policies ...string) error {
+	return c.send(ctx, http.MethodPost, policyNames{policies}, nil, "environments", environment, "policies")
+}
+
+// DetachPolicies detaches policies from environment.
+func (c *Client) DetachPolicies(ctx context.Context, environment string, policies ...string) error {
+	return c.send(ctx, http.MethodDelete, policyNames{policies}, nil, "environments", environment, "policies")
+}
@@ -0,0 +1,88 @@
+package kosli
+
+import (
+	"context"
+	"net/url"
+)
+
+// SnapshotSummary is a snapshot of an environment in a list of snapshots.
+type SnapshotSummary struct {
+	Index     int        `json:"index"`
+	From      Timestamp  `json:"from"`
+	To        *Timestamp `json:"to,omitempty"`
+	Duration  float64    `json:"duration"`
+	Compliant bool       `json:"compliant"`
+}
+
+// Snapshot is what was running in an environment at the time it was reported.
+type Snapshot struct {
+	Index     int                `json:"index"`
+	Timestamp Timestamp          `json:"timestamp"`
+	Type      string             `json:"type"`
+	UserID    string             `json:"user_id"`
+	UserName  string             `json:"user_name"`
+	Compliant bool               `json:"compliant"`
+	Artifacts []SnapshotArtifact `json:"artifacts"`
+}
+
+// SnapshotArtifact is an artifact running in a snapshot.
+type SnapshotArtifact struct {
+	Name              string             `json:"name"`
+	FlowName          string             `json:"flow_name"`
+	Fingerprint       string             `json:"fingerprint"`
+	Compliant         bool               `json:"compliant"`
+	Deployments       []int              `json:"deployments,omitempty"`
+	GitCommit         string             `json:"git_commit"`
+	CommitURL         string             `json:"commit_url"`
+	CreationTimestamp []Timestamp        `json:"creation_timestamp,omitempty"`
+	Pods              map[string]any     `json:"pods,omitempty"`
+	Annotation        SnapshotAnnotation `json:"annotation"`
+}
+
+// SnapshotAnnotation tells how an artifact changed since the previous
+// snapshot, from Was instances to Now instances.
+type SnapshotAnnotation struct {
+	// Type is one of started, exited, scaled or unchanged.
+	Type string `json:"type"`
+	Was  int    `json:"was"`
+	Now  int    `json:"now"`
+}
+
+// ListSnapshotsOptions selects the snapshots returned by ListSnapshots.
+type ListSnapshotsOptions struct {
+	PageOptions
+	// Interval only returns the snapshots in an interval, such as 3..5 or
+	// ~3..NOW.
+	Interval string
+	// Reverse returns the oldest snapshots first.
+	Reverse bool
+}
+
+// ListSnapshots returns a page of the snapshots of environment, the latest
+// first.
+func (c *Client) ListSnapshots(ctx context.Context, environment string, options ListSnapshotsOptions) ([]SnapshotSummary, error) {
+	query := url.Values{}
+	options.set(query)
+	if options.Interval != "" {
+		query.Set("interval", options.Interval)
+	}
+	if options.Reverse {
+		query.Set("reverse", "true")
+	}
+	snapshots := []SnapshotSummary{}
+	if err := c.get(ctx, &snapshots, "snapshots", query, environment); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// GetSnapshot returns a snapshot of environment. id is a snapshot index, or
+// an expression such as -1 for the latest snapshot or ~2 for two snapshots
+// before it.
+func (c *Client) GetSnapshot(ctx context.Context, environment, id string) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	if err := c.get(ctx, snapshot, "snapshots", nil, environment, id); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,102 @@
+package kosli
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/kosli-dev/cli/internal/requests"
+)
+
+// Trail is a single execution of the process of a flow, such as the build
+// of a commit, with its artifacts and attestations.
+type Trail struct {
+	Name            string      `json:"name"`
+	Description     string      `json:"description"`
+	ComplianceState string      `json:"compliance_state"`
+	OriginURL       string      `json:"origin_url,omitempty"`
+	GitCommitInfo   *CommitInfo `json:"git_commit_info,omitempty"`
+	LastModifiedAt  Timestamp   `json:"last_modified_at,omitempty"`
+	// ComplianceStatus has the status of each attestation and artifact
+	// expected by the template of the trail.
+	ComplianceStatus map[string]any   `json:"compliance_status,omitempty"`
+	Events           []map[string]any `json:"events,omitempty"`
+}
+
+// TrailList is a page of trails.
+type TrailList struct {
+	Trails     []Trail    `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// URLInfo is a link attached to a trail or an attestation, with the
+// fingerprint of what it links to.
+type URLInfo struct {
+	Href        string `json:"href"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// TrailInput begins or updates a trail.
+type TrailInput struct {
+	Name          string              `json:"name"`
+	Description   string              `json:"description,omitempty"`
+	UserData      any                 `json:"user_data,omitempty"`
+	GitCommitInfo *CommitInfo         `json:"git_commit_info,omitempty"`
+	ExternalURLs  map[string]*URLInfo `json:"external_urls,omitempty"`
+	OriginURL     string              `json:"origin_url,omitempty"`
+	// TemplateFile is the path to a YAML template overriding the one of
+	// the flow for this trail.
+	TemplateFile string `json:"-"`
+}
+
+// ListTrailsOptions filters the trails returned by ListTrails.
+type ListTrailsOptions struct {
+	PageOptions
+	Flow string
+	// Fingerprint only returns the trails with an artifact of this fingerprint.
+	Fingerprint string
+	// FlowTag only returns the trails of the flows with this tag, as key=value.
+	FlowTag string
+}
+
+// ListTrails returns a page of the trails of the organization.
+func (c *Client) ListTrails(ctx context.Context, options ListTrailsOptions) (*TrailList, error) {
+	query := url.Values{}
+	options.set(query)
+	if options.Flow != "" {
+		query.Set("flow", options.Flow)
+	}
+	if options.Fingerprint != "" {
+		query.Set("fingerprint", options.Fingerprint)
+	}
+	if options.FlowTag != "" {
+		query.Set("flow_tag", options.FlowTag)
+	}
+	trails := &TrailList{}
+	if err := c.get(ctx, trails, "trails", query); err != nil {
+		return nil, err
+	}
+	return trails, nil
+}
+
+// GetTrail returns the trail called name of flow.
+func (c *Client) GetTrail(ctx context.Context, flow, name string) (*Trail, error) {
+	trail := &Trail{}
+	if err := c.get(ctx, trail, "trails", nil, flow, name); err != nil {
+		return nil, err
+	}
+	return trail, nil
+}
+
+// BeginTrail begins a trail of flow, or updates it when it exists.
+func (c *Client) BeginTrail(ctx context.Context, flow string, input TrailInput) error {
+	endpoint, err := c.endpoint("trails", nil, flow)
+	if err != nil {
+		return err
+	}
+	form := []requests.FormItem{{Type: "field", FieldName: "data_json", Content: input}}
+	if input.TemplateFile != "" {
+		form = append(form, requests.FormItem{Type: "file", FieldName: "template_file", Content: input.TemplateFile})
+	}
+	return c.do(ctx, http.MethodPut, endpoint, nil, form, nil)
+}